@@ -53,7 +53,13 @@ func main() {
 	// Dependencies
 	repo := repository.New(db.DB())
 	jwtManager := tokens.NewJWTManager(cfg.JWT)
-	services := usecases.New(repo, jwtManager)
+	services := usecases.New(repo, jwtManager, cfg)
+
+	// Планировщик периодических транзакций
+	go services.RecurringScv.Run(ctx, cfg.Recurring.Interval)
+
+	// Удаление аккаунтов, срок отмены удаления которых истёк
+	go services.DeletionScv.Run(ctx, cfg.Deletion.Interval)
 
 	// HTTP Server
 	router := api.SetupRouter(services, jwtManager, db)
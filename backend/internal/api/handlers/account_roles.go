@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountRoleHandler struct {
+	service *usecases.AccountMemberService
+}
+
+func NewAccountRoleHandler(service *usecases.AccountMemberService) *AccountRoleHandler {
+	return &AccountRoleHandler{service: service}
+}
+
+// RoleRequest представляет данные пользовательской роли счёта. Права: tx.view.any, tx.create.income,
+// tx.create.expense, tx.edit.own, tx.edit.any, reports.view, categories.manage, account.edit,
+// members.invite, members.remove, members.change_role
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required" example:"Бухгалтер"`
+	Permissions []string `json:"permissions" example:"tx.view.any,reports.view"`
+}
+
+// RoleResponse представляет роль счёта с её правами. У встроенных ролей id равен null
+type RoleResponse struct {
+	ID          *int     `json:"id" example:"3"`
+	Name        string   `json:"name" binding:"required" example:"Бухгалтер"`
+	Permissions []string `json:"permissions" binding:"required" example:"tx.view.any,reports.view"`
+}
+
+// ListRoles godoc
+// @Summary      Роли счёта
+// @Description  Возвращает встроенные роли (viewer, editor, admin, owner) и пользовательские роли счёта с их правами. Доступно всем участникам счёта.
+// @Tags         roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} RoleResponse "Роли счёта"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не участник счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/roles [get]
+func (h *AccountRoleHandler) ListRoles(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	roles, err := h.service.ListRoles(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]RoleResponse, len(roles))
+	for i := range roles {
+		response[i] = newRoleResponse(&roles[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRole godoc
+// @Summary      Создание пользовательской роли
+// @Description  Создаёт роль счёта с выбранными правами. Роль назначается участнику через PATCH /accounts/{id}/members/{user_id} с role custom и role_id. Право members.manage_admins в пользовательскую роль не входит. Название уникально в счёте без учёта регистра и не совпадает со встроенными ролями. Доступно только владельцу (Owner) счёта.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body RoleRequest true "Название и права роли"
+// @Success      201 {object} RoleResponse "Роль создана"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, недопустимое название или неизвестное право"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Создавать роли может только Owner"
+// @Failure      409 {object} ErrorResponse "Роль с таким названием уже есть в счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/roles [post]
+func (h *AccountRoleHandler) CreateRole(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), accountID, userID, req.Name, req.Permissions)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newRoleResponse(role))
+}
+
+// UpdateRole godoc
+// @Summary      Изменение пользовательской роли
+// @Description  Меняет название и права пользовательской роли счёта. Новые права сразу действуют для всех участников с этой ролью. Доступно только владельцу (Owner) счёта.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        role_id path int true "ID роли" example(3)
+// @Param        request body RoleRequest true "Название и права роли"
+// @Success      200 {object} RoleResponse "Роль изменена"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, недопустимое название или неизвестное право"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Изменять роли может только Owner"
+// @Failure      404 {object} ErrorResponse "Роль не найдена в данном счёте"
+// @Failure      409 {object} ErrorResponse "Роль с таким названием уже есть в счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/roles/{role_id} [put]
+func (h *AccountRoleHandler) UpdateRole(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	roleID, err := strconv.Atoi(c.Param("role_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), accountID, userID, roleID, req.Name, req.Permissions)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+// DeleteRole godoc
+// @Summary      Удаление пользовательской роли
+// @Description  Удаляет пользовательскую роль счёта. Роль, назначенную участникам, удалить нельзя: сначала нужно сменить им роль. Доступно только владельцу (Owner) счёта.
+// @Tags         roles
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        role_id path int true "ID роли" example(3)
+// @Success      204 "Роль удалена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Удалять роли может только Owner"
+// @Failure      404 {object} ErrorResponse "Роль не найдена в данном счёте"
+// @Failure      409 {object} ErrorResponse "Роль назначена участникам счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/roles/{role_id} [delete]
+func (h *AccountRoleHandler) DeleteRole(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	roleID, err := strconv.Atoi(c.Param("role_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	if err := h.service.DeleteRole(c.Request.Context(), accountID, userID, roleID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *AccountRoleHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case err == usecases.ErrInvalidAccountRole:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err == usecases.ErrAccountRoleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == usecases.ErrAccountRoleExists, err == usecases.ErrAccountRoleInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func newRoleResponse(role *models.AccountRole) RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Permissions: permissions,
+	}
+}
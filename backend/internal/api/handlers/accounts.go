@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository/query"
 	"microservices/accounter/internal/usecases"
 
@@ -28,24 +31,36 @@ func NewAccountHandler(
 
 // CreateAccountRequest представляет данные для создания счёта
 type CreateAccountRequest struct {
-	Name        string  `json:"name" binding:"required" example:"Семейный бюджет"`
-	Description *string `json:"description" example:"Общий счёт для домашних расходов"`
+	Name           string        `json:"name" binding:"required" example:"Семейный бюджет"`
+	Description    *string       `json:"description" example:"Общий счёт для домашних расходов"`
+	Currency       *string       `json:"currency" example:"RUB"`
+	OpeningBalance *money.Amount `json:"opening_balance" swaggertype:"string" example:"15000.00"`
+}
+
+// OpeningBalanceRequest представляет новый начальный остаток счёта
+type OpeningBalanceRequest struct {
+	OpeningBalance *money.Amount `json:"opening_balance" binding:"required" swaggertype:"string" example:"15000.00"`
 }
 
 // AccountResponse представляет информацию о счёте
 type AccountResponse struct {
-	ID          int32   `json:"id" binding:"required" example:"1"`
-	OwnerID     int32   `json:"owner_id" binding:"required" example:"42"`
-	Name        string  `json:"name" binding:"required" example:"Семейный бюджет"`
-	Description *string `json:"description" example:"Общий счёт для домашних расходов"`
+	ID             int32   `json:"id" binding:"required" example:"1"`
+	OwnerID        int32   `json:"owner_id" binding:"required" example:"42"`
+	Name           string  `json:"name" binding:"required" example:"Семейный бюджет"`
+	Description    *string `json:"description" example:"Общий счёт для домашних расходов"`
+	Currency       string  `json:"currency" binding:"required" example:"RUB"`
+	OpeningBalance string  `json:"opening_balance" binding:"required" example:"15000.00"`
 }
 
-// Account модель счёта
+// Account модель счёта. Для роли custom role_id и role_name - пользовательская роль счёта
 type AccountRoleResponse struct {
 	ID          int32   `json:"id" binding:"required" example:"1"`
 	Name        string  `json:"name" binding:"required" example:"Основной счёт"`
 	Description *string `json:"description" example:"Общий счёт для домашних расходов"`
-	Role        string  `json:"role" binding:"required,oneof=viewer editor admin owner" enums:"viewer,editor,admin,owner" example:"editor"`
+	Currency    string  `json:"currency" binding:"required" example:"RUB"`
+	Role        string  `json:"role" binding:"required,oneof=viewer editor admin owner custom" enums:"viewer,editor,admin,owner,custom" example:"editor"`
+	RoleID      *int    `json:"role_id" example:"3"`
+	RoleName    *string `json:"role_name" example:"Бухгалтер"`
 }
 
 // InviteMemberRequest представляет данные для приглашения участника
@@ -54,16 +69,20 @@ type InviteMemberRequest struct {
 	Role  string `json:"role" binding:"required,oneof=viewer editor admin" enums:"viewer,editor,admin" example:"editor"`
 }
 
-// MemberResponse модель участника счёта
+// MemberResponse модель участника счёта. Для роли custom role_id и role_name - пользовательская роль счёта
 type MemberResponse struct {
-	UserID int32  `json:"user_id" binding:"required,user_id" example:"2"`
-	Email  string `json:"email" binding:"required,email" example:"newmember@example.com"`
-	Role   string `json:"role" binding:"required,oneof=viewer editor admin owner" enums:"viewer,editor,admin,owner" example:"editor"`
+	UserID   int32   `json:"user_id" binding:"required,user_id" example:"2"`
+	Email    string  `json:"email" binding:"required,email" example:"newmember@example.com"`
+	Role     string  `json:"role" binding:"required,oneof=viewer editor admin owner custom" enums:"viewer,editor,admin,owner,custom" example:"editor"`
+	RoleID   *int    `json:"role_id" example:"3"`
+	RoleName *string `json:"role_name" example:"Бухгалтер"`
 }
 
-// ChangeRoleRequest представляет данные для изменения роли участника
+// ChangeRoleRequest представляет данные для изменения роли участника.
+// role_id - пользовательская роль счёта, задаётся только вместе с role custom
 type ChangeRoleRequest struct {
-	Role string `json:"role" binding:"required,oneof=viewer editor admin" enums:"viewer,editor,admin" example:"admin"`
+	Role   string `json:"role" binding:"required,oneof=viewer editor admin custom" enums:"viewer,editor,admin,custom" example:"admin"`
+	RoleID *int   `json:"role_id" example:"3"`
 }
 
 // IDResponse представляет ответ с ID созданной сущности
@@ -73,14 +92,14 @@ type IDResponse struct {
 
 // CreateAccount godoc
 // @Summary      Создание нового счёта
-// @Description  Создаёт новый счёт для управления финансами. Создатель счёта автоматически получает роль Owner и может приглашать других участников, управлять их ролями и удалять счёт. Название счёта должно быть уникальным в рамках пользователя. Описание опционально.
+// @Description  Создаёт новый счёт для управления финансами. Создатель счёта автоматически получает роль Owner и может приглашать других участников, управлять их ролями и удалять счёт. Название счёта должно быть уникальным в рамках пользователя. Описание опционально. Валюта счёта задаётся кодом ISO 4217 (RUB, USD, EUR, JPY, ...) при создании и не меняется, по умолчанию RUB. Все транзакции счёта записываются в его валюте. Начальный остаток (opening_balance) - сумма на счёте до первой транзакции, по умолчанию 0. В новом счёте создаётся стандартный набор категорий (Еда > Продукты, Транспорт > Такси и т.д.), который можно изменить через /accounts/{id}/categories.
 // @Tags         accounts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        request body CreateAccountRequest true "Данные нового счёта. Название обязательно, описание опционально."
+// @Param        request body CreateAccountRequest true "Данные нового счёта. Название обязательно, описание, валюта и начальный остаток опциональны."
 // @Success      201 {object} IDResponse "Счёт успешно создан. Возвращается ID нового счёта."
-// @Failure      400 {object} ErrorResponse "Неверный формат данных. Проверьте наличие названия счёта."
+// @Failure      400 {object} ErrorResponse "Неверный формат данных. Проверьте наличие названия счёта, код валюты и формат начального остатка."
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при создании счёта"
 // @Router       /accounts [post]
@@ -93,13 +112,32 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 		return
 	}
 
-	accountID, err := h.accountService.CreateAccount(
-		c.Request.Context(),
-		userID,
-		req.Name,
-		req.Description,
-	)
+	currency := money.DefaultCurrency
+	if req.Currency != nil && *req.Currency != "" {
+		parsed, err := money.ParseCurrency(*req.Currency)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		currency = parsed
+	}
+
+	params := &models.CreateAccountParams{
+		OwnerID:     userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Currency:    currency,
+	}
+	if req.OpeningBalance != nil {
+		params.OpeningBalance = *req.OpeningBalance
+	}
+
+	accountID, err := h.accountService.CreateAccount(c.Request.Context(), params)
 	if err != nil {
+		if err == usecases.ErrAmountPrecision {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
@@ -147,13 +185,61 @@ func (h *AccountHandler) GetAccount(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, AccountResponse{
-		ID:          account.ID,
-		OwnerID:     account.OwnerID,
-		Name:        account.Name,
-		Description: convertNullString(account.Description),
+		ID:             account.ID,
+		OwnerID:        account.OwnerID,
+		Name:           account.Name,
+		Description:    convertNullString(account.Description),
+		Currency:       string(account.Currency),
+		OpeningBalance: account.OpeningBalance.Format(account.Currency),
 	})
 }
 
+// SetOpeningBalance godoc
+// @Summary      Изменение начального остатка счёта
+// @Description  Задаёт сумму на счёте до первой транзакции. От неё отсчитываются остаток (GET /accounts/{id}/balance) и остаток после каждой транзакции в списке. Доступно Owner и Admin.
+// @Tags         accounts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body OpeningBalanceRequest true "Начальный остаток в валюте счёта"
+// @Success      200 {object} MessageResponse "Начальный остаток изменён"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта или суммы"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Нужно право account.edit (есть у Owner и Admin)"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/opening-balance [put]
+func (h *AccountHandler) SetOpeningBalance(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req OpeningBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.accountService.SetOpeningBalance(c.Request.Context(), accountID, userID, *req.OpeningBalance)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err == usecases.ErrAmountPrecision:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "opening balance updated"})
+}
+
 // ListUserAccounts godoc
 // @Summary      Получение списка счетов пользователя
 // @Description  Возвращает все счета, к которым пользователь имеет доступ (включая счета, где пользователь является участником). Список включает как собственные счета (роль Owner), так и счета, к которым пользователь был приглашён (роли Participant, Viewer и т.д.)
@@ -184,7 +270,10 @@ func (h *AccountHandler) ListUserAccounts(c *gin.Context) {
 			ID:          acc.ID,
 			Name:        acc.Name,
 			Description: convertNullString(acc.Description),
+			Currency:    string(acc.Currency),
 			Role:        string(acc.Role),
+			RoleID:      convertNullInt32(acc.RoleID),
+			RoleName:    convertNullString(acc.RoleName),
 		})
 	}
 
@@ -193,7 +282,7 @@ func (h *AccountHandler) ListUserAccounts(c *gin.Context) {
 
 // ListAccountMembers godoc
 // @Summary      Получение списка участников счёта
-// @Description  Возвращает список пользователей с доступом к счёту и их ролями
+// @Description  Возвращает список пользователей с доступом к счёту и их ролями. У участников с пользовательской ролью (custom) возвращаются её ID и название
 // @Tags         accounts
 // @Security     BearerAuth
 // @Produce      json
@@ -231,9 +320,11 @@ func (h *AccountHandler) ListAccountMembers(c *gin.Context) {
 	resp := make([]MemberResponse, 0, len(members))
 	for _, m := range members {
 		resp = append(resp, MemberResponse{
-			UserID: m.UserID,
-			Email:  m.Email,
-			Role:   string(m.Role),
+			UserID:   m.UserID,
+			Email:    m.Email,
+			Role:     string(m.Role),
+			RoleID:   convertNullInt32(m.RoleID),
+			RoleName: convertNullString(m.RoleName),
 		})
 	}
 
@@ -289,19 +380,19 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 
 // InviteMember godoc
 // @Summary      Приглашение участника в счёт
-// @Description  Добавляет нового участника в счёт с указанной ролью. Доступно только владельцу счёта (Owner). Приглашаемый пользователь должен быть зарегистрирован в системе. Роли: viewer (только просмотр), editor (создание/редактирование своих транзакций), admin (полные права на транзакции).
+// @Description  Создаёт приглашение в счёт с указанной ролью. Доступно владельцу (Owner) и администраторам (Admin) счёта; приглашать с ролью admin может только Owner. Роль не может давать прав, которых нет у приглашающего. Участником приглашённый становится, только приняв приглашение через POST /invitations/{token}/accept; до этого приглашение можно отозвать. Приглашение действует INVITATION_TTL (по умолчанию 7 дней). Email может быть ещё не зарегистрирован: приглашение появится у пользователя после регистрации с этим email. Роли: viewer (только просмотр), editor (создание/редактирование своих транзакций), admin (полные права на транзакции).
 // @Tags         members
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "ID счёта" example(1)
 // @Param        request body InviteMemberRequest true "Email пользователя и его роль в счёте"
-// @Success      201 {object} MessageResponse "Участник успешно добавлен в счёт"
+// @Success      201 {object} InvitationResponse "Приглашение создано"
 // @Failure      400 {object} ErrorResponse "Неверный формат данных или неподдерживаемая роль"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Приглашать участников может только Owner"
-// @Failure      404 {object} ErrorResponse "Пользователь с указанным email не найден в системе"
-// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при добавлении участника"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Приглашать могут Owner и Admin, с ролью admin - только Owner. В ошибке указано недостающее право"
+// @Failure      409 {object} ErrorResponse "Пользователь уже участник счёта или у email уже есть приглашение, ожидающее ответа"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при создании приглашения"
 // @Router       /accounts/{id}/members [post]
 func (h *AccountHandler) InviteMember(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -322,34 +413,32 @@ func (h *AccountHandler) InviteMember(c *gin.Context) {
 		return
 	}
 
-	role := parseRole(req.Role)
-
-	err = h.memberService.Invite(
+	invitation, err := h.memberService.Invite(
 		c.Request.Context(),
 		accountID,
 		userID.(int),
 		req.Email,
-		role,
+		query.InvitationsRole(parseRole(req.Role)),
 	)
 	if err != nil {
-		if err == usecases.ErrForbidden {
+		if errors.Is(err, usecases.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
-		if err == usecases.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		if err == usecases.ErrAlreadyMember || err == usecases.ErrInvitationExists {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "member invited"})
+	c.JSON(http.StatusCreated, newInvitationResponse(invitation))
 }
 
 // ChangeRole godoc
 // @Summary      Изменение роли участника
-// @Description  Изменяет роль существующего участника счёта. Доступно только владельцу счёта (Owner). Нельзя изменить роль самого владельца. Роли: viewer (только просмотр), editor (создание/редактирование своих транзакций), admin (полные права на транзакции). Изменение роли применяется немедленно.
+// @Description  Изменяет роль существующего участника счёта. Доступно владельцу (Owner) и администраторам (Admin) счёта: Admin меняет роль только между viewer и editor, назначать и снимать admin и пользовательские роли может только Owner. Новая роль не может давать прав, которых нет у меняющего. Нельзя изменить роль самого владельца. Роли: viewer (только просмотр), editor (создание/редактирование своих транзакций), admin (полные права на транзакции), custom (пользовательская роль счёта role_id из GET /accounts/{id}/roles). Изменение роли применяется немедленно.
 // @Tags         members
 // @Accept       json
 // @Produce      json
@@ -358,10 +447,11 @@ func (h *AccountHandler) InviteMember(c *gin.Context) {
 // @Param        user_id path int true "ID пользователя, чью роль нужно изменить" example(42)
 // @Param        request body ChangeRoleRequest true "Новая роль участника"
 // @Success      200 {object} MessageResponse "Роль участника успешно изменена"
-// @Failure      400 {object} ErrorResponse "Неверный формат данных или неподдерживаемая роль"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, неподдерживаемая роль или role_id без роли custom"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Изменять роли может только Owner"
-// @Failure      404 {object} ErrorResponse "Участник не найден в данном счёте"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Изменять роли могут Owner и Admin, роль admin и пользовательские роли - только Owner. В ошибке указано недостающее право"
+// @Failure      404 {object} ErrorResponse "Участник или пользовательская роль не найдены в данном счёте"
+// @Failure      409 {object} ErrorResponse "Роль владельца меняется только передачей счёта (POST /accounts/{id}/transfer-ownership)"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при изменении роли"
 // @Router       /accounts/{id}/members/{user_id} [patch]
 func (h *AccountHandler) ChangeRole(c *gin.Context) {
@@ -393,12 +483,25 @@ func (h *AccountHandler) ChangeRole(c *gin.Context) {
 		ownerID,
 		memberUserID,
 		role,
+		req.RoleID,
 	)
 	if err != nil {
-		if err == usecases.ErrForbidden {
+		if errors.Is(err, usecases.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if err == usecases.ErrInvalidRoleID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == usecases.ErrMemberNotFound || err == usecases.ErrAccountRoleNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == usecases.ErrOwnerMembership {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
@@ -408,7 +511,7 @@ func (h *AccountHandler) ChangeRole(c *gin.Context) {
 
 // RemoveMember godoc
 // @Summary      Удаление участника из счёта
-// @Description  Удаляет участника из счёта, лишая его доступа ко всем данным счёта. Доступно только владельцу счёта (Owner). Нельзя удалить самого владельца. После удаления участник теряет доступ к просмотру и редактированию транзакций. Созданные им транзакции остаются в счёте.
+// @Description  Удаляет участника из счёта, лишая его доступа ко всем данным счёта. Доступно владельцу (Owner) и администраторам (Admin) счёта; удалять admin может только Owner. Нельзя удалить самого владельца. После удаления участник теряет доступ к просмотру и редактированию транзакций. Созданные им транзакции остаются в счёте.
 // @Tags         members
 // @Security     BearerAuth
 // @Param        id path int true "ID счёта" example(1)
@@ -416,8 +519,9 @@ func (h *AccountHandler) ChangeRole(c *gin.Context) {
 // @Success      204 "Участник успешно удалён из счёта"
 // @Failure      400 {object} ErrorResponse "Неверный формат ID счёта или пользователя"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Удалять участников может только Owner"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Удалять участников могут Owner и Admin, admin - только Owner. В ошибке указано недостающее право"
 // @Failure      404 {object} ErrorResponse "Участник не найден в данном счёте"
+// @Failure      409 {object} ErrorResponse "Владельца нельзя удалить из счёта, сначала передайте счёт"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при удалении участника"
 // @Router       /accounts/{id}/members/{user_id} [delete]
 func (h *AccountHandler) RemoveMember(c *gin.Context) {
@@ -446,10 +550,18 @@ func (h *AccountHandler) RemoveMember(c *gin.Context) {
 		memberUserID,
 	)
 	if err != nil {
-		if err == usecases.ErrForbidden {
+		if errors.Is(err, usecases.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if err == usecases.ErrMemberNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == usecases.ErrOwnerMembership {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
@@ -465,11 +577,22 @@ func parseRole(role string) query.AccountMembersRole {
 		return query.AccountMembersRoleEditor
 	case "admin":
 		return query.AccountMembersRoleAdmin
+	case "custom":
+		return query.AccountMembersRoleCustom
 	default:
 		return query.AccountMembersRoleViewer
 	}
 }
 
+func convertNullInt32(n sql.NullInt32) *int {
+	if !n.Valid {
+		return nil
+	}
+
+	value := int(n.Int32)
+	return &value
+}
+
 func convertNullString(ns sql.NullString) *string {
 	if !ns.Valid {
 		return nil
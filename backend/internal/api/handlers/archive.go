@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"microservices/accounter/internal/archive"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxArchiveFileSize - ограничение размера загружаемого архива
+const maxArchiveFileSize = 64 << 20
+
+type ArchiveHandler struct {
+	service *usecases.ArchiveService
+}
+
+func NewArchiveHandler(service *usecases.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: service}
+}
+
+// ArchiveImportedAccountResponse представляет счёт, созданный из архива
+type ArchiveImportedAccountResponse struct {
+	SourceID int    `json:"source_id" binding:"required" example:"3"`
+	ID       int    `json:"id" binding:"required" example:"17"`
+	Name     string `json:"name" binding:"required" example:"Семейный бюджет (2)"`
+}
+
+// ArchiveInvitationResponse представляет приглашение участника архива в созданный счёт
+type ArchiveInvitationResponse struct {
+	AccountID int    `json:"account_id" binding:"required" example:"17"`
+	Email     string `json:"email" binding:"required" example:"anna@example.com"`
+	Role      string `json:"role" binding:"required" enums:"viewer,editor,admin" example:"editor"`
+}
+
+// ArchiveConflictResponse представляет запись архива, перенесённую не как есть
+type ArchiveConflictResponse struct {
+	Kind    string `json:"kind" binding:"required" enums:"account_name,member_role,author_not_found,exchange_rate" example:"member_role"`
+	Subject string `json:"subject" binding:"required" example:"anna@example.com"`
+	Message string `json:"message" binding:"required" example:"custom role \"Бухгалтер\" is not granted by invitation, invited to account \"Семейный бюджет\" as viewer"`
+}
+
+// ArchiveImportResponse представляет результат импорта архива
+type ArchiveImportResponse struct {
+	Accounts       []ArchiveImportedAccountResponse `json:"accounts" binding:"required"`
+	Categories     int                              `json:"categories" binding:"required" example:"12"`
+	RecurringRules int                              `json:"recurring_rules" binding:"required" example:"2"`
+	Transactions   int                              `json:"transactions" binding:"required" example:"340"`
+	ExchangeRates  int                              `json:"exchange_rates" binding:"required" example:"30"`
+	Invitations    []ArchiveInvitationResponse      `json:"invitations" binding:"required"`
+	Conflicts      []ArchiveConflictResponse        `json:"conflicts" binding:"required"`
+}
+
+// ExportArchive godoc
+// @Summary      Архив всех данных пользователя
+// @Description  Возвращает переносимый архив данных текущего пользователя для резервной копии или переноса на другой сервер: все счета, владельцем которых он является, с участниками (email и роль), категориями, правилами повторения (вместе с состоянием серии), транзакциями (включая метки, примечания, external_ref и уже созданные вхождения серий) и парами транзакций, отмеченными как разные операции, а также сохранённые курсы валют пользователя. Счета, в которых пользователь только участник, в архив не входят. Авторы записей указаны по email. Архив - JSON с полями format ("accounter-archive") и version; format=zip возвращает ZIP с одним файлом archive.json. Загрузить архив можно через POST /auth/import.
+// @Tags         archive
+// @Produce      application/zip,json
+// @Security     BearerAuth
+// @Param        format query string false "Формат файла (по умолчанию zip)" Enums(zip, json)
+// @Success      200 {file} file "Архив: accounter-<дата>.zip или accounter-<дата>.json"
+// @Failure      400 {object} ErrorResponse "Неизвестный формат"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/export [get]
+func (h *ArchiveHandler) ExportArchive(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	format := c.DefaultQuery("format", archive.FileZIP)
+
+	// Архив собирается в памяти: при ошибке клиент получает её, а не обрезанный файл
+	var file bytes.Buffer
+	if err := h.service.Export(c.Request.Context(), userID, format, &file); err != nil {
+		if err == usecases.ErrUnsupportedArchiveFormat {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	filename := "accounter-" + time.Now().UTC().Format("2006-01-02") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, archive.ContentType(format), file.Bytes())
+}
+
+// ImportArchive godoc
+// @Summary      Загрузка архива данных
+// @Description  Создаёт счета из архива, полученного через GET /auth/export (в том числе на другом сервере), и сохраняет курсы валют из него. Файл (ZIP или JSON, до 64 МБ) передаётся полем file формы multipart/form-data, формат определяется по содержимому. Владельцем всех созданных счетов становится текущий пользователь, записи владельца архива переходят к нему. Все записи получают новые ID, ссылки между ними (категории, правила повторения, родительские категории) пересчитываются; соответствие старых и новых ID счетов возвращается в accounts. Архив загружается целиком в одной транзакции БД: если хотя бы одна запись некорректна, не создаётся ничего и возвращается 400 с причиной. Участники счетов архива не добавляются в счёт сразу: каждый получает приглашение в созданный счёт с той же ролью и обычным сроком действия и становится участником, только приняв его (незарегистрированные - после регистрации на этом сервере). Созданные приглашения перечисляются в invitations. Конфликты не прерывают загрузку и перечисляются в conflicts: account_name - счёт с таким названием уже есть, созданный счёт переименован ("Название (2)"); member_role - у участника пользовательская роль, которую приглашение не выдаёт, он приглашён с ролью viewer; author_not_found - автор записей не участник созданного счёта, его записи переданы текущему пользователю; exchange_rate - курс на ту же дату уже сохранён с другим значением и оставлен без изменений. Повторная загрузка того же архива создаёт счета заново.
+// @Tags         archive
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file true "Файл архива"
+// @Success      201 {object} ArchiveImportResponse "Архив загружен"
+// @Failure      400 {object} ErrorResponse "Файл не является архивом, версия архива не поддерживается или запись архива некорректна. Сообщение содержит причину"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      413 {object} ErrorResponse "Файл больше 64 МБ"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/import [post]
+func (h *ArchiveHandler) ImportArchive(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveFileSize)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(c.Request.Context(), userID, file)
+	if err != nil {
+		switch {
+		case err == usecases.ErrUnsupportedArchiveVersion,
+			errors.Is(err, usecases.ErrInvalidArchive):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	response := ArchiveImportResponse{
+		Accounts:       make([]ArchiveImportedAccountResponse, len(result.Accounts)),
+		Categories:     result.Categories,
+		RecurringRules: result.RecurringRules,
+		Transactions:   result.Transactions,
+		ExchangeRates:  result.ExchangeRates,
+		Invitations:    make([]ArchiveInvitationResponse, len(result.Invitations)),
+		Conflicts:      make([]ArchiveConflictResponse, len(result.Conflicts)),
+	}
+
+	for i, a := range result.Accounts {
+		response.Accounts[i] = ArchiveImportedAccountResponse{SourceID: a.SourceID, ID: a.ID, Name: a.Name}
+	}
+
+	for i, invitation := range result.Invitations {
+		response.Invitations[i] = ArchiveInvitationResponse{
+			AccountID: invitation.AccountID,
+			Email:     invitation.Email,
+			Role:      string(invitation.Role),
+		}
+	}
+
+	for i, conflict := range result.Conflicts {
+		response.Conflicts[i] = ArchiveConflictResponse{
+			Kind:    conflict.Kind,
+			Subject: conflict.Subject,
+			Message: conflict.Message,
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	service *usecases.CategoryService
+}
+
+func NewCategoryHandler(service *usecases.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+// CategoryRequest представляет данные категории
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required" example:"Продукты"`
+	ParentID *int   `json:"parent_id" example:"1"`
+}
+
+// CategoryResponse представляет категорию с подкатегориями
+type CategoryResponse struct {
+	ID       int32              `json:"id" binding:"required" example:"2"`
+	ParentID *int32             `json:"parent_id" example:"1"`
+	Name     string             `json:"name" binding:"required" example:"Продукты"`
+	Children []CategoryResponse `json:"children" binding:"required"`
+}
+
+// CreateCategory godoc
+// @Summary      Создание категории
+// @Description  Создаёт категорию в счёте. Если указан parent_id, категория становится подкатегорией (например, Еда > Продукты). Вложенность - не более 5 уровней, названия подкатегорий одного родителя не повторяются (без учёта регистра). Доступно участникам с правом categories.manage (Editor и выше). При создании счёта в нём создаётся стандартный набор категорий.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body CategoryRequest true "Название и родительская категория"
+// @Success      201 {object} IDResponse "Категория создана"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, родительская категория не найдена или превышена вложенность"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Управлять категориями могут участники с правом categories.manage (Editor, Admin и Owner)"
+// @Failure      409 {object} ErrorResponse "Категория с таким названием уже есть у этого родителя"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryID, err := h.service.Create(c.Request.Context(), &models.CreateCategoryParams{
+		AccountID: accountID,
+		UserID:    userID,
+		ParentID:  req.ParentID,
+		Name:      req.Name,
+	})
+	if err != nil {
+		// Родитель из запроса, а не ресурс из пути
+		if err == usecases.ErrCategoryNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent category not found"})
+			return
+		}
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": categoryID})
+}
+
+// ListCategories godoc
+// @Summary      Дерево категорий счёта
+// @Description  Возвращает категории счёта деревом: категории верхнего уровня с вложенными подкатегориями, по алфавиту. Доступно всем участникам счёта.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} CategoryResponse "Дерево категорий"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/categories [get]
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	tree, err := h.service.Tree(c.Request.Context(), accountID, userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCategoryResponses(tree))
+}
+
+// UpdateCategory godoc
+// @Summary      Изменение категории
+// @Description  Переименовывает категорию и/или переносит её вместе с подкатегориями к другому родителю. Без parent_id категория становится категорией верхнего уровня. Категорию нельзя перенести в неё саму или в её подкатегорию.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        category_id path int true "ID категории" example(2)
+// @Param        request body CategoryRequest true "Новое название и родительская категория"
+// @Success      200 {object} MessageResponse "Категория изменена"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, перенос в собственную подкатегорию или превышена вложенность"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Категория или родительская категория не найдена в счёте"
+// @Failure      409 {object} ErrorResponse "Категория с таким названием уже есть у этого родителя"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/categories/{category_id} [patch]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	categoryID, err := strconv.Atoi(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.service.Update(c.Request.Context(), accountID, categoryID, userID, &models.UpdateCategoryParams{
+		ParentID: req.ParentID,
+		Name:     req.Name,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category updated successfully"})
+}
+
+// DeleteCategory godoc
+// @Summary      Удаление категории
+// @Description  Удаляет категорию вместе со всеми подкатегориями. Транзакции и периодические серии этих категорий не удаляются, а остаются без категории.
+// @Tags         categories
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        category_id path int true "ID категории" example(2)
+// @Success      204 "Категория удалена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Категория не найдена в счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/categories/{category_id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	categoryID, err := strconv.Atoi(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), accountID, categoryID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *CategoryHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err == usecases.ErrCategoryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == usecases.ErrCategoryExists:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err == usecases.ErrInvalidCategoryName || err == usecases.ErrCategoryCycle || err == usecases.ErrCategoryDepth:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func newCategoryResponses(nodes []models.CategoryNode) []CategoryResponse {
+	response := make([]CategoryResponse, len(nodes))
+	for i, n := range nodes {
+		response[i] = CategoryResponse{
+			ID:       n.ID,
+			Name:     n.Name,
+			Children: newCategoryResponses(n.Children),
+		}
+
+		if n.ParentID.Valid {
+			response[i].ParentID = &n.ParentID.Int32
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DuplicateHandler struct {
+	service *usecases.DuplicateService
+}
+
+func NewDuplicateHandler(service *usecases.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{service: service}
+}
+
+// DuplicatePairResponse представляет пару вероятных дубликатов: duplicate создана позже transaction
+type DuplicatePairResponse struct {
+	Transaction TransactionResponse `json:"transaction" binding:"required"`
+	Duplicate   TransactionResponse `json:"duplicate" binding:"required"`
+}
+
+// MergeDuplicatesRequest представляет объединение дубликатов
+type MergeDuplicatesRequest struct {
+	KeepID   int `json:"keep_id" binding:"required" example:"118"`
+	RemoveID int `json:"remove_id" binding:"required" example:"123"`
+}
+
+// DismissDuplicateRequest представляет пару транзакций, которые не являются дубликатами
+type DismissDuplicateRequest struct {
+	TransactionID int `json:"transaction_id" binding:"required" example:"118"`
+	DuplicateID   int `json:"duplicate_id" binding:"required" example:"123"`
+}
+
+// ListDuplicates godoc
+// @Summary      Вероятные дубликаты транзакций
+// @Description  Возвращает пары транзакций счёта, похожих на одну и ту же операцию: например, выписка импортирована дважды или транзакция введена вручную после импорта. Дубликаты - транзакции с одинаковыми суммой и валютой, даты которых отличаются не больше чем на 3 дня, а слова одного названия входят в другое (без учёта регистра, знаков препинания и разницы между е и ё). Транзакции с разными идентификаторами выписки (external_ref) дубликатами не считаются, вхождения периодических серий не учитываются. Пары, отмеченные как разные операции (см. /accounts/{id}/duplicates/dismiss), не возвращаются. Возвращается не больше 100 пар, новые первыми; после объединения или отметки пар список можно запросить снова. Доступно участникам с правом tx.view.any (всем встроенным ролям).
+// @Tags         duplicates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} DuplicatePairResponse "Пары вероятных дубликатов"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/duplicates [get]
+func (h *DuplicateHandler) ListDuplicates(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	pairs, err := h.service.List(c.Request.Context(), accountID, userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]DuplicatePairResponse, len(pairs))
+	for i := range pairs {
+		response[i] = DuplicatePairResponse{
+			Transaction: newTransactionResponse(&pairs[i].Transaction),
+			Duplicate:   newTransactionResponse(&pairs[i].Duplicate),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MergeDuplicates godoc
+// @Summary      Объединение дубликатов
+// @Description  Удаляет транзакцию remove_id как дубликат keep_id. Метки удаляемой транзакции добавляются к оставшейся, а категория, примечание и идентификатор выписки переносятся, если у оставшейся их нет: так при повторном импорте выписки удалённая транзакция не появится снова. Обе транзакции должны принадлежать счёту. С правом tx.edit.own (Editor) можно объединять только свои транзакции, с tx.edit.any (Admin и Owner) - любые. Операция необратима.
+// @Tags         duplicates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body MergeDuplicatesRequest true "Оставляемая и удаляемая транзакции"
+// @Success      200 {object} MessageResponse "Дубликаты объединены"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных или keep_id совпадает с remove_id"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может объединять только свои транзакции, Viewer - никакие. В ошибке указано недостающее право"
+// @Failure      404 {object} ErrorResponse "Транзакция не найдена в счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/duplicates/merge [post]
+func (h *DuplicateHandler) MergeDuplicates(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req MergeDuplicatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Merge(c.Request.Context(), accountID, userID, req.KeepID, req.RemoveID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "duplicates merged"})
+}
+
+// DismissDuplicate godoc
+// @Summary      Отметка «не дубликат»
+// @Description  Отмечает две похожие транзакции как разные операции (например, две одинаковые покупки в один день): пара больше не возвращается в списке дубликатов. Повторная отметка ничего не меняет. Обе транзакции должны принадлежать счёту. С правом tx.edit.own (Editor) можно отмечать только свои транзакции, с tx.edit.any (Admin и Owner) - любые.
+// @Tags         duplicates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body DismissDuplicateRequest true "Пара транзакций"
+// @Success      200 {object} MessageResponse "Пара отмечена как разные операции"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных или transaction_id совпадает с duplicate_id"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может отмечать только свои транзакции, Viewer - никакие. В ошибке указано недостающее право"
+// @Failure      404 {object} ErrorResponse "Транзакция не найдена в счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/duplicates/dismiss [post]
+func (h *DuplicateHandler) DismissDuplicate(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req DismissDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Dismiss(c.Request.Context(), accountID, userID, req.TransactionID, req.DuplicateID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "duplicate dismissed"})
+}
+
+func (h *DuplicateHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err == usecases.ErrTransactionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == usecases.ErrSameTransaction:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
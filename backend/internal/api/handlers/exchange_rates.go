@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRatesFileSize - ограничение размера импортируемого файла с курсами
+const maxRatesFileSize = 5 << 20
+
+type ExchangeRateHandler struct {
+	service *usecases.ExchangeRateService
+}
+
+func NewExchangeRateHandler(service *usecases.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+// ExchangeRateRequest представляет курс валюты на дату
+type ExchangeRateRequest struct {
+	Base  string `json:"base" binding:"required" example:"USD"`
+	Quote string `json:"quote" binding:"required" example:"RUB"`
+	Date  string `json:"date" binding:"required" example:"2024-12-13"`
+	Rate  string `json:"rate" binding:"required" example:"100.3582"`
+}
+
+// ExchangeRateResponse представляет сохранённый курс: одна единица base стоит rate единиц quote
+type ExchangeRateResponse struct {
+	ID    int32  `json:"id" binding:"required" example:"15"`
+	Base  string `json:"base" binding:"required" example:"USD"`
+	Quote string `json:"quote" binding:"required" example:"RUB"`
+	Date  string `json:"date" binding:"required" example:"2024-12-13"`
+	Rate  string `json:"rate" binding:"required" example:"100.35820000"`
+}
+
+// ImportRatesResponse представляет результат импорта курсов
+type ImportRatesResponse struct {
+	Imported int `json:"imported" binding:"required" example:"43"`
+}
+
+// SaveExchangeRate godoc
+// @Summary      Сохранение курса валюты
+// @Description  Сохраняет курс на дату: одна единица base стоит rate единиц quote. Курс той же пары на ту же дату заменяется. Курсы личные: они используются только для пересчёта итогов этого пользователя. Для пересчёта суммы берётся последний курс на дату транзакции или раньше; если прямого курса нет, используется обратный или кросс-курс через третью валюту.
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ExchangeRateRequest true "Курс. Дата в формате YYYY-MM-DD, курс - положительное число с не более чем 8 знаками после точки"
+// @Success      200 {object} MessageResponse "Курс сохранён"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных: код валюты, дата или курс"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /exchange-rates [put]
+func (h *ExchangeRateHandler) SaveExchangeRate(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	base, err := money.ParseCurrency(req.Base)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := money.ParseCurrency(req.Quote)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	rate, err := money.ParseRate(req.Rate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.service.Save(c.Request.Context(), userID, &models.ExchangeRate{
+		Base:  base,
+		Quote: quote,
+		Date:  date,
+		Rate:  rate,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "exchange rate saved"})
+}
+
+// ListExchangeRates godoc
+// @Summary      Список курсов валют
+// @Description  Возвращает курсы пользователя, новые первыми. Фильтры base, quote, date_from и date_to опциональны.
+// @Tags         exchange-rates
+// @Produce      json
+// @Security     BearerAuth
+// @Param        base query string false "Базовая валюта" example(USD)
+// @Param        quote query string false "Валюта котировки" example(RUB)
+// @Param        date_from query string false "Начальная дата (YYYY-MM-DD)" example(2024-12-01)
+// @Param        date_to query string false "Конечная дата (YYYY-MM-DD)" example(2024-12-31)
+// @Success      200 {array} ExchangeRateResponse "Список курсов"
+// @Failure      400 {object} ErrorResponse "Неверный формат фильтров"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /exchange-rates [get]
+func (h *ExchangeRateHandler) ListExchangeRates(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	filter := &models.ListExchangeRatesFilter{UserID: userID}
+
+	if base := c.Query("base"); base != "" {
+		parsed, err := money.ParseCurrency(base)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Base = parsed
+	}
+
+	if quote := c.Query("quote"); quote != "" {
+		parsed, err := money.ParseCurrency(quote)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Quote = parsed
+	}
+
+	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, dateFromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, use YYYY-MM-DD"})
+			return
+		}
+		filter.DateFrom = &parsed
+	}
+
+	if dateToStr := c.Query("date_to"); dateToStr != "" {
+		parsed, err := time.Parse(time.DateOnly, dateToStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, use YYYY-MM-DD"})
+			return
+		}
+		filter.DateTo = &parsed
+	}
+
+	rates, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]ExchangeRateResponse, len(rates))
+	for i, r := range rates {
+		response[i] = ExchangeRateResponse{
+			ID:    r.ID,
+			Base:  string(r.BaseCurrency),
+			Quote: string(r.QuoteCurrency),
+			Date:  r.RateDate.Format(time.DateOnly),
+			Rate:  r.Rate,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteExchangeRate godoc
+// @Summary      Удаление курса валюты
+// @Description  Удаляет курс пользователя.
+// @Tags         exchange-rates
+// @Security     BearerAuth
+// @Param        id path int true "ID курса" example(15)
+// @Success      204 "Курс удалён"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID курса"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Курс не найден"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /exchange-rates/{id} [delete]
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	rateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exchange rate id"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), rateID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ImportExchangeRates godoc
+// @Summary      Импорт курсов валют из файла
+// @Description  Загружает курсы из файла и сохраняет их, заменяя курсы тех же пар на те же даты. Форматы: csv - таблица с заголовком date,base,quote,rate[,nominal] (разделитель запятая или точка с запятой, даты YYYY-MM-DD или DD.MM.YYYY, nominal - за сколько единиц base указан курс); cbr - ежедневная XML-выгрузка ЦБ РФ (https://www.cbr.ru/scripts/XML_daily.asp), курсы к рублю с учётом номинала. Файл передаётся полем file формы multipart/form-data или телом запроса. Ошибка в любой строке отменяет импорт целиком.
+// @Tags         exchange-rates
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format query string true "Формат файла" Enums(csv, cbr)
+// @Param        file formData file false "Файл с курсами"
+// @Success      200 {object} ImportRatesResponse "Число импортированных курсов"
+// @Failure      400 {object} ErrorResponse "Неверный формат или содержимое файла. Сообщение содержит номер строки с ошибкой"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      413 {object} ErrorResponse "Файл больше 5 МБ"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /exchange-rates/import [post]
+func (h *ExchangeRateHandler) ImportExchangeRates(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRatesFileSize)
+
+	file, err := uploadedFile(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.service.Import(c.Request.Context(), userID, c.Query("format"), file)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ImportRatesResponse{Imported: imported})
+}
+
+func (h *ExchangeRateHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case err == usecases.ErrExchangeRateNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == usecases.ErrInvalidExchangeRate,
+		err == usecases.ErrUnsupportedRatesFormat,
+		errors.Is(err, usecases.ErrInvalidRatesFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// uploadedFile возвращает файл из поля file формы multipart/form-data или тело запроса
+func uploadedFile(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return c.Request.Body, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+
+	return header.Open()
+}
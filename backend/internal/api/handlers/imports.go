@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"microservices/accounter/internal/imports"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize - ограничение размера импортируемой выписки
+const maxImportFileSize = 10 << 20
+
+type ImportHandler struct {
+	service *usecases.ImportService
+}
+
+func NewImportHandler(service *usecases.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// ImportCSVRequest представляет описание колонок CSV-выписки. Колонка задаётся
+// именем из заголовка или номером, начиная с 1
+type ImportCSVRequest struct {
+	Delimiter        string `form:"delimiter" example:";"`
+	Encoding         string `form:"encoding" example:"windows-1251"`
+	HasHeader        *bool  `form:"has_header" example:"true"`
+	SkipRows         int    `form:"skip_rows" binding:"min=0" example:"0"`
+	DateColumn       string `form:"date_column" binding:"required" example:"Дата операции"`
+	DateFormat       string `form:"date_format" example:"DD.MM.YYYY"`
+	TitleColumn      string `form:"title_column" binding:"required" example:"Описание"`
+	NotesColumn      string `form:"notes_column" example:"Категория"`
+	AmountColumn     string `form:"amount_column" example:"Сумма операции"`
+	DebitColumn      string `form:"debit_column" example:"Расход"`
+	CreditColumn     string `form:"credit_column" example:"Приход"`
+	DecimalSeparator string `form:"decimal_separator" example:","`
+	InvertSign       bool   `form:"invert_sign" example:"false"`
+}
+
+// ImportedTransactionResponse представляет транзакцию, прочитанную из выписки
+type ImportedTransactionResponse struct {
+	Line       int       `json:"line" binding:"required" example:"2"`
+	OccurredAt time.Time `json:"occurred_at" binding:"required" example:"2024-12-13T00:00:00Z"`
+	Title      string    `json:"title" binding:"required" example:"Пятёрочка"`
+	Amount     string    `json:"amount" binding:"required" example:"-1500.50"`
+	Notes      *string   `json:"notes" example:"Супермаркеты"`
+
+	// Идентификатор транзакции в выписке (FITID в OFX, AcctSvcrRef в camt.053)
+	ExternalRef *string `json:"external_ref" example:"40817810000000000001/202412130001"`
+
+	// Транзакция уже была импортирована раньше и будет пропущена
+	AlreadyImported bool `json:"already_imported" binding:"required" example:"false"`
+
+	// ID транзакций счёта, похожих на эту: вероятно, она уже записана вручную или другой выпиской
+	PossibleDuplicates []int `json:"possible_duplicates" binding:"required" example:"118"`
+}
+
+// ImportLineErrorResponse представляет ошибку в строке выписки
+type ImportLineErrorResponse struct {
+	Line  int    `json:"line" binding:"required" example:"7"`
+	Error string `json:"error" binding:"required" example:"invalid date \"31.02.2024\", expected format DD.MM.YYYY"`
+}
+
+// ImportResponse представляет результат импорта выписки
+type ImportResponse struct {
+	DryRun       bool                          `json:"dry_run" binding:"required" example:"true"`
+	Imported     int                           `json:"imported" binding:"required" example:"0"`
+	Skipped      int                           `json:"skipped" binding:"required" example:"0"`
+	Duplicates   int                           `json:"duplicates" binding:"required" example:"1"`
+	Transactions []ImportedTransactionResponse `json:"transactions" binding:"required"`
+	Errors       []ImportLineErrorResponse     `json:"errors" binding:"required"`
+}
+
+// ImportTransactions godoc
+// @Summary      Импорт транзакций из банковской выписки
+// @Description  Загружает транзакции из выписки банка или другой программы учёта. Доступно участникам с правом tx.create.income или tx.create.expense (Editor и выше); доходы без tx.create.income и расходы без tx.create.expense возвращаются как ошибки строк. Файл передаётся полем file формы multipart/form-data (до 10 МБ, не более 10000 транзакций). Форматы: csv - таблица, колонки которой описываются полями формы (см. ниже); ofx и qfx - OFX 1.x (SGML) и 2.x (XML), включая несколько выписок в одном файле; qif - Quicken Interchange Format (разделы Bank, Cash, CCard, Oth A, Oth L; даты MM/DD/YYYY, MM/DD'YY, DD.MM.YYYY или YYYY-MM-DD); camt053 - выписка ISO 20022 camt.053, включая несколько выписок (Stmt) в одном файле: из записи берутся дата проводки, сумма с признаком CdtDbtInd, контрагент и назначение платежа, записи не в статусе BOOK пропускаются. Файлы OFX и QIF не в UTF-8 читаются как windows-1251. Если в выписке указана валюта (CURDEF в OFX, Ccy в camt.053), она должна совпадать с валютой счёта, иначе выписка отклоняется целиком. У транзакций OFX есть идентификатор FITID, у записей camt.053 - ссылка банка AcctSvcrRef: он сохраняется как external_ref, и при повторном импорте той же выписки уже загруженные транзакции пропускаются (already_imported=true, считаются в skipped). В QIF и CSV идентификаторов нет, поэтому повторный импорт создаст транзакции заново. Транзакции, похожие на уже записанные в счёт (та же сумма, даты отличаются не больше чем на 3 дня, слова одного названия входят в другое; вхождения серий не учитываются), отмечаются как вероятные дубликаты: в possible_duplicates перечисляются ID похожих транзакций, их число возвращается в duplicates. При skip_duplicates=true такие транзакции не записываются и считаются в skipped, иначе записываются, и пару можно объединить позже (см. /accounts/{id}/duplicates). Поля формы для csv: колонка задаётся именем из заголовка или номером, начиная с 1. Обязательны date_column и title_column, а сумма берётся либо из amount_column, либо из пары debit_column (списания, всегда расход) и credit_column (поступления, всегда доход). date_format составляется из YYYY, YY, MM, DD, HH, mm, ss (например DD.MM.YYYY или YYYY-MM-DD HH:mm:ss), по умолчанию YYYY-MM-DD. decimal_separator - точка или запятая; пробелы и разделитель тысяч в суммах пропускаются. invert_sign=true - в amount_column расходы записаны положительными суммами (так выгружают, например, кредитные карты). Транзакции записываются в валюте счёта. При dry_run=true ничего не записывается: ответ содержит прочитанные транзакции и ошибки по строкам для предпросмотра. Без dry_run все транзакции записываются одной транзакцией БД; если хотя бы одна строка содержит ошибку, не записывается ничего и возвращается 422 с ошибками по строкам.
+// @Tags         imports
+// @Accept       mpfd
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        format query string false "Формат выписки (по умолчанию csv)" Enums(csv, ofx, qfx, qif, camt053)
+// @Param        dry_run query bool false "Только предпросмотр, без записи" example(true)
+// @Param        skip_duplicates query bool false "Не записывать вероятные дубликаты" example(true)
+// @Param        file formData file true "Файл выписки"
+// @Param        delimiter formData string false "Разделитель колонок: запятая (по умолчанию), точка с запятой, | или tab" example(;)
+// @Param        encoding formData string false "Кодировка файла (по умолчанию utf-8)" Enums(utf-8, windows-1251)
+// @Param        has_header formData bool false "Первая строка (после skip_rows) - заголовок (по умолчанию true)"
+// @Param        skip_rows formData int false "Сколько строк пропустить в начале файла" example(0)
+// @Param        date_column formData string false "Колонка даты, обязательна для csv" example(Дата операции)
+// @Param        date_format formData string false "Формат даты (по умолчанию YYYY-MM-DD)" example(DD.MM.YYYY)
+// @Param        title_column formData string false "Колонка названия, обязательна для csv" example(Описание)
+// @Param        notes_column formData string false "Колонка примечания" example(Категория)
+// @Param        amount_column formData string false "Колонка суммы со знаком" example(Сумма операции)
+// @Param        debit_column formData string false "Колонка списаний" example(Расход)
+// @Param        credit_column formData string false "Колонка поступлений" example(Приход)
+// @Param        decimal_separator formData string false "Десятичный разделитель сумм: точка (по умолчанию) или запятая"
+// @Param        invert_sign formData bool false "Расходы в amount_column записаны положительными суммами"
+// @Success      200 {object} ImportResponse "Предпросмотр (dry_run=true)"
+// @Success      201 {object} ImportResponse "Транзакции записаны"
+// @Failure      400 {object} ErrorResponse "Неизвестный формат, неверное описание колонок, файл не читается в указанном формате или валюта выписки не совпадает с валютой счёта. Сообщение содержит причину"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Импортировать транзакции могут участники с правом на создание транзакций (Editor, Admin и Owner)"
+// @Failure      413 {object} ErrorResponse "Файл больше 10 МБ"
+// @Failure      422 {object} ImportResponse "В выписке есть строки с ошибками, ничего не записано"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/imports [post]
+func (h *ImportHandler) ImportTransactions(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := c.Query("dry_run"); dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run format"})
+			return
+		}
+	}
+
+	skipDuplicates := false
+	if skipStr := c.Query("skip_duplicates"); skipStr != "" {
+		skipDuplicates, err = strconv.ParseBool(skipStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid skip_duplicates format"})
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", usecases.ImportFormatCSV)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Колонки описываются только для CSV, остальные форматы описывают себя сами
+	var mapping *imports.CSVMapping
+	if format == usecases.ImportFormatCSV {
+		var req ImportCSVRequest
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		mapping, err = req.mapping()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer file.Close()
+
+	options := models.ImportOptions{DryRun: dryRun, SkipDuplicates: skipDuplicates}
+
+	result, err := h.service.Import(c.Request.Context(), accountID, userID, format, mapping, file, options)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	writeImportResult(c, result, dryRun)
+}
+
+func (h *ImportHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err == usecases.ErrUnsupportedImportFormat,
+		err == usecases.ErrImportCurrencyMismatch,
+		errors.Is(err, usecases.ErrInvalidImportFile),
+		errors.Is(err, imports.ErrInvalidMapping):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// mapping переводит поля формы в описание колонок со значениями по умолчанию
+func (r *ImportCSVRequest) mapping() (*imports.CSVMapping, error) {
+	mapping := &imports.CSVMapping{
+		Delimiter:        ',',
+		Encoding:         r.Encoding,
+		HasHeader:        r.HasHeader == nil || *r.HasHeader,
+		SkipRows:         r.SkipRows,
+		DateColumn:       r.DateColumn,
+		DateFormat:       r.DateFormat,
+		TitleColumn:      r.TitleColumn,
+		NotesColumn:      r.NotesColumn,
+		AmountColumn:     r.AmountColumn,
+		DebitColumn:      r.DebitColumn,
+		CreditColumn:     r.CreditColumn,
+		DecimalSeparator: '.',
+		InvertSign:       r.InvertSign,
+	}
+
+	switch r.Delimiter {
+	case "", ",":
+	case ";":
+		mapping.Delimiter = ';'
+	case "|":
+		mapping.Delimiter = '|'
+	case "tab", "\t":
+		mapping.Delimiter = '\t'
+	default:
+		return nil, errors.New("delimiter must be one of: ',', ';', '|', tab")
+	}
+
+	switch r.DecimalSeparator {
+	case "", ".":
+	case ",":
+		mapping.DecimalSeparator = ','
+	default:
+		return nil, errors.New("decimal_separator must be '.' or ','")
+	}
+
+	if mapping.Delimiter == mapping.DecimalSeparator {
+		return nil, errors.New("delimiter and decimal_separator must differ")
+	}
+
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = "YYYY-MM-DD"
+	}
+
+	return mapping, nil
+}
+
+// writeImportResult отвечает результатом импорта: 200 для предпросмотра, 201 если транзакции
+// записаны и 422 если в выписке есть ошибки и ничего не записано
+func writeImportResult(c *gin.Context, result *models.ImportResult, dryRun bool) {
+	response := ImportResponse{
+		DryRun:       dryRun,
+		Imported:     result.Imported,
+		Skipped:      result.Skipped,
+		Duplicates:   result.Duplicates,
+		Transactions: make([]ImportedTransactionResponse, len(result.Rows)),
+		Errors:       make([]ImportLineErrorResponse, len(result.Errors)),
+	}
+
+	for i, row := range result.Rows {
+		response.Transactions[i] = ImportedTransactionResponse{
+			Line:       row.Line,
+			OccurredAt: row.OccurredAt,
+			Title:      row.Title,
+			Amount:     row.Amount.Format(result.Currency),
+			Notes:      row.Notes,
+
+			ExternalRef:     row.ExternalRef,
+			AlreadyImported: row.AlreadyImported,
+
+			PossibleDuplicates: idsOrEmpty(row.DuplicateOf),
+		}
+	}
+
+	for i, e := range result.Errors {
+		response.Errors[i] = ImportLineErrorResponse{Line: e.Line, Error: e.Message}
+	}
+
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, response)
+	case len(result.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, response)
+	default:
+		c.JSON(http.StatusCreated, response)
+	}
+}
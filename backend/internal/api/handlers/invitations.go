@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+// invitationStatusExpired - статус приглашения, не получившего ответа до истечения срока
+const invitationStatusExpired = "expired"
+
+type InvitationHandler struct {
+	service *usecases.AccountMemberService
+}
+
+func NewInvitationHandler(service *usecases.AccountMemberService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+// InvitationResponse представляет приглашение в счёт. inviter_id равен null, если пригласивший удалил аккаунт
+type InvitationResponse struct {
+	ID          int32      `json:"id" binding:"required" example:"7"`
+	AccountID   int32      `json:"account_id" binding:"required" example:"1"`
+	Email       string     `json:"email" binding:"required" example:"newmember@example.com"`
+	Role        string     `json:"role" binding:"required" enums:"viewer,editor,admin" example:"editor"`
+	Status      string     `json:"status" binding:"required" enums:"pending,accepted,declined,revoked,expired" example:"pending"`
+	InviterID   *int       `json:"inviter_id" example:"1"`
+	CreatedAt   time.Time  `json:"created_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	ExpiresAt   time.Time  `json:"expires_at" binding:"required" example:"2024-12-20T14:30:00Z"`
+	RespondedAt *time.Time `json:"responded_at" example:"2024-12-14T09:12:00Z"`
+}
+
+// UserInvitationResponse представляет приглашение текущего пользователя, ожидающее ответа.
+// По token приглашение принимают или отклоняют
+type UserInvitationResponse struct {
+	Token        string    `json:"token" binding:"required" example:"4f0c6a1e9b2d7c3a5e8f1b0d2c4a6e8f0b1d3c5a7e9f2b4d6c8a0e1f3b5d7c9a"`
+	AccountID    int32     `json:"account_id" binding:"required" example:"1"`
+	AccountName  string    `json:"account_name" binding:"required" example:"Семейный бюджет"`
+	InviterEmail *string   `json:"inviter_email" example:"owner@example.com"`
+	Role         string    `json:"role" binding:"required" enums:"viewer,editor,admin" example:"editor"`
+	CreatedAt    time.Time `json:"created_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	ExpiresAt    time.Time `json:"expires_at" binding:"required" example:"2024-12-20T14:30:00Z"`
+}
+
+// ListAccountInvitations godoc
+// @Summary      Приглашения в счёт
+// @Description  Возвращает все приглашения в счёт, новые первыми, вместе с ответами на них. Приглашение, не получившее ответа до истечения срока, имеет статус expired. Доступно владельцу (Owner) и администраторам (Admin) счёта.
+// @Tags         invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} InvitationResponse "Приглашения в счёт"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Просматривать приглашения могут Owner и Admin"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/invitations [get]
+func (h *InvitationHandler) ListAccountInvitations(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]InvitationResponse, len(invitations))
+	for i := range invitations {
+		response[i] = newInvitationResponse(&invitations[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation godoc
+// @Summary      Отзыв приглашения
+// @Description  Отзывает приглашение в счёт, ещё не получившее ответа: принять его больше нельзя. Доступно владельцу (Owner) и администраторам (Admin) счёта; приглашение с ролью admin может отозвать только Owner.
+// @Tags         invitations
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        invitation_id path int true "ID приглашения" example(7)
+// @Success      204 "Приглашение отозвано"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Отзывать приглашения могут Owner и Admin, с ролью admin - только Owner"
+// @Failure      404 {object} ErrorResponse "Приглашение не найдено в этом счёте"
+// @Failure      409 {object} ErrorResponse "Приглашение уже принято, отклонено или отозвано"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/invitations/{invitation_id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	invitationID, err := strconv.Atoi(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	err = h.service.RevokeInvitation(c.Request.Context(), accountID, userID, invitationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err == usecases.ErrInvitationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err == usecases.ErrInvitationNotPending:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListUserInvitations godoc
+// @Summary      Мои приглашения
+// @Description  Возвращает приглашения текущего пользователя в счета, ожидающие ответа и не истёкшие, новые первыми. Сюда попадают и приглашения, отправленные на email до регистрации.
+// @Tags         invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} UserInvitationResponse "Приглашения, ожидающие ответа"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /invitations [get]
+func (h *InvitationHandler) ListUserInvitations(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	invitations, err := h.service.ListUserInvitations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]UserInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = UserInvitationResponse{
+			Token:        invitation.Token,
+			AccountID:    invitation.AccountID,
+			AccountName:  invitation.AccountName,
+			InviterEmail: convertNullString(invitation.InviterEmail),
+			Role:         string(invitation.Role),
+			CreatedAt:    invitation.CreatedAt,
+			ExpiresAt:    invitation.ExpiresAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AcceptInvitation godoc
+// @Summary      Принятие приглашения
+// @Description  Принимает приглашение текущего пользователя: он становится участником счёта с ролью из приглашения. Чужое приглашение не найдётся.
+// @Tags         invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        token path string true "Токен приглашения из GET /invitations"
+// @Success      200 {object} InvitationResponse "Приглашение принято"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Приглашение не найдено"
+// @Failure      409 {object} ErrorResponse "Приглашение уже принято, отклонено или отозвано, либо пользователь уже участник счёта"
+// @Failure      410 {object} ErrorResponse "Срок приглашения истёк"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /invitations/{token}/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	invitation, err := h.service.AcceptInvitation(c.Request.Context(), userID, c.Param("token"))
+	if err != nil {
+		writeInvitationAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newInvitationResponse(invitation))
+}
+
+// DeclineInvitation godoc
+// @Summary      Отклонение приглашения
+// @Description  Отклоняет приглашение текущего пользователя. Пригласивший увидит статус declined и сможет пригласить снова.
+// @Tags         invitations
+// @Security     BearerAuth
+// @Param        token path string true "Токен приглашения из GET /invitations"
+// @Success      204 "Приглашение отклонено"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Приглашение не найдено"
+// @Failure      409 {object} ErrorResponse "Приглашение уже принято, отклонено или отозвано"
+// @Failure      410 {object} ErrorResponse "Срок приглашения истёк"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /invitations/{token}/decline [post]
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	if err := h.service.DeclineInvitation(c.Request.Context(), userID, c.Param("token")); err != nil {
+		writeInvitationAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func writeInvitationAnswerError(c *gin.Context, err error) {
+	switch err {
+	case usecases.ErrInvitationNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case usecases.ErrInvitationNotPending, usecases.ErrAlreadyMember:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case usecases.ErrInvitationExpired:
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func newInvitationResponse(invitation *query.Invitation) InvitationResponse {
+	status := string(invitation.Status)
+	if invitation.Status == query.InvitationsStatusPending && !time.Now().Before(invitation.ExpiresAt) {
+		status = invitationStatusExpired
+	}
+
+	response := InvitationResponse{
+		ID:        invitation.ID,
+		AccountID: invitation.AccountID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		Status:    status,
+		CreatedAt: invitation.CreatedAt,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+
+	if invitation.InviterID.Valid {
+		inviterID := int(invitation.InviterID.Int32)
+		response.InviterID = &inviterID
+	}
+
+	if invitation.RespondedAt.Valid {
+		response.RespondedAt = &invitation.RespondedAt.Time
+	}
+
+	return response
+}
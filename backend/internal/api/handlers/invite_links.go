@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InviteLinkHandler struct {
+	service *usecases.AccountMemberService
+}
+
+func NewInviteLinkHandler(service *usecases.AccountMemberService) *InviteLinkHandler {
+	return &InviteLinkHandler{service: service}
+}
+
+// CreateInviteLinkRequest представляет данные для создания ссылки-приглашения.
+// Без max_uses число присоединений не ограничено, без expires_at ссылка действует INVITATION_TTL
+type CreateInviteLinkRequest struct {
+	Role      string     `json:"role" binding:"required,oneof=viewer editor admin" enums:"viewer,editor,admin" example:"viewer"`
+	MaxUses   *int       `json:"max_uses" example:"5"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2024-12-20T14:30:00Z"`
+}
+
+// InviteLinkResponse представляет ссылку-приглашение в счёт. created_by равен null, если создавший удалил аккаунт
+type InviteLinkResponse struct {
+	ID        int32      `json:"id" binding:"required" example:"4"`
+	Code      string     `json:"code" binding:"required" example:"9b2d7c3a5e8f1b0d2c4a6e8f0b1d3c5a"`
+	AccountID int32      `json:"account_id" binding:"required" example:"1"`
+	Role      string     `json:"role" binding:"required" enums:"viewer,editor,admin" example:"viewer"`
+	MaxUses   *int32     `json:"max_uses" example:"5"`
+	Uses      int32      `json:"uses" binding:"required" example:"2"`
+	CreatedBy *int       `json:"created_by" example:"1"`
+	CreatedAt time.Time  `json:"created_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	ExpiresAt time.Time  `json:"expires_at" binding:"required" example:"2024-12-20T14:30:00Z"`
+	RevokedAt *time.Time `json:"revoked_at" example:"2024-12-14T09:12:00Z"`
+}
+
+// JoinResponse представляет участие в счёте, полученное по ссылке-приглашению
+type JoinResponse struct {
+	AccountID int32  `json:"account_id" binding:"required" example:"1"`
+	Role      string `json:"role" binding:"required" enums:"viewer,editor,admin" example:"viewer"`
+}
+
+// CreateInviteLink godoc
+// @Summary      Создание ссылки-приглашения
+// @Description  Создаёт ссылку-приглашение в счёт: любой пользователь, получивший код, может присоединиться к счёту с ролью ссылки через POST /invites/{code}/join. Роль владельца ссылкой выдать нельзя. max_uses ограничивает число присоединений (по умолчанию без ограничения), expires_at - срок действия (по умолчанию INVITATION_TTL, 7 дней). Доступно владельцу (Owner) и администраторам (Admin) счёта; ссылку с ролью admin может создать только Owner. Роль ссылки не может давать прав, которых нет у её создателя.
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body CreateInviteLinkRequest true "Роль, число использований и срок действия"
+// @Success      201 {object} InviteLinkResponse "Ссылка создана"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, max_uses меньше 1 или expires_at в прошлом"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Создавать ссылки могут Owner и Admin, с ролью admin - только Owner"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/invite-links [post]
+func (h *InviteLinkHandler) CreateInviteLink(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req CreateInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.service.CreateInviteLink(
+		c.Request.Context(),
+		accountID,
+		userID,
+		query.InviteLinksRole(parseRole(req.Role)),
+		req.MaxUses,
+		req.ExpiresAt,
+	)
+	if err != nil {
+		switch {
+		case err == usecases.ErrInvalidInviteLink:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, newInviteLinkResponse(link))
+}
+
+// ListInviteLinks godoc
+// @Summary      Ссылки-приглашения в счёт
+// @Description  Возвращает все ссылки-приглашения в счёт, новые первыми, включая отозванные и истёкшие. Доступно владельцу (Owner) и администраторам (Admin) счёта.
+// @Tags         invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} InviteLinkResponse "Ссылки-приглашения"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Просматривать ссылки могут Owner и Admin"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/invite-links [get]
+func (h *InviteLinkHandler) ListInviteLinks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	links, err := h.service.ListInviteLinks(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]InviteLinkResponse, len(links))
+	for i := range links {
+		response[i] = newInviteLinkResponse(&links[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInviteLink godoc
+// @Summary      Отзыв ссылки-приглашения
+// @Description  Отзывает ссылку-приглашение: присоединиться по ней больше нельзя, уже присоединившиеся остаются участниками. Повторный отзыв ничего не меняет. Доступно владельцу (Owner) и администраторам (Admin) счёта.
+// @Tags         invitations
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        link_id path int true "ID ссылки" example(4)
+// @Success      204 "Ссылка отозвана"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Отзывать ссылки могут Owner и Admin, с ролью admin - только Owner"
+// @Failure      404 {object} ErrorResponse "Ссылка не найдена в этом счёте"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/invite-links/{link_id} [delete]
+func (h *InviteLinkHandler) RevokeInviteLink(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	linkID, err := strconv.Atoi(c.Param("link_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite link id"})
+		return
+	}
+
+	if err := h.service.RevokeInviteLink(c.Request.Context(), accountID, userID, linkID); err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err == usecases.ErrInviteLinkNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// JoinByInviteLink godoc
+// @Summary      Присоединение к счёту по ссылке
+// @Description  Делает текущего пользователя участником счёта с ролью ссылки-приглашения. Каждое присоединение расходует одно использование ссылки.
+// @Tags         invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code path string true "Код ссылки-приглашения"
+// @Success      200 {object} JoinResponse "Пользователь стал участником счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Ссылка не найдена"
+// @Failure      409 {object} ErrorResponse "Пользователь уже участник счёта"
+// @Failure      410 {object} ErrorResponse "Ссылка отозвана, истекла или исчерпала число использований"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /invites/{code}/join [post]
+func (h *InviteLinkHandler) JoinByInviteLink(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	link, err := h.service.JoinByInviteLink(c.Request.Context(), userID, c.Param("code"))
+	if err != nil {
+		switch err {
+		case usecases.ErrInviteLinkNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case usecases.ErrAlreadyMember:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case usecases.ErrInviteLinkRevoked, usecases.ErrInviteLinkExpired, usecases.ErrInviteLinkUsedUp:
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, JoinResponse{AccountID: link.AccountID, Role: string(link.Role)})
+}
+
+func newInviteLinkResponse(link *query.InviteLink) InviteLinkResponse {
+	response := InviteLinkResponse{
+		ID:        link.ID,
+		Code:      link.Code,
+		AccountID: link.AccountID,
+		Role:      string(link.Role),
+		Uses:      link.Uses,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+
+	if link.MaxUses.Valid {
+		response.MaxUses = &link.MaxUses.Int32
+	}
+
+	if link.CreatedBy.Valid {
+		createdBy := int(link.CreatedBy.Int32)
+		response.CreatedBy = &createdBy
+	}
+
+	if link.RevokedAt.Valid {
+		response.RevokedAt = &link.RevokedAt.Time
+	}
+
+	return response
+}
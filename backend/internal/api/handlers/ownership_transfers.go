@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OwnershipTransferHandler struct {
+	service *usecases.AccountService
+}
+
+func NewOwnershipTransferHandler(service *usecases.AccountService) *OwnershipTransferHandler {
+	return &OwnershipTransferHandler{service: service}
+}
+
+// TransferOwnershipRequest представляет предложение передать счёт участнику
+type TransferOwnershipRequest struct {
+	NewOwnerID int `json:"new_owner_id" binding:"required" example:"42"`
+}
+
+// OwnershipTransferResponse представляет передачу счёта, ожидающую подтверждения нового владельца
+type OwnershipTransferResponse struct {
+	AccountID  int32     `json:"account_id" binding:"required" example:"1"`
+	FromUserID int32     `json:"from_user_id" binding:"required" example:"1"`
+	ToUserID   int32     `json:"to_user_id" binding:"required" example:"42"`
+	CreatedAt  time.Time `json:"created_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	ExpiresAt  time.Time `json:"expires_at" binding:"required" example:"2024-12-20T14:30:00Z"`
+}
+
+// TransferOwnership godoc
+// @Summary      Передача счёта участнику
+// @Description  Предлагает передать счёт другому участнику. Владелец меняется, только когда новый владелец подтвердит передачу через POST /accounts/{id}/transfer-ownership/accept в течение INVITATION_TTL (по умолчанию 7 дней). До подтверждения владелец может отозвать предложение, а новый владелец - отказаться (DELETE /accounts/{id}/transfer-ownership). Новое предложение заменяет прежнее. Доступно только владельцу счёта (Owner).
+// @Tags         members
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body TransferOwnershipRequest true "Новый владелец"
+// @Success      202 {object} OwnershipTransferResponse "Передача ожидает подтверждения нового владельца"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных или new_owner_id не является другим участником счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Передать счёт может только Owner"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/transfer-ownership [post]
+func (h *OwnershipTransferHandler) TransferOwnership(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.service.RequestOwnershipTransfer(c.Request.Context(), accountID, userID, req.NewOwnerID)
+	if err != nil {
+		switch err {
+		case usecases.ErrInvalidOwnershipTransfer:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case usecases.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newOwnershipTransferResponse(transfer))
+}
+
+// GetOwnershipTransfer godoc
+// @Summary      Неподтверждённая передача счёта
+// @Description  Возвращает передачу счёта, ожидающую подтверждения. Видна только владельцу счёта и новому владельцу.
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {object} OwnershipTransferResponse "Передача счёта"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Передачи нет"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/transfer-ownership [get]
+func (h *OwnershipTransferHandler) GetOwnershipTransfer(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	transfer, err := h.service.OwnershipTransfer(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if err == usecases.ErrOwnershipTransferNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newOwnershipTransferResponse(transfer))
+}
+
+// CancelOwnershipTransfer godoc
+// @Summary      Отмена передачи счёта
+// @Description  Отменяет передачу счёта, ожидающую подтверждения: владелец отзывает предложение, новый владелец отказывается от него.
+// @Tags         members
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      204 "Передача отменена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Передачи нет"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/transfer-ownership [delete]
+func (h *OwnershipTransferHandler) CancelOwnershipTransfer(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	if err := h.service.CancelOwnershipTransfer(c.Request.Context(), accountID, userID); err != nil {
+		if err == usecases.ErrOwnershipTransferNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AcceptOwnershipTransfer godoc
+// @Summary      Подтверждение передачи счёта
+// @Description  Подтверждает передачу счёта текущему пользователю: он становится владельцем (Owner), прежний владелец - администратором (Admin). Владелец счёта и обе роли меняются атомарно, у счёта всегда ровно один владелец. Если у нового владельца уже есть счёт с таким названием, к названию добавляется номер ("Счёт (2)"). Возвращает счёт после передачи.
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {object} AccountResponse "Счёт передан"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь больше не участник счёта"
+// @Failure      404 {object} ErrorResponse "Передачи текущему пользователю нет"
+// @Failure      410 {object} ErrorResponse "Срок подтверждения истёк"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/transfer-ownership/accept [post]
+func (h *OwnershipTransferHandler) AcceptOwnershipTransfer(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	account, err := h.service.AcceptOwnershipTransfer(c.Request.Context(), accountID, userID)
+	if err != nil {
+		switch err {
+		case usecases.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case usecases.ErrOwnershipTransferNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case usecases.ErrOwnershipTransferExpired:
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountResponse{
+		ID:             account.ID,
+		OwnerID:        account.OwnerID,
+		Name:           account.Name,
+		Description:    convertNullString(account.Description),
+		Currency:       string(account.Currency),
+		OpeningBalance: account.OpeningBalance.Format(account.Currency),
+	})
+}
+
+func newOwnershipTransferResponse(transfer *query.OwnershipTransfer) OwnershipTransferResponse {
+	return OwnershipTransferResponse{
+		AccountID:  transfer.AccountID,
+		FromUserID: transfer.FromUserID,
+		ToUserID:   transfer.ToUserID,
+		CreatedAt:  transfer.CreatedAt,
+		ExpiresAt:  transfer.ExpiresAt,
+	}
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringHandler struct {
+	service *usecases.RecurringService
+}
+
+func NewRecurringHandler(service *usecases.RecurringService) *RecurringHandler {
+	return &RecurringHandler{service: service}
+}
+
+// CreateRecurringRuleRequest представляет данные для создания правила повторения
+type CreateRecurringRuleRequest struct {
+	Title          string       `json:"title" binding:"required" example:"Аренда квартиры"`
+	Amount         money.Amount `json:"amount" binding:"required" swaggertype:"string" example:"-45000.00"`
+	Period         string       `json:"period" binding:"required,oneof=day week month year" enums:"day,week,month,year" example:"month"`
+	Interval       *int         `json:"interval" binding:"omitempty,min=1" example:"1"`
+	StartsAt       *string      `json:"starts_at" example:"2024-12-01T10:00:00Z"`
+	EndsAt         *string      `json:"ends_at" example:"2025-11-30T23:59:59Z"`
+	MaxOccurrences *int         `json:"max_occurrences" binding:"omitempty,min=1" example:"12"`
+	CategoryID     *int         `json:"category_id" example:"8"`
+	Notes          *string      `json:"notes" example:"Перевод хозяйке до 5 числа"`
+	Tags           []string     `json:"tags" example:"housing"`
+}
+
+// UpdateRecurringRuleRequest представляет новые параметры серии начиная с даты from
+type UpdateRecurringRuleRequest struct {
+	From           string       `json:"from" binding:"required" example:"2025-03-01T10:00:00Z"`
+	Title          string       `json:"title" binding:"required" example:"Аренда квартиры"`
+	Amount         money.Amount `json:"amount" binding:"required" swaggertype:"string" example:"-50000.00"`
+	Period         string       `json:"period" binding:"required,oneof=day week month year" enums:"day,week,month,year" example:"month"`
+	Interval       *int         `json:"interval" binding:"omitempty,min=1" example:"1"`
+	EndsAt         *string      `json:"ends_at" example:"2025-11-30T23:59:59Z"`
+	MaxOccurrences *int         `json:"max_occurrences" binding:"omitempty,min=1" example:"9"`
+	CategoryID     *int         `json:"category_id" example:"8"`
+	Notes          *string      `json:"notes" example:"Перевод хозяйке до 5 числа"`
+	Tags           []string     `json:"tags" example:"housing"`
+}
+
+// RecurringRuleResponse представляет информацию о правиле повторения. UserID - автор правила,
+// null, если автор удалил свой аккаунт
+type RecurringRuleResponse struct {
+	ID               int32      `json:"id" binding:"required" example:"7"`
+	AccountID        int32      `json:"account_id" binding:"required" example:"1"`
+	UserID           *int32     `json:"user_id" example:"42"`
+	Title            string     `json:"title" binding:"required" example:"Аренда квартиры"`
+	Amount           string     `json:"amount" binding:"required" example:"-45000.00"`
+	Currency         string     `json:"currency" binding:"required" example:"RUB"`
+	Period           string     `json:"period" binding:"required" enums:"day,week,month,year" example:"month"`
+	Interval         int32      `json:"interval" binding:"required" example:"1"`
+	StartsAt         time.Time  `json:"starts_at" binding:"required" example:"2024-12-01T10:00:00Z"`
+	EndsAt           *time.Time `json:"ends_at" example:"2025-11-30T23:59:59Z"`
+	MaxOccurrences   *int32     `json:"max_occurrences" example:"12"`
+	CategoryID       *int32     `json:"category_id" example:"8"`
+	Notes            *string    `json:"notes" example:"Перевод хозяйке до 5 числа"`
+	Tags             []string   `json:"tags" binding:"required" example:"housing"`
+	Paused           bool       `json:"paused" binding:"required" example:"false"`
+	OccurrencesCount int32      `json:"occurrences_count" binding:"required" example:"12"`
+}
+
+// CreateRecurringRule godoc
+// @Summary      Создание правила повторения
+// @Description  Создаёт периодическую серию транзакций в счёте. Доступно участникам с правом tx.create.income для доходов или tx.create.expense для расходов (Editor и выше). Вхождения серии создаются как обычные транзакции не все сразу, а только на горизонт планирования вперёд (по умолчанию год, переменная RECURRING_HORIZON); дальнейшие вхождения досоздаются автоматически. Серию можно ограничить датой окончания ends_at и/или числом вхождений max_occurrences. interval задаёт шаг: period=week, interval=2 - раз в две недели. Месячные серии с 29-31 числа в коротких месяцах попадают на последний день месяца. category_id - категория этого же счёта, notes - примечание до 1000 символов, tags - метки; они переходят ко всем вхождениям серии.
+// @Tags         recurring
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        request body CreateRecurringRuleRequest true "Параметры серии. starts_at по умолчанию - текущее время, interval - 1"
+// @Success      201 {object} IDResponse "Правило создано. Возвращается ID правила"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных: amount (строка, не более 2 знаков после точки), даты (RFC3339), период, ends_at раньше starts_at"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Создавать серии могут только Editor, Admin и Owner. В ошибке указано недостающее право"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/recurring-rules [post]
+func (h *RecurringHandler) CreateRecurringRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req CreateRecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.StartsAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid starts_at format, use RFC3339"})
+			return
+		}
+		startsAt = parsed
+	}
+
+	endsAt, err := parseOptionalTime(req.EndsAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ends_at format, use RFC3339"})
+		return
+	}
+
+	ruleID, err := h.service.Create(c.Request.Context(), &models.CreateRecurringRuleParams{
+		AccountID:      accountID,
+		UserID:         userID,
+		Title:          req.Title,
+		Amount:         req.Amount,
+		Period:         query.RecurringRulesPeriod(req.Period),
+		Interval:       intervalOrDefault(req.Interval),
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		MaxOccurrences: req.MaxOccurrences,
+		CategoryID:     req.CategoryID,
+		Notes:          req.Notes,
+		Tags:           req.Tags,
+	})
+	if err != nil {
+		if errors.Is(err, usecases.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err == usecases.ErrInvalidRecurringRule ||
+			err == usecases.ErrAmountPrecision ||
+			err == usecases.ErrCategoryNotFound ||
+			err == usecases.ErrNotesTooLong ||
+			err == usecases.ErrInvalidTag ||
+			err == usecases.ErrTooManyTags {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": ruleID})
+}
+
+// ListRecurringRules godoc
+// @Summary      Список правил повторения счёта
+// @Description  Возвращает все периодические серии счёта, включая приостановленные и завершённые. Доступно всем участникам счёта; участники без права tx.view.any видят только свои серии.
+// @Tags         recurring
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} RecurringRuleResponse "Список правил"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/recurring-rules [get]
+func (h *RecurringHandler) ListRecurringRules(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	rules, err := h.service.List(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]RecurringRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		response = append(response, newRecurringRuleResponse(&r))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateRecurringRule godoc
+// @Summary      Изменение серии начиная с даты
+// @Description  Меняет параметры серии начиная с даты from. Вхождения до from остаются без изменений, вхождения начиная с from пересоздаются по новым параметрам. Технически старое правило завершается перед from, и с from начинается новое правило - возвращается его ID. Если from не позже начала серии, меняется вся серия. Без tags серия сохраняет прежние метки, а без notes остаётся без примечания. Права доступа: с правом tx.edit.own (Editor) - только свои серии, с tx.edit.any (Admin и Owner) - любые.
+// @Tags         recurring
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID правила" example(7)
+// @Param        request body UpdateRecurringRuleRequest true "Дата, с которой действуют изменения, и новые параметры серии"
+// @Success      200 {object} IDResponse "Серия изменена. Возвращается ID правила, действующего с from"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Правило не найдено"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /recurring-rules/{id} [patch]
+func (h *RecurringHandler) UpdateRecurringRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	var req UpdateRecurringRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from format, use RFC3339"})
+		return
+	}
+
+	endsAt, err := parseOptionalTime(req.EndsAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ends_at format, use RFC3339"})
+		return
+	}
+
+	newRuleID, err := h.service.UpdateFrom(c.Request.Context(), ruleID, userID, &models.UpdateRecurringRuleParams{
+		From:           from,
+		Title:          req.Title,
+		Amount:         req.Amount,
+		Period:         query.RecurringRulesPeriod(req.Period),
+		Interval:       intervalOrDefault(req.Interval),
+		EndsAt:         endsAt,
+		MaxOccurrences: req.MaxOccurrences,
+		CategoryID:     req.CategoryID,
+		Notes:          req.Notes,
+		Tags:           req.Tags,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": newRuleID})
+}
+
+// PauseRecurringRule godoc
+// @Summary      Приостановка серии
+// @Description  Приостанавливает серию: будущие вхождения удаляются и не создаются, пока серия не будет возобновлена. Прошедшие вхождения не меняются.
+// @Tags         recurring
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID правила" example(7)
+// @Success      200 {object} MessageResponse "Серия приостановлена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID правила"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Правило не найдено"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /recurring-rules/{id}/pause [post]
+func (h *RecurringHandler) PauseRecurringRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.service.Pause(c.Request.Context(), ruleID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring rule paused"})
+}
+
+// ResumeRecurringRule godoc
+// @Summary      Возобновление серии
+// @Description  Возобновляет приостановленную серию. Вхождения, пропущенные за время паузы, не создаются - серия продолжается с ближайшей будущей даты расписания.
+// @Tags         recurring
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID правила" example(7)
+// @Success      200 {object} MessageResponse "Серия возобновлена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID правила"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Правило не найдено"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /recurring-rules/{id}/resume [post]
+func (h *RecurringHandler) ResumeRecurringRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.service.Resume(c.Request.Context(), ruleID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring rule resumed"})
+}
+
+// DeleteRecurringRule godoc
+// @Summary      Удаление серии
+// @Description  Удаляет правило повторения и все его будущие вхождения. Прошедшие вхождения остаются в счёте как обычные транзакции.
+// @Tags         recurring
+// @Security     BearerAuth
+// @Param        id path int true "ID правила" example(7)
+// @Success      204 "Серия удалена"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID правила"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав"
+// @Failure      404 {object} ErrorResponse "Правило не найдено"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /recurring-rules/{id} [delete]
+func (h *RecurringHandler) DeleteRecurringRule(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), ruleID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *RecurringHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, usecases.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch err {
+	case usecases.ErrRecurringRuleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case usecases.ErrInvalidRecurringRule, usecases.ErrAmountPrecision, usecases.ErrCategoryNotFound,
+		usecases.ErrNotesTooLong, usecases.ErrInvalidTag, usecases.ErrTooManyTags:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func newRecurringRuleResponse(r *models.TaggedRecurringRule) RecurringRuleResponse {
+	response := RecurringRuleResponse{
+		ID:               r.ID,
+		AccountID:        r.AccountID,
+		Title:            r.Title,
+		Amount:           r.Amount.Format(r.Currency),
+		Currency:         string(r.Currency),
+		Period:           string(r.Period),
+		Interval:         r.IntervalCount,
+		StartsAt:         r.StartsAt,
+		Paused:           r.Paused,
+		OccurrencesCount: r.OccurrencesCount,
+		Tags:             tagsOrEmpty(r.Tags),
+	}
+
+	if r.UserID.Valid {
+		response.UserID = &r.UserID.Int32
+	}
+
+	if r.EndsAt.Valid {
+		response.EndsAt = &r.EndsAt.Time
+	}
+
+	if r.MaxOccurrences.Valid {
+		response.MaxOccurrences = &r.MaxOccurrences.Int32
+	}
+
+	if r.CategoryID.Valid {
+		response.CategoryID = &r.CategoryID.Int32
+	}
+
+	if r.Notes.Valid {
+		response.Notes = &r.Notes.String
+	}
+
+	return response
+}
+
+func parseOptionalTime(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func intervalOrDefault(interval *int) int {
+	if interval == nil {
+		return 1
+	}
+
+	return *interval
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	service *usecases.SessionService
+}
+
+func NewSessionHandler(service *usecases.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// SessionResponse представляет информацию об активной сессии (входе с устройства)
+type SessionResponse struct {
+	ID         string    `json:"id" binding:"required" example:"3f2c9a0d8b7e4f1a9c6d5e4b3a2f1e0d"`
+	UserAgent  string    `json:"user_agent" binding:"required" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"`
+	IP         string    `json:"ip" binding:"required" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	LastSeenAt time.Time `json:"last_seen_at" binding:"required" example:"2024-12-14T09:12:00Z"`
+	Current    bool      `json:"current" binding:"required" example:"true"`
+}
+
+// ListSessions godoc
+// @Summary      Список активных сессий
+// @Description  Возвращает все активные сессии текущего пользователя: устройство (User-Agent), IP-адрес, время входа и последней активности. Сессия, из которой выполнен запрос, помечена флагом current. Сессии отсортированы по последней активности (свежие первыми).
+// @Tags         sessions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} SessionResponse "Список активных сессий"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	currentID := c.GetString("session_id")
+
+	sessions, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.Ip,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession godoc
+// @Summary      Завершение сессии
+// @Description  Завершает указанную сессию текущего пользователя (выход на другом устройстве). Все токены этой сессии сразу перестают приниматься. Можно завершить и текущую сессию - это равносильно выходу из системы.
+// @Tags         sessions
+// @Security     BearerAuth
+// @Param        id path string true "ID сессии" example(3f2c9a0d8b7e4f1a9c6d5e4b3a2f1e0d)
+// @Success      204 "Сессия завершена"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Сессия не найдена"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	err := h.service.Revoke(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		if err == usecases.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeOtherSessions godoc
+// @Summary      Завершение всех остальных сессий
+// @Description  Завершает все сессии текущего пользователя, кроме той, из которой выполнен запрос. Полезно, если устройство потеряно или вход выполнялся на чужом компьютере.
+// @Tags         sessions
+// @Security     BearerAuth
+// @Success      204 "Остальные сессии завершены"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	err := h.service.RevokeOthers(c.Request.Context(), userID, c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
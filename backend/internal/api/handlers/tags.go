@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service *usecases.TagService
+}
+
+func NewTagHandler(service *usecases.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// TagResponse представляет метку счёта
+type TagResponse struct {
+	Name              string `json:"name" binding:"required" example:"vacation-2026"`
+	TransactionsCount int64  `json:"transactions_count" binding:"required" example:"14"`
+}
+
+// ListTags godoc
+// @Summary      Метки счёта
+// @Description  Возвращает метки, которые есть у транзакций счёта, по алфавиту и с числом транзакций. Метки создаются при первом использовании в транзакции (поле tags). Доступно всем участникам счёта.
+// @Tags         tags
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Success      200 {array} TagResponse "Список меток"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	tags, err := h.service.List(c.Request.Context(), accountID, userID)
+	if err != nil {
+		if err == usecases.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := make([]TagResponse, len(tags))
+	for i, t := range tags {
+		response[i] = TagResponse{
+			Name:              t.Name,
+			TransactionsCount: t.TransactionsCount,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
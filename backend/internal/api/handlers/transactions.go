@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"microservices/accounter/internal/export"
 	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository/query"
 	"microservices/accounter/internal/usecases"
 
@@ -22,43 +28,109 @@ func NewTransactionHandler(service *usecases.TransactionService) *TransactionHan
 
 // CreateTransactionRequest представляет данные для создания транзакции
 type CreateTransactionRequest struct {
-	Title      string  `json:"title" binding:"required" example:"Покупка продуктов"`
-	Amount     float64 `json:"amount" binding:"required" example:"-1500.50"`
-	OccurredAt *string `json:"occurred_at" example:"2024-12-13T14:30:00Z"`
-	Period     *string `json:"period" enums:"day,week,month,year" example:"week"`
+	Title      string       `json:"title" binding:"required" example:"Покупка продуктов"`
+	Amount     money.Amount `json:"amount" binding:"required" swaggertype:"string" example:"-1500.50"`
+	OccurredAt *string      `json:"occurred_at" example:"2024-12-13T14:30:00Z"`
+	Period     *string      `json:"period" enums:"day,week,month,year" example:"week"`
+	CategoryID *int         `json:"category_id" example:"5"`
+	Notes      *string      `json:"notes" example:"Ашан на Ленина, чек в почте"`
+	Tags       []string     `json:"tags" example:"vacation-2026,reimbursable"`
+}
+
+// CreateTransactionResponse представляет созданную транзакцию
+type CreateTransactionResponse struct {
+	ID int `json:"id" binding:"required" example:"123"`
+
+	// ID транзакций счёта, похожих на созданную (та же сумма, близкая дата и название)
+	PossibleDuplicates []int `json:"possible_duplicates" binding:"required" example:"118"`
 }
 
 // UpdateTransactionRequest представляет данные для обновления транзакции
 type UpdateTransactionRequest struct {
-	Title      string  `json:"title" binding:"required" example:"Обновленное название"`
-	Amount     float64 `json:"amount" binding:"required" example:"-2000.00"`
-	OccurredAt *string `json:"occurred_at" binding:"required" example:"2024-12-20T15:00:00Z"`
+	Title      string       `json:"title" binding:"required" example:"Обновленное название"`
+	Amount     money.Amount `json:"amount" binding:"required" swaggertype:"string" example:"-2000.00"`
+	OccurredAt *string      `json:"occurred_at" binding:"required" example:"2024-12-20T15:00:00Z"`
+	CategoryID *int         `json:"category_id" example:"5"`
+	Notes      *string      `json:"notes" example:"Ашан на Ленина, чек в почте"`
+	Tags       []string     `json:"tags" example:"vacation-2026,reimbursable"`
 }
 
-// TransactionResponse представляет информацию о транзакции
+// TransactionResponse представляет информацию о транзакции. UserID - автор транзакции,
+// null, если автор удалил свой аккаунт
 type TransactionResponse struct {
 	ID         int32     `json:"id" binding:"required" example:"123"`
 	AccountID  int32     `json:"account_id" binding:"required" example:"1"`
-	UserID     int32     `json:"user_id" binding:"required" example:"42"`
+	UserID     *int32    `json:"user_id" example:"42"`
 	Title      string    `json:"title" binding:"required" example:"Покупка продуктов"`
-	Amount     float64   `json:"amount" binding:"required" example:"-1500.50"`
+	Amount     string    `json:"amount" binding:"required" example:"-1500.50"`
+	Currency   string    `json:"currency" binding:"required" example:"RUB"`
 	OccurredAt time.Time `json:"occurred_at" binding:"required" example:"2024-12-13T14:30:00Z"`
 	Period     *string   `json:"period" example:"week"`
+	RuleID     *int32    `json:"rule_id" example:"7"`
+	CategoryID *int32    `json:"category_id" example:"5"`
+	Notes      *string   `json:"notes" example:"Ашан на Ленина, чек в почте"`
+	Tags       []string  `json:"tags" binding:"required" example:"vacation-2026,reimbursable"`
+
+	// Остаток счёта сразу после транзакции, только при running_balance=true
+	RunningBalance *string `json:"running_balance,omitempty" example:"48200.00"`
+}
+
+// TransactionListResponse представляет страницу списка транзакций
+type TransactionListResponse struct {
+	Items []TransactionResponse `json:"items" binding:"required"`
+
+	// Курсор следующей страницы, null на последней странице
+	NextCursor *string `json:"next_cursor" example:"eyJzIjoib2NjdXJyZWRfYXQiLCJkIjp0cnVlLCJ2IjoiMjAyNC0xMi0xM1QxNDozMDowMFoiLCJpIjoxMjN9"`
+}
+
+// BalanceResponse представляет остаток счёта на момент времени
+type BalanceResponse struct {
+	AccountID      int       `json:"account_id" binding:"required" example:"1"`
+	Currency       string    `json:"currency" binding:"required" example:"RUB"`
+	At             time.Time `json:"at" binding:"required" example:"2024-12-31T23:59:59Z"`
+	OpeningBalance string    `json:"opening_balance" binding:"required" example:"15000.00"`
+	Balance        string    `json:"balance" binding:"required" example:"48200.00"`
+}
+
+// CurrencyTotalResponse представляет итоги по транзакциям в одной валюте.
+// Суммы записаны с числом знаков дробной части валюты
+type CurrencyTotalResponse struct {
+	Currency   string `json:"currency" binding:"required" example:"RUB"`
+	MinorUnits int    `json:"minor_units" binding:"required" example:"2"`
+	Count      int    `json:"count" binding:"required" example:"42"`
+	Income     string `json:"income" binding:"required" example:"120000.00"`
+	Expense    string `json:"expense" binding:"required" example:"-85400.50"`
+	Total      string `json:"total" binding:"required" example:"34599.50"`
+}
+
+// ConvertedTotalResponse представляет итоги, пересчитанные в валюту отчёта
+type ConvertedTotalResponse struct {
+	Currency   string `json:"currency" binding:"required" example:"USD"`
+	MinorUnits int    `json:"minor_units" binding:"required" example:"2"`
+	Income     string `json:"income" binding:"required" example:"1310.25"`
+	Expense    string `json:"expense" binding:"required" example:"-932.40"`
+	Total      string `json:"total" binding:"required" example:"377.85"`
+}
+
+// SummaryResponse представляет итоги по транзакциям в разрезе валют
+type SummaryResponse struct {
+	Totals    []CurrencyTotalResponse `json:"totals" binding:"required"`
+	Converted *ConvertedTotalResponse `json:"converted,omitempty"`
 }
 
 // CreateTransaction godoc
 // @Summary      Создание транзакции (обычной или периодической)
-// @Description  Создаёт финансовую транзакцию в счёте. Доступно участникам с ролью Editor и выше. Amount: положительное число для дохода, отрицательное для расхода. Если указан период (day/week/month/year), автоматически создаётся 500 периодических записей с указанным интервалом. Например, period="week" создаст транзакции с интервалом в 7 дней на ~9.6 лет вперёд. Это удобно для регулярных платежей: зарплата, аренда, подписки.
+// @Description  Создаёт финансовую транзакцию в счёте. Доступно участникам с правом tx.create.income для доходов или tx.create.expense для расходов (Editor и выше). Amount передаётся строкой с не более чем двумя знаками после точки (например "-1500.50"): положительная сумма для дохода, отрицательная для расхода. Суммы хранятся как DECIMAL(12,2): значения больше 10 знаков до точки отклоняются. Транзакция записывается в валюте счёта, и сумма не может иметь больше знаков после точки, чем у валюты (например, у JPY их нет). Если указан период (day/week/month/year), создаётся бессрочное правило повторения с началом в occurred_at (см. /accounts/{id}/recurring-rules): его вхождения создаются как транзакции на горизонт планирования вперёд и досоздаются автоматически. Это удобно для регулярных платежей: зарплата, аренда, подписки. Для серий с датой окончания или числом повторений используйте /accounts/{id}/recurring-rules. category_id - категория этого же счёта (см. /accounts/{id}/categories), для периодической транзакции она переходит ко всем вхождениям серии. tags - произвольные метки (например, vacation-2026, reimbursable): не более 20, до 50 символов, без запятых; хранятся в нижнем регистре. Метки периодической транзакции тоже переходят ко всем вхождениям. notes - произвольное примечание до 1000 символов, по нему работает поиск q в списке транзакций; у периодической транзакции оно переходит ко всем вхождениям. В possible_duplicates возвращаются ID транзакций счёта, похожих на созданную: та же сумма, даты отличаются не больше чем на 3 дня, слова одного названия входят в другое (например, транзакция уже загружена из выписки). Транзакция создаётся в любом случае; дубликаты можно объединить или отметить как разные операции (см. /accounts/{id}/duplicates). Периодические транзакции на дубликаты не проверяются.
 // @Tags         transactions
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "ID счёта, в котором создаётся транзакция" example(1)
 // @Param        request body CreateTransactionRequest true "Данные транзакции. Title и amount обязательны. occurred_at опционален (по умолчанию текущее время). period опционален (day/week/month/year для периодических платежей)"
-// @Success      201 {object} IDResponse "Транзакция успешно создана. Для периодической транзакции создаётся 500 записей"
-// @Failure      400 {object} ErrorResponse "Неверный формат данных. Проверьте формат amount, occurred_at (RFC3339) и period (day/week/month/year)"
+// @Success      201 {object} CreateTransactionResponse "Транзакция успешно создана. Для периодической транзакции возвращается ID первого вхождения серии"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных. Проверьте формат amount (строка, не более 2 знаков после точки и 10 до неё), occurred_at (RFC3339), period (day/week/month/year) category_id (категория должна принадлежать счёту), notes (до 1000 символов) и tags"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Создавать транзакции могут только Editor, Admin и Owner"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Создавать транзакции могут только Editor, Admin и Owner. В ошибке указано недостающее право"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при создании транзакции"
 // @Router       /accounts/{id}/transactions [post]
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
@@ -101,30 +173,44 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		}
 	}
 
-	transactionID, err := h.service.Create(
+	transactionID, duplicates, err := h.service.Create(
 		c.Request.Context(),
 		accountID,
 		userID.(int),
 		req.Title,
-		floatToDecimal(req.Amount),
+		req.Amount,
 		occurredAt,
 		period,
+		req.CategoryID,
+		req.Notes,
+		req.Tags,
 	)
 	if err != nil {
-		if err == usecases.ErrForbidden {
+		if errors.Is(err, usecases.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if err == usecases.ErrAmountPrecision ||
+			err == usecases.ErrCategoryNotFound ||
+			err == usecases.ErrNotesTooLong ||
+			err == usecases.ErrInvalidTag ||
+			err == usecases.ErrTooManyTags {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"id": transactionID})
+	c.JSON(http.StatusCreated, CreateTransactionResponse{
+		ID:                 transactionID,
+		PossibleDuplicates: idsOrEmpty(duplicates),
+	})
 }
 
 // ListTransactions godoc
 // @Summary      Список транзакций с фильтрацией
-// @Description  Возвращает список транзакций счёта с возможностью фильтрации. Доступно всем участникам счёта (включая Viewer). Фильтры: date_from/date_to (временной диапазон в RFC3339), type (income/expense для доходов/расходов), user_id (транзакции конкретного пользователя). Все фильтры опциональны и могут комбинироваться. Возвращаются все транзакции (включая периодические), соответствующие фильтрам, отсортированные по дате (новые первыми).
+// @Description  Возвращает список транзакций счёта с возможностью фильтрации. Доступно всем участникам счёта (включая Viewer); участники без права tx.view.any видят только свои транзакции. Фильтры: date_from/date_to (временной диапазон в RFC3339), type (income/expense для доходов/расходов), user_id (транзакции конкретного пользователя), category_id (категория вместе со всеми подкатегориями), tags (any:a,b - хотя бы одна из меток, all:a,b - все метки), q (подстрока названия или примечания, без учёта регистра; символы % и _ ищутся буквально), amount_min/amount_max (границы суммы со знаком включительно: расходы от 1000 до 5000 - amount_min=-5000&amount_max=-1000), exclude_planned (без вхождений серий, дата которых ещё не наступила). Все фильтры опциональны и могут комбинироваться. Возвращаются транзакции (включая вхождения периодических серий до горизонта планирования), соответствующие фильтрам, постранично: не более limit транзакций (по умолчанию 100, максимум 500). Если есть следующая страница, в next_cursor возвращается курсор: запрос с тем же фильтром и cursor=next_cursor вернёт следующую страницу. Курсор указывает на последнюю выданную транзакцию, поэтому новые транзакции не сдвигают страницы. sort задаёт поле сортировки (occurred_at, amount или title), order - направление (по умолчанию desc: новые, крупные или последние по алфавиту первыми); при равных значениях транзакции упорядочиваются по ID. Курсор действует только для того порядка сортировки, в котором он выдан; sort и order можно не повторять. У вхождений серий заполнено поле rule_id. При running_balance=true у каждой транзакции возвращается остаток счёта сразу после неё (начальный остаток плюс все транзакции счёта по порядку даты), фильтры на него не влияют.
 // @Tags         transactions
 // @Produce      json
 // @Security     BearerAuth
@@ -133,10 +219,21 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 // @Param        date_to query string false "Конечная дата (RFC3339). Включает транзакции до этой даты включительно" example(2024-12-31T23:59:59Z)
 // @Param        type query string false "Фильтр по типу транзакции" Enums(income, expense)
 // @Param        user_id query int false "Фильтр по ID пользователя (создателя транзакции)" example(42)
-// @Success      200 {array} TransactionResponse "Список транзакций, соответствующих фильтрам. Пустой массив если транзакций нет"
-// @Failure      400 {object} ErrorResponse "Неверные параметры фильтрации. Проверьте формат дат и значение type"
+// @Param        category_id query int false "Фильтр по категории, включая подкатегории" example(1)
+// @Param        tags query string false "Фильтр по меткам: any:метка1,метка2 или all:метка1,метка2" example(any:vacation-2026,reimbursable)
+// @Param        q query string false "Поиск подстроки в названии и примечании без учёта регистра, до 100 символов" example(ашан)
+// @Param        amount_min query string false "Нижняя граница суммы со знаком, включительно" example(-5000.00)
+// @Param        amount_max query string false "Верхняя граница суммы со знаком, включительно" example(-1000.00)
+// @Param        exclude_planned query bool false "Исключить ещё не наступившие вхождения периодических серий" example(true)
+// @Param        running_balance query bool false "Добавить остаток счёта после каждой транзакции" example(true)
+// @Param        sort query string false "Поле сортировки (по умолчанию occurred_at)" Enums(occurred_at, amount, title)
+// @Param        order query string false "Направление сортировки (по умолчанию desc)" Enums(asc, desc)
+// @Param        limit query int false "Размер страницы, от 1 до 500 (по умолчанию 100)" example(50)
+// @Param        cursor query string false "Курсор следующей страницы из next_cursor предыдущего ответа"
+// @Success      200 {object} TransactionListResponse "Страница списка транзакций, соответствующих фильтрам. Пустой items если транзакций нет"
+// @Failure      400 {object} ErrorResponse "Неверные параметры фильтрации. Проверьте формат дат, значение type, category_id (категория должна принадлежать счёту), tags, q, amount_min/amount_max (amount_min не больше amount_max), sort, order, limit и cursor"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта или у него нет нужного права"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при получении транзакций"
 // @Router       /accounts/{id}/transactions [get]
 func (h *TransactionHandler) ListTransactions(c *gin.Context) {
@@ -148,99 +245,285 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 		return
 	}
 
-	filter := &models.ListTransactionsFilter{
-		AccountID: accountID,
+	filter, err := parseTransactionFilter(c, accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// date_from
-	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
-		parsed, err := time.Parse(time.RFC3339, dateFromStr)
+	// running_balance (остаток после каждой транзакции)
+	withBalance := false
+	if balanceStr := c.Query("running_balance"); balanceStr != "" {
+		withBalance, err = strconv.ParseBool(balanceStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, use RFC3339"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid running_balance format"})
 			return
 		}
-		filter.DateFrom = &parsed
 	}
 
-	// date_to
-	if dateToStr := c.Query("date_to"); dateToStr != "" {
-		parsed, err := time.Parse(time.RFC3339, dateToStr)
+	// limit, cursor, sort, order (страница и сортировка)
+	if err := parseTransactionPage(c, filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if withBalance {
+		transactions, next, err := h.service.ListWithBalance(c.Request.Context(), accountID, userID.(int), filter)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, use RFC3339"})
+			writeListError(c, err)
 			return
 		}
-		filter.DateTo = &parsed
-	}
 
-	// type (income/expense)
-	if typeStr := c.Query("type"); typeStr != "" {
-		if typeStr != "income" && typeStr != "expense" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be 'income' or 'expense'"})
-			return
+		response := TransactionListResponse{
+			Items:      make([]TransactionResponse, len(transactions)),
+			NextCursor: encodeCursor(next),
 		}
-		filter.Type = &typeStr
-	}
+		for i, t := range transactions {
+			balance := t.RunningBalance.Format(t.Currency)
 
-	// user_id (фильтр по создателю транзакции)
-	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		filterUserID, err := strconv.Atoi(userIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id format"})
-			return
+			response.Items[i] = newTransactionResponse(&t.TaggedTransaction)
+			response.Items[i].RunningBalance = &balance
 		}
-		filter.UserID = &filterUserID
+
+		c.JSON(http.StatusOK, response)
+		return
 	}
 
-	transactions, err := h.service.List(
+	transactions, next, err := h.service.List(
 		c.Request.Context(),
 		accountID,
 		userID.(int),
 		filter,
 	)
 	if err != nil {
-		if err == usecases.ErrForbidden {
+		writeListError(c, err)
+		return
+	}
+
+	response := TransactionListResponse{
+		Items:      make([]TransactionResponse, len(transactions)),
+		NextCursor: encodeCursor(next),
+	}
+	for i := range transactions {
+		response.Items[i] = newTransactionResponse(&transactions[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportTransactions godoc
+// @Summary      Выгрузка транзакций в файл
+// @Description  Выгружает в файл все транзакции счёта, подходящие под фильтры, без постраничной выдачи. Фильтры, sort и order - те же, что у списка транзакций (/accounts/{id}/transactions); limit и cursor не используются. Форматы: csv (UTF-8 с BOM, чтобы Excel правильно показал кириллицу), xlsx (книга Excel с одним листом, даты и суммы - числа) и json (массив объектов). Колонки: id, date, title, amount, currency, account (название счёта), category (путь категории от верхнего уровня через " / "), tags (через запятую), notes, member_email (email автора транзакции) и recurring_rule_id. Даты выгружаются в UTC. Файл формируется по мере чтения транзакций, поэтому выгрузка большого счёта не занимает память сервера. Доступно всем участникам счёта; участники без права tx.view.any выгружают только свои транзакции.
+// @Tags         transactions
+// @Produce      text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        format query string false "Формат файла (по умолчанию csv)" Enums(csv, xlsx, json)
+// @Param        date_from query string false "Начальная дата (RFC3339). Включает транзакции с этой даты и позже" example(2024-12-01T00:00:00Z)
+// @Param        date_to query string false "Конечная дата (RFC3339). Включает транзакции до этой даты включительно" example(2024-12-31T23:59:59Z)
+// @Param        type query string false "Фильтр по типу транзакции" Enums(income, expense)
+// @Param        user_id query int false "Фильтр по ID пользователя (создателя транзакции)" example(42)
+// @Param        category_id query int false "Фильтр по категории, включая подкатегории" example(1)
+// @Param        tags query string false "Фильтр по меткам: any:метка1,метка2 или all:метка1,метка2" example(any:vacation-2026,reimbursable)
+// @Param        q query string false "Поиск подстроки в названии и примечании без учёта регистра, до 100 символов" example(ашан)
+// @Param        amount_min query string false "Нижняя граница суммы со знаком, включительно" example(-5000.00)
+// @Param        amount_max query string false "Верхняя граница суммы со знаком, включительно" example(-1000.00)
+// @Param        exclude_planned query bool false "Исключить ещё не наступившие вхождения периодических серий" example(true)
+// @Param        sort query string false "Поле сортировки (по умолчанию occurred_at)" Enums(occurred_at, amount, title)
+// @Param        order query string false "Направление сортировки (по умолчанию desc)" Enums(asc, desc)
+// @Success      200 {file} file "Файл выгрузки: transactions-<ID счёта>-<дата>.<формат>"
+// @Failure      400 {object} ErrorResponse "Неверный формат файла или параметры фильтрации"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта или у него нет нужного права"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/transactions/export [get]
+func (h *TransactionHandler) ExportTransactions(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+
+	writer, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseTransactionFilter(c, accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter.Sort = models.SortByOccurredAt
+	filter.Desc = true
+	if err := parseTransactionSort(c, filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Выгрузка большого счёта пишется дольше WriteTimeout сервера: снимаем для неё срок записи,
+	// иначе файл обрывается на середине
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		_ = c.Error(err)
+	}
+
+	filename := fmt.Sprintf("transactions-%d-%s.%s", accountID, time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	err = h.service.Export(c.Request.Context(), accountID, userID, filter, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Писатель ничего не пишет до первой транзакции, поэтому, пока ответ не начат,
+	// вместо файла ещё можно вернуть ошибку
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		writeListError(c, err)
+		return
+	}
+
+	// Файл уже частично отправлен: закрываем соединение без завершения ответа,
+	// чтобы клиент не принял обрезанный файл за полный
+	_ = c.Error(err)
+	c.Abort()
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// GetStatement godoc
+// @Summary      Выписка по счёту в PDF
+// @Description  Возвращает выписку по счёту за период в PDF для печати: остаток на начало периода, все транзакции периода по порядку даты с остатком после каждой, остаток на конец периода, поступления и расходы за период, итоги по категориям (путь категории от верхнего уровня) и по участникам (email автора транзакции). Период задаётся месяцем month (YYYY-MM) или датами date_from и date_to (RFC3339, обе включительно); без параметров выписка строится за текущий месяц. Период - не больше года. Даты в выписке указаны в UTC. Запланированные вхождения периодических серий, попавшие в период, включаются в выписку. Доступно участникам с правами reports.view и tx.view.any (всем встроенным ролям).
+// @Tags         transactions
+// @Produce      application/pdf,json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        month query string false "Месяц выписки (YYYY-MM, UTC)" example(2024-12)
+// @Param        date_from query string false "Начало периода (RFC3339), вместо month" example(2024-12-01T00:00:00Z)
+// @Param        date_to query string false "Конец периода включительно (RFC3339), вместо month" example(2024-12-31T23:59:59Z)
+// @Success      200 {file} file "PDF-файл выписки: statement-<ID счёта>-<начало периода>.pdf"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта, month или дат; задан только один из date_from и date_to или вместе с month; период длиннее года или заканчивается раньше начала"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта или у него нет нужного права"
+// @Failure      404 {object} ErrorResponse "Счёт не найден"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/statement [get]
+func (h *TransactionHandler) GetStatement(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	from, to, err := parseStatementPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Выписка собирается в памяти: при ошибке клиент получает её, а не обрезанный файл
+	var document bytes.Buffer
+	if err := h.service.Statement(c.Request.Context(), accountID, userID, from, to, &document); err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrForbidden):
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
+		case err == usecases.ErrAccountNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err == usecases.ErrInvalidStatementPeriod:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	response := make([]TransactionResponse, len(transactions))
-	for i, t := range transactions {
-		var period *string
-		if t.Period.Valid {
-			periodStr := string(t.Period.TransactionsPeriod)
-			period = &periodStr
+	filename := fmt.Sprintf("statement-%d-%s.pdf", accountID, from.UTC().Format("2006-01-02"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", document.Bytes())
+}
+
+// GetBalance godoc
+// @Summary      Остаток счёта
+// @Description  Возвращает остаток счёта на момент at: начальный остаток плюс сумма всех транзакций не позже at. Считается в БД, без загрузки транзакций. Доступно участникам с правом reports.view (всем встроенным ролям). Остаток на будущую дату учитывает запланированные вхождения периодических серий.
+// @Tags         transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        at query string false "Момент времени (RFC3339), по умолчанию текущий" example(2024-12-31T23:59:59Z)
+// @Success      200 {object} BalanceResponse "Остаток счёта"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта или даты"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта или у него нет нужного права"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/balance [get]
+func (h *TransactionHandler) GetBalance(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	at := time.Now()
+	if atStr := c.Query("at"); atStr != "" {
+		at, err = time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at format, use RFC3339"})
+			return
 		}
+	}
 
-		response[i] = TransactionResponse{
-			ID:         t.ID,
-			AccountID:  t.AccountID,
-			UserID:     t.UserID,
-			Title:      t.Title,
-			Amount:     decimalToFloat(t.Amount),
-			OccurredAt: t.OccurredAt,
-			Period:     period,
+	balance, err := h.service.Balance(c.Request.Context(), accountID, userID, at)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err == usecases.ErrAccountNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		}
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, BalanceResponse{
+		AccountID:      accountID,
+		Currency:       string(balance.Currency),
+		At:             at,
+		OpeningBalance: balance.OpeningBalance.Format(balance.Currency),
+		Balance:        balance.Balance.Format(balance.Currency),
+	})
 }
 
 // UpdateTransaction godoc
 // @Summary      Обновление транзакции
-// @Description  Обновляет поля транзакции: title, amount, occurred_at. Поле period обновить нельзя. Права доступа: Editor может редактировать только свои транзакции (созданные им), Admin и Owner могут редактировать любые транзакции. Viewer не может редактировать транзакции. При обновлении периодической транзакции изменяется только одна запись, а не вся серия.
+// @Description  Обновляет поля транзакции: title, amount, occurred_at, category_id. Поле period обновить нельзя. Без category_id транзакция остаётся без категории, без notes - без примечания. tags заменяет метки транзакции целиком, пустой массив удаляет все метки; без tags метки не меняются. Права доступа: с правом tx.edit.own (Editor) можно редактировать только свои транзакции (созданные им), с tx.edit.any (Admin и Owner) - любые транзакции. Viewer не может редактировать транзакции. Для новой суммы нужно право на создание транзакции того же знака. Для вхождения периодической серии параметр scope определяет, что изменится: this - только эта запись, following - это и все следующие вхождения (серия разделяется на две), all - вся серия, включая прошедшие вхождения. Для following и all изменение occurred_at сдвигает расписание серии на ту же величину, а права проверяются по правилу повторения. При all вхождения меняются на месте и сохраняют ID; у них меняются только поля, отличающиеся от прежних параметров серии, поэтому правки отдельных вхождений в остальных полях сохраняются.
 // @Tags         transactions
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "ID транзакции для обновления" example(123)
+// @Param        scope query string false "Область изменения для вхождения серии (по умолчанию this)" Enums(this, following, all)
 // @Param        request body UpdateTransactionRequest true "Новые данные транзакции. Все поля обязательны."
 // @Success      200 {object} MessageResponse "Транзакция успешно обновлена"
-// @Failure      400 {object} ErrorResponse "Неверный формат данных или ID транзакции"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных или ID транзакции, неверный scope или транзакция не входит в серию"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может редактировать только свои транзакции, Admin/Owner - любые"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может редактировать только свои транзакции, Admin/Owner - любые. В ошибке указано недостающее право"
 // @Failure      404 {object} ErrorResponse "Транзакция с указанным ID не найдена"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при обновлении транзакции"
 // @Router       /transactions/{id} [patch]
@@ -253,6 +536,12 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseSeriesScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -284,25 +573,30 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 		return
 	}
 
-	// Обновляем транзакцию
-	err = h.service.Update(
-		c.Request.Context(),
-		int32(transactionID),
-		int(transaction.AccountID),
-		userID.(int),
-		int(transaction.UserID),
-		&models.UpdateTransactionParams{
-			Title:      req.Title,
-			Amount:     floatToDecimal(req.Amount),
-			OccurredAt: occurredAt,
-		},
-	)
+	params := &models.UpdateTransactionParams{
+		Title:      req.Title,
+		Amount:     req.Amount,
+		OccurredAt: occurredAt,
+		CategoryID: req.CategoryID,
+		Notes:      req.Notes,
+		Tags:       req.Tags,
+	}
+
+	// Обновляем транзакцию или серию, в которую она входит
+	if scope == models.SeriesScopeThis {
+		err = h.service.Update(
+			c.Request.Context(),
+			int32(transactionID),
+			int(transaction.AccountID),
+			userID.(int),
+			transaction.UserID,
+			params,
+		)
+	} else {
+		err = h.service.UpdateSeries(c.Request.Context(), transaction, userID.(int), scope, params)
+	}
 	if err != nil {
-		if err == usecases.ErrForbidden {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		writeSeriesError(c, err)
 		return
 	}
 
@@ -311,14 +605,15 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 
 // DeleteTransaction godoc
 // @Summary      Удаление транзакции
-// @Description  Удаляет транзакцию из счёта. Права доступа: Editor может удалять только свои транзакции (созданные им), Admin и Owner могут удалять любые транзакции. Viewer не может удалять транзакции. Операция необратима. Транзакция автоматически получается по ID для проверки прав доступа. ВАЖНО: при удалении периодической транзакции удаляется только одна запись, а не вся серия.
+// @Description  Удаляет транзакцию из счёта. Права доступа: с правом tx.edit.own (Editor) можно удалять только свои транзакции (созданные им), с tx.edit.any (Admin и Owner) - любые транзакции. Viewer не может удалять транзакции. Операция необратима. Транзакция автоматически получается по ID для проверки прав доступа. Для вхождения периодической серии параметр scope определяет, что удалится: this - только эта запись, following - это и все следующие вхождения (серия завершается перед ним), all - вся серия вместе с прошедшими вхождениями.
 // @Tags         transactions
 // @Security     BearerAuth
 // @Param        id path int true "ID транзакции для удаления" example(123)
+// @Param        scope query string false "Область удаления для вхождения серии (по умолчанию this)" Enums(this, following, all)
 // @Success      204 "Транзакция успешно удалена"
-// @Failure      400 {object} ErrorResponse "Неверный формат ID транзакции"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID транзакции, неверный scope или транзакция не входит в серию"
 // @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
-// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может удалять только свои транзакции, Admin/Owner - любые"
+// @Failure      403 {object} ErrorResponse "Недостаточно прав. Editor может удалять только свои транзакции, Admin/Owner - любые. В ошибке указано недостающее право"
 // @Failure      404 {object} ErrorResponse "Транзакция с указанным ID не найдена"
 // @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера при удалении транзакции"
 // @Router       /transactions/{id} [delete]
@@ -331,6 +626,12 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 		return
 	}
 
+	scope, err := parseSeriesScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Получаем транзакцию для проверки прав
 	transaction, err := h.service.GetByID(c.Request.Context(), int32(transactionID))
 	if err != nil {
@@ -342,23 +643,482 @@ func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(
-		c.Request.Context(),
-		int(transaction.AccountID),
-		userID.(int),
-		int(transaction.UserID),
-		transactionID,
-	)
+	if scope == models.SeriesScopeThis {
+		err = h.service.Delete(
+			c.Request.Context(),
+			int(transaction.AccountID),
+			userID.(int),
+			transaction.UserID,
+			transactionID,
+		)
+	} else {
+		err = h.service.DeleteSeries(c.Request.Context(), transaction, userID.(int), scope)
+	}
 	if err != nil {
-		if err == usecases.ErrForbidden {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		writeSeriesError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetAccountSummary godoc
+// @Summary      Итоги по счёту
+// @Description  Возвращает доходы, расходы и итог по транзакциям счёта за период отдельно для каждой валюты. Суммы записаны с числом знаков дробной части валюты (minor_units): 2 для RUB, 0 для JPY. Доступно участникам с правом reports.view (всем встроенным ролям). По умолчанию период заканчивается текущим моментом, поэтому будущие вхождения периодических серий не учитываются. Если указан параметр currency, в поле converted возвращаются итоги, пересчитанные в эту валюту по курсам пользователя (см. /exchange-rates) на дату каждой транзакции.
+// @Tags         transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "ID счёта" example(1)
+// @Param        date_from query string false "Начальная дата (RFC3339)" example(2024-12-01T00:00:00Z)
+// @Param        date_to query string false "Конечная дата (RFC3339), по умолчанию текущий момент" example(2024-12-31T23:59:59Z)
+// @Param        currency query string false "Валюта отчёта (ISO 4217)" example(USD)
+// @Success      200 {object} SummaryResponse "Итоги по валютам. Пустой список, если транзакций нет"
+// @Failure      400 {object} ErrorResponse "Неверный формат ID счёта, дат или валюты"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Пользователь не является участником данного счёта или у него нет нужного права"
+// @Failure      422 {object} ErrorResponse "Нет курса для пересчёта одной из валют на дату транзакции"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/{id}/summary [get]
+func (h *TransactionHandler) GetAccountSummary(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	filter, err := parseSummaryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportCurrency, err := parseReportCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	totals, err := h.service.Summary(c.Request.Context(), accountID, userID, filter)
+	if err != nil {
+		writeSummaryError(c, err)
+		return
+	}
+
+	response := newSummaryResponse(totals)
+
+	if reportCurrency != "" {
+		converted, err := h.service.ConvertedSummary(c.Request.Context(), accountID, userID, filter, reportCurrency)
+		if err != nil {
+			writeSummaryError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		response.Converted = newConvertedTotalResponse(converted)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSummary godoc
+// @Summary      Итоги по всем счетам
+// @Description  Возвращает доходы, расходы и итог по транзакциям всех счетов пользователя за период отдельно для каждой валюты. Суммы в разных валютах не складываются. Счета, в которых у пользователя нет права reports.view, не учитываются. По умолчанию период заканчивается текущим моментом. Если указан параметр currency, в поле converted возвращаются итоги всех счетов, пересчитанные в эту валюту по курсам пользователя на дату каждой транзакции.
+// @Tags         transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        date_from query string false "Начальная дата (RFC3339)" example(2024-12-01T00:00:00Z)
+// @Param        date_to query string false "Конечная дата (RFC3339), по умолчанию текущий момент" example(2024-12-31T23:59:59Z)
+// @Param        currency query string false "Валюта отчёта (ISO 4217)" example(RUB)
+// @Success      200 {object} SummaryResponse "Итоги по валютам. Пустой список, если транзакций нет"
+// @Failure      400 {object} ErrorResponse "Неверный формат дат или валюты"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      422 {object} ErrorResponse "Нет курса для пересчёта одной из валют на дату транзакции"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /accounts/summary [get]
+func (h *TransactionHandler) GetSummary(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	filter, err := parseSummaryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	reportCurrency, err := parseReportCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	totals, err := h.service.SummaryForUser(c.Request.Context(), userID, filter)
+	if err != nil {
+		writeSummaryError(c, err)
+		return
+	}
+
+	response := newSummaryResponse(totals)
+
+	if reportCurrency != "" {
+		converted, err := h.service.ConvertedSummaryForUser(c.Request.Context(), userID, filter, reportCurrency)
+		if err != nil {
+			writeSummaryError(c, err)
+			return
+		}
+		response.Converted = newConvertedTotalResponse(converted)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseSummaryFilter читает период итогов из date_from и date_to
+func parseSummaryFilter(c *gin.Context) (*models.SummaryFilter, error) {
+	filter := &models.SummaryFilter{DateTo: time.Now()}
+
+	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, dateFromStr)
+		if err != nil {
+			return nil, errors.New("invalid date_from format, use RFC3339")
+		}
+		filter.DateFrom = &parsed
+	}
+
+	if dateToStr := c.Query("date_to"); dateToStr != "" {
+		parsed, err := time.Parse(time.RFC3339, dateToStr)
+		if err != nil {
+			return nil, errors.New("invalid date_to format, use RFC3339")
+		}
+		filter.DateTo = parsed
+	}
+
+	return filter, nil
+}
+
+// parseReportCurrency читает валюту отчёта из параметра currency. Пустая строка - без пересчёта
+func parseReportCurrency(c *gin.Context) (money.Currency, error) {
+	code := c.Query("currency")
+	if code == "" {
+		return "", nil
+	}
+
+	return money.ParseCurrency(code)
+}
+
+// writeListError отвечает ошибкой получения списка транзакций
+func writeListError(c *gin.Context, err error) {
+	if errors.Is(err, usecases.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch err {
+	case usecases.ErrCategoryNotFound, usecases.ErrInvalidTag, usecases.ErrTooManyTags,
+		usecases.ErrInvalidLimit, usecases.ErrCursorMismatch, usecases.ErrSearchTooLong, usecases.ErrInvalidAmountRange:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// writeSummaryError отвечает ошибкой построения итогов
+func writeSummaryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrExchangeRateNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+func newConvertedTotalResponse(t *models.ConvertedTotal) *ConvertedTotalResponse {
+	return &ConvertedTotalResponse{
+		Currency:   string(t.Currency),
+		MinorUnits: t.Currency.MinorUnits(),
+		Income:     t.Income.Format(t.Currency),
+		Expense:    t.Expense.Format(t.Currency),
+		Total:      t.Total.Format(t.Currency),
+	}
+}
+
+func newSummaryResponse(totals []models.CurrencyTotal) SummaryResponse {
+	response := SummaryResponse{Totals: make([]CurrencyTotalResponse, len(totals))}
+	for i, t := range totals {
+		response.Totals[i] = CurrencyTotalResponse{
+			Currency:   string(t.Currency),
+			MinorUnits: t.Currency.MinorUnits(),
+			Count:      t.Count,
+			Income:     t.Income.Format(t.Currency),
+			Expense:    t.Expense.Format(t.Currency),
+			Total:      t.Total.Format(t.Currency),
+		}
+	}
+
+	return response
+}
+
+func newTransactionResponse(t *models.TaggedTransaction) TransactionResponse {
+	var period *string
+	if t.Period.Valid {
+		periodStr := string(t.Period.TransactionsPeriod)
+		period = &periodStr
+	}
+
+	var ruleID *int32
+	if t.RuleID.Valid {
+		ruleID = &t.RuleID.Int32
+	}
+
+	var categoryID *int32
+	if t.CategoryID.Valid {
+		categoryID = &t.CategoryID.Int32
+	}
+
+	var notes *string
+	if t.Notes.Valid {
+		notes = &t.Notes.String
+	}
+
+	var userID *int32
+	if t.UserID.Valid {
+		userID = &t.UserID.Int32
+	}
+
+	return TransactionResponse{
+		ID:         t.ID,
+		AccountID:  t.AccountID,
+		UserID:     userID,
+		Title:      t.Title,
+		Amount:     t.Amount.Format(t.Currency),
+		Currency:   string(t.Currency),
+		OccurredAt: t.OccurredAt,
+		Period:     period,
+		RuleID:     ruleID,
+		CategoryID: categoryID,
+		Notes:      notes,
+		Tags:       tagsOrEmpty(t.Tags),
+	}
+}
+
+// tagsOrEmpty возвращает пустой массив вместо nil, чтобы в JSON было [], а не null
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func idsOrEmpty(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+	return ids
+}
+
+// parseTransactionFilter читает фильтры списка транзакций счёта: даты, тип, автора, категорию,
+// поиск, диапазон суммы, запланированные вхождения и метки
+func parseTransactionFilter(c *gin.Context, accountID int) (*models.ListTransactionsFilter, error) {
+	filter := &models.ListTransactionsFilter{
+		AccountID: accountID,
+	}
+
+	// date_from
+	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, dateFromStr)
+		if err != nil {
+			return nil, errors.New("invalid date_from format, use RFC3339")
+		}
+		filter.DateFrom = &parsed
+	}
+
+	// date_to
+	if dateToStr := c.Query("date_to"); dateToStr != "" {
+		parsed, err := time.Parse(time.RFC3339, dateToStr)
+		if err != nil {
+			return nil, errors.New("invalid date_to format, use RFC3339")
+		}
+		filter.DateTo = &parsed
+	}
+
+	// type (income/expense)
+	if typeStr := c.Query("type"); typeStr != "" {
+		if typeStr != "income" && typeStr != "expense" {
+			return nil, errors.New("type must be 'income' or 'expense'")
+		}
+		filter.Type = &typeStr
+	}
+
+	// user_id (фильтр по создателю транзакции)
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		filterUserID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			return nil, errors.New("invalid user_id format")
+		}
+		filter.UserID = &filterUserID
+	}
+
+	// category_id (категория вместе с подкатегориями)
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		categoryID, err := strconv.Atoi(categoryIDStr)
+		if err != nil {
+			return nil, errors.New("invalid category_id format")
+		}
+		filter.CategoryID = &categoryID
+	}
+
+	// q (поиск по названию и примечанию)
+	filter.Search = c.Query("q")
+
+	// amount_min (нижняя граница суммы со знаком)
+	if amountStr := c.Query("amount_min"); amountStr != "" {
+		amount, err := money.Parse(amountStr)
+		if err != nil {
+			return nil, errors.New("invalid amount_min format")
+		}
+		filter.AmountMin = &amount
+	}
+
+	// amount_max (верхняя граница суммы со знаком)
+	if amountStr := c.Query("amount_max"); amountStr != "" {
+		amount, err := money.Parse(amountStr)
+		if err != nil {
+			return nil, errors.New("invalid amount_max format")
+		}
+		filter.AmountMax = &amount
+	}
+
+	// exclude_planned (без ещё не наступивших вхождений серий)
+	if plannedStr := c.Query("exclude_planned"); plannedStr != "" {
+		excludePlanned, err := strconv.ParseBool(plannedStr)
+		if err != nil {
+			return nil, errors.New("invalid exclude_planned format")
+		}
+		if excludePlanned {
+			now := time.Now()
+			filter.PlannedAfter = &now
+		}
+	}
+
+	// tags (any:a,b или all:a,b)
+	if tagsStr := c.Query("tags"); tagsStr != "" {
+		tagFilter, err := parseTagFilter(tagsStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Tags = tagFilter
+	}
+
+	return filter, nil
+}
+
+// parseStatementPeriod читает период выписки: месяц month или даты date_from и date_to.
+// Без параметров - текущий месяц
+func parseStatementPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	month := c.Query("month")
+	dateFrom := c.Query("date_from")
+	dateTo := c.Query("date_to")
+
+	if dateFrom != "" || dateTo != "" {
+		if month != "" || dateFrom == "" || dateTo == "" {
+			return time.Time{}, time.Time{}, errors.New("use either month or both date_from and date_to")
+		}
+
+		from, err := time.Parse(time.RFC3339, dateFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid date_from format, use RFC3339")
+		}
+
+		to, err := time.Parse(time.RFC3339, dateTo)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid date_to format, use RFC3339")
+		}
+
+		return from, to, nil
+	}
+
+	start := time.Now().UTC()
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if month != "" {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid month format, use YYYY-MM")
+		}
+		start = parsed
+	}
+
+	// Месяц заканчивается последней секундой перед началом следующего
+	return start, start.AddDate(0, 1, 0).Add(-time.Second), nil
+}
+
+// parseTransactionPage читает размер страницы, курсор и порядок сортировки списка транзакций.
+// Если передан только курсор, порядок сортировки берётся из него
+func parseTransactionPage(c *gin.Context, filter *models.ListTransactionsFilter) error {
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return errors.New("invalid limit format")
+		}
+		filter.Limit = limit
+	}
+
+	filter.Sort = models.SortByOccurredAt
+	filter.Desc = true
+
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := models.DecodeTransactionCursor(cursorStr)
+		if err != nil {
+			return err
+		}
+		filter.After = cursor
+		filter.Sort = cursor.Sort
+		filter.Desc = cursor.Desc
+	}
+
+	return parseTransactionSort(c, filter)
+}
+
+// parseTransactionSort читает поле sort и направление order сортировки транзакций
+// поверх уже заданного в фильтре порядка
+func parseTransactionSort(c *gin.Context, filter *models.ListTransactionsFilter) error {
+	if sort := c.Query("sort"); sort != "" {
+		switch models.TransactionSort(sort) {
+		case models.SortByOccurredAt, models.SortByAmount, models.SortByTitle:
+			filter.Sort = models.TransactionSort(sort)
+		default:
+			return errors.New("sort must be one of: occurred_at, amount, title")
+		}
+	}
+
+	if order := c.Query("order"); order != "" {
+		if order != "asc" && order != "desc" {
+			return errors.New("order must be 'asc' or 'desc'")
+		}
+		filter.Desc = order == "desc"
+	}
+
+	return nil
+}
+
+func encodeCursor(cursor *models.TransactionCursor) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	encoded := cursor.Encode()
+	return &encoded
+}
+
+// parseTagFilter разбирает фильтр по меткам вида any:a,b или all:a,b
+func parseTagFilter(value string) (*models.TagFilter, error) {
+	mode, list, ok := strings.Cut(value, ":")
+	if !ok || (mode != "any" && mode != "all") {
+		return nil, errors.New("tags filter must look like any:a,b or all:a,b")
+	}
+
+	return &models.TagFilter{
+		Names: strings.Split(list, ","),
+		All:   mode == "all",
+	}, nil
 }
 
 // parsePeriod конвертирует строку в TransactionsPeriod с валидацией
@@ -381,11 +1141,32 @@ func parsePeriod(period string) (query.TransactionsPeriod, error) {
 	}
 }
 
-func floatToDecimal(v float64) string {
-	return strconv.FormatFloat(v, 'f', 2, 64)
+// parseSeriesScope конвертирует строку в SeriesScope, пустая строка означает this
+func parseSeriesScope(scope string) (models.SeriesScope, error) {
+	switch models.SeriesScope(scope) {
+	case "", models.SeriesScopeThis:
+		return models.SeriesScopeThis, nil
+	case models.SeriesScopeFollowing, models.SeriesScopeAll:
+		return models.SeriesScope(scope), nil
+	default:
+		return "", errors.New("scope must be one of: this, following, all")
+	}
 }
 
-func decimalToFloat(s string) float64 {
-	double, _ := strconv.ParseFloat(s, 64)
-	return double
+// writeSeriesError отвечает ошибкой изменения транзакции или её серии
+func writeSeriesError(c *gin.Context, err error) {
+	if errors.Is(err, usecases.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch err {
+	case usecases.ErrRecurringRuleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case usecases.ErrNotInSeries, usecases.ErrInvalidRecurringRule, usecases.ErrAmountPrecision,
+		usecases.ErrCategoryNotFound, usecases.ErrNotesTooLong, usecases.ErrInvalidTag, usecases.ErrTooManyTags:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
 }
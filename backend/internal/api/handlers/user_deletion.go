@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserDeletionHandler struct {
+	service *usecases.UserDeletionService
+}
+
+func NewUserDeletionHandler(service *usecases.UserDeletionService) *UserDeletionHandler {
+	return &UserDeletionHandler{service: service}
+}
+
+// DeletionAccountRequest представляет выбор для счёта, владельцем которого является пользователь.
+// new_owner_id обязателен для action=transfer и не указывается для action=delete
+type DeletionAccountRequest struct {
+	AccountID  int    `json:"account_id" binding:"required" example:"3"`
+	Action     string `json:"action" binding:"required,oneof=transfer delete" enums:"transfer,delete" example:"transfer"`
+	NewOwnerID *int   `json:"new_owner_id" example:"42"`
+}
+
+// DeleteUserRequest представляет запрос на удаление аккаунта
+type DeleteUserRequest struct {
+	Password string                   `json:"password" binding:"required" example:"securePassword123"`
+	Accounts []DeletionAccountRequest `json:"accounts" binding:"omitempty,dive"`
+}
+
+// DeletionAccountResponse представляет, что будет со счётом пользователя при удалении.
+// default - выбор сделан сервером: счёт не был указан в запросе или выбранный участник покинул счёт
+type DeletionAccountResponse struct {
+	AccountID  int    `json:"account_id" binding:"required" example:"3"`
+	Name       string `json:"name" binding:"required" example:"Семейный бюджет"`
+	Action     string `json:"action" binding:"required" enums:"transfer,delete" example:"transfer"`
+	NewOwnerID *int   `json:"new_owner_id" example:"42"`
+	Default    bool   `json:"default" binding:"required" example:"false"`
+}
+
+// UserDeletionResponse представляет запланированное удаление аккаунта
+type UserDeletionResponse struct {
+	RequestedAt time.Time                 `json:"requested_at" binding:"required" example:"2024-12-13T14:30:00Z"`
+	DeleteAt    time.Time                 `json:"delete_at" binding:"required" example:"2025-01-12T14:30:00Z"`
+	Accounts    []DeletionAccountResponse `json:"accounts" binding:"required"`
+}
+
+// DeleteUser godoc
+// @Summary      Удаление аккаунта
+// @Description  Планирует удаление аккаунта текущего пользователя после подтверждения паролем. Аккаунт удаляется по истечении срока ACCOUNT_DELETION_GRACE_PERIOD (по умолчанию 30 дней), до этого удаление можно отменить через DELETE /auth/account/deletion; вход и работа со счетами до удаления не ограничены. Для счетов, владельцем которых является пользователь, в accounts можно выбрать: transfer - передать счёт участнику new_owner_id (он станет владельцем, название при совпадении с его счётом получит номер), delete - удалить счёт со всеми данными. Для счетов, не указанных в accounts, и если выбранный участник к моменту удаления покинул счёт, владельцем становится участник с самой старшей ролью (admin, editor, viewer), а счёт без других участников удаляется. При удалении стираются email, пароль, сессии, курсы валют и участие в счетах; транзакции и правила повторения пользователя в оставшихся счетах сохраняются без автора (user_id становится null). Возвращает дату удаления и что будет с каждым счётом.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body DeleteUserRequest true "Пароль и выбор для счетов пользователя"
+// @Success      202 {object} UserDeletionResponse "Удаление запланировано"
+// @Failure      400 {object} ErrorResponse "Неверный формат данных, счёт указан дважды, не принадлежит пользователю или new_owner_id не является другим участником счёта"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      403 {object} ErrorResponse "Неверный пароль"
+// @Failure      409 {object} ErrorResponse "Удаление уже запланировано"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/account [delete]
+func (h *UserDeletionHandler) DeleteUser(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req DeleteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accounts := make(map[int]*int, len(req.Accounts))
+	for _, account := range req.Accounts {
+		if _, ok := accounts[account.AccountID]; ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account is listed more than once"})
+			return
+		}
+
+		switch {
+		case account.Action == models.DeletionTransferAccount && account.NewOwnerID == nil:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "new_owner_id is required to transfer an account"})
+			return
+		case account.Action == models.DeletionDeleteAccount && account.NewOwnerID != nil:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "new_owner_id must not be set to delete an account"})
+			return
+		}
+
+		accounts[account.AccountID] = account.NewOwnerID
+	}
+
+	deletion, err := h.service.Request(c.Request.Context(), userID, req.Password, accounts)
+	if err != nil {
+		switch err {
+		case usecases.ErrInvalidDeletionPlan:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case usecases.ErrInvalidPassword:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case usecases.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case usecases.ErrDeletionAlreadyScheduled:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newUserDeletionResponse(deletion))
+}
+
+// GetUserDeletion godoc
+// @Summary      Запланированное удаление аккаунта
+// @Description  Возвращает дату удаления аккаунта текущего пользователя и что на текущий момент будет с каждым его счётом. План пересчитывается при каждом запросе: если выбранный участник покинул счёт, выбор делает сервер (default=true).
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} UserDeletionResponse "Запланированное удаление"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Удаление не запланировано"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/account/deletion [get]
+func (h *UserDeletionHandler) GetUserDeletion(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	deletion, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		if err == usecases.ErrDeletionNotScheduled {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserDeletionResponse(deletion))
+}
+
+// CancelUserDeletion godoc
+// @Summary      Отмена удаления аккаунта
+// @Description  Отменяет запланированное удаление аккаунта текущего пользователя. Выбор для счетов сбрасывается.
+// @Tags         auth
+// @Security     BearerAuth
+// @Success      204 "Удаление отменено"
+// @Failure      401 {object} ErrorResponse "Отсутствует или невалидный JWT токен"
+// @Failure      404 {object} ErrorResponse "Удаление не запланировано"
+// @Failure      500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router       /auth/account/deletion [delete]
+func (h *UserDeletionHandler) CancelUserDeletion(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	if err := h.service.Cancel(c.Request.Context(), userID); err != nil {
+		if err == usecases.ErrDeletionNotScheduled {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func newUserDeletionResponse(deletion *models.UserDeletion) UserDeletionResponse {
+	response := UserDeletionResponse{
+		RequestedAt: deletion.RequestedAt,
+		DeleteAt:    deletion.DeleteAt,
+		Accounts:    make([]DeletionAccountResponse, len(deletion.Accounts)),
+	}
+
+	for i, account := range deletion.Accounts {
+		response.Accounts[i] = DeletionAccountResponse{
+			AccountID:  account.AccountID,
+			Name:       account.Name,
+			Action:     account.Action,
+			NewOwnerID: account.NewOwnerID,
+			Default:    account.Default,
+		}
+	}
+
+	return response
+}
@@ -5,12 +5,13 @@ import (
 	"strings"
 
 	"microservices/accounter/internal/tokens"
+	"microservices/accounter/internal/usecases"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware проверяет JWT токен и кладёт user_id в context
-func AuthMiddleware(jwtManager *tokens.JWTManager) gin.HandlerFunc {
+// AuthMiddleware проверяет JWT токен и его сессию и кладёт user_id и session_id в context
+func AuthMiddleware(jwtManager *tokens.JWTManager, sessions *usecases.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Проверяем заголовок
 		authHeader := c.GetHeader("Authorization")
@@ -36,15 +37,28 @@ func AuthMiddleware(jwtManager *tokens.JWTManager) gin.HandlerFunc {
 			}
 		}
 
-		userID, err := jwtManager.Parse(token)
+		claims, err := jwtManager.Parse(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Кладём user_id в context
-		c.Set("user_id", userID)
+		// Токен валиден, но сессия могла быть завершена с другого устройства
+		if err := sessions.Validate(c.Request.Context(), claims.UserID, claims.SessionID); err != nil {
+			if err == usecases.ErrSessionRevoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+
+		// Кладём user_id и session_id в context
+		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
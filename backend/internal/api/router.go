@@ -33,8 +33,21 @@ func SetupRouter(services *usecases.Service, jwtManager *tokens.JWTManager, db *
 
 	// Handlers
 	authHandler := handlers.NewAuthHandler(services.AuthScv)
+	sessionHandler := handlers.NewSessionHandler(services.SessionScv)
 	accountHandler := handlers.NewAccountHandler(services.AccountScv, services.AccountMember)
 	transactionHandler := handlers.NewTransactionHandler(services.TransactionScv)
+	recurringHandler := handlers.NewRecurringHandler(services.RecurringScv)
+	ratesHandler := handlers.NewExchangeRateHandler(services.RatesScv)
+	categoryHandler := handlers.NewCategoryHandler(services.CategoryScv)
+	tagHandler := handlers.NewTagHandler(services.TagScv)
+	importHandler := handlers.NewImportHandler(services.ImportScv)
+	duplicateHandler := handlers.NewDuplicateHandler(services.DuplicateScv)
+	archiveHandler := handlers.NewArchiveHandler(services.ArchiveScv)
+	deletionHandler := handlers.NewUserDeletionHandler(services.DeletionScv)
+	invitationHandler := handlers.NewInvitationHandler(services.AccountMember)
+	inviteLinkHandler := handlers.NewInviteLinkHandler(services.AccountMember)
+	roleHandler := handlers.NewAccountRoleHandler(services.AccountMember)
+	ownershipHandler := handlers.NewOwnershipTransferHandler(services.AccountScv)
 	healthHandler := handlers.NewHealthHandler(db)
 
 	router.GET("/health", healthHandler.Health)
@@ -44,16 +57,31 @@ func SetupRouter(services *usecases.Service, jwtManager *tokens.JWTManager, db *
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", authHandler.Logout)
 	}
 
 	// Protected routes
-	authMiddleware := middleware.AuthMiddleware(jwtManager)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, services.SessionScv)
 
 	auth = router.Group("/auth", authMiddleware)
 	{
 		auth.POST("/change-password", authHandler.ChangePassword)
 		auth.GET("/profile", authHandler.GetProfile)
-		auth.POST("/logout", authHandler.Logout)
+
+		// Sessions
+		auth.GET("/sessions", sessionHandler.ListSessions)
+		auth.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+		auth.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+		// Archive
+		auth.GET("/export", archiveHandler.ExportArchive)
+		auth.POST("/import", archiveHandler.ImportArchive)
+
+		// Account deletion
+		auth.DELETE("/account", deletionHandler.DeleteUser)
+		auth.GET("/account/deletion", deletionHandler.GetUserDeletion)
+		auth.DELETE("/account/deletion", deletionHandler.CancelUserDeletion)
 	}
 
 	// Accounts
@@ -61,8 +89,10 @@ func SetupRouter(services *usecases.Service, jwtManager *tokens.JWTManager, db *
 	{
 		accounts.GET("", accountHandler.ListUserAccounts)
 		accounts.POST("", accountHandler.CreateAccount)
+		accounts.GET("/summary", transactionHandler.GetSummary)
 		accounts.GET("/:id", accountHandler.GetAccount)
 		accounts.DELETE("/:id", accountHandler.DeleteAccount)
+		accounts.PUT("/:id/opening-balance", accountHandler.SetOpeningBalance)
 
 		// Members
 		accounts.GET("/:id/members", accountHandler.ListAccountMembers)
@@ -70,14 +100,87 @@ func SetupRouter(services *usecases.Service, jwtManager *tokens.JWTManager, db *
 		accounts.PATCH("/:id/members/:user_id", accountHandler.ChangeRole)
 		accounts.DELETE("/:id/members/:user_id", accountHandler.RemoveMember)
 
+		// Roles
+		accounts.GET("/:id/roles", roleHandler.ListRoles)
+		accounts.POST("/:id/roles", roleHandler.CreateRole)
+		accounts.PUT("/:id/roles/:role_id", roleHandler.UpdateRole)
+		accounts.DELETE("/:id/roles/:role_id", roleHandler.DeleteRole)
+
+		// Ownership transfer
+		accounts.POST("/:id/transfer-ownership", ownershipHandler.TransferOwnership)
+		accounts.GET("/:id/transfer-ownership", ownershipHandler.GetOwnershipTransfer)
+		accounts.DELETE("/:id/transfer-ownership", ownershipHandler.CancelOwnershipTransfer)
+		accounts.POST("/:id/transfer-ownership/accept", ownershipHandler.AcceptOwnershipTransfer)
+
+		// Invitations
+		accounts.GET("/:id/invitations", invitationHandler.ListAccountInvitations)
+		accounts.DELETE("/:id/invitations/:invitation_id", invitationHandler.RevokeInvitation)
+		accounts.GET("/:id/invite-links", inviteLinkHandler.ListInviteLinks)
+		accounts.POST("/:id/invite-links", inviteLinkHandler.CreateInviteLink)
+		accounts.DELETE("/:id/invite-links/:link_id", inviteLinkHandler.RevokeInviteLink)
+
 		// Transactions
 		accounts.POST("/:id/transactions", transactionHandler.CreateTransaction)
 		accounts.GET("/:id/transactions", transactionHandler.ListTransactions)
+		accounts.GET("/:id/transactions/export", transactionHandler.ExportTransactions)
+		accounts.GET("/:id/statement", transactionHandler.GetStatement)
+		accounts.GET("/:id/summary", transactionHandler.GetAccountSummary)
+		accounts.GET("/:id/balance", transactionHandler.GetBalance)
+
+		// Recurring rules
+		accounts.GET("/:id/recurring-rules", recurringHandler.ListRecurringRules)
+		accounts.POST("/:id/recurring-rules", recurringHandler.CreateRecurringRule)
+
+		// Categories
+		accounts.GET("/:id/categories", categoryHandler.ListCategories)
+		accounts.POST("/:id/categories", categoryHandler.CreateCategory)
+		accounts.PATCH("/:id/categories/:category_id", categoryHandler.UpdateCategory)
+		accounts.DELETE("/:id/categories/:category_id", categoryHandler.DeleteCategory)
+
+		// Tags
+		accounts.GET("/:id/tags", tagHandler.ListTags)
+
+		// Imports
+		accounts.POST("/:id/imports", importHandler.ImportTransactions)
+
+		// Duplicates
+		accounts.GET("/:id/duplicates", duplicateHandler.ListDuplicates)
+		accounts.POST("/:id/duplicates/merge", duplicateHandler.MergeDuplicates)
+		accounts.POST("/:id/duplicates/dismiss", duplicateHandler.DismissDuplicate)
+	}
+
+	// Invitations
+	invitations := router.Group("/invitations", authMiddleware)
+	{
+		invitations.GET("", invitationHandler.ListUserInvitations)
+		invitations.POST("/:token/accept", invitationHandler.AcceptInvitation)
+		invitations.POST("/:token/decline", invitationHandler.DeclineInvitation)
 	}
 
+	// Invite links
+	router.POST("/invites/:code/join", authMiddleware, inviteLinkHandler.JoinByInviteLink)
+
 	// Transactions
 	router.DELETE("/transactions/:id", authMiddleware, transactionHandler.DeleteTransaction)
 	router.PATCH("/transactions/:id", authMiddleware, transactionHandler.UpdateTransaction)
 
+	// Recurring rules
+	rules := router.Group("/recurring-rules", authMiddleware)
+	{
+		rules.PATCH("/:id", recurringHandler.UpdateRecurringRule)
+		rules.DELETE("/:id", recurringHandler.DeleteRecurringRule)
+		rules.POST("/:id/pause", recurringHandler.PauseRecurringRule)
+		rules.POST("/:id/resume", recurringHandler.ResumeRecurringRule)
+	}
+
+	// Exchange rates
+	rates := router.Group("/exchange-rates", authMiddleware)
+	{
+		rates.GET("", ratesHandler.ListExchangeRates)
+		rates.PUT("", ratesHandler.SaveExchangeRate)
+		rates.POST("/import", ratesHandler.ImportExchangeRates)
+		rates.DELETE("/:id", ratesHandler.DeleteExchangeRate)
+	}
+
 	return router
 }
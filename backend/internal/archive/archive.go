@@ -0,0 +1,241 @@
+// Package archive описывает переносимый архив данных пользователя: счета, которыми он владеет,
+// их участников, категории, правила повторения, транзакции и курсы валют. Архив - это JSON
+// или ZIP с одним файлом archive.json. Идентификаторы в архиве - исходные, при импорте
+// они заменяются новыми, а ссылки между записями пересчитываются
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+// Format - метка формата архива, по которой импорт отличает архив от другого JSON
+const Format = "accounter-archive"
+
+// Version - версия формата архива. Импорт принимает архивы версии не выше текущей
+const Version = 1
+
+// Форматы файла архива
+const (
+	FileZIP  = "zip"
+	FileJSON = "json"
+)
+
+// fileName - имя файла с данными внутри ZIP
+const fileName = "archive.json"
+
+// maxDecodedSize ограничивает размер распакованного archive.json
+const maxDecodedSize = 256 << 20
+
+var (
+	ErrUnsupportedFile    = errors.New("unsupported archive format, use zip or json")
+	ErrUnsupportedVersion = fmt.Errorf("unsupported archive version, expected at most %d", Version)
+)
+
+// Archive - данные пользователя на момент выгрузки
+type Archive struct {
+	Format        string         `json:"format"`
+	Version       int            `json:"version"`
+	ExportedAt    time.Time      `json:"exported_at"`
+	User          User           `json:"user"`
+	ExchangeRates []ExchangeRate `json:"exchange_rates"`
+	Accounts      []Account      `json:"accounts"`
+}
+
+// User - владелец архива. При импорте его записи переходят к импортирующему пользователю
+type User struct {
+	Email string `json:"email"`
+}
+
+// ExchangeRate - курс пользователя на дату: одна единица Base стоит Rate единиц Quote
+type ExchangeRate struct {
+	Base  money.Currency `json:"base"`
+	Quote money.Currency `json:"quote"`
+	Date  string         `json:"date"` // 2006-01-02
+	Rate  string         `json:"rate"`
+}
+
+// Account - счёт со всеми данными
+type Account struct {
+	ID                  int                  `json:"id"`
+	Name                string               `json:"name"`
+	Description         *string              `json:"description"`
+	Currency            money.Currency       `json:"currency"`
+	OpeningBalance      money.Amount         `json:"opening_balance"`
+	Members             []Member             `json:"members"`
+	Roles               []Role               `json:"roles,omitempty"`
+	Categories          []Category           `json:"categories"`
+	RecurringRules      []RecurringRule      `json:"recurring_rules"`
+	Transactions        []Transaction        `json:"transactions"`
+	DuplicateDismissals []DuplicateDismissal `json:"duplicate_dismissals"`
+}
+
+// Member - участник счёта. Пользователи разных серверов сопоставляются по email.
+// У участника с ролью custom CustomRole - название пользовательской роли счёта из Roles
+type Member struct {
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	CustomRole string `json:"custom_role,omitempty"`
+}
+
+// Role - пользовательская роль счёта с её правами
+type Role struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type Category struct {
+	ID       int    `json:"id"`
+	ParentID *int   `json:"parent_id"`
+	Name     string `json:"name"`
+}
+
+// RecurringRule - правило повторения вместе с состоянием: уже созданные вхождения
+// лежат в Transactions и ссылаются на правило через RuleID
+type RecurringRule struct {
+	ID               int            `json:"id"`
+	AuthorEmail      string         `json:"author_email"`
+	Title            string         `json:"title"`
+	Amount           money.Amount   `json:"amount"`
+	Currency         money.Currency `json:"currency"`
+	Period           string         `json:"period"`
+	Interval         int            `json:"interval"`
+	StartsAt         time.Time      `json:"starts_at"`
+	EndsAt           *time.Time     `json:"ends_at"`
+	MaxOccurrences   *int           `json:"max_occurrences"`
+	Paused           bool           `json:"paused"`
+	NextIndex        int            `json:"next_index"`
+	NextOccurrenceAt time.Time      `json:"next_occurrence_at"`
+	OccurrencesCount int            `json:"occurrences_count"`
+	CategoryID       *int           `json:"category_id"`
+	Notes            *string        `json:"notes"`
+	Tags             []string       `json:"tags"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+type Transaction struct {
+	ID          int            `json:"id"`
+	AuthorEmail string         `json:"author_email"`
+	Title       string         `json:"title"`
+	Amount      money.Amount   `json:"amount"`
+	Currency    money.Currency `json:"currency"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Period      *string        `json:"period"`
+	RuleID      *int           `json:"rule_id"`
+	CategoryID  *int           `json:"category_id"`
+	Notes       *string        `json:"notes"`
+	ExternalRef *string        `json:"external_ref"`
+	Tags        []string       `json:"tags"`
+}
+
+// DuplicateDismissal - пара транзакций, отмеченная как разные операции
+type DuplicateDismissal struct {
+	TransactionID int `json:"transaction_id"`
+	DuplicateID   int `json:"duplicate_id"`
+}
+
+// ContentType возвращает MIME-тип файла архива
+func ContentType(file string) string {
+	if file == FileZIP {
+		return "application/zip"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Encode записывает архив в формате file
+func Encode(w io.Writer, file string, a *Archive) error {
+	switch file {
+	case FileJSON:
+		return encodeJSON(w, a)
+	case FileZIP:
+		zw := zip.NewWriter(w)
+
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     fileName,
+			Method:   zip.Deflate,
+			Modified: a.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := encodeJSON(f, a); err != nil {
+			return err
+		}
+
+		return zw.Close()
+	default:
+		return ErrUnsupportedFile
+	}
+}
+
+func encodeJSON(w io.Writer, a *Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Decode читает архив в формате ZIP или JSON: формат определяется по содержимому.
+// Содержимое записей не проверяется, это делает импорт
+func Decode(data []byte) (*Archive, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		var err error
+		if data, err = unzip(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var a Archive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+
+	if a.Format != Format {
+		return nil, fmt.Errorf("format must be %q", Format)
+	}
+	if a.Version < 1 || a.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	return &a, nil
+}
+
+// unzip возвращает содержимое archive.json из ZIP
+func unzip(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zr.File {
+		if f.Name != fileName {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		// Размер из заголовка ZIP не проверяется: читается не больше лимита
+		content, err := io.ReadAll(io.LimitReader(rc, maxDecodedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > maxDecodedSize {
+			return nil, fmt.Errorf("%s is too large", fileName)
+		}
+
+		return content, nil
+	}
+
+	return nil, fmt.Errorf("%s not found", fileName)
+}
@@ -23,14 +23,35 @@ type Logger struct {
 }
 
 type JWT struct {
-	Secret  string        `env:"JWT_SECRET"`
-	Expires time.Duration `env:"JWT_EXPIRES"`
+	Secret         string        `env:"JWT_SECRET"`
+	Expires        time.Duration `env:"JWT_EXPIRES" env-default:"15m"`
+	RefreshExpires time.Duration `env:"JWT_REFRESH_EXPIRES" env-default:"720h"`
+}
+
+type Recurring struct {
+	Horizon  time.Duration `env:"RECURRING_HORIZON" env-default:"8760h"`
+	Interval time.Duration `env:"RECURRING_SCHEDULER_INTERVAL" env-default:"1h"`
+}
+
+// Deletion - удаление аккаунтов пользователей: срок, в течение которого удаление можно отменить,
+// и период планировщика, удаляющего аккаунты с истёкшим сроком
+type Deletion struct {
+	GracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD" env-default:"720h"`
+	Interval    time.Duration `env:"ACCOUNT_DELETION_SCHEDULER_INTERVAL" env-default:"1h"`
+}
+
+// Invitation - срок, в течение которого можно принять приглашение в счёт или подтвердить передачу счёта
+type Invitation struct {
+	TTL time.Duration `env:"INVITATION_TTL" env-default:"168h"`
 }
 
 type Config struct {
 	Database
 	Logger
 	JWT
+	Recurring
+	Deletion
+	Invitation
 }
 
 func Load() (*Config, error) {
@@ -0,0 +1,93 @@
+// Package dedupe находит вероятные дубликаты транзакций: одну и ту же операцию,
+// импортированную дважды или введённую вручную после импорта выписки
+package dedupe
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"microservices/accounter/internal/money"
+)
+
+// Window - наибольшая разница дат у дубликатов. Банк проводит операцию на день-два позже,
+// чем её записывает пользователь
+const Window = 3 * 24 * time.Hour
+
+// Fingerprint - отпечаток транзакции: всё, по чему сравниваются дубликаты
+type Fingerprint struct {
+	ExternalRef string
+	OccurredAt  time.Time
+	Amount      money.Amount
+	Currency    money.Currency
+
+	// words - слова названия в нижнем регистре, без знаков препинания
+	words []string
+}
+
+// New возвращает отпечаток транзакции
+func New(externalRef *string, occurredAt time.Time, amount money.Amount, currency money.Currency, title string) Fingerprint {
+	f := Fingerprint{
+		OccurredAt: occurredAt,
+		Amount:     amount,
+		Currency:   currency,
+		words:      titleWords(title),
+	}
+
+	if externalRef != nil {
+		f.ExternalRef = *externalRef
+	}
+
+	return f
+}
+
+// Match сообщает, похожи ли транзакции на одну и ту же операцию. Транзакции с одинаковым
+// идентификатором выписки - дубликаты, с разными - нет: банк различает их сам. Иначе у дубликатов
+// совпадают сумма и валюта, даты отличаются не больше чем на Window, а слова одного
+// названия входят в другое ("Пятёрочка" и "ПЯТЁРОЧКА 1234 МОСКВА")
+func Match(a, b Fingerprint) bool {
+	if a.ExternalRef != "" && b.ExternalRef != "" {
+		return a.ExternalRef == b.ExternalRef
+	}
+
+	if a.Amount != b.Amount || a.Currency != b.Currency {
+		return false
+	}
+
+	diff := a.OccurredAt.Sub(b.OccurredAt)
+	if diff < -Window || diff > Window {
+		return false
+	}
+
+	if len(a.words) > len(b.words) {
+		a, b = b, a
+	}
+
+	// Название без букв и цифр ("---") совпадает только с таким же
+	if len(a.words) == 0 {
+		return len(b.words) == 0
+	}
+
+	words := make(map[string]bool, len(b.words))
+	for _, word := range b.words {
+		words[word] = true
+	}
+
+	for _, word := range a.words {
+		if !words[word] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// titleWords разбивает название на слова из букв и цифр в нижнем регистре. Ё приравнивается к Е:
+// банки пишут названия и так, и так
+func titleWords(title string) []string {
+	title = strings.ReplaceAll(strings.ToLower(title), "ё", "е")
+
+	return strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
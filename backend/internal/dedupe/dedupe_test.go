@@ -0,0 +1,159 @@
+package dedupe
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	at := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	ref := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		a    Fingerprint
+		b    Fingerprint
+		want bool
+	}{
+		{
+			name: "same title",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: true,
+		},
+		{
+			name: "yo equals ye",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "ПЯТЕРОЧКА"),
+			want: true,
+		},
+		{
+			name: "words subset",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "ПЯТЁРОЧКА 1234 МОСКВА"),
+			want: true,
+		},
+		{
+			name: "words subset reversed",
+			a:    New(nil, at, -150050, "RUB", "ПЯТЁРОЧКА 1234 МОСКВА"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: true,
+		},
+		{
+			name: "punctuation ignored",
+			a:    New(nil, at, -99900, "RUB", "Яндекс.Такси"),
+			b:    New(nil, at, -99900, "RUB", "YANDEX / яндекс такси"),
+			want: true,
+		},
+		{
+			name: "different words",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "Магнит"),
+			want: false,
+		},
+		{
+			name: "partial overlap",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка Москва"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка Казань"),
+			want: false,
+		},
+		{
+			name: "both titles empty",
+			a:    New(nil, at, -150050, "RUB", ""),
+			b:    New(nil, at, -150050, "RUB", "---"),
+			want: true,
+		},
+		{
+			name: "one title empty",
+			a:    New(nil, at, -150050, "RUB", ""),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "one title empty reversed",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "—"),
+			want: false,
+		},
+		{
+			name: "different amount",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150000, "RUB", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "different currency",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "USD", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "window boundary",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at.Add(Window), -150050, "RUB", "Пятёрочка"),
+			want: true,
+		},
+		{
+			name: "window boundary reversed",
+			a:    New(nil, at.Add(Window), -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: true,
+		},
+		{
+			name: "outside window",
+			a:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at.Add(Window+time.Second), -150050, "RUB", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "outside window reversed",
+			a:    New(nil, at.Add(Window+time.Second), -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "same external refs",
+			a:    New(ref("TX-1"), at, -150050, "RUB", "Пятёрочка"),
+			b:    New(ref("TX-1"), at.Add(30*24*time.Hour), -100, "USD", "Магнит"),
+			want: true,
+		},
+		{
+			name: "different external refs",
+			a:    New(ref("TX-1"), at, -150050, "RUB", "Пятёрочка"),
+			b:    New(ref("TX-2"), at, -150050, "RUB", "Пятёрочка"),
+			want: false,
+		},
+		{
+			name: "one external ref",
+			a:    New(ref("TX-1"), at, -150050, "RUB", "Пятёрочка"),
+			b:    New(nil, at, -150050, "RUB", "Пятёрочка"),
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTitleWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "---", want: nil},
+		{in: "Пятёрочка", want: []string{"пятерочка"}},
+		{in: "ПЯТЁРОЧКА 1234, МОСКВА", want: []string{"пятерочка", "1234", "москва"}},
+		{in: "Яндекс.Такси", want: []string{"яндекс", "такси"}},
+	}
+
+	for _, tt := range tests {
+		if got := titleWords(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("titleWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"microservices/accounter/internal/models"
+)
+
+// bom - метка порядка байтов: по ней Excel узнаёт, что CSV в UTF-8, и не портит кириллицу
+const bom = "\uFEFF"
+
+type csvWriter struct {
+	out     io.Writer
+	w       *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{out: w, w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+
+	if _, err := io.WriteString(c.out, bom); err != nil {
+		return err
+	}
+
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) Write(row *models.ExportRow) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	notes := ""
+	if row.Notes != nil {
+		notes = *row.Notes
+	}
+
+	ruleID := ""
+	if row.RuleID != nil {
+		ruleID = strconv.Itoa(int(*row.RuleID))
+	}
+
+	return c.w.Write([]string{
+		strconv.Itoa(int(row.ID)),
+		formatDate(row.OccurredAt),
+		csvText(row.Title),
+		row.Amount.Format(row.Currency),
+		string(row.Currency),
+		csvText(row.Account),
+		csvText(row.Category),
+		csvText(strings.Join(row.Tags, ", ")),
+		csvText(notes),
+		csvText(row.MemberEmail),
+		ruleID,
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvText защищает текст от выполнения как формулы: Excel считает формулой ячейку,
+// которая начинается с =, +, - или @, а названия транзакций приходят и из чужих выписок
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newCSVWriter(&out)
+
+	for _, row := range testRows() {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := strings.CutPrefix(out.String(), bom)
+	if !ok {
+		t.Fatalf("output does not start with BOM: %q", out.String()[:min(out.Len(), 10)])
+	}
+
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		columns,
+		{
+			"101", "2025-03-10 12:00:00", "Пятёрочка", "-1500.50", "RUB", "Семья",
+			"Еда / Продукты", "дом, еда", "'+79001234567 <курьер> & Co", "anna@example.com", "7",
+		},
+		{
+			// Текст, который Excel принял бы за формулу, начинается с апострофа, суммы - нет
+			"102", "2025-03-11 06:30:00", `'=HYPERLINK("http://evil.example","click")`, "1500", "JPY", "'@travel",
+			"", "'=cmd|' /C calc'!A0, trip", "", "'-bob@example.com", "",
+		},
+	}
+
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !slices.Equal(records[i], want[i]) {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestCSVWriterEmpty(t *testing.T) {
+	var out bytes.Buffer
+	w := newCSVWriter(&out)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := bom + strings.Join(columns, ",") + "\n"
+	if out.String() != want {
+		t.Errorf("Close() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "Пятёрочка", want: "Пятёрочка"},
+		{in: "=1+1", want: "'=1+1"},
+		{in: "+7900", want: "'+7900"},
+		{in: "-5", want: "'-5"},
+		{in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{in: "\tcmd", want: "'\tcmd"},
+		{in: "\rcmd", want: "'\rcmd"},
+		{in: "a=1", want: "a=1"},
+		{in: "'quoted", want: "'quoted"},
+	}
+
+	for _, tt := range tests {
+		if got := csvText(tt.in); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
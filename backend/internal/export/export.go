@@ -0,0 +1,68 @@
+// Package export записывает транзакции в файлы для бухгалтерии и табличных редакторов.
+// Писатели потоковые: строка записывается сразу, без накопления всей выгрузки в памяти
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format, use csv, xlsx or json")
+
+// columns - заголовки колонок CSV и XLSX в порядке записи
+var columns = []string{
+	"id", "date", "title", "amount", "currency", "account",
+	"category", "tags", "notes", "member_email", "recurring_rule_id",
+}
+
+// Writer записывает транзакции выгрузки. До первого Write или Close писатель ничего
+// не пишет в выходной поток, поэтому ошибку, случившуюся раньше, ещё можно вернуть клиенту
+type Writer interface {
+	Write(row *models.ExportRow) error
+
+	// Close дописывает окончание файла. Выгрузка без Close неполная
+	Close() error
+}
+
+// NewWriter возвращает писатель выгрузки в формате format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ContentType возвращает MIME-тип файла выгрузки
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// dateLayout - формат даты в CSV
+const dateLayout = "2006-01-02 15:04:05"
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := NewWriter("xls", &out); err != ErrUnsupportedFormat {
+		t.Errorf("NewWriter(xls) error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX, FormatJSON} {
+		if _, err := NewWriter(format, &out); err != nil {
+			t.Errorf("NewWriter(%s) error = %v", format, err)
+		}
+	}
+
+	// Писатель ничего не пишет до первого Write или Close
+	if out.Len() != 0 {
+		t.Errorf("NewWriter wrote %d bytes, want 0", out.Len())
+	}
+}
+
+// testRows возвращает транзакции выгрузки: с категорией, метками и серией и без них,
+// в валюте без дробной части и с текстом, похожим на формулы
+func testRows() []*models.ExportRow {
+	ref := func(v int32) *int32 { return &v }
+	notes := "+79001234567 <курьер> & Co"
+
+	return []*models.ExportRow{
+		{
+			ID:          101,
+			OccurredAt:  time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			Title:       "Пятёрочка",
+			Amount:      -150050,
+			Currency:    "RUB",
+			Account:     "Семья",
+			CategoryID:  ref(5),
+			Category:    "Еда / Продукты",
+			Tags:        []string{"дом", "еда"},
+			Notes:       &notes,
+			MemberEmail: "anna@example.com",
+			RuleID:      ref(7),
+		},
+		{
+			ID:          102,
+			OccurredAt:  time.Date(2025, 3, 11, 9, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			Title:       `=HYPERLINK("http://evil.example","click")`,
+			Amount:      150000,
+			Currency:    "JPY",
+			Account:     "@travel",
+			Tags:        []string{"=cmd|' /C calc'!A0", "trip"},
+			MemberEmail: "-bob@example.com",
+		},
+	}
+}
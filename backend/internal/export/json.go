@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+// jsonRow - транзакция в JSON-выгрузке
+type jsonRow struct {
+	ID              int32     `json:"id"`
+	OccurredAt      time.Time `json:"occurred_at"`
+	Title           string    `json:"title"`
+	Amount          string    `json:"amount"`
+	Currency        string    `json:"currency"`
+	Account         string    `json:"account"`
+	CategoryID      *int32    `json:"category_id"`
+	Category        *string   `json:"category"`
+	Tags            []string  `json:"tags"`
+	Notes           *string   `json:"notes"`
+	MemberEmail     string    `json:"member_email"`
+	RecurringRuleID *int32    `json:"recurring_rule_id"`
+}
+
+// jsonWriter пишет массив транзакций по одной, не собирая его целиком
+type jsonWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) Write(row *models.ExportRow) error {
+	separator := ",\n"
+	if !j.started {
+		separator = "[\n"
+		j.started = true
+	}
+
+	item := jsonRow{
+		ID:              row.ID,
+		OccurredAt:      row.OccurredAt,
+		Title:           row.Title,
+		Amount:          row.Amount.Format(row.Currency),
+		Currency:        string(row.Currency),
+		Account:         row.Account,
+		CategoryID:      row.CategoryID,
+		Tags:            row.Tags,
+		Notes:           row.Notes,
+		MemberEmail:     row.MemberEmail,
+		RecurringRuleID: row.RuleID,
+	}
+
+	if row.CategoryID != nil {
+		item.Category = &row.Category
+	}
+
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if !j.started {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
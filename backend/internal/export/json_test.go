@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONWriter(&out)
+
+	for _, row := range testRows() {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out.String())
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d items, want 2", len(got))
+	}
+
+	first, second := got[0], got[1]
+
+	checks := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{field: "id", got: first["id"], want: 101.0},
+		{field: "occurred_at", got: first["occurred_at"], want: "2025-03-10T12:00:00Z"},
+		{field: "amount", got: first["amount"], want: "-1500.50"},
+		{field: "currency", got: first["currency"], want: "RUB"},
+		{field: "category_id", got: first["category_id"], want: 5.0},
+		{field: "category", got: first["category"], want: "Еда / Продукты"},
+		{field: "notes", got: first["notes"], want: "+79001234567 <курьер> & Co"},
+		{field: "recurring_rule_id", got: first["recurring_rule_id"], want: 7.0},
+
+		// В JSON формулы не экранируются, а сумма записана с числом знаков валюты
+		{field: "title", got: second["title"], want: `=HYPERLINK("http://evil.example","click")`},
+		{field: "amount", got: second["amount"], want: "1500"},
+		{field: "occurred_at", got: second["occurred_at"], want: "2025-03-11T09:30:00+03:00"},
+		{field: "category_id", got: second["category_id"], want: nil},
+		{field: "category", got: second["category"], want: nil},
+		{field: "notes", got: second["notes"], want: nil},
+		{field: "recurring_rule_id", got: second["recurring_rule_id"], want: nil},
+	}
+
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.field, c.got, c.want)
+		}
+	}
+
+	if len(first) != 12 {
+		t.Errorf("item has %d fields, want 12", len(first))
+	}
+}
+
+func TestJSONWriterNilTags(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONWriter(&out)
+
+	row := testRows()[0]
+	row.Tags = nil
+
+	if err := w.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// Без меток - пустой массив, а не null
+	if len(got) != 1 || got[0].Tags == nil || len(got[0].Tags) != 0 {
+		t.Errorf("tags = %#v, want empty array\n%s", got, out.String())
+	}
+}
+
+func TestJSONWriterEmpty(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONWriter(&out)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "[]\n" {
+		t.Errorf("Close() wrote %q, want %q", out.String(), "[]\n")
+	}
+}
@@ -0,0 +1,242 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+// Стили ячеек: индексы cellXfs в xl/styles.xml
+const (
+	styleDate         = 1
+	styleAmount       = 2
+	styleAmountMinor0 = 3
+	styleHeader       = 4
+)
+
+// excelEpoch - нулевой день дат Excel. Даты хранятся числом дней от него
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter пишет книгу Excel с одним листом. Строки записываются как inline-строки,
+// без общей таблицы строк, поэтому лист пишется в архив сразу, строка за строкой
+type xlsxWriter struct {
+	out     io.Writer
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	row     int
+	started bool
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{out: w}
+}
+
+func (x *xlsxWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+
+	x.zip = zip.NewWriter(x.out)
+
+	for _, part := range xlsxParts {
+		file, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	file, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(file)
+
+	x.sheet.WriteString(xml.Header)
+	x.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// Первая строка - заголовок, она закреплена при прокрутке
+	x.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`</sheetView></sheetViews>`)
+	x.sheet.WriteString(`<cols>` +
+		`<col min="1" max="1" width="10" customWidth="1"/>` +
+		`<col min="2" max="2" width="18" customWidth="1"/>` +
+		`<col min="3" max="3" width="40" customWidth="1"/>` +
+		`<col min="4" max="5" width="12" customWidth="1"/>` +
+		`<col min="6" max="8" width="24" customWidth="1"/>` +
+		`<col min="9" max="9" width="40" customWidth="1"/>` +
+		`<col min="10" max="10" width="28" customWidth="1"/>` +
+		`<col min="11" max="11" width="10" customWidth="1"/>` +
+		`</cols>`)
+	x.sheet.WriteString(`<sheetData>`)
+
+	x.startRow()
+	for _, column := range columns {
+		x.text(column, styleHeader)
+	}
+	x.endRow()
+
+	return nil
+}
+
+func (x *xlsxWriter) Write(row *models.ExportRow) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+
+	amountStyle := styleAmount
+	if row.Currency.MinorUnits() == 0 {
+		amountStyle = styleAmountMinor0
+	}
+
+	notes := ""
+	if row.Notes != nil {
+		notes = *row.Notes
+	}
+
+	x.startRow()
+	x.number(strconv.Itoa(int(row.ID)), 0)
+	x.number(excelDate(row.OccurredAt), styleDate)
+	x.text(row.Title, 0)
+	x.number(row.Amount.String(), amountStyle)
+	x.text(string(row.Currency), 0)
+	x.text(row.Account, 0)
+	x.text(row.Category, 0)
+	x.text(strings.Join(row.Tags, ", "), 0)
+	x.text(notes, 0)
+	x.text(row.MemberEmail, 0)
+	if row.RuleID != nil {
+		x.number(strconv.Itoa(int(*row.RuleID)), 0)
+	}
+	x.endRow()
+
+	// Ошибка записи в bufio.Writer запоминается и возвращается при следующих записях
+	_, err := x.sheet.Write(nil)
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return x.zip.Close()
+}
+
+func (x *xlsxWriter) startRow() {
+	x.row++
+	x.sheet.WriteString(`<row r="` + strconv.Itoa(x.row) + `">`)
+}
+
+func (x *xlsxWriter) endRow() {
+	x.sheet.WriteString(`</row>`)
+}
+
+// text пишет текстовую ячейку. Пустой текст - пустая ячейка
+func (x *xlsxWriter) text(value string, style int) {
+	if value == "" {
+		x.sheet.WriteString(`<c/>`)
+		return
+	}
+
+	x.sheet.WriteString(`<c t="inlineStr"` + styleAttr(style) + `><is><t xml:space="preserve">`)
+	// EscapeText заменяет недопустимые в XML символы на U+FFFD
+	xml.EscapeText(x.sheet, []byte(value))
+	x.sheet.WriteString(`</t></is></c>`)
+}
+
+// number пишет числовую ячейку: value - десятичная запись числа
+func (x *xlsxWriter) number(value string, style int) {
+	x.sheet.WriteString(`<c` + styleAttr(style) + `><v>` + value + `</v></c>`)
+}
+
+func styleAttr(style int) string {
+	if style == 0 {
+		return ""
+	}
+	return ` s="` + strconv.Itoa(style) + `"`
+}
+
+// excelDate переводит время в дату Excel: число дней от excelEpoch с дробной частью - временем суток.
+// Даты выгружаются в UTC
+func excelDate(t time.Time) string {
+	days := t.UTC().Sub(excelEpoch).Seconds() / (24 * 60 * 60)
+	return strconv.FormatFloat(days, 'f', 6, 64)
+}
+
+// xlsxParts - неизменные части книги: описание типов, связи, книга с одним листом и стили
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: xml.Header +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: xml.Header +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		// Формат 4 - "#,##0.00", 3 - "#,##0"; 164 - дата и время
+		name: "xl/styles.xml",
+		content: xml.Header +
+			`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+			`<fonts count="2">` +
+			`<font><sz val="11"/><name val="Calibri"/></font>` +
+			`<font><b/><sz val="11"/><name val="Calibri"/></font>` +
+			`</fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="5">` +
+			`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+			`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+			`<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+			`</cellXfs>` +
+			`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+			`</styleSheet>`,
+	},
+}
@@ -0,0 +1,190 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// xlsxSheet - ячейки листа книги
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Type  string `xml:"t,attr"`
+			Style int    `xml:"s,attr"`
+			Value string `xml:"v"`
+			Text  string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	rows := testRows()
+	rows[0].Title = `<b>"Tom's" & Jerry</b>` + "\x01"
+
+	var out bytes.Buffer
+	w := newXLSXWriter(&out)
+
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := readXLSXSheet(t, out.Bytes())
+
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(sheet.Rows))
+	}
+
+	for i, row := range sheet.Rows {
+		if row.R != i+1 {
+			t.Errorf("row %d has r=%d", i, row.R)
+		}
+	}
+
+	var header []string
+	for _, cell := range sheet.Rows[0].Cells {
+		if cell.Type != "inlineStr" || cell.Style != styleHeader {
+			t.Errorf("header cell %q: t=%q s=%d, want inlineStr s=%d", cell.Text, cell.Type, cell.Style, styleHeader)
+		}
+		header = append(header, cell.Text)
+	}
+	if !slices.Equal(header, columns) {
+		t.Errorf("header = %q, want %q", header, columns)
+	}
+
+	first := sheet.Rows[1].Cells
+	if len(first) != len(columns) {
+		t.Fatalf("first row has %d cells, want %d", len(first), len(columns))
+	}
+
+	// Недопустимый в XML управляющий символ заменяется, остальное возвращается без изменений
+	if want := `<b>"Tom's" & Jerry</b>` + "\uFFFD"; first[2].Text != want {
+		t.Errorf("title = %q, want %q", first[2].Text, want)
+	}
+
+	checks := []struct {
+		name  string
+		value string
+		style int
+		want  string
+		wantS int
+	}{
+		{name: "id", value: first[0].Value, style: first[0].Style, want: "101"},
+		{name: "date", value: first[1].Value, style: first[1].Style, want: "45726.500000", wantS: styleDate},
+		{name: "amount", value: first[3].Value, style: first[3].Style, want: "-1500.50", wantS: styleAmount},
+		{name: "tags", value: first[7].Text, style: first[7].Style, want: "дом, еда"},
+		{name: "notes", value: first[8].Text, style: first[8].Style, want: "+79001234567 <курьер> & Co"},
+		{name: "rule", value: first[10].Value, style: first[10].Style, want: "7"},
+	}
+
+	for _, c := range checks {
+		if c.value != c.want || c.style != c.wantS {
+			t.Errorf("%s = %q (s=%d), want %q (s=%d)", c.name, c.value, c.style, c.want, c.wantS)
+		}
+	}
+
+	// Сумма в валюте без дробной части - целое число со своим форматом; пустая категория - пустая ячейка,
+	// строки без серии - без последней ячейки. Формулы в XLSX не выполняются: текст остаётся текстом
+	second := sheet.Rows[2].Cells
+	if len(second) != len(columns)-1 {
+		t.Fatalf("second row has %d cells, want %d", len(second), len(columns)-1)
+	}
+	if second[3].Value != "1500.00" || second[3].Style != styleAmountMinor0 {
+		t.Errorf("JPY amount = %q (s=%d), want %q (s=%d)", second[3].Value, second[3].Style, "1500.00", styleAmountMinor0)
+	}
+	if second[1].Value != "45727.270833" {
+		t.Errorf("date = %q, want %q (UTC)", second[1].Value, "45727.270833")
+	}
+	if second[2].Type != "inlineStr" || !strings.HasPrefix(second[2].Text, "=HYPERLINK") {
+		t.Errorf("title cell t=%q %q, want inline string", second[2].Type, second[2].Text)
+	}
+	if second[6].Type != "" || second[6].Text != "" || second[6].Value != "" {
+		t.Errorf("empty category cell = %+v, want empty", second[6])
+	}
+}
+
+func TestXLSXWriterEmpty(t *testing.T) {
+	var out bytes.Buffer
+	w := newXLSXWriter(&out)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := readXLSXSheet(t, out.Bytes())
+
+	if len(sheet.Rows) != 1 || len(sheet.Rows[0].Cells) != len(columns) {
+		t.Errorf("empty workbook rows = %+v, want header only", sheet.Rows)
+	}
+}
+
+// readXLSXSheet проверяет состав книги и то, что все её части - корректный XML, и возвращает лист
+func readXLSXSheet(t *testing.T, data []byte) *xlsxSheet {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+
+	var names []string
+	for _, part := range xlsxParts {
+		names = append(names, part.name)
+	}
+	names = append(names, "xl/worksheets/sheet1.xml")
+
+	var got []string
+	var sheet xlsxSheet
+
+	for _, file := range archive.File {
+		got = append(got, file.Name)
+
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", file.Name, err)
+		}
+
+		if !bytes.HasPrefix(content, []byte(xml.Header)) {
+			t.Errorf("%s has no XML declaration", file.Name)
+		}
+
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", file.Name, err)
+			}
+		}
+
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			if err := xml.Unmarshal(content, &sheet); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// [Content_Types].xml должен быть в архиве первым
+	if !slices.Equal(got, names) {
+		t.Errorf("archive parts = %q, want %q", got, names)
+	}
+
+	return &sheet
+}
@@ -0,0 +1,335 @@
+package imports
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+)
+
+var ErrNotCAMT = errors.New("file is not a camt.053 statement")
+
+// camtAccount - счёт выписки (Stmt/Acct)
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtDate - дата (Dt) или дата и время (DtTm)
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// camtStatus - статус записи: текстом до camt.053.001.07 и кодом Cd начиная с версии 08
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+// camtEntry - запись выписки (Ntry). Одна запись может объединять несколько операций (TxDtls),
+// например пакет платежей
+type camtEntry struct {
+	Ref            string            `xml:"NtryRef"`
+	Amount         camtAmount        `xml:"Amt"`
+	Indicator      string            `xml:"CdtDbtInd"`
+	Reversal       bool              `xml:"RvslInd"`
+	Status         camtStatus        `xml:"Sts"`
+	BookingDate    camtDate          `xml:"BookgDt"`
+	ServicerRef    string            `xml:"AcctSvcrRef"`
+	Details        []camtTransaction `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string            `xml:"AddtlNtryInf"`
+}
+
+// camtTransaction - операция внутри записи (TxDtls). Сумма указывается в Amt начиная
+// с camt.053.001.04 и в AmtDtls/TxAmt/Amt в более ранних версиях, участники - в Dbtr/Nm
+// или, начиная с версии 08, в Dbtr/Pty/Nm
+type camtTransaction struct {
+	ServicerRef    string      `xml:"Refs>AcctSvcrRef"`
+	TransactionID  string      `xml:"Refs>TxId"`
+	Amount         *camtAmount `xml:"Amt"`
+	LegacyAmount   *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	Indicator      string      `xml:"CdtDbtInd"`
+	Debtor         string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorParty    string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Creditor       string      `xml:"RltdPties>Cdtr>Nm"`
+	CreditorParty  string      `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Unstructured   []string    `xml:"RmtInf>Ustrd"`
+	CreditorRefs   []string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInfo string      `xml:"AddtlTxInf"`
+}
+
+// ParseCAMT053 разбирает выписку ISO 20022 camt.053 (BkToCstmrStmt) любой версии.
+// Транзакции берутся из всех выписок (Stmt) файла; записи в статусе, отличном от BOOK,
+// пропускаются - банк пришлёт их проведёнными в следующей выписке. Запись с несколькими
+// операциями, у каждой из которых указана сумма, делится на транзакции по операциям.
+// Ссылка банка на запись (AcctSvcrRef, а без неё NtryRef) вместе со счётом становится
+// внешним идентификатором транзакции
+func ParseCAMT053(r io.Reader) (*Statement, error) {
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	// readText уже перевёл файл в UTF-8, объявленная в заголовке кодировка не важна
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var (
+		statement Statement
+		account   camtAccount
+		root      bool
+		found     bool
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !root {
+			if start.Name.Local != "Document" {
+				return nil, ErrNotCAMT
+			}
+			root = true
+			continue
+		}
+
+		switch start.Name.Local {
+		case "BkToCstmrStmt":
+			found = true
+
+		case "Stmt":
+			account = camtAccount{}
+
+		case "Acct":
+			if err := decoder.DecodeElement(&account, &start); err != nil {
+				return nil, err
+			}
+
+		case "Ntry":
+			line, _ := decoder.InputPos()
+
+			var entry camtEntry
+			if err := decoder.DecodeElement(&entry, &start); err != nil {
+				return nil, err
+			}
+
+			if status := firstNonEmpty(entry.Status.Code, entry.Status.Text); status != "" && status != "BOOK" {
+				continue
+			}
+
+			accountID := firstNonEmpty(account.IBAN, account.Other)
+			for _, part := range entry.parts() {
+				currency := firstNonEmpty(part.amount.Currency, account.Currency)
+				if currency != "" {
+					if err := statement.setCurrency(currency); err != nil {
+						return nil, fmt.Errorf("line %d: %w", line, err)
+					}
+				}
+
+				row, err := part.row(line, accountID)
+				if err := statement.add(row, err); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if !found {
+		return nil, ErrNotCAMT
+	}
+
+	if len(statement.Rows)+len(statement.Errors) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return &statement, nil
+}
+
+// camtPart - запись выписки или одна из её операций, если запись делится на транзакции
+type camtPart struct {
+	entry  *camtEntry
+	detail *camtTransaction
+	amount camtAmount
+	index  int
+}
+
+// parts возвращает части записи, каждая из которых становится транзакцией
+func (e *camtEntry) parts() []camtPart {
+	split := len(e.Details) > 1
+	for _, detail := range e.Details {
+		if detail.amount() == nil {
+			split = false
+		}
+	}
+
+	if !split {
+		part := camtPart{entry: e, amount: e.Amount}
+		if len(e.Details) == 1 {
+			part.detail = &e.Details[0]
+		}
+		return []camtPart{part}
+	}
+
+	parts := make([]camtPart, len(e.Details))
+	for i := range e.Details {
+		parts[i] = camtPart{entry: e, detail: &e.Details[i], amount: *e.Details[i].amount(), index: i + 1}
+	}
+	return parts
+}
+
+func (t *camtTransaction) amount() *camtAmount {
+	if t.Amount != nil {
+		return t.Amount
+	}
+	return t.LegacyAmount
+}
+
+// row переводит часть записи в транзакцию. Название - контрагент (получатель для списаний,
+// плательщик для зачислений), а без него - назначение платежа
+func (p camtPart) row(line int, accountID string) (models.ImportRow, error) {
+	row := models.ImportRow{Line: line}
+
+	occurredAt, err := parseCAMTDate(p.entry.BookingDate)
+	if err != nil {
+		return row, err
+	}
+
+	amount, err := money.Parse(strings.TrimSpace(p.amount.Value))
+	if err != nil {
+		return row, fmt.Errorf("invalid amount %q: %w", p.amount.Value, err)
+	}
+
+	if amount <= 0 {
+		return row, errors.New("amount must be positive, direction is set by CdtDbtInd")
+	}
+
+	indicator := p.entry.Indicator
+	if p.index > 0 && p.detail.Indicator != "" {
+		indicator = p.detail.Indicator
+	}
+
+	switch indicator {
+	case "CRDT":
+	case "DBIT":
+		amount = -amount
+	default:
+		return row, fmt.Errorf("invalid CdtDbtInd %q, expected CRDT or DBIT", indicator)
+	}
+
+	// Сторнирующая запись сохраняет признак исходной операции и движет деньги в обратную сторону
+	if p.entry.Reversal {
+		amount = -amount
+	}
+
+	var counterparty, remittance, additionalInfo string
+	if p.detail != nil {
+		if indicator == "DBIT" {
+			counterparty = firstNonEmpty(p.detail.Creditor, p.detail.CreditorParty)
+		} else {
+			counterparty = firstNonEmpty(p.detail.Debtor, p.detail.DebtorParty)
+		}
+
+		remittance = strings.Join(strings.Fields(strings.Join(p.detail.Unstructured, " ")), " ")
+		if remittance == "" {
+			remittance = strings.Join(p.detail.CreditorRefs, " ")
+		}
+
+		additionalInfo = p.detail.AdditionalInfo
+	}
+
+	title := firstNonEmpty(counterparty, remittance, additionalInfo, p.entry.AdditionalInfo)
+	if title == "" {
+		return row, errors.New("entry has no counterparty, remittance information or AddtlNtryInf")
+	}
+
+	if row.ExternalRef, err = p.externalRef(accountID); err != nil {
+		return row, err
+	}
+
+	row.OccurredAt = occurredAt
+	row.Title = truncate(title, maxTitleLength)
+	row.Amount = amount
+
+	if remittance != "" && remittance != title {
+		row.Notes = &remittance
+	}
+
+	return row, nil
+}
+
+// externalRef возвращает идентификатор транзакции. У операции пакетной записи это её
+// ссылка банка или TxId, а без них - ссылка записи и номер операции в ней
+func (p camtPart) externalRef(accountID string) (*string, error) {
+	entryRef := firstNonEmpty(p.entry.ServicerRef, p.entry.Ref)
+
+	if p.index == 0 {
+		if entryRef == "" {
+			return nil, nil
+		}
+		return externalRef(accountID, entryRef)
+	}
+
+	if ref := firstNonEmpty(p.detail.ServicerRef, p.detail.TransactionID); ref != "" && ref != "NOTPROVIDED" {
+		return externalRef(accountID, ref)
+	}
+
+	if entryRef == "" {
+		return nil, nil
+	}
+	return externalRef(accountID, entryRef, strconv.Itoa(p.index))
+}
+
+// parseCAMTDate разбирает дату проводки: xs:date (2024-01-05, возможно с часовым поясом)
+// или xs:dateTime. Время без часового пояса считается в UTC
+func parseCAMTDate(date camtDate) (time.Time, error) {
+	if value := strings.TrimSpace(date.DateTime); value != "" {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				return parsed.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid booking date %q, expected YYYY-MM-DDThh:mm:ss", value)
+	}
+
+	value := strings.TrimSpace(date.Date)
+	if value == "" {
+		return time.Time{}, errors.New("entry has no booking date (BookgDt)")
+	}
+
+	// Часовой пояс у даты без времени не сдвигает день
+	if len(value) > len("2006-01-02") {
+		value = value[:len("2006-01-02")]
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid booking date %q, expected YYYY-MM-DD", date.Date)
+	}
+
+	return parsed, nil
+}
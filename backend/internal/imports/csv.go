@@ -0,0 +1,372 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Кодировки CSV-файла
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1251 = "windows-1251"
+)
+
+var ErrInvalidMapping = errors.New("invalid column mapping")
+
+// CSVMapping описывает, как читать CSV-выписку конкретного банка.
+// Колонка задаётся именем из заголовка или номером, начиная с 1
+type CSVMapping struct {
+	Delimiter rune
+	Encoding  string
+	HasHeader bool
+	SkipRows  int // строки перед заголовком (название банка, период выписки и т.п.)
+
+	DateColumn string
+	DateFormat string // например DD.MM.YYYY или YYYY-MM-DD HH:mm:ss
+
+	TitleColumn string
+	NotesColumn string
+
+	// Сумма либо в одной колонке AmountColumn, либо в двух: списания DebitColumn и поступления CreditColumn
+	AmountColumn string
+	DebitColumn  string
+	CreditColumn string
+
+	DecimalSeparator rune
+	InvertSign       bool // в AmountColumn расходы записаны положительными суммами
+}
+
+// dateTokens переводит элементы формата даты в элементы макета time.Parse
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+// DateLayout переводит формат даты вида DD.MM.YYYY в макет time.Parse
+func DateLayout(format string) (string, error) {
+	layout := dateTokens.Replace(format)
+	if !strings.Contains(layout, "2006") && !strings.Contains(layout, "06") ||
+		!strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("%w: date format must contain YYYY (or YY), MM and DD", ErrInvalidMapping)
+	}
+
+	return layout, nil
+}
+
+// csvColumns - номера колонок выписки, начиная с 0. -1 - колонки нет
+type csvColumns struct {
+	date, title, notes, amount, debit, credit int
+}
+
+// ParseCSV разбирает CSV-выписку по описанию m. Ошибки в отдельных строках не прерывают
+// разбор: они возвращаются вместе с номерами строк. Ошибка возвращается, если файл
+// не читается целиком или описание колонок не подходит к файлу
+func ParseCSV(r io.Reader, m *CSVMapping) (*Statement, error) {
+	layout, err := DateLayout(m.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.DecimalSeparator != '.' && m.DecimalSeparator != ',' {
+		return nil, fmt.Errorf("%w: decimal separator must be '.' or ','", ErrInvalidMapping)
+	}
+
+	switch m.Encoding {
+	case "", EncodingUTF8:
+	case EncodingWindows1251:
+		r = charmap.Windows1251.NewDecoder().Reader(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported encoding %q", ErrInvalidMapping, m.Encoding)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), bom)))
+	reader.Comma = m.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	for i := 0; i < m.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrEmptyFile
+			}
+			return nil, lineError(reader, err)
+		}
+	}
+
+	var header []string
+	if m.HasHeader {
+		header, err = reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrEmptyFile
+			}
+			return nil, lineError(reader, err)
+		}
+	}
+
+	columns, err := resolveColumns(m, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement Statement
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, lineError(reader, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if isBlank(record) {
+			continue
+		}
+
+		row, err := parseRecord(record, columns, layout, m)
+		row.Line = line
+
+		if err := statement.add(row, err); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(statement.Rows)+len(statement.Errors) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return &statement, nil
+}
+
+// resolveColumns находит колонки описания в заголовке или по номерам
+func resolveColumns(m *CSVMapping, header []string) (*csvColumns, error) {
+	find := func(name string, column string) (int, error) {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			return -1, nil
+		}
+
+		for i, title := range header {
+			if strings.EqualFold(strings.TrimSpace(title), column) {
+				return i, nil
+			}
+		}
+
+		if n, err := strconv.Atoi(column); err == nil && n >= 1 {
+			return n - 1, nil
+		}
+
+		return 0, fmt.Errorf("%w: %s column %q not found", ErrInvalidMapping, name, column)
+	}
+
+	var (
+		columns csvColumns
+		err     error
+	)
+
+	for _, c := range []struct {
+		name   string
+		column string
+		index  *int
+	}{
+		{"date", m.DateColumn, &columns.date},
+		{"title", m.TitleColumn, &columns.title},
+		{"notes", m.NotesColumn, &columns.notes},
+		{"amount", m.AmountColumn, &columns.amount},
+		{"debit", m.DebitColumn, &columns.debit},
+		{"credit", m.CreditColumn, &columns.credit},
+	} {
+		if *c.index, err = find(c.name, c.column); err != nil {
+			return nil, err
+		}
+	}
+
+	if columns.date < 0 || columns.title < 0 {
+		return nil, fmt.Errorf("%w: date and title columns are required", ErrInvalidMapping)
+	}
+
+	hasAmount := columns.amount >= 0
+	hasDebitCredit := columns.debit >= 0 || columns.credit >= 0
+
+	if hasAmount == hasDebitCredit {
+		return nil, fmt.Errorf("%w: set either amount column or debit and credit columns", ErrInvalidMapping)
+	}
+
+	return &columns, nil
+}
+
+func parseRecord(record []string, columns *csvColumns, layout string, m *CSVMapping) (models.ImportRow, error) {
+	cell := func(index int) (string, error) {
+		if index < 0 {
+			return "", nil
+		}
+		if index >= len(record) {
+			return "", fmt.Errorf("line has %d columns, expected at least %d", len(record), index+1)
+		}
+		return strings.TrimSpace(record[index]), nil
+	}
+
+	dateCell, err := cell(columns.date)
+	if err != nil {
+		return models.ImportRow{}, err
+	}
+
+	occurredAt, err := time.Parse(layout, dateCell)
+	if err != nil {
+		return models.ImportRow{}, fmt.Errorf("invalid date %q, expected format %s", dateCell, m.DateFormat)
+	}
+
+	title, err := cell(columns.title)
+	if err != nil {
+		return models.ImportRow{}, err
+	}
+	if title == "" {
+		return models.ImportRow{}, errors.New("title is empty")
+	}
+	title = truncate(title, maxTitleLength)
+
+	notesCell, err := cell(columns.notes)
+	if err != nil {
+		return models.ImportRow{}, err
+	}
+
+	var notes *string
+	if notesCell != "" {
+		notes = &notesCell
+	}
+
+	var amount money.Amount
+	if columns.amount >= 0 {
+		amountCell, err := cell(columns.amount)
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+
+		amount, err = parseAmount(amountCell, m.DecimalSeparator)
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+
+		if m.InvertSign {
+			amount = -amount
+		}
+	} else {
+		debitCell, err := cell(columns.debit)
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+
+		creditCell, err := cell(columns.credit)
+		if err != nil {
+			return models.ImportRow{}, err
+		}
+
+		if debitCell == "" && creditCell == "" {
+			return models.ImportRow{}, errors.New("both debit and credit are empty")
+		}
+
+		// Списания и поступления часто записаны без знака: списание всегда расход
+		if debitCell != "" {
+			debit, err := parseAmount(debitCell, m.DecimalSeparator)
+			if err != nil {
+				return models.ImportRow{}, err
+			}
+			amount -= abs(debit)
+		}
+
+		if creditCell != "" {
+			credit, err := parseAmount(creditCell, m.DecimalSeparator)
+			if err != nil {
+				return models.ImportRow{}, err
+			}
+			amount += abs(credit)
+		}
+	}
+
+	if amount == 0 {
+		return models.ImportRow{}, errors.New("amount is zero")
+	}
+
+	return models.ImportRow{
+		OccurredAt: occurredAt,
+		Title:      title,
+		Amount:     amount,
+		Notes:      notes,
+	}, nil
+}
+
+// parseAmount разбирает сумму с десятичным разделителем separator. Пробелы и разделитель
+// тысяч (точка при десятичной запятой и наоборот) пропускаются: "-1 500,50" при separator ','
+// читается как -1500.50
+func parseAmount(value string, separator rune) (money.Amount, error) {
+	thousands := ","
+	if separator == ',' {
+		thousands = "."
+	}
+
+	cleaned := strings.NewReplacer(
+		" ", "",
+		"\u00A0", "",
+		"\u202F", "",
+		"'", "",
+		thousands, "",
+	).Replace(value)
+
+	if separator == ',' {
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	}
+
+	// Минус в выгрузках иногда записан типографским знаком
+	cleaned = strings.Replace(cleaned, "\u2212", "-", 1)
+
+	amount, err := money.Parse(cleaned)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+
+	return amount, nil
+}
+
+// lineError добавляет к ошибке чтения CSV номер строки
+func lineError(reader *csv.Reader, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("line %d: %w", parseErr.Line, parseErr.Err)
+	}
+
+	line, _ := reader.FieldPos(0)
+	return fmt.Errorf("line %d: %w", line, err)
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
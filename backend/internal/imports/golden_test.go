@@ -0,0 +1,124 @@
+package imports
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Эталоны обновляются командой: go test ./internal/imports -update
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestParseGolden(t *testing.T) {
+	parsers := map[string]func(io.Reader) (*Statement, error){
+		".ofx": ParseOFX,
+		".qfx": ParseOFX,
+		".qif": ParseQIF,
+		".xml": ParseCAMT053,
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range files {
+		parse, ok := parsers[filepath.Ext(path)]
+		if !ok {
+			continue
+		}
+
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			statement, err := parse(file)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got := renderStatement(statement)
+			golden := path + ".golden"
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("statement differs from %s\n--- got\n%s\n--- want\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsOtherFormats(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("!Type:Bank\nD1/1/2024\nT-1\n^\n")); err != ErrNotOFX {
+		t.Errorf("ParseOFX(qif) error = %v, want %v", err, ErrNotOFX)
+	}
+
+	if _, err := ParseQIF(strings.NewReader("<OFX></OFX>")); err != ErrNotQIF {
+		t.Errorf("ParseQIF(ofx) error = %v, want %v", err, ErrNotQIF)
+	}
+
+	if _, err := ParseQIF(strings.NewReader("!Type:Invst\nD1/1/2024\n^\n")); err == nil {
+		t.Error("ParseQIF(invst) error = nil, want error")
+	}
+
+	if _, err := ParseCAMT053(strings.NewReader("<OFX></OFX>")); err != ErrNotCAMT {
+		t.Errorf("ParseCAMT053(ofx) error = %v, want %v", err, ErrNotCAMT)
+	}
+}
+
+func TestParseCAMT053RejectsMixedCurrencies(t *testing.T) {
+	const document = `<Document><BkToCstmrStmt>
+<Stmt><Acct><Id><IBAN>A</IBAN></Id><Ccy>EUR</Ccy></Acct>
+<Ntry><Amt Ccy="EUR">1.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><BookgDt><Dt>2024-01-01</Dt></BookgDt><AddtlNtryInf>x</AddtlNtryInf></Ntry></Stmt>
+<Stmt><Acct><Id><IBAN>B</IBAN></Id><Ccy>USD</Ccy></Acct>
+<Ntry><Amt Ccy="USD">1.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><BookgDt><Dt>2024-01-01</Dt></BookgDt><AddtlNtryInf>x</AddtlNtryInf></Ntry></Stmt>
+</BkToCstmrStmt></Document>`
+
+	if _, err := ParseCAMT053(strings.NewReader(document)); err == nil {
+		t.Error("ParseCAMT053(EUR and USD) error = nil, want error")
+	}
+}
+
+// renderStatement записывает выписку в текстовом виде для сравнения с эталоном
+func renderStatement(s *Statement) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "currency: %q\n", s.Currency)
+
+	for _, row := range s.Rows {
+		fmt.Fprintf(&b, "row %d: %s %s %q", row.Line, row.OccurredAt.Format(time.RFC3339), row.Amount, row.Title)
+		if row.Notes != nil {
+			fmt.Fprintf(&b, " notes=%q", *row.Notes)
+		}
+		if row.ExternalRef != nil {
+			fmt.Fprintf(&b, " ref=%q", *row.ExternalRef)
+		}
+		b.WriteByte('\n')
+	}
+
+	for _, lineErr := range s.Errors {
+		fmt.Fprintf(&b, "error %d: %s\n", lineErr.Line, lineErr.Message)
+	}
+
+	return b.Bytes()
+}
@@ -0,0 +1,250 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+var ErrNotOFX = errors.New("file is not an OFX statement")
+
+// ofxTag - тег OFX с текстом до следующего тега. У листовых элементов текст - значение,
+// у агрегатов (STMTTRN, BANKTRANLIST) текста нет
+type ofxTag struct {
+	name    string
+	value   string
+	closing bool
+	line    int
+}
+
+// ofxTransaction - поля элемента STMTTRN
+type ofxTransaction struct {
+	line   int
+	fields map[string]string
+}
+
+// ParseOFX разбирает выписку OFX или QFX: OFX 1.x (SGML, листовые элементы без закрывающих тегов)
+// и OFX 2.x (XML). Транзакции берутся из всех выписок файла (STMTTRS, CCSTMTRS). FITID транзакции
+// вместе с ACCTID счёта становится её внешним идентификатором
+func ParseOFX(r io.Reader) (*Statement, error) {
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, ErrNotOFX
+	}
+
+	var (
+		statement Statement
+		accountID string
+		current   *ofxTransaction
+		inPayee   bool
+	)
+
+	line := 1 + strings.Count(text[:start], "\n")
+
+	for _, tag := range ofxTags(text[start:], line) {
+		switch {
+		case tag.name == "STMTTRN" && !tag.closing:
+			current = &ofxTransaction{line: tag.line, fields: make(map[string]string)}
+
+		case tag.name == "STMTTRN" && tag.closing:
+			if current == nil {
+				continue
+			}
+
+			row, err := current.row(accountID)
+			if err := statement.add(row, err); err != nil {
+				return nil, err
+			}
+			current = nil
+
+		case tag.name == "PAYEE":
+			inPayee = !tag.closing
+
+		case tag.closing || tag.value == "":
+			// Закрывающие теги и агрегаты без значения
+
+		case current != nil:
+			name := tag.name
+			if inPayee {
+				name = "PAYEE." + name
+			}
+			current.fields[name] = tag.value
+
+		case tag.name == "ACCTID":
+			accountID = tag.value
+
+		case tag.name == "CURDEF":
+			if err := statement.setCurrency(tag.value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", tag.line, err)
+			}
+		}
+	}
+
+	if len(statement.Rows)+len(statement.Errors) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return &statement, nil
+}
+
+// ofxTags разбивает тело OFX на теги. Инструкции <?...?> и комментарии <!...> пропускаются
+func ofxTags(text string, line int) []ofxTag {
+	var tags []ofxTag
+
+	for {
+		open := strings.IndexByte(text, '<')
+		if open < 0 {
+			return tags
+		}
+		line += strings.Count(text[:open], "\n")
+		text = text[open+1:]
+
+		end := strings.IndexByte(text, '>')
+		if end < 0 {
+			return tags
+		}
+
+		name := strings.TrimSpace(text[:end])
+		text = text[end+1:]
+
+		next := strings.IndexByte(text, '<')
+		if next < 0 {
+			next = len(text)
+		}
+		value := text[:next]
+
+		if name == "" || name[0] == '?' || name[0] == '!' {
+			continue
+		}
+
+		// Атрибуты в OFX не используются, но XML-заголовки их допускают
+		if i := strings.IndexAny(name, " \t\r\n"); i >= 0 {
+			name = name[:i]
+		}
+
+		tag := ofxTag{line: line, value: html.UnescapeString(strings.TrimSpace(value))}
+		if strings.HasPrefix(name, "/") {
+			tag.closing = true
+			name = name[1:]
+		}
+		// Пустой элемент XML: <MEMO/>
+		name = strings.TrimSuffix(name, "/")
+		tag.name = strings.ToUpper(name)
+
+		tags = append(tags, tag)
+	}
+}
+
+// row переводит STMTTRN в транзакцию
+func (t *ofxTransaction) row(accountID string) (models.ImportRow, error) {
+	row := models.ImportRow{Line: t.line}
+
+	posted, ok := t.fields["DTPOSTED"]
+	if !ok {
+		return row, errors.New("transaction has no DTPOSTED")
+	}
+
+	occurredAt, err := parseOFXDate(posted)
+	if err != nil {
+		return row, err
+	}
+
+	amountValue, ok := t.fields["TRNAMT"]
+	if !ok {
+		return row, errors.New("transaction has no TRNAMT")
+	}
+
+	amount, err := parseLooseAmount(amountValue)
+	if err != nil {
+		return row, err
+	}
+
+	if amount == 0 {
+		return row, errors.New("amount is zero")
+	}
+
+	title := firstNonEmpty(t.fields["NAME"], t.fields["PAYEE.NAME"], t.fields["MEMO"], t.fields["TRNTYPE"])
+	if title == "" {
+		return row, errors.New("transaction has no NAME, PAYEE or MEMO")
+	}
+
+	// Без FITID транзакцию нельзя узнать при повторном импорте
+	if fitID := t.fields["FITID"]; fitID != "" {
+		if row.ExternalRef, err = externalRef(accountID, fitID); err != nil {
+			return row, err
+		}
+	}
+
+	row.OccurredAt = occurredAt
+	row.Title = truncate(title, maxTitleLength)
+	row.Amount = amount
+
+	if memo := t.fields["MEMO"]; memo != "" && memo != title {
+		row.Notes = &memo
+	}
+
+	return row, nil
+}
+
+// parseOFXDate разбирает дату OFX: YYYYMMDD[HHMMSS[.XXX]][[gmt offset[:tz name]]].
+// Без смещения время считается в UTC
+func parseOFXDate(value string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid date %q, expected YYYYMMDDHHMMSS", value)
+
+	digits, zone, hasZone := strings.Cut(strings.TrimSpace(value), "[")
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		digits = digits[:i]
+	}
+
+	var layout string
+	switch len(digits) {
+	case 8:
+		layout = "20060102"
+	case 12:
+		layout = "200601021504"
+	case 14:
+		layout = "20060102150405"
+	default:
+		return time.Time{}, invalid
+	}
+
+	location := time.UTC
+	if hasZone {
+		offset, name, _ := strings.Cut(strings.TrimSuffix(zone, "]"), ":")
+		hours, err := strconv.ParseFloat(offset, 64)
+		if err != nil || hours < -12 || hours > 14 {
+			return time.Time{}, invalid
+		}
+		if name == "" {
+			name = "GMT" + offset
+		}
+		location = time.FixedZone(name, int(hours*3600))
+	}
+
+	parsed, err := time.ParseInLocation(layout, digits, location)
+	if err != nil {
+		return time.Time{}, invalid
+	}
+
+	return parsed.UTC(), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package imports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+)
+
+var ErrNotQIF = errors.New("file is not a QIF statement")
+
+// qifTransactionTypes - разделы QIF с транзакциями счёта. Инвестиционные разделы (Invst)
+// и списки (Cat, Class, Memorized) не поддерживаются
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// qifRecord - поля одной записи QIF до символа ^
+type qifRecord struct {
+	line   int
+	fields map[byte]string
+}
+
+// ParseQIF разбирает выписку QIF (Quicken Interchange Format). Читаются разделы !Type:Bank,
+// Cash, CCard, Oth A и Oth L; разбивка транзакции на части (S, E, $) не учитывается.
+// В QIF нет идентификаторов транзакций, поэтому повторный импорт их не распознаёт
+func ParseQIF(r io.Reader) (*Statement, error) {
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		statement Statement
+		section   string
+		record    *qifRecord
+		seenType  bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimRight(scanner.Text(), " \t\r")
+		if value == "" {
+			continue
+		}
+
+		if value[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(value[1:]))
+
+			switch {
+			case strings.HasPrefix(header, "type:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "type:"))
+				if !qifTransactionTypes[section] && strings.HasPrefix(section, "invst") {
+					return nil, fmt.Errorf("line %d: investment accounts are not supported", line)
+				}
+				seenType = true
+			case header == "account":
+				section = "account"
+			}
+			// !Option и !Clear меняют только отображение в Quicken
+
+			record = nil
+			continue
+		}
+
+		if !qifTransactionTypes[section] {
+			continue
+		}
+
+		if value[0] == '^' {
+			if record != nil {
+				row, err := record.row()
+				if err := statement.add(row, err); err != nil {
+					return nil, err
+				}
+			}
+			record = nil
+			continue
+		}
+
+		if record == nil {
+			record = &qifRecord{line: line, fields: make(map[byte]string)}
+		}
+
+		// Первое вхождение поля важнее: S, E и $ повторяются для частей разбивки
+		if _, ok := record.fields[value[0]]; !ok {
+			record.fields[value[0]] = strings.TrimSpace(value[1:])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !seenType {
+		return nil, ErrNotQIF
+	}
+
+	// Последняя запись может быть без завершающего ^
+	if record != nil {
+		row, err := record.row()
+		if err := statement.add(row, err); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(statement.Rows)+len(statement.Errors) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return &statement, nil
+}
+
+// row переводит запись QIF в транзакцию. Название - получатель (P), а без него - описание (M)
+// или категория (L)
+func (r *qifRecord) row() (models.ImportRow, error) {
+	row := models.ImportRow{Line: r.line}
+
+	date, ok := r.fields['D']
+	if !ok {
+		return row, errors.New("record has no date (D)")
+	}
+
+	occurredAt, err := parseQIFDate(date)
+	if err != nil {
+		return row, err
+	}
+
+	amountValue, ok := r.fields['T']
+	if !ok {
+		amountValue, ok = r.fields['U']
+	}
+	if !ok {
+		return row, errors.New("record has no amount (T)")
+	}
+
+	amount, err := parseLooseAmount(amountValue)
+	if err != nil {
+		return row, err
+	}
+
+	if amount == 0 {
+		return row, errors.New("amount is zero")
+	}
+
+	title := firstNonEmpty(r.fields['P'], r.fields['M'], r.fields['L'])
+	if title == "" {
+		return row, errors.New("record has no payee (P), memo (M) or category (L)")
+	}
+
+	row.OccurredAt = occurredAt
+	row.Title = truncate(title, maxTitleLength)
+	row.Amount = amount
+
+	if memo := r.fields['M']; memo != "" && memo != title {
+		row.Notes = &memo
+	}
+
+	return row, nil
+}
+
+// parseQIFDate разбирает дату QIF. Quicken пишет даты как MM/DD/YYYY, MM/DD/YY или MM/DD'YY
+// (апостроф - год после 2000), иногда с пробелами вместо ведущих нулей. Другие программы
+// выгружают DD.MM.YYYY и YYYY-MM-DD
+func parseQIFDate(value string) (time.Time, error) {
+	cleaned := strings.ReplaceAll(value, " ", "")
+	invalid := fmt.Errorf("invalid date %q, expected MM/DD/YYYY, DD.MM.YYYY or YYYY-MM-DD", value)
+
+	var layouts []string
+	switch {
+	case strings.Contains(cleaned, "'"):
+		cleaned = strings.Replace(cleaned, "'", "/20", 1)
+		layouts = []string{"1/2/2006"}
+	case strings.Contains(cleaned, "/"):
+		layouts = []string{"1/2/2006", "1/2/06"}
+	case strings.Contains(cleaned, "."):
+		layouts = []string{"2.1.2006", "2.1.06"}
+	case strings.Contains(cleaned, "-"):
+		layouts = []string{"2006-1-2"}
+	}
+
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, cleaned); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, invalid
+}
@@ -0,0 +1,172 @@
+// Package imports разбирает банковские выписки для импорта транзакций
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// MaxRows - наибольшее число транзакций в одной выписке
+const MaxRows = 10000
+
+// Длины колонок transactions.title и transactions.external_ref.
+// Более длинные названия обрезаются
+const (
+	maxTitleLength       = 255
+	maxExternalRefLength = 255
+)
+
+var (
+	ErrEmptyFile   = errors.New("file contains no transactions")
+	ErrTooManyRows = fmt.Errorf("file contains more than %d transactions", MaxRows)
+)
+
+// Statement - прочитанная выписка: транзакции, ошибки по строкам и валюта,
+// если она указана в файле (пустая строка - не указана)
+type Statement struct {
+	Currency money.Currency
+	Rows     []models.ImportRow
+	Errors   []models.ImportLineError
+}
+
+// add добавляет транзакцию или ошибку её строки
+func (s *Statement) add(row models.ImportRow, err error) error {
+	if len(s.Rows)+len(s.Errors) >= MaxRows {
+		return ErrTooManyRows
+	}
+
+	if err != nil {
+		s.Errors = append(s.Errors, models.ImportLineError{Line: row.Line, Message: err.Error()})
+		return nil
+	}
+
+	s.Rows = append(s.Rows, row)
+	return nil
+}
+
+// setCurrency запоминает валюту выписки. Выписка должна быть в одной валюте
+func (s *Statement) setCurrency(code string) error {
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+
+	if s.Currency != "" && s.Currency != currency {
+		return fmt.Errorf("statement contains transactions in several currencies: %s and %s", s.Currency, currency)
+	}
+
+	s.Currency = currency
+	return nil
+}
+
+// bom - метка порядка байтов, которую Excel и Блокнот пишут в начало UTF-8 файлов
+const bom = "\uFEFF"
+
+// readText читает файл целиком. Файлы не в UTF-8 считаются записанными в windows-1251:
+// в ней обычно выгружают выписки российские банки и старые программы учёта
+func readText(r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if utf8.Valid(content) {
+		return strings.TrimPrefix(string(content), bom), nil
+	}
+
+	decoded, err := charmap.Windows1251.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+// externalRef возвращает идентификатор транзакции выписки или nil, если его нет
+func externalRef(parts ...string) (*string, error) {
+	ref := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if ref != "" {
+			ref += "/"
+		}
+		ref += part
+	}
+
+	if ref == "" {
+		return nil, nil
+	}
+
+	if len(ref) > maxExternalRefLength {
+		return nil, fmt.Errorf("transaction id is longer than %d characters", maxExternalRefLength)
+	}
+
+	return &ref, nil
+}
+
+// parseLooseAmount разбирает сумму, записанную без заданного формата: с десятичной точкой
+// или запятой и, возможно, разделителями тысяч ("-1,500.50", "1.500,50", "-.50").
+// Если в записи есть и точка, и запятая, десятичный разделитель - последний из них.
+// Одна запятая с тремя цифрами после неё считается разделителем тысяч
+func parseLooseAmount(value string) (money.Amount, error) {
+	cleaned := strings.NewReplacer(" ", "", "\u00A0", "", "'", "").Replace(strings.TrimSpace(value))
+
+	lastPoint := strings.LastIndexByte(cleaned, '.')
+	lastComma := strings.LastIndexByte(cleaned, ',')
+
+	switch {
+	case lastPoint >= 0 && lastComma >= 0:
+		if lastComma > lastPoint {
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(cleaned, ",") == 1 && len(cleaned)-lastComma-1 != 3 {
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+	}
+
+	// ".50" и "-.50" - сумма без целой части
+	sign := ""
+	if strings.HasPrefix(cleaned, "-") || strings.HasPrefix(cleaned, "+") {
+		sign, cleaned = cleaned[:1], cleaned[1:]
+	}
+	if strings.HasPrefix(cleaned, ".") {
+		cleaned = "0" + cleaned
+	}
+
+	amount, err := money.Parse(sign + cleaned)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+
+	return amount, nil
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func abs(a money.Amount) money.Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
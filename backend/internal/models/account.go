@@ -0,0 +1,11 @@
+package models
+
+import "microservices/accounter/internal/money"
+
+type CreateAccountParams struct {
+	OwnerID        int
+	Name           string
+	Description    *string
+	Currency       money.Currency
+	OpeningBalance money.Amount
+}
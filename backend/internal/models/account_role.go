@@ -0,0 +1,9 @@
+package models
+
+// AccountRole - роль счёта с её правами. У встроенных ролей (viewer, editor, admin, owner) ID == nil,
+// пользовательские роли счёта задаёт его владелец
+type AccountRole struct {
+	ID          *int
+	Name        string
+	Permissions []string
+}
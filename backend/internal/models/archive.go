@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+// Виды конфликтов импорта архива
+const (
+	// ArchiveConflictAccountName - у пользователя уже есть счёт с таким названием, счёт переименован
+	ArchiveConflictAccountName = "account_name"
+	// ArchiveConflictMemberRole - участник счёта с пользовательской ролью приглашён наблюдателем:
+	// приглашение не выдаёт пользовательские роли
+	ArchiveConflictMemberRole = "member_role"
+	// ArchiveConflictAuthorNotFound - автор транзакций не участник счёта, его записи переданы импортирующему
+	ArchiveConflictAuthorNotFound = "author_not_found"
+	// ArchiveConflictExchangeRate - курс на ту же дату уже сохранён с другим значением и оставлен без изменений
+	ArchiveConflictExchangeRate = "exchange_rate"
+)
+
+// ArchiveConflict - запись архива, перенесённая не как есть
+type ArchiveConflict struct {
+	Kind    string
+	Subject string // название счёта, email или пара валют с датой
+	Message string
+}
+
+// ArchiveImportedAccount - счёт, созданный из архива. SourceID - его ID в архиве
+type ArchiveImportedAccount struct {
+	SourceID int
+	ID       int
+	Name     string
+}
+
+// ArchiveInvitation - приглашение участника архива в созданный счёт
+type ArchiveInvitation struct {
+	AccountID int
+	Email     string
+	Role      query.InvitationsRole
+}
+
+// ArchiveImportResult - итог импорта архива. ExchangeRates - число сохранённых курсов,
+// курсы, уже сохранённые с тем же значением, не считаются
+type ArchiveImportResult struct {
+	Accounts       []ArchiveImportedAccount
+	Categories     int
+	RecurringRules int
+	Transactions   int
+	ExchangeRates  int
+	Invitations    []ArchiveInvitation
+	Conflicts      []ArchiveConflict
+}
+
+// ArchiveRecurringRuleParams - правило повторения из архива вместе с состоянием серии
+type ArchiveRecurringRuleParams struct {
+	CreateRecurringRuleParams
+
+	Paused           bool
+	NextIndex        int
+	NextOccurrenceAt time.Time
+	OccurrencesCount int
+	CreatedAt        time.Time
+}
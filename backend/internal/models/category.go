@@ -0,0 +1,21 @@
+package models
+
+import "microservices/accounter/internal/repository/query"
+
+type CreateCategoryParams struct {
+	AccountID int
+	UserID    int
+	ParentID  *int
+	Name      string
+}
+
+type UpdateCategoryParams struct {
+	ParentID *int
+	Name     string
+}
+
+// CategoryNode - категория с подкатегориями
+type CategoryNode struct {
+	query.Category
+	Children []CategoryNode
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionSort - поле сортировки списка транзакций. При равных значениях
+// транзакции упорядочиваются по ID, поэтому порядок стабилен
+type TransactionSort string
+
+const (
+	SortByOccurredAt TransactionSort = "occurred_at"
+	SortByAmount     TransactionSort = "amount"
+	SortByTitle      TransactionSort = "title"
+)
+
+// TransactionCursor - позиция в списке транзакций: порядок сортировки, значение поля
+// сортировки и ID последней выданной транзакции. Следующая страница начинается сразу после неё
+type TransactionCursor struct {
+	Sort       TransactionSort
+	Desc       bool
+	OccurredAt time.Time
+	Amount     money.Amount
+	Title      string
+	ID         int32
+}
+
+// cursorPayload - представление курсора внутри непрозрачной строки
+type cursorPayload struct {
+	Sort  TransactionSort `json:"s"`
+	Desc  bool            `json:"d"`
+	Value string          `json:"v"`
+	ID    int32           `json:"i"`
+}
+
+// NewTransactionCursor возвращает курсор, указывающий на транзакцию t
+func NewTransactionCursor(sort TransactionSort, desc bool, t *TaggedTransaction) *TransactionCursor {
+	return &TransactionCursor{
+		Sort:       sort,
+		Desc:       desc,
+		OccurredAt: t.OccurredAt,
+		Amount:     t.Amount,
+		Title:      t.Title,
+		ID:         t.ID,
+	}
+}
+
+// Encode записывает курсор в непрозрачную строку для клиента
+func (c *TransactionCursor) Encode() string {
+	payload := cursorPayload{Sort: c.Sort, Desc: c.Desc, ID: c.ID}
+
+	switch c.Sort {
+	case SortByAmount:
+		payload.Value = c.Amount.String()
+	case SortByTitle:
+		payload.Value = c.Title
+	default:
+		payload.Value = c.OccurredAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(payload)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTransactionCursor разбирает строку, полученную из Encode
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &TransactionCursor{Sort: payload.Sort, Desc: payload.Desc, ID: payload.ID}
+
+	switch payload.Sort {
+	case SortByOccurredAt:
+		cursor.OccurredAt, err = time.Parse(time.RFC3339Nano, payload.Value)
+	case SortByAmount:
+		cursor.Amount, err = money.Parse(payload.Value)
+	case SortByTitle:
+		cursor.Title = payload.Value
+	default:
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
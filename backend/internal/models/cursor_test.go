@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+func TestTransactionCursorRoundTrip(t *testing.T) {
+	transaction := &TaggedTransaction{Transaction: query.Transaction{
+		ID:         42,
+		Title:      `Пятёрочка "у дома", 50%`,
+		Amount:     -150050,
+		OccurredAt: time.Date(2025, 3, 10, 15, 4, 5, 123456000, time.FixedZone("MSK", 3*60*60)),
+	}}
+
+	tests := []struct {
+		sort TransactionSort
+		desc bool
+		want TransactionCursor
+	}{
+		{
+			sort: SortByOccurredAt,
+			desc: true,
+			want: TransactionCursor{Sort: SortByOccurredAt, Desc: true, OccurredAt: transaction.OccurredAt, ID: 42},
+		},
+		{
+			sort: SortByAmount,
+			want: TransactionCursor{Sort: SortByAmount, Amount: -150050, ID: 42},
+		},
+		{
+			sort: SortByTitle,
+			desc: true,
+			want: TransactionCursor{Sort: SortByTitle, Desc: true, Title: transaction.Title, ID: 42},
+		},
+	}
+
+	for _, tt := range tests {
+		encoded := NewTransactionCursor(tt.sort, tt.desc, transaction).Encode()
+
+		got, err := DecodeTransactionCursor(encoded)
+		if err != nil {
+			t.Errorf("%s: DecodeTransactionCursor(%q) error = %v", tt.sort, encoded, err)
+			continue
+		}
+
+		// В курсор попадает только значение поля сортировки
+		if got.Sort != tt.want.Sort || got.Desc != tt.want.Desc || got.ID != tt.want.ID ||
+			!got.OccurredAt.Equal(tt.want.OccurredAt) || got.Amount != tt.want.Amount || got.Title != tt.want.Title {
+			t.Errorf("%s: decoded cursor = %+v, want %+v", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeTransactionCursorInvalid(t *testing.T) {
+	valid := (&TransactionCursor{Sort: SortByAmount, Amount: 100, ID: 1}).Encode()
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"s":"amount","v":"1.00","i":1}`))},
+		{name: "truncated", cursor: valid[:len(valid)-3]},
+		{name: "not json", cursor: encode("amount:1.00:1")},
+		{name: "json array", cursor: encode(`["amount","1.00",1]`)},
+		{name: "no sort", cursor: encode(`{"v":"1.00","i":1}`)},
+		{name: "unknown sort", cursor: encode(`{"s":"id","v":"1","i":1}`)},
+		{name: "sql in sort", cursor: encode(`{"s":"amount; DROP TABLE transactions","v":"1.00","i":1}`)},
+		{name: "bad date", cursor: encode(`{"s":"occurred_at","v":"yesterday","i":1}`)},
+		{name: "bad amount", cursor: encode(`{"s":"amount","v":"1e3","i":1}`)},
+		{name: "amount precision", cursor: encode(`{"s":"amount","v":"1.001","i":1}`)},
+		{name: "amount range", cursor: encode(`{"s":"amount","v":"10000000000","i":1}`)},
+		{name: "value type", cursor: encode(`{"s":"amount","v":100,"i":1}`)},
+		{name: "id type", cursor: encode(`{"s":"title","v":"a","i":"1"}`)},
+		{name: "id overflow", cursor: encode(`{"s":"title","v":"a","i":4294967296}`)},
+	}
+
+	for _, tt := range tests {
+		if _, err := DecodeTransactionCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: DecodeTransactionCursor(%q) error = %v, want %v", tt.name, tt.cursor, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "microservices/accounter/internal/repository/query"
+
+// DuplicateCandidatePair - две транзакции счёта с одинаковой суммой и близкими датами.
+// Transaction создана раньше (меньший ID)
+type DuplicateCandidatePair struct {
+	Transaction query.ListDedupeCandidatesRow
+	Duplicate   query.ListDedupeCandidatesRow
+}
+
+// DuplicatePair - вероятный дубликат: Duplicate похожа на более раннюю Transaction
+type DuplicatePair struct {
+	Transaction TaggedTransaction
+	Duplicate   TaggedTransaction
+}
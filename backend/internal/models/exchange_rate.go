@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+// ExchangeRate - курс на дату: одна единица Base стоит Rate единиц Quote
+type ExchangeRate struct {
+	Base  money.Currency
+	Quote money.Currency
+	Date  time.Time
+	Rate  money.Rate
+}
+
+type ListExchangeRatesFilter struct {
+	UserID   int
+	Base     money.Currency // пустая строка - любая валюта
+	Quote    money.Currency
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+// DailyTotal - доходы и расходы в одной валюте за один день
+type DailyTotal struct {
+	Currency money.Currency
+	Day      time.Time
+	Income   money.Amount
+	Expense  money.Amount
+}
+
+// ConvertedTotal - итоги, пересчитанные в одну валюту по курсам на даты транзакций
+type ConvertedTotal struct {
+	Currency money.Currency
+	Income   money.Amount
+	Expense  money.Amount
+	Total    money.Amount
+}
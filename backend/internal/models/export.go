@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+// ExportRow - транзакция в выгрузке: вместо ID счёта, категории и автора - их названия
+// и email, чтобы выгрузку можно было читать без остальных данных
+type ExportRow struct {
+	ID          int32
+	OccurredAt  time.Time
+	Title       string
+	Amount      money.Amount
+	Currency    money.Currency
+	Account     string
+	CategoryID  *int32
+	Category    string // путь категории: "Еда / Продукты", пустой без категории
+	Tags        []string
+	Notes       *string
+	MemberEmail string
+	RuleID      *int32
+}
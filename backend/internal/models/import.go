@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+// ImportRow - транзакция, прочитанная из банковской выписки. Line - номер строки файла
+type ImportRow struct {
+	Line       int
+	OccurredAt time.Time
+	Title      string
+	Amount     money.Amount
+	Notes      *string
+
+	// ExternalRef - идентификатор транзакции в выписке, если он есть в формате (FITID в OFX, AcctSvcrRef в camt.053)
+	ExternalRef *string
+
+	// AlreadyImported - транзакция с тем же ExternalRef уже есть в счёте или выше в выписке
+	AlreadyImported bool
+
+	// DuplicateOf - ID транзакций счёта, похожих на эту (та же сумма, близкая дата и название)
+	DuplicateOf []int
+}
+
+// ImportLineError - ошибка разбора одной строки выписки
+type ImportLineError struct {
+	Line    int
+	Message string
+}
+
+// ImportOptions - режим импорта. DryRun - только предпросмотр, SkipDuplicates - не записывать
+// транзакции, похожие на уже существующие
+type ImportOptions struct {
+	DryRun         bool
+	SkipDuplicates bool
+}
+
+// ImportResult - результат разбора выписки: прочитанные транзакции, ошибки по строкам,
+// число записанных транзакций (0 при предпросмотре или если есть ошибки), число
+// пропущенных, потому что они уже были импортированы или пропущены как дубликаты,
+// и число вероятных дубликатов
+type ImportResult struct {
+	Currency   money.Currency
+	Rows       []ImportRow
+	Errors     []ImportLineError
+	Imported   int
+	Skipped    int
+	Duplicates int
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+// CreateInvitationParams - приглашение в счёт. InviteeID задан, если пользователь с Email зарегистрирован
+type CreateInvitationParams struct {
+	Token     string
+	AccountID int
+	InviterID int
+	Email     string
+	InviteeID *int
+	Role      query.InvitationsRole
+	ExpiresAt time.Time
+}
+
+// CreateInviteLinkParams - ссылка-приглашение в счёт. MaxUses == nil - без ограничения числа использований
+type CreateInviteLinkParams struct {
+	Code      string
+	AccountID int
+	CreatedBy int
+	Role      query.InviteLinksRole
+	MaxUses   *int
+	ExpiresAt time.Time
+}
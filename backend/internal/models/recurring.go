@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository/query"
+)
+
+type CreateRecurringRuleParams struct {
+	AccountID      int
+	UserID         int
+	Title          string
+	Amount         money.Amount
+	Currency       money.Currency
+	Period         query.RecurringRulesPeriod
+	Interval       int
+	StartsAt       time.Time
+	EndsAt         *time.Time
+	MaxOccurrences *int
+	CategoryID     *int
+	Notes          *string
+	Tags           []string
+}
+
+// UpdateRecurringRuleParams описывает серию начиная с даты From.
+// StartsAt - дата первого вхождения изменённой серии, по умолчанию совпадает с From
+type UpdateRecurringRuleParams struct {
+	From           time.Time
+	StartsAt       time.Time
+	Title          string
+	Amount         money.Amount
+	Period         query.RecurringRulesPeriod
+	Interval       int
+	EndsAt         *time.Time
+	MaxOccurrences *int
+	CategoryID     *int
+	Notes          *string
+	Tags           []string // nil - метки серии не меняются
+}
+
+// UpdateRuleOccurrencesParams - изменения уже созданных вхождений серии. nil-поля не меняются,
+// категория и примечание меняются только при SetCategory и SetNotes (nil в них очищает поле).
+// Shift сдвигает даты вхождений
+type UpdateRuleOccurrencesParams struct {
+	Title       *string
+	Amount      *money.Amount
+	SetCategory bool
+	CategoryID  *int
+	SetNotes    bool
+	Notes       *string
+	Shift       time.Duration
+}
+
+// TaggedRecurringRule - правило повторения с его метками
+type TaggedRecurringRule struct {
+	query.RecurringRule
+	Tags []string
+}
+
+// SeriesScope определяет, к каким вхождениям серии применяется изменение транзакции
+type SeriesScope string
+
+const (
+	SeriesScopeThis      SeriesScope = "this"
+	SeriesScopeFollowing SeriesScope = "following"
+	SeriesScopeAll       SeriesScope = "all"
+)
@@ -0,0 +1,7 @@
+package models
+
+// ClientInfo описывает устройство, с которого выполнен вход
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
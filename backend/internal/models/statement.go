@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"microservices/accounter/internal/money"
+)
+
+// Statement - заголовок выписки по счёту за период: транзакции выписки передаются отдельно,
+// по одной, в порядке даты
+type Statement struct {
+	Account        string
+	Currency       money.Currency
+	From           time.Time
+	To             time.Time // включительно
+	OpeningBalance money.Amount
+	GeneratedAt    time.Time
+}
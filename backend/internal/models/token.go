@@ -3,11 +3,15 @@ package models
 import "time"
 
 type Token struct {
-	AccessToken  string
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
 
 type GenerateToken struct {
 	UserID    int
+	FamilyID  string
 	TokenHash string
 	ExpiresAt time.Time
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository/query"
 )
 
@@ -10,15 +11,27 @@ type CreateTransactionParams struct {
 	AccountID  int
 	UserID     int
 	Title      string
-	Amount     string
+	Amount     money.Amount
+	Currency   money.Currency
 	OccurredAt time.Time
 	Period     query.NullTransactionsPeriod
+	CategoryID *int
+	Notes      *string
+
+	// ExternalRef - идентификатор транзакции в импортированной выписке
+	ExternalRef *string
+
+	// RuleID - правило, вхождением которого является транзакция. Задаётся только при переносе из архива
+	RuleID *int
 }
 
 type UpdateTransactionParams struct {
 	Title      string
-	Amount     string
+	Amount     money.Amount
 	OccurredAt time.Time
+	CategoryID *int
+	Notes      *string
+	Tags       []string // nil - метки не меняются
 }
 
 type ListTransactionsFilter struct {
@@ -27,4 +40,60 @@ type ListTransactionsFilter struct {
 	DateFrom  *time.Time
 	DateTo    *time.Time
 	Type      *string // "income" | "expense"
+
+	// Search - подстрока названия или примечания, без учёта регистра
+	Search string
+
+	// Границы суммы со знаком, включительно: расходы отрицательные
+	AmountMin *money.Amount
+	AmountMax *money.Amount
+
+	// PlannedAfter исключает вхождения серий, запланированные позже этого момента
+	PlannedAfter *time.Time
+
+	// CategoryID - категория вместе с подкатегориями. CategoryIDs заполняется сервисом:
+	// это ID самой категории и всех её подкатегорий
+	CategoryID  *int
+	CategoryIDs []int
+
+	Tags *TagFilter
+
+	// Сортировка и страница: не более Limit транзакций после курсора After
+	Sort  TransactionSort
+	Desc  bool
+	Limit int
+	After *TransactionCursor
+}
+
+// TagFilter - фильтр по меткам: хотя бы одна из Names или, если All, все
+type TagFilter struct {
+	Names []string
+	All   bool
+}
+
+// TaggedTransaction - транзакция с её метками
+type TaggedTransaction struct {
+	query.Transaction
+	Tags []string
+}
+
+// SummaryFilter ограничивает период, за который считаются итоги
+type SummaryFilter struct {
+	DateFrom *time.Time
+	DateTo   time.Time
+}
+
+// CurrencyTotal - итоги по транзакциям в одной валюте
+type CurrencyTotal struct {
+	Currency money.Currency
+	Count    int
+	Income   money.Amount
+	Expense  money.Amount
+	Total    money.Amount
+}
+
+// TransactionWithBalance - транзакция и остаток счёта сразу после неё
+type TransactionWithBalance struct {
+	TaggedTransaction
+	RunningBalance money.Amount
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CreateUser struct {
 	Email        string
 	PasswordHash string
@@ -10,3 +12,26 @@ type User struct {
 	Email        string
 	PasswordHash string
 }
+
+// Действия со счётом владельца при удалении пользователя
+const (
+	DeletionTransferAccount = "transfer"
+	DeletionDeleteAccount   = "delete"
+)
+
+// UserDeletion - запланированное удаление пользователя и что будет с его счетами
+type UserDeletion struct {
+	RequestedAt time.Time
+	DeleteAt    time.Time
+	Accounts    []DeletionAccount
+}
+
+// DeletionAccount - счёт удаляемого пользователя. NewOwnerID задан, если счёт будет передан участнику.
+// Default - выбор сделан сервером: пользователь не указал счёт или выбранный участник покинул счёт
+type DeletionAccount struct {
+	AccountID  int
+	Name       string
+	Action     string
+	NewOwnerID *int
+	Default    bool
+}
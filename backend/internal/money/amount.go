@@ -0,0 +1,165 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Scale - число знаков после запятой в колонках DECIMAL(12,2)
+const Scale = 2
+
+// maxIntegerDigits - число знаков до запятой в колонках DECIMAL(12,2)
+const maxIntegerDigits = 12 - Scale
+
+var (
+	ErrInvalidAmount = errors.New("amount must be a decimal number like -1500.50")
+	ErrAmountScale   = errors.New("amount must have at most 2 decimal places")
+	ErrAmountRange   = errors.New("amount is out of range, at most 10 digits before the decimal point")
+)
+
+// Amount - денежная сумма в сотых долях (копейках). Хранится как целое число,
+// поэтому не проходит через двоичную плавающую точку ни при разборе, ни при выводе
+type Amount int64
+
+// Parse разбирает десятичную запись суммы: необязательный знак, цифры
+// и не более Scale знаков после точки. Экспоненциальная запись не допускается.
+// Суммы, не помещающиеся в DECIMAL(12,2), отклоняются
+func Parse(s string) (Amount, error) {
+	a, digits, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if digits > maxIntegerDigits {
+		return 0, ErrAmountRange
+	}
+
+	return a, nil
+}
+
+// parse разбирает десятичную запись и возвращает число значащих цифр до точки
+func parse(s string) (Amount, int, error) {
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	integer, fraction, hasPoint := strings.Cut(s, ".")
+	if integer == "" || (hasPoint && fraction == "") || !isDigits(integer) || !isDigits(fraction) {
+		return 0, 0, ErrInvalidAmount
+	}
+
+	if len(fraction) > Scale {
+		// Незначащие нули в конце допустимы: 10.500 == 10.50
+		if strings.Trim(fraction[Scale:], "0") != "" {
+			return 0, 0, ErrAmountScale
+		}
+		fraction = fraction[:Scale]
+	}
+
+	integer = strings.TrimLeft(integer, "0")
+	fraction += strings.Repeat("0", Scale-len(fraction))
+
+	value, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return 0, 0, ErrAmountRange
+	}
+
+	if negative {
+		value = -value
+	}
+
+	return Amount(value), len(integer), nil
+}
+
+// String возвращает сумму в виде "-1500.50"
+func (a Amount) String() string {
+	value := int64(a)
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+// MarshalJSON выводит сумму строкой, чтобы клиент не терял точность
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON принимает сумму строкой ("-1500.50"). Для совместимости со старыми
+// клиентами принимается и JSON-число: оно разбирается как текст, без float64
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		unquoted, err := strconv.Unquote(text)
+		if err != nil {
+			return ErrInvalidAmount
+		}
+		text = strings.TrimSpace(unquoted)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
+
+// Value сохраняет сумму в колонку DECIMAL в виде десятичной строки
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan читает сумму из колонки DECIMAL или результата агрегатной функции
+func (a *Amount) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		*a = Amount(v * 100)
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", src)
+	}
+
+	// Итоги (SUM) могут быть больше DECIMAL(12,2), поэтому диапазон здесь не ограничивается
+	parsed, _, err := parse(text)
+	if err != nil {
+		return fmt.Errorf("money: cannot scan %q: %w", text, err)
+	}
+
+	*a = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		{in: "0", want: 0},
+		{in: "1500.50", want: 150050},
+		{in: "1500.5", want: 150050},
+		{in: "-1500.50", want: -150050},
+		{in: "+42", want: 4200},
+		{in: "0.01", want: 1},
+		{in: "-0.01", want: -1},
+		{in: "007.10", want: 710},
+
+		// Незначащие нули в дробной части отбрасываются
+		{in: "10.500", want: 1050},
+		{in: "10.000000", want: 1000},
+		{in: "10.501", err: ErrAmountScale},
+		{in: "0.001", err: ErrAmountScale},
+
+		// Отрицательный ноль - просто ноль
+		{in: "-0", want: 0},
+		{in: "-0.00", want: 0},
+
+		// DECIMAL(12,2): не больше 10 знаков до точки
+		{in: "9999999999.99", want: 999999999999},
+		{in: "-9999999999.99", want: -999999999999},
+		{in: "0000000000001.00", want: 100},
+		{in: "10000000000", err: ErrAmountRange},
+		{in: "-10000000000.00", err: ErrAmountRange},
+		{in: "99999999999999999999", err: ErrAmountRange},
+
+		{in: "", err: ErrInvalidAmount},
+		{in: "-", err: ErrInvalidAmount},
+		{in: "+-1", err: ErrInvalidAmount},
+		{in: "10.", err: ErrInvalidAmount},
+		{in: ".5", err: ErrInvalidAmount},
+		{in: "1,5", err: ErrInvalidAmount},
+		{in: "1e3", err: ErrInvalidAmount},
+		{in: " 1", err: ErrInvalidAmount},
+		{in: "1.2.3", err: ErrInvalidAmount},
+		{in: "abc", err: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: 1, want: "0.01"},
+		{in: -1, want: "-0.01"},
+		{in: 150050, want: "1500.50"},
+		{in: -150050, want: "-1500.50"},
+		{in: 999999999999, want: "9999999999.99"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAmountUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		// Строка - основной формат
+		{in: `"-1500.50"`, want: -150050},
+		{in: `"1500"`, want: 150000},
+		{in: `"10.500"`, want: 1050},
+		{in: `" 10.5 "`, want: 1050},
+		{in: `"-0"`, want: 0},
+
+		// Число разбирается как текст, без float64
+		{in: `-1500.50`, want: -150050},
+		{in: `1500`, want: 150000},
+		{in: `0.1`, want: 10},
+		{in: `9999999999.99`, want: 999999999999},
+		{in: `-0`, want: 0},
+
+		// null оставляет сумму без изменений
+		{in: `null`, want: 0},
+
+		{in: `"10.555"`, err: ErrAmountScale},
+		{in: `10.555`, err: ErrAmountScale},
+		{in: `"10000000000"`, err: ErrAmountRange},
+		{in: `10000000000`, err: ErrAmountRange},
+		{in: `1e3`, err: ErrInvalidAmount},
+		{in: `"1e3"`, err: ErrInvalidAmount},
+		{in: `""`, err: ErrInvalidAmount},
+		{in: `"abc"`, err: ErrInvalidAmount},
+		{in: `"10`, err: ErrInvalidAmount},
+		{in: `true`, err: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		var got Amount
+		err := got.UnmarshalJSON([]byte(tt.in))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("UnmarshalJSON(%s) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UnmarshalJSON(%s) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAmountMarshalJSON(t *testing.T) {
+	data, err := Amount(-150050).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `"-1500.50"` {
+		t.Errorf("MarshalJSON() = %s, want %q", data, "-1500.50")
+	}
+}
+
+func TestAmountFits(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		currency Currency
+		want     bool
+	}{
+		{amount: 150050, currency: "RUB", want: true},
+		{amount: 1, currency: "USD", want: true},
+		{amount: 0, currency: "JPY", want: true},
+		{amount: 150000, currency: "JPY", want: true},
+		{amount: -150000, currency: "JPY", want: true},
+		{amount: 150050, currency: "JPY", want: false},
+		{amount: -1, currency: "JPY", want: false},
+		{amount: 10, currency: "KRW", want: false},
+
+		// Неизвестная валюта считается двухзнаковой
+		{amount: 1, currency: "XXX", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Fits(tt.currency); got != tt.want {
+			t.Errorf("Amount(%d).Fits(%s) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
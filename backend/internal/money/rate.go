@@ -0,0 +1,85 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// RateScale - число знаков после запятой в колонке курса DECIMAL(18,8)
+const RateScale = 8
+
+var ErrInvalidRate = errors.New("rate must be a positive decimal number with at most 8 decimal places")
+
+// Rate - курс обмена: сколько единиц валюты котировки стоит одна единица базовой валюты.
+// Хранится как точная дробь, поэтому произведение курсов при кросс-конвертации не теряет точность
+type Rate struct {
+	value *big.Rat
+}
+
+// One - курс валюты к самой себе
+var One = Rate{value: big.NewRat(1, 1)}
+
+// ParseRate разбирает десятичную запись курса. Как в выгрузках ЦБ РФ, в качестве
+// разделителя дробной части допускается и запятая: "92,5058"
+func ParseRate(s string) (Rate, error) {
+	s = strings.Replace(strings.TrimSpace(s), ",", ".", 1)
+
+	integer, fraction, _ := strings.Cut(s, ".")
+	if integer == "" || !isDigits(integer) || !isDigits(fraction) || len(fraction) > RateScale {
+		return Rate{}, ErrInvalidRate
+	}
+
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || value.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+
+	return Rate{value: value}, nil
+}
+
+// PerUnits возвращает курс за одну единицу, если курс указан за nominal единиц (100 JPY = 58,9 RUB)
+func (r Rate) PerUnits(nominal int64) Rate {
+	return Rate{value: new(big.Rat).Quo(r.value, big.NewRat(nominal, 1))}
+}
+
+// Inverse возвращает обратный курс
+func (r Rate) Inverse() Rate {
+	return Rate{value: new(big.Rat).Inv(r.value)}
+}
+
+// Mul возвращает произведение курсов: курс A/B, умноженный на курс B/C, даёт курс A/C
+func (r Rate) Mul(other Rate) Rate {
+	return Rate{value: new(big.Rat).Mul(r.value, other.value)}
+}
+
+// String возвращает курс с RateScale знаками после запятой
+func (r Rate) String() string {
+	return r.value.FloatString(RateScale)
+}
+
+// Convert пересчитывает сумму по курсу и округляет её до минорных единиц валюты to
+// (половина округляется от нуля)
+func (a Amount) Convert(r Rate, to Currency) Amount {
+	step := pow10(Scale - to.MinorUnits())
+
+	// Результат в единицах шага валюты to: для RUB - копейки, для JPY - иены
+	value := new(big.Rat).Mul(big.NewRat(int64(a), step), r.value)
+
+	num := new(big.Int).Set(value.Num())
+	den := value.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	// round(num / den) = (2*num + den) / (2*den)
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, den)
+	rounded := num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if negative {
+		rounded.Neg(rounded)
+	}
+
+	return Amount(rounded.Int64() * step)
+}
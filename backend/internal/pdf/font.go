@@ -0,0 +1,389 @@
+package pdf
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Шрифты документа - Oswald (SIL Open Font License), тот же, что во фронтенде.
+// В нём есть кириллица, поэтому названия транзакций выводятся без замены символов
+//
+//go:embed fonts/*.ttf
+var fonts embed.FS
+
+var (
+	Regular = mustLoadFont("fonts/Oswald-Regular.ttf", "Oswald-Regular")
+	Bold    = mustLoadFont("fonts/Oswald-SemiBold.ttf", "Oswald-SemiBold")
+)
+
+var ErrBadFont = errors.New("invalid TrueType font")
+
+// Font - шрифт TrueType. В документ встраиваются только использованные глифы
+type Font struct {
+	name   string
+	data   []byte
+	tables map[string][]byte
+
+	unitsPerEm int
+	ascent     int
+	descent    int
+	capHeight  int
+	bbox       [4]int
+	italic     float64
+
+	numGlyphs int
+	advances  []int // ширина глифа в единицах unitsPerEm
+	glyphs    map[rune]uint16
+}
+
+func mustLoadFont(path string, name string) *Font {
+	data, err := fonts.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	font, err := ParseFont(data, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return font
+}
+
+// ParseFont читает шрифт TrueType с таблицей символов Unicode BMP (формат 4)
+func ParseFont(data []byte, name string) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrBadFont
+	}
+
+	f := &Font{name: name, data: data, tables: make(map[string][]byte)}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, ErrBadFont
+	}
+	for i := range numTables {
+		record := data[12+16*i:]
+		tag := string(record[:4])
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+		if offset+length > len(data) {
+			return nil, ErrBadFont
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf"} {
+		if _, ok := f.tables[tag]; !ok {
+			return nil, ErrBadFont
+		}
+	}
+
+	head := f.tables["head"]
+	hhea := f.tables["hhea"]
+	if len(head) < 54 || len(hhea) < 36 || len(f.tables["maxp"]) < 6 {
+		return nil, ErrBadFont
+	}
+
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	f.capHeight = f.ascent
+	if os2 := f.tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		f.capHeight = int(int16(binary.BigEndian.Uint16(os2[88:])))
+	}
+	if post := f.tables["post"]; len(post) >= 8 {
+		f.italic = float64(int32(binary.BigEndian.Uint32(post[4:]))) / 65536
+	}
+
+	f.numGlyphs = int(binary.BigEndian.Uint16(f.tables["maxp"][4:]))
+
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := f.tables["hmtx"]
+	if numMetrics == 0 || len(hmtx) < 4*numMetrics {
+		return nil, ErrBadFont
+	}
+	f.advances = make([]int, f.numGlyphs)
+	for i := range f.advances {
+		// Глифы после numMetrics имеют ширину последнего из них
+		f.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*min(i, numMetrics-1):]))
+	}
+
+	glyphs, err := parseCmap(f.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+
+	return f, nil
+}
+
+// parseCmap читает таблицу символов Windows Unicode BMP (platform 3, encoding 1, формат 4)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrBadFont
+	}
+
+	var sub []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := range numTables {
+		record := cmap[4+8*i:]
+		if len(record) < 8 {
+			return nil, ErrBadFont
+		}
+		platform := binary.BigEndian.Uint16(record)
+		encoding := binary.BigEndian.Uint16(record[2:])
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if platform == 3 && encoding == 1 && offset+14 <= len(cmap) {
+			sub = cmap[offset:]
+		}
+	}
+	if sub == nil || binary.BigEndian.Uint16(sub) != 4 {
+		return nil, ErrBadFont
+	}
+
+	segments := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	ends := 14
+	starts := ends + 2*segments + 2
+	deltas := starts + 2*segments
+	rangeOffsets := deltas + 2*segments
+	if len(sub) < rangeOffsets+2*segments {
+		return nil, ErrBadFont
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := range segments {
+		end := int(binary.BigEndian.Uint16(sub[ends+2*i:]))
+		start := int(binary.BigEndian.Uint16(sub[starts+2*i:]))
+		delta := binary.BigEndian.Uint16(sub[deltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsets+2*i:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			glyph := uint16(c) + delta
+			if rangeOffset != 0 {
+				// Смещение отсчитывается от самого поля idRangeOffset
+				at := rangeOffsets + 2*i + rangeOffset + 2*(c-start)
+				if at+2 > len(sub) {
+					return nil, ErrBadFont
+				}
+				glyph = binary.BigEndian.Uint16(sub[at:])
+				if glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 {
+				glyphs[rune(c)] = glyph
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+// glyph возвращает номер глифа символа. Символы, которых нет в шрифте, выводятся глифом 0
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// Width возвращает ширину текста в пунктах при размере шрифта size
+func (f *Font) Width(text string, size float64) float64 {
+	units := 0
+	for _, r := range text {
+		units += f.advances[f.glyph(r)]
+	}
+
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// scale переводит единицы шрифта в тысячные доли кегля, в которых размеры записываются в PDF
+func (f *Font) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// subset возвращает шрифт, в котором оставлены только глифы used и их составные части.
+// Номера глифов не меняются: остальные глифы становятся пустыми
+func (f *Font) subset(used map[uint16]rune) ([]byte, error) {
+	head := f.tables["head"]
+	loca := f.tables["loca"]
+	glyf := f.tables["glyf"]
+
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+	offset := func(glyph int) (int, int, error) {
+		var start, end int
+		if longLoca {
+			if 4*glyph+8 > len(loca) {
+				return 0, 0, ErrBadFont
+			}
+			start = int(binary.BigEndian.Uint32(loca[4*glyph:]))
+			end = int(binary.BigEndian.Uint32(loca[4*glyph+4:]))
+		} else {
+			if 2*glyph+4 > len(loca) {
+				return 0, 0, ErrBadFont
+			}
+			start = 2 * int(binary.BigEndian.Uint16(loca[2*glyph:]))
+			end = 2 * int(binary.BigEndian.Uint16(loca[2*glyph+2:]))
+		}
+		if start > end || end > len(glyf) {
+			return 0, 0, ErrBadFont
+		}
+		return start, end, nil
+	}
+
+	// Глиф 0 нужен всегда: им выводятся отсутствующие в шрифте символы
+	keep := map[int]bool{0: true}
+	queue := []int{0}
+	for glyph := range used {
+		keep[int(glyph)] = true
+		queue = append(queue, int(glyph))
+	}
+
+	// Составной глиф ссылается на другие глифы, они тоже остаются
+	for len(queue) > 0 {
+		glyph := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		start, end, err := offset(glyph)
+		if err != nil {
+			return nil, err
+		}
+		data := glyf[start:end]
+		if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+			continue
+		}
+
+		for at := 10; at+4 <= len(data); {
+			flags := binary.BigEndian.Uint16(data[at:])
+			component := int(binary.BigEndian.Uint16(data[at+2:]))
+			if !keep[component] {
+				keep[component] = true
+				queue = append(queue, component)
+			}
+
+			at += 4
+			if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+				at += 4
+			} else {
+				at += 2
+			}
+			switch {
+			case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+				at += 2
+			case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+				at += 4
+			case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+				at += 8
+			}
+			if flags&0x0020 == 0 { // MORE_COMPONENTS
+				break
+			}
+		}
+	}
+
+	var newGlyf bytes.Buffer
+	newLoca := make([]byte, 4*(f.numGlyphs+1))
+	for glyph := range f.numGlyphs {
+		binary.BigEndian.PutUint32(newLoca[4*glyph:], uint32(newGlyf.Len()))
+		if !keep[glyph] {
+			continue
+		}
+
+		start, end, err := offset(glyph)
+		if err != nil {
+			return nil, err
+		}
+		newGlyf.Write(glyf[start:end])
+		// Глифы выравниваются по 4 байта
+		for newGlyf.Len()%4 != 0 {
+			newGlyf.WriteByte(0)
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*f.numGlyphs:], uint32(newGlyf.Len()))
+
+	// loca пишется в длинном формате, это отмечается в head
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	binary.BigEndian.PutUint32(newHead[8:], 0)
+
+	tables := map[string][]byte{
+		"head": newHead,
+		"hhea": f.tables["hhea"],
+		"maxp": f.tables["maxp"],
+		"hmtx": f.tables["hmtx"],
+		"loca": newLoca,
+		"glyf": newGlyf.Bytes(),
+	}
+	// Инструкции хинтинга нужны глифам, которые на них ссылаются
+	for _, tag := range []string{"cvt ", "fpgm", "prep"} {
+		if table, ok := f.tables[tag]; ok {
+			tables[tag] = table
+		}
+	}
+
+	return writeFont(tables), nil
+}
+
+// writeFont собирает файл шрифта TrueType из таблиц
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	searchRange, selector := 1, 0
+	for searchRange*2 <= numTables {
+		searchRange *= 2
+		selector++
+	}
+
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange*16))
+	binary.BigEndian.PutUint16(header[8:], uint16(selector))
+	binary.BigEndian.PutUint16(header[10:], uint16(numTables*16-searchRange*16))
+
+	var body bytes.Buffer
+	headOffset := 0
+	for i, tag := range tags {
+		table := tables[tag]
+		offset := len(header) + body.Len()
+		if tag == "head" {
+			headOffset = offset
+		}
+
+		record := header[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], checksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(offset))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+
+		body.Write(table)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	font := append(header, body.Bytes()...)
+	// checkSumAdjustment в head считается по всему файлу
+	binary.BigEndian.PutUint32(font[headOffset+8:], 0xB1B0AFBA-checksum(font))
+
+	return font
+}
+
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
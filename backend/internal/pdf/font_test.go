@@ -0,0 +1,253 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestParseFont(t *testing.T) {
+	for _, font := range []*Font{Regular, Bold} {
+		if font.unitsPerEm == 0 || font.numGlyphs == 0 || len(font.advances) != font.numGlyphs {
+			t.Errorf("%s: unitsPerEm=%d numGlyphs=%d advances=%d", font.name, font.unitsPerEm, font.numGlyphs, len(font.advances))
+		}
+
+		for _, r := range "AzАяЁё0€" {
+			if font.glyph(r) == 0 {
+				t.Errorf("%s: no glyph for %q", font.name, r)
+			}
+		}
+
+		// Символа нет в шрифте - глиф 0
+		if glyph := font.glyph('😀'); glyph != 0 {
+			t.Errorf("%s: glyph for emoji = %d, want 0", font.name, glyph)
+		}
+	}
+
+	for _, data := range [][]byte{nil, []byte("not a font"), Regular.data[:100]} {
+		if _, err := ParseFont(data, "broken"); err != ErrBadFont {
+			t.Errorf("ParseFont(%d bytes) error = %v, want %v", len(data), err, ErrBadFont)
+		}
+	}
+}
+
+func TestSubset(t *testing.T) {
+	const text = "Съешь же ещё этих мягких французских булок, да выпей чаю. Йод, Ёж"
+
+	font := Regular
+	used := make(map[uint16]rune)
+	for _, r := range text {
+		used[font.glyph(r)] = r
+	}
+
+	// й и ё в шрифте составные: их части должны попасть в подмножество
+	for _, r := range "йё" {
+		if !isComposite(fontGlyph(font.tables, font.glyph(r))) {
+			t.Fatalf("glyph of %q is not composite, the test needs another character", r)
+		}
+	}
+
+	data, err := font.subset(used)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := checkFontFile(t, data)
+
+	head := tables["head"]
+	if format := binary.BigEndian.Uint16(head[50:]); format != 1 {
+		t.Fatalf("indexToLocFormat = %d, want 1", format)
+	}
+
+	loca, glyf := tables["loca"], tables["glyf"]
+	if len(loca) != 4*(font.numGlyphs+1) {
+		t.Fatalf("loca has %d bytes, want %d", len(loca), 4*(font.numGlyphs+1))
+	}
+
+	for glyph := range font.numGlyphs {
+		start := binary.BigEndian.Uint32(loca[4*glyph:])
+		end := binary.BigEndian.Uint32(loca[4*glyph+4:])
+		if start > end || start%4 != 0 || int(end) > len(glyf) {
+			t.Fatalf("glyph %d: loca offsets %d..%d are invalid for glyf of %d bytes", glyph, start, end, len(glyf))
+		}
+	}
+	if last := binary.BigEndian.Uint32(loca[4*font.numGlyphs:]); int(last) != len(glyf) {
+		t.Errorf("last loca offset = %d, want glyf length %d", last, len(glyf))
+	}
+
+	// Остаются использованные глифы, глиф 0 и все части составных глифов, остальные пустые
+	keep := map[uint16]bool{0: true}
+	queue := []uint16{0}
+	for glyph := range used {
+		queue = append(queue, glyph)
+	}
+	for len(queue) > 0 {
+		glyph := queue[0]
+		queue = queue[1:]
+		keep[glyph] = true
+		for _, component := range glyphComponents(fontGlyph(font.tables, glyph)) {
+			if !keep[component] {
+				queue = append(queue, component)
+			}
+		}
+	}
+
+	components := 0
+	for glyph := range font.numGlyphs {
+		original := fontGlyph(font.tables, uint16(glyph))
+		got := fontGlyph(tables, uint16(glyph))
+
+		if !keep[uint16(glyph)] {
+			if len(got) != 0 {
+				t.Errorf("glyph %d is not used but has %d bytes", glyph, len(got))
+			}
+			continue
+		}
+
+		if _, ok := used[uint16(glyph)]; !ok && glyph != 0 {
+			components++
+		}
+
+		// Глиф копируется без изменений, после него - выравнивание нулями до 4 байт
+		if !bytes.HasPrefix(got, original) || len(got)-len(original) >= 4 || len(bytes.Trim(got[len(original):], "\x00")) != 0 {
+			t.Errorf("glyph %d differs from the original: %d bytes, want %d", glyph, len(got), len(original))
+		}
+	}
+	if components == 0 {
+		t.Error("no composite glyph components in the subset")
+	}
+
+	// Таблицы метрик не меняются: номера глифов в подмножестве те же
+	for _, tag := range []string{"hhea", "hmtx", "maxp"} {
+		if !bytes.Equal(tables[tag], font.tables[tag]) {
+			t.Errorf("table %s differs from the original", tag)
+		}
+	}
+}
+
+func TestSubsetEmpty(t *testing.T) {
+	data, err := Bold.subset(map[uint16]rune{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := checkFontFile(t, data)
+
+	// Только глиф 0
+	loca := tables["loca"]
+	for glyph := 1; glyph <= Bold.numGlyphs; glyph++ {
+		if got := binary.BigEndian.Uint32(loca[4*glyph:]); int(got) != len(tables["glyf"]) {
+			t.Fatalf("glyph %d starts at %d, want %d", glyph-1, got, len(tables["glyf"]))
+		}
+	}
+}
+
+// checkFontFile проверяет каталог таблиц файла шрифта, их выравнивание и контрольные суммы
+// и возвращает таблицы по тегам
+func checkFontFile(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	if len(data) < 12 || binary.BigEndian.Uint32(data) != 0x00010000 {
+		t.Fatal("not a TrueType font")
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	searchRange := int(binary.BigEndian.Uint16(data[6:]))
+	selector := int(binary.BigEndian.Uint16(data[8:]))
+	rangeShift := int(binary.BigEndian.Uint16(data[10:]))
+	if searchRange != 16*(1<<selector) || searchRange > 16*numTables || 2*searchRange <= 16*numTables ||
+		rangeShift != 16*numTables-searchRange {
+		t.Errorf("numTables=%d searchRange=%d entrySelector=%d rangeShift=%d are inconsistent",
+			numTables, searchRange, selector, rangeShift)
+	}
+
+	tables := make(map[string][]byte)
+	previous := ""
+	for i := range numTables {
+		record := data[12+16*i:]
+		tag := string(record[:4])
+		sum := binary.BigEndian.Uint32(record[4:])
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+
+		if tag <= previous {
+			t.Errorf("table %q is out of order after %q", tag, previous)
+		}
+		previous = tag
+
+		if offset%4 != 0 || offset+length > len(data) {
+			t.Fatalf("table %q: offset %d length %d in a file of %d bytes", tag, offset, length, len(data))
+		}
+		table := data[offset : offset+length]
+		tables[tag] = table
+
+		// Сумма head считается с обнулённым checkSumAdjustment
+		if tag == "head" {
+			table = append([]byte(nil), table...)
+			binary.BigEndian.PutUint32(table[8:], 0)
+		}
+		if got := checksum(table); got != sum {
+			t.Errorf("table %q checksum = %08X, recorded %08X", tag, got, sum)
+		}
+	}
+
+	if got := checksum(data); got != 0xB1B0AFBA {
+		t.Errorf("file checksum = %08X, want B1B0AFBA", got)
+	}
+
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			t.Fatalf("no %q table", tag)
+		}
+	}
+
+	return tables
+}
+
+// fontGlyph возвращает описание глифа из таблиц loca и glyf в любом формате loca
+func fontGlyph(tables map[string][]byte, glyph uint16) []byte {
+	loca := tables["loca"]
+	var start, end int
+	if binary.BigEndian.Uint16(tables["head"][50:]) == 1 {
+		start = int(binary.BigEndian.Uint32(loca[4*int(glyph):]))
+		end = int(binary.BigEndian.Uint32(loca[4*int(glyph)+4:]))
+	} else {
+		start = 2 * int(binary.BigEndian.Uint16(loca[2*int(glyph):]))
+		end = 2 * int(binary.BigEndian.Uint16(loca[2*int(glyph)+2:]))
+	}
+	return tables["glyf"][start:end]
+}
+
+func isComposite(data []byte) bool {
+	return len(data) >= 10 && int16(binary.BigEndian.Uint16(data)) < 0
+}
+
+// glyphComponents возвращает номера глифов, из которых состоит составной глиф
+func glyphComponents(data []byte) []uint16 {
+	if !isComposite(data) {
+		return nil
+	}
+
+	var components []uint16
+	for at := 10; ; {
+		flags := binary.BigEndian.Uint16(data[at:])
+		components = append(components, binary.BigEndian.Uint16(data[at+2:]))
+
+		size := 4 + 2
+		if flags&0x0001 != 0 {
+			size = 4 + 4
+		}
+		if flags&0x0008 != 0 {
+			size += 2
+		} else if flags&0x0040 != 0 {
+			size += 4
+		} else if flags&0x0080 != 0 {
+			size += 8
+		}
+		at += size
+
+		if flags&0x0020 == 0 {
+			return components
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Package pdf записывает простые PDF-документы: страницы с текстом, линиями и заливками.
+// Шрифты TrueType встраиваются подмножеством глифов, поэтому текст может быть на любом языке,
+// символы которого есть в шрифте. Готовые страницы пишутся сразу, не дожидаясь конца документа
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размер страницы A4 в пунктах
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document - PDF-документ. Страницы добавляются по одной через NewPage, Close дописывает
+// шрифты, дерево страниц и таблицу ссылок
+type Document struct {
+	w       *countingWriter
+	offsets []int64 // смещения объектов; индекс - номер объекта минус 1
+
+	title     string
+	created   time.Time
+	pages     []int
+	pagesRef  int
+	resources int
+	fonts     []*documentFont
+
+	page *Page
+	err  error
+}
+
+// documentFont - шрифт документа и использованные в нём глифы
+type documentFont struct {
+	font *Font
+	name string // имя ресурса: F1, F2, ...
+	ref  int
+	used map[uint16]rune
+}
+
+// New начинает документ с заголовком title
+func New(w io.Writer, title string) *Document {
+	d := &Document{
+		w:       &countingWriter{w: bufio.NewWriter(w)},
+		title:   title,
+		created: time.Now(),
+	}
+
+	// Двоичные байты в комментарии сообщают программам, что файл не текстовый
+	d.write("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+
+	// Дерево страниц и общий для всех страниц словарь ресурсов пишутся в конце,
+	// когда известны все страницы и шрифты, а страницы ссылаются на них заранее
+	d.pagesRef = d.reserve()
+	d.resources = d.reserve()
+
+	return d
+}
+
+// NewPage завершает текущую страницу и начинает новую размером width на height пунктов
+func (d *Document) NewPage(width, height float64) *Page {
+	d.finishPage()
+
+	d.page = &Page{doc: d, width: width, height: height}
+	return d.page
+}
+
+// Close завершает документ. Ошибка записи в любой момент возвращается здесь
+func (d *Document) Close() error {
+	d.finishPage()
+	if d.err != nil {
+		return d.err
+	}
+
+	fonts := new(bytes.Buffer)
+	for _, f := range d.fonts {
+		if err := d.writeFont(f); err != nil {
+			return err
+		}
+		fmt.Fprintf(fonts, "/%s %d 0 R ", f.name, f.ref)
+	}
+	d.object(d.resources, fmt.Sprintf("<< /Font << %s>> >>", fonts.String()))
+
+	kids := new(bytes.Buffer)
+	for _, page := range d.pages {
+		fmt.Fprintf(kids, "%d 0 R ", page)
+	}
+	d.object(d.pagesRef, fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count %d >>", kids.String(), len(d.pages)))
+
+	catalog := d.reserve()
+	d.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", d.pagesRef))
+
+	info := d.reserve()
+	d.object(info, fmt.Sprintf("<< /Title %s /Producer (accounter) /CreationDate (D:%s) >>",
+		textString(d.title), d.created.UTC().Format("20060102150405Z")))
+
+	xref := d.w.n
+	d.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(d.offsets)+1))
+	for _, offset := range d.offsets {
+		d.write(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	d.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(d.offsets)+1, catalog, info, xref))
+
+	if d.err != nil {
+		return d.err
+	}
+
+	return d.w.w.Flush()
+}
+
+func (d *Document) finishPage() {
+	if d.page == nil {
+		return
+	}
+	page := d.page
+	d.page = nil
+
+	content := d.reserve()
+	d.stream(content, "", page.content.Bytes())
+
+	ref := d.reserve()
+	d.pages = append(d.pages, ref)
+	d.object(ref, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+		d.pagesRef, number(page.width), number(page.height), d.resources, content,
+	))
+}
+
+// font возвращает шрифт документа, при первом использовании регистрирует его
+func (d *Document) font(font *Font) *documentFont {
+	for _, f := range d.fonts {
+		if f.font == font {
+			return f
+		}
+	}
+
+	f := &documentFont{
+		font: font,
+		name: "F" + strconv.Itoa(len(d.fonts)+1),
+		ref:  d.reserve(),
+		used: make(map[uint16]rune),
+	}
+	d.fonts = append(d.fonts, f)
+
+	return f
+}
+
+// writeFont пишет шрифт как составной (Type0) с кодировкой Identity-H: строка текста -
+// последовательность двухбайтовых номеров глифов
+func (d *Document) writeFont(f *documentFont) error {
+	data, err := f.font.subset(f.used)
+	if err != nil {
+		return err
+	}
+
+	// Имя подмножества шрифта начинается с шести заглавных букв и знака +
+	name := subsetTag(f.used) + "+" + f.font.name
+
+	file := d.reserve()
+	d.stream(file, fmt.Sprintf("/Length1 %d", len(data)), data)
+
+	descriptor := d.reserve()
+	font := f.font
+	d.object(descriptor, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle %s "+
+			"/Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+		number(font.italic), font.scale(font.ascent), font.scale(font.descent), font.scale(font.capHeight), file,
+	))
+
+	glyphs := make([]int, 0, len(f.used))
+	for glyph := range f.used {
+		glyphs = append(glyphs, int(glyph))
+	}
+	sort.Ints(glyphs)
+
+	widths := new(bytes.Buffer)
+	for _, glyph := range glyphs {
+		fmt.Fprintf(widths, "%d [%d] ", glyph, font.scale(font.advances[glyph]))
+	}
+
+	cid := d.reserve()
+	d.object(cid, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /DW %d /W [ %s] /CIDToGIDMap /Identity >>",
+		name, descriptor, font.scale(font.advances[0]), widths.String(),
+	))
+
+	toUnicode := d.reserve()
+	d.stream(toUnicode, "", toUnicodeCMap(glyphs, f.used))
+
+	d.object(f.ref, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+			"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cid, toUnicode,
+	))
+
+	return nil
+}
+
+// toUnicodeCMap сопоставляет глифам символы, чтобы текст документа можно было искать и копировать
+func toUnicodeCMap(glyphs []int, used map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// В одном блоке bfchar допускается не больше 100 записей
+	for start := 0; start < len(glyphs); start += 100 {
+		block := glyphs[start:min(start+100, len(glyphs))]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(block))
+		for _, glyph := range block {
+			fmt.Fprintf(&b, "<%04X> <%s>\n", glyph, utf16Hex(used[uint16(glyph)]))
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// subsetTag возвращает метку подмножества шрифта, зависящую от набора глифов
+func subsetTag(used map[uint16]rune) string {
+	var hash uint32 = 2166136261
+	for glyph := range used {
+		hash ^= uint32(glyph) * 16777619
+	}
+
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(hash%26)
+		hash /= 26
+	}
+
+	return string(tag)
+}
+
+// reserve выделяет номер объекта, который будет записан позже
+func (d *Document) reserve() int {
+	d.offsets = append(d.offsets, -1)
+	return len(d.offsets)
+}
+
+func (d *Document) object(ref int, body string) {
+	d.offsets[ref-1] = d.w.n
+	d.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", ref, body))
+}
+
+// stream пишет поток, сжатый Deflate. extra - дополнительные записи словаря потока
+func (d *Document) stream(ref int, extra string, data []byte) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+
+	d.offsets[ref-1] = d.w.n
+	d.write(fmt.Sprintf("%d 0 obj\n<< /Length %d /Filter /FlateDecode %s>>\nstream\n", ref, compressed.Len(), extra))
+	d.write(compressed.String())
+	d.write("\nendstream\nendobj\n")
+}
+
+func (d *Document) write(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.WriteString(d.w, s)
+}
+
+// Page - страница документа. Координаты - в пунктах от левого нижнего угла
+type Page struct {
+	doc     *Document
+	width   float64
+	height  float64
+	content bytes.Buffer
+}
+
+func (p *Page) Width() float64 {
+	return p.width
+}
+
+func (p *Page) Height() float64 {
+	return p.height
+}
+
+// Text выводит текст шрифтом font размера size. (x, y) - начало базовой линии
+func (p *Page) Text(font *Font, size float64, x, y float64, text string) {
+	if text == "" {
+		return
+	}
+
+	f := p.doc.font(font)
+
+	var glyphs strings.Builder
+	for _, r := range text {
+		glyph := font.glyph(r)
+		if _, ok := f.used[glyph]; !ok {
+			f.used[glyph] = r
+		}
+		fmt.Fprintf(&glyphs, "%04X", glyph)
+	}
+
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td <%s> Tj ET\n",
+		f.name, number(size), number(x), number(y), glyphs.String())
+}
+
+// TextRight выводит текст, выровненный по правому краю right
+func (p *Page) TextRight(font *Font, size float64, right, y float64, text string) {
+	p.Text(font, size, right-font.Width(text, size), y, text)
+}
+
+// Line проводит линию толщиной width оттенка серого gray (0 - чёрный, 1 - белый)
+func (p *Page) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&p.content, "%s G %s w %s %s m %s %s l S\n",
+		number(gray), number(width), number(x1), number(y1), number(x2), number(y2))
+}
+
+// Fill закрашивает прямоугольник оттенком серого gray. Цвет текста после заливки снова чёрный
+func (p *Page) Fill(x, y, width, height, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f 0 g\n",
+		number(gray), number(x), number(y), number(width), number(height))
+}
+
+// Fit обрезает текст до ширины width, заменяя конец многоточием
+func Fit(font *Font, size float64, width float64, text string) string {
+	if font.Width(text, size) <= width {
+		return text
+	}
+
+	const ellipsis = "…"
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimRight(string(runes), " ") + ellipsis
+		if font.Width(cut, size) <= width {
+			return cut
+		}
+	}
+
+	return ""
+}
+
+// number записывает число с точностью до сотых без лишних нулей
+func number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// textString записывает строку PDF в UTF-16BE с меткой порядка байтов
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, r := range s {
+		b.WriteString(utf16Hex(r))
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+func utf16Hex(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04X", r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+}
+
+// countingWriter считает записанные байты: по ним строится таблица ссылок на объекты
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,241 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	var out bytes.Buffer
+	doc := New(&out, "Выписка за март")
+
+	page := doc.NewPage(A4Width, A4Height)
+	page.Text(Bold, 16, 40, 800, "Выписка по счёту «Семья»")
+	page.Fill(40, 760, 515, 20, 0.9)
+	page.Line(40, 750, 555, 750, 0.5, 0)
+	page.TextRight(Regular, 10, 555, 740, "-1 500,50 ₽")
+
+	page = doc.NewPage(A4Width, A4Height)
+	page.Text(Regular, 10, 40, 800, "Йогурт, ёлка")
+
+	if err := doc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := out.Bytes()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("no PDF header or trailer:\n%s", truncate(data))
+	}
+
+	objects := checkXref(t, data)
+
+	// Каждый объект записан ровно один раз
+	headers := regexp.MustCompile(`(?m)^(\d+) 0 obj$`).FindAllSubmatch(data, -1)
+	if len(headers) != len(objects) {
+		t.Errorf("file has %d objects, xref lists %d", len(headers), len(objects))
+	}
+
+	streams := checkStreams(t, data)
+
+	if !bytes.Contains(data, []byte("/Type /Pages /Kids [ ")) || !bytes.Contains(data, []byte("/Count 2 >>")) {
+		t.Error("page tree does not list two pages")
+	}
+
+	var fontFiles, cmaps int
+	for _, stream := range streams {
+		switch {
+		case stream.length1 > 0:
+			fontFiles++
+			if len(stream.data) != stream.length1 {
+				t.Errorf("font file has %d bytes, /Length1 %d", len(stream.data), stream.length1)
+			}
+			checkFontFile(t, stream.data)
+
+		case bytes.Contains(stream.data, []byte("beginbfchar")):
+			cmaps++
+		}
+	}
+	if fontFiles != 2 {
+		t.Errorf("document has %d embedded fonts, want 2", fontFiles)
+	}
+	if cmaps != 2 {
+		t.Errorf("document has %d ToUnicode maps, want 2", cmaps)
+	}
+
+	// Текст второй страницы записан номерами глифов обычного шрифта, а ToUnicode возвращает символы
+	var glyphs strings.Builder
+	for _, r := range "Йогурт" {
+		fmt.Fprintf(&glyphs, "%04X", Regular.glyph(r))
+	}
+	if !streamsContain(streams, "<"+glyphs.String()) {
+		t.Errorf("no page content with glyphs %s", glyphs.String())
+	}
+	for _, r := range "Йё" {
+		mapping := fmt.Sprintf("<%04X> <%04X>", Regular.glyph(r), r)
+		if !streamsContain(streams, mapping) {
+			t.Errorf("no ToUnicode mapping %s for %q", mapping, r)
+		}
+	}
+}
+
+func TestDocumentWriteError(t *testing.T) {
+	errWrite := errors.New("disk full")
+
+	doc := New(failingWriter{err: errWrite}, "test")
+	for range 50 {
+		doc.NewPage(A4Width, A4Height).Text(Regular, 10, 40, 800, strings.Repeat("Текст ", 100))
+	}
+
+	if err := doc.Close(); !errors.Is(err, errWrite) {
+		t.Errorf("Close() error = %v, want %v", err, errWrite)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{in: 0, want: "0"},
+		{in: -0.001, want: "0"},
+		{in: 12, want: "12"},
+		{in: 12.5, want: "12.5"},
+		{in: 595.28, want: "595.28"},
+		{in: 0.126, want: "0.13"},
+		{in: -3.10, want: "-3.1"},
+	}
+
+	for _, tt := range tests {
+		if got := number(tt.in); got != tt.want {
+			t.Errorf("number(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextString(t *testing.T) {
+	if got, want := textString("Яa😀"), "<FEFF042F0061D83DDE00>"; got != want {
+		t.Errorf("textString() = %q, want %q", got, want)
+	}
+}
+
+// checkXref проверяет, что смещения таблицы ссылок указывают на начала объектов,
+// и возвращает их по номерам объектов
+func checkXref(t *testing.T, data []byte) map[int]int {
+	t.Helper()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if xref >= len(data) || !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the xref table", xref)
+	}
+
+	header := regexp.MustCompile(`^xref\n0 (\d+)\n`).FindSubmatch(data[xref:])
+	if header == nil {
+		t.Fatal("no xref subsection header")
+	}
+	size, _ := strconv.Atoi(string(header[1]))
+
+	entries := data[xref+len(header[0]):]
+	if len(entries) < 20*size {
+		t.Fatalf("xref table is shorter than %d entries", size)
+	}
+	if string(entries[:20]) != "0000000000 65535 f \n" {
+		t.Errorf("first xref entry = %q", entries[:20])
+	}
+
+	objects := make(map[int]int)
+	for ref := 1; ref < size; ref++ {
+		entry := string(entries[20*ref : 20*ref+20])
+		if !strings.HasSuffix(entry, " 00000 n \n") {
+			t.Fatalf("xref entry %d = %q", ref, entry)
+		}
+
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil || offset >= xref {
+			t.Fatalf("xref entry %d has invalid offset %q", ref, entry[:10])
+		}
+
+		if want := fmt.Sprintf("%d 0 obj\n", ref); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("object %d: offset %d points to %q", ref, offset, truncate(data[offset:]))
+		}
+		objects[ref] = offset
+	}
+
+	if !bytes.Contains(entries[20*size:], []byte(fmt.Sprintf("trailer\n<< /Size %d ", size))) {
+		t.Errorf("trailer /Size is not %d", size)
+	}
+
+	return objects
+}
+
+type pdfStream struct {
+	length1 int
+	data    []byte // распакованное содержимое
+}
+
+// checkStreams проверяет длины потоков и распаковывает их
+func checkStreams(t *testing.T, data []byte) []pdfStream {
+	t.Helper()
+
+	var streams []pdfStream
+	re := regexp.MustCompile(`(?m)^\d+ 0 obj\n<< /Length (\d+) /Filter /FlateDecode (?:/Length1 (\d+))?>>\nstream\n`)
+	for _, match := range re.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		start := match[1]
+		if start+length > len(data) || !bytes.HasPrefix(data[start+length:], []byte("\nendstream\nendobj\n")) {
+			t.Fatalf("stream at %d: /Length %d does not end at endstream", match[0], length)
+		}
+
+		zr, err := zlib.NewReader(bytes.NewReader(data[start : start+length]))
+		if err != nil {
+			t.Fatalf("stream at %d: %v", match[0], err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("stream at %d: %v", match[0], err)
+		}
+
+		stream := pdfStream{data: content}
+		if match[4] >= 0 {
+			stream.length1, _ = strconv.Atoi(string(data[match[4]:match[5]]))
+		}
+		streams = append(streams, stream)
+	}
+
+	if len(streams) == 0 {
+		t.Fatal("no streams")
+	}
+
+	return streams
+}
+
+func streamsContain(streams []pdfStream, s string) bool {
+	for _, stream := range streams {
+		if bytes.Contains(stream.data, []byte(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(data []byte) string {
+	return string(data[:min(len(data), 40)])
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
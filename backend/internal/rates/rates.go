@@ -0,0 +1,195 @@
+// Package rates разбирает файлы с курсами валют для импорта
+package rates
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var ErrEmptyFile = errors.New("file contains no rates")
+
+// dateLayouts - допустимые форматы дат в CSV
+var dateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+// ParseCSV разбирает CSV с заголовком date,base,quote,rate[,nominal] (порядок колонок любой).
+// Разделитель - запятая или точка с запятой; при точке с запятой курс может быть записан
+// с десятичной запятой. Nominal - за сколько единиц base указан курс, по умолчанию 1
+func ParseCSV(r io.Reader) ([]models.ExchangeRate, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimPrefix(string(content), "\uFEFF")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.TrimLeadingSpace = true
+	if header, _, _ := strings.Cut(text, "\n"); strings.Contains(header, ";") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyFile
+		}
+		return nil, fmt.Errorf("line 1: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("line 1: missing column %q", name)
+		}
+	}
+
+	var result []models.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		nominal := ""
+		if i, ok := columns["nominal"]; ok {
+			nominal = record[i]
+		}
+
+		rate, err := parseRate(
+			record[columns["date"]],
+			record[columns["base"]],
+			record[columns["quote"]],
+			record[columns["rate"]],
+			nominal,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		result = append(result, rate)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return result, nil
+}
+
+// cbrDaily - ежедневная выгрузка ЦБ РФ (XML_daily.asp): курсы валют к рублю на дату
+type cbrDaily struct {
+	Date   string `xml:"Date,attr"`
+	Valute []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+}
+
+// ParseCBR разбирает ежедневную XML-выгрузку ЦБ РФ. Валюты, которые не поддерживаются
+// (например, XDR), пропускаются
+func ParseCBR(r io.Reader) ([]models.ExchangeRate, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(label, "windows-1251") {
+			return charmap.Windows1251.NewDecoder().Reader(input), nil
+		}
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+
+	var daily cbrDaily
+	if err := decoder.Decode(&daily); err != nil {
+		return nil, fmt.Errorf("invalid CBR XML: %w", err)
+	}
+
+	var result []models.ExchangeRate
+	for _, v := range daily.Valute {
+		if _, err := money.ParseCurrency(v.CharCode); err != nil {
+			continue
+		}
+
+		rate, err := parseRate(daily.Date, v.CharCode, string(money.CurrencyRUB), v.Value, v.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.CharCode, err)
+		}
+
+		result = append(result, rate)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return result, nil
+}
+
+func parseRate(date, base, quote, value, nominal string) (models.ExchangeRate, error) {
+	day, err := parseDate(date)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	baseCurrency, err := money.ParseCurrency(base)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	quoteCurrency, err := money.ParseCurrency(quote)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	if baseCurrency == quoteCurrency {
+		return models.ExchangeRate{}, errors.New("base and quote currencies must differ")
+	}
+
+	rate, err := money.ParseRate(value)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	if nominal = strings.TrimSpace(nominal); nominal != "" {
+		units, err := strconv.ParseInt(nominal, 10, 64)
+		if err != nil || units <= 0 {
+			return models.ExchangeRate{}, errors.New("nominal must be a positive integer")
+		}
+		rate = rate.PerUnits(units)
+	}
+
+	return models.ExchangeRate{
+		Base:  baseCurrency,
+		Quote: quoteCurrency,
+		Date:  day,
+		Rate:  rate,
+	}, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if day, err := time.Parse(layout, value); err == nil {
+			return day, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or DD.MM.YYYY", value)
+}
@@ -52,6 +52,20 @@ func (r *AccountMemberRepository) GetMemberRole(
 	return role, nil
 }
 
+// GetMember возвращает роль участника счёта и, для роли custom, ID пользовательской роли.
+// Возвращает sql.ErrNoRows, если пользователь не участник счёта
+func (r *AccountMemberRepository) GetMember(ctx context.Context, accountID int, userID int) (*query.GetAccountMemberRow, error) {
+	member, err := r.queries.GetAccountMember(ctx, query.GetAccountMemberParams{
+		AccountID: int32(accountID),
+		UserID:    int32(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
 func (r *AccountMemberRepository) ListMembers(ctx context.Context, accountID int) ([]query.ListAccountMembersRow, error) {
 	return r.queries.ListAccountMembers(ctx, int32(accountID))
 }
@@ -70,6 +84,15 @@ func (r *AccountMemberRepository) UpdateMemberRole(
 	})
 }
 
+// SetCustomRole назначает участнику пользовательскую роль счёта roleID
+func (r *AccountMemberRepository) SetCustomRole(ctx context.Context, accountID int, userID int, roleID int) error {
+	return r.queries.SetAccountMemberCustomRole(ctx, query.SetAccountMemberCustomRoleParams{
+		RoleID:    sql.NullInt32{Int32: int32(roleID), Valid: true},
+		AccountID: int32(accountID),
+		UserID:    int32(userID),
+	})
+}
+
 func (r *AccountMemberRepository) IsMember(ctx context.Context, accountID int, userID int) error {
 	_, err := r.queries.GetAccountMemberRole(ctx, query.GetAccountMemberRoleParams{
 		AccountID: int32(accountID),
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+// AccountRoleRepository хранит пользовательские роли счетов и их права
+type AccountRoleRepository struct {
+	queries *query.Queries
+}
+
+func newAccountRoleRepository(db query.DBTX) *AccountRoleRepository {
+	return &AccountRoleRepository{queries: query.New(db)}
+}
+
+func (r *AccountRoleRepository) Create(ctx context.Context, accountID int, name string) (int, error) {
+	result, err := r.queries.CreateAccountRole(ctx, query.CreateAccountRoleParams{
+		AccountID: int32(accountID),
+		Name:      name,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Get возвращает роль счёта. Возвращает sql.ErrNoRows, если в этом счёте такой роли нет
+func (r *AccountRoleRepository) Get(ctx context.Context, accountID int, id int) (*query.AccountRole, error) {
+	role, err := r.queries.GetAccountRole(ctx, query.GetAccountRoleParams{
+		ID:        int32(id),
+		AccountID: int32(accountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// List возвращает роли счёта по алфавиту
+func (r *AccountRoleRepository) List(ctx context.Context, accountID int) ([]query.AccountRole, error) {
+	return r.queries.ListAccountRoles(ctx, int32(accountID))
+}
+
+func (r *AccountRoleRepository) Rename(ctx context.Context, id int, name string) error {
+	return r.queries.RenameAccountRole(ctx, query.RenameAccountRoleParams{
+		Name: name,
+		ID:   int32(id),
+	})
+}
+
+func (r *AccountRoleRepository) Delete(ctx context.Context, id int) error {
+	return r.queries.DeleteAccountRole(ctx, int32(id))
+}
+
+// CountMembers возвращает число участников счёта с этой ролью
+func (r *AccountRoleRepository) CountMembers(ctx context.Context, id int) (int, error) {
+	count, err := r.queries.CountAccountRoleMembers(ctx, sql.NullInt32{Int32: int32(id), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// Permissions возвращает права роли по алфавиту
+func (r *AccountRoleRepository) Permissions(ctx context.Context, id int) ([]string, error) {
+	return r.queries.ListAccountRolePermissions(ctx, int32(id))
+}
+
+// PermissionsForAccount возвращает права всех ролей счёта по ID роли
+func (r *AccountRoleRepository) PermissionsForAccount(ctx context.Context, accountID int) (map[int32][]string, error) {
+	rows, err := r.queries.ListAccountRolesPermissions(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[int32][]string)
+	for _, row := range rows {
+		permissions[row.RoleID] = append(permissions[row.RoleID], row.Permission)
+	}
+
+	return permissions, nil
+}
+
+// SetPermissions заменяет права роли. Вызывается внутри транзакции БД
+func (r *AccountRoleRepository) SetPermissions(ctx context.Context, id int, permissions []string) error {
+	if err := r.queries.DeleteAccountRolePermissions(ctx, int32(id)); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		err := r.queries.AddAccountRolePermission(ctx, query.AddAccountRolePermissionParams{
+			RoleID:     int32(id),
+			Permission: permission,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
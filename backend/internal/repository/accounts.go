@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository/query"
 )
 
@@ -18,23 +21,19 @@ func newAccountRepository(db query.DBTX) *AccountRepository {
 	}
 }
 
-func (r *AccountRepository) CreateAccount(
-	ctx context.Context,
-	ownerID int,
-	name string,
-	description *string,
-) (int, error) {
-
+func (r *AccountRepository) CreateAccount(ctx context.Context, p *models.CreateAccountParams) (int, error) {
 	desc := sql.NullString{}
-	if description != nil {
-		desc.String = *description
+	if p.Description != nil {
+		desc.String = *p.Description
 		desc.Valid = true
 	}
 
 	result, err := r.queries.CreateAccount(ctx, query.CreateAccountParams{
-		Name:        name,
-		Description: desc,
-		OwnerID:     int32(ownerID),
+		Name:           p.Name,
+		Description:    desc,
+		OwnerID:        int32(p.OwnerID),
+		Currency:       p.Currency,
+		OpeningBalance: p.OpeningBalance,
 	})
 	if err != nil {
 		return 0, err
@@ -65,6 +64,51 @@ func (r *AccountRepository) ListUserAccounts(ctx context.Context, userID int) ([
 	return r.queries.ListUserAccounts(ctx, int32(userID))
 }
 
+// GetAccountForUpdate возвращает счёт и блокирует его до конца транзакции. Возвращает sql.ErrNoRows, если счёта нет
+func (r *AccountRepository) GetAccountForUpdate(ctx context.Context, accountID int) (*query.Account, error) {
+	account, err := r.queries.GetAccountForUpdate(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// ListOwned возвращает счета, владельцем которых является пользователь
+func (r *AccountRepository) ListOwned(ctx context.Context, userID int) ([]query.Account, error) {
+	return r.queries.ListOwnedAccounts(ctx, int32(userID))
+}
+
 func (r *AccountRepository) DeleteAccountByID(ctx context.Context, accountID int) error {
 	return r.queries.DeleteAccountByID(ctx, int32(accountID))
 }
+
+// SetOwner меняет владельца счёта. Название меняется вместе с ним: у владельца названия счетов уникальны
+func (r *AccountRepository) SetOwner(ctx context.Context, accountID int, ownerID int, name string) error {
+	return r.queries.SetAccountOwner(ctx, query.SetAccountOwnerParams{
+		OwnerID: int32(ownerID),
+		Name:    name,
+		ID:      int32(accountID),
+	})
+}
+
+// SetOpeningBalance меняет начальный остаток счёта
+func (r *AccountRepository) SetOpeningBalance(ctx context.Context, accountID int, balance money.Amount) error {
+	return r.queries.SetAccountOpeningBalance(ctx, query.SetAccountOpeningBalanceParams{
+		OpeningBalance: balance,
+		ID:             int32(accountID),
+	})
+}
+
+// Balance возвращает остаток счёта на момент at: начальный остаток плюс сумма транзакций не позже at
+func (r *AccountRepository) Balance(ctx context.Context, accountID int, at time.Time) (*query.GetAccountBalanceRow, error) {
+	balance, err := r.queries.GetAccountBalance(ctx, query.GetAccountBalanceParams{
+		At:        at,
+		AccountID: int32(accountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository/query"
+)
+
+// ArchiveRepository читает и записывает данные счетов целиком для переносимого архива пользователя
+type ArchiveRepository struct {
+	queries *query.Queries
+}
+
+func newArchiveRepository(db query.DBTX) *ArchiveRepository {
+	return &ArchiveRepository{queries: query.New(db)}
+}
+
+// EmailsByIDs возвращает email пользователей по их ID. Удалённых пользователей в ответе нет
+func (r *ArchiveRepository) EmailsByIDs(ctx context.Context, ids []int32) (map[int32]string, error) {
+	emails := make(map[int32]string)
+	if len(ids) == 0 {
+		return emails, nil
+	}
+
+	rows, err := r.queries.ListUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		emails[row.ID] = row.Email
+	}
+
+	return emails, nil
+}
+
+// IDsByEmails возвращает ID пользователей по email. Незарегистрированных email в ответе нет
+func (r *ArchiveRepository) IDsByEmails(ctx context.Context, emails []string) (map[string]int, error) {
+	ids := make(map[string]int)
+	if len(emails) == 0 {
+		return ids, nil
+	}
+
+	rows, err := r.queries.ListUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		ids[row.Email] = int(row.ID)
+	}
+
+	return ids, nil
+}
+
+// ListTransactions возвращает все транзакции счёта в порядке создания
+func (r *ArchiveRepository) ListTransactions(ctx context.Context, accountID int) ([]query.Transaction, error) {
+	return r.queries.ListAccountTransactions(ctx, int32(accountID))
+}
+
+// TransactionTags возвращает метки всех транзакций счёта, метки отсортированы по названию
+func (r *ArchiveRepository) TransactionTags(ctx context.Context, accountID int) (map[int32][]string, error) {
+	rows, err := r.queries.ListAccountTransactionTags(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[int32][]string)
+	for _, row := range rows {
+		tags[row.TransactionID] = append(tags[row.TransactionID], row.Name)
+	}
+
+	return tags, nil
+}
+
+// ListDuplicateDismissals возвращает пары транзакций счёта, отмеченные как разные операции
+func (r *ArchiveRepository) ListDuplicateDismissals(
+	ctx context.Context,
+	accountID int,
+) ([]query.ListAccountDuplicateDismissalsRow, error) {
+	return r.queries.ListAccountDuplicateDismissals(ctx, int32(accountID))
+}
+
+// CreateRule создаёт правило повторения вместе с его состоянием: следующим вхождением,
+// числом созданных вхождений и паузой
+func (r *ArchiveRepository) CreateRule(ctx context.Context, p *models.ArchiveRecurringRuleParams) (int, error) {
+	result, err := r.queries.ImportRecurringRule(ctx, query.ImportRecurringRuleParams{
+		AccountID:        int32(p.AccountID),
+		UserID:           sql.NullInt32{Int32: int32(p.UserID), Valid: true},
+		Title:            p.Title,
+		Amount:           p.Amount,
+		Currency:         p.Currency,
+		Period:           p.Period,
+		IntervalCount:    int32(p.Interval),
+		StartsAt:         p.StartsAt,
+		EndsAt:           toNullTime(p.EndsAt),
+		MaxOccurrences:   toNullInt32(p.MaxOccurrences),
+		Paused:           p.Paused,
+		NextIndex:        int32(p.NextIndex),
+		NextOccurrenceAt: p.NextOccurrenceAt,
+		OccurrencesCount: int32(p.OccurrencesCount),
+		CreatedAt:        p.CreatedAt,
+		CategoryID:       toNullInt32(p.CategoryID),
+		Notes:            toNullString(p.Notes),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// CreateTransaction создаёт транзакцию со ссылкой на правило и идентификатором выписки
+func (r *ArchiveRepository) CreateTransaction(ctx context.Context, p *models.CreateTransactionParams) (int, error) {
+	result, err := r.queries.ImportTransaction(ctx, query.ImportTransactionParams{
+		AccountID:   int32(p.AccountID),
+		UserID:      sql.NullInt32{Int32: int32(p.UserID), Valid: true},
+		Title:       p.Title,
+		Amount:      p.Amount,
+		Currency:    p.Currency,
+		OccurredAt:  p.OccurredAt,
+		Period:      p.Period,
+		RuleID:      toNullInt32(p.RuleID),
+		CategoryID:  toNullInt32(p.CategoryID),
+		Notes:       toNullString(p.Notes),
+		ExternalRef: toNullString(p.ExternalRef),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetExchangeRate возвращает сохранённый курс пользователя на дату. ok == false, если курса нет
+func (r *ArchiveRepository) GetExchangeRate(
+	ctx context.Context,
+	userID int,
+	base money.Currency,
+	quote money.Currency,
+	date time.Time,
+) (rate string, ok bool, err error) {
+	row, err := r.queries.GetExchangeRate(ctx, query.GetExchangeRateParams{
+		UserID:        int32(userID),
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		RateDate:      date,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return row.Rate, true, nil
+}
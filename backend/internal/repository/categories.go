@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+type CategoryRepository struct {
+	queries *query.Queries
+}
+
+func newCategoryRepository(db query.DBTX) *CategoryRepository {
+	return &CategoryRepository{queries: query.New(db)}
+}
+
+// Create создаёт категорию счёта. parentID == nil - категория верхнего уровня
+func (r *CategoryRepository) Create(ctx context.Context, accountID int, parentID *int, name string) (int, error) {
+	result, err := r.queries.CreateCategory(ctx, query.CreateCategoryParams{
+		AccountID: int32(accountID),
+		ParentID:  toNullInt32(parentID),
+		Name:      name,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id int) (*query.Category, error) {
+	category, err := r.queries.GetCategoryByID(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// ListForAccount возвращает все категории счёта, отсортированные по названию
+func (r *CategoryRepository) ListForAccount(ctx context.Context, accountID int) ([]query.Category, error) {
+	return r.queries.ListAccountCategories(ctx, int32(accountID))
+}
+
+// Update меняет название и родителя категории
+func (r *CategoryRepository) Update(ctx context.Context, id int, parentID *int, name string) error {
+	return r.queries.UpdateCategory(ctx, query.UpdateCategoryParams{
+		ParentID: toNullInt32(parentID),
+		Name:     name,
+		ID:       int32(id),
+	})
+}
+
+// DeleteByID удаляет категорию вместе с подкатегориями. Транзакции остаются без категории
+func (r *CategoryRepository) DeleteByID(ctx context.Context, id int) error {
+	return r.queries.DeleteCategoryByID(ctx, int32(id))
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+type DuplicateRepository struct {
+	queries *query.Queries
+	db      query.DBTX
+}
+
+func newDuplicateRepository(db query.DBTX) *DuplicateRepository {
+	return &DuplicateRepository{
+		queries: query.New(db),
+		db:      db,
+	}
+}
+
+// ListCandidates возвращает транзакции счёта с датой от from до to включительно,
+// с которыми сравниваются новые транзакции. Вхождения серий не учитываются:
+// они повторяются по замыслу
+func (r *DuplicateRepository) ListCandidates(
+	ctx context.Context,
+	accountID int,
+	from time.Time,
+	to time.Time,
+) ([]query.ListDedupeCandidatesRow, error) {
+	return r.queries.ListDedupeCandidates(ctx, query.ListDedupeCandidatesParams{
+		AccountID: int32(accountID),
+		DateFrom:  from,
+		DateTo:    to,
+	})
+}
+
+// ListPairs возвращает пары транзакций счёта с одинаковой суммой и валютой, даты которых
+// отличаются не больше чем на window, новые первыми. Пары с разными идентификаторами выписки,
+// вхождения серий и пары, отмеченные как разные операции, пропускаются. В результат попадают
+// только пары, для которых match вернул true; после limit таких пар остальные строки не читаются
+func (r *DuplicateRepository) ListPairs(
+	ctx context.Context,
+	accountID int,
+	window time.Duration,
+	match func(p *models.DuplicateCandidatePair) bool,
+	limit int,
+) ([]models.DuplicateCandidatePair, error) {
+	sql := `
+		SELECT
+			a.id, a.title, a.amount, a.currency, a.occurred_at, a.external_ref,
+			b.id, b.title, b.amount, b.currency, b.occurred_at, b.external_ref
+		FROM transactions a
+		JOIN transactions b
+			ON b.account_id = a.account_id
+			AND b.id > a.id
+			AND b.amount = a.amount
+			AND b.currency = a.currency
+			AND b.occurred_at BETWEEN a.occurred_at - INTERVAL ? SECOND AND a.occurred_at + INTERVAL ? SECOND
+			AND b.rule_id IS NULL
+		LEFT JOIN duplicate_dismissals d
+			ON d.transaction_id = a.id AND d.duplicate_id = b.id
+		WHERE a.account_id = ?
+			AND a.rule_id IS NULL
+			AND (a.external_ref IS NULL OR b.external_ref IS NULL)
+			AND d.transaction_id IS NULL
+		ORDER BY b.occurred_at DESC, b.id DESC
+	`
+
+	seconds := int64(window / time.Second)
+
+	rows, err := r.db.QueryContext(ctx, sql, seconds, seconds, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []models.DuplicateCandidatePair
+	for len(pairs) < limit && rows.Next() {
+		var p models.DuplicateCandidatePair
+		if err := rows.Scan(
+			&p.Transaction.ID,
+			&p.Transaction.Title,
+			&p.Transaction.Amount,
+			&p.Transaction.Currency,
+			&p.Transaction.OccurredAt,
+			&p.Transaction.ExternalRef,
+			&p.Duplicate.ID,
+			&p.Duplicate.Title,
+			&p.Duplicate.Amount,
+			&p.Duplicate.Currency,
+			&p.Duplicate.OccurredAt,
+			&p.Duplicate.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+
+		if match(&p) {
+			pairs = append(pairs, p)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// Dismiss отмечает две транзакции как разные операции
+func (r *DuplicateRepository) Dismiss(ctx context.Context, transactionID int, duplicateID int) error {
+	if transactionID > duplicateID {
+		transactionID, duplicateID = duplicateID, transactionID
+	}
+
+	return r.queries.DismissDuplicate(ctx, query.DismissDuplicateParams{
+		TransactionID: int32(transactionID),
+		DuplicateID:   int32(duplicateID),
+	})
+}
+
+// Merge удаляет дубликат remove, перенося его метки в keep, а категорию, примечание
+// и идентификатор выписки - если у keep их нет
+func (r *DuplicateRepository) Merge(ctx context.Context, keep *query.Transaction, remove *query.Transaction) error {
+	err := r.queries.CopyTransactionTags(ctx, query.CopyTransactionTagsParams{
+		ToTransactionID:   keep.ID,
+		FromTransactionID: remove.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Идентификатор выписки уникален в счёте, поэтому дубликат удаляется до его переноса
+	if err := r.queries.DeleteTransactionByID(ctx, remove.ID); err != nil {
+		return err
+	}
+
+	return r.queries.FillTransactionFromDuplicate(ctx, query.FillTransactionFromDuplicateParams{
+		CategoryID:  remove.CategoryID,
+		Notes:       remove.Notes,
+		ExternalRef: remove.ExternalRef,
+		ID:          keep.ID,
+	})
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+type ExchangeRateRepository struct {
+	queries *query.Queries
+}
+
+func newExchangeRateRepository(db query.DBTX) *ExchangeRateRepository {
+	return &ExchangeRateRepository{queries: query.New(db)}
+}
+
+// Upsert сохраняет курс пользователя на дату, заменяя уже сохранённый курс той же пары на ту же дату
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, userID int, rate *models.ExchangeRate) error {
+	return r.queries.UpsertExchangeRate(ctx, query.UpsertExchangeRateParams{
+		UserID:        int32(userID),
+		BaseCurrency:  rate.Base,
+		QuoteCurrency: rate.Quote,
+		RateDate:      rate.Date,
+		Rate:          rate.Rate.String(),
+	})
+}
+
+// List возвращает курсы пользователя с фильтрацией, новые первыми
+func (r *ExchangeRateRepository) List(ctx context.Context, f *models.ListExchangeRatesFilter) ([]query.ExchangeRate, error) {
+	return r.queries.ListUserExchangeRates(ctx, query.ListUserExchangeRatesParams{
+		UserID:        int32(f.UserID),
+		BaseCurrency:  f.Base,
+		QuoteCurrency: f.Quote,
+		DateFrom:      toNullTime(f.DateFrom),
+		DateTo:        toNullTime(f.DateTo),
+	})
+}
+
+// ListUntil возвращает все курсы пользователя на даты не позже until в порядке возрастания даты
+func (r *ExchangeRateRepository) ListUntil(ctx context.Context, userID int, until time.Time) ([]query.ExchangeRate, error) {
+	return r.queries.ListUserExchangeRatesUntil(ctx, query.ListUserExchangeRatesUntilParams{
+		UserID:   int32(userID),
+		RateDate: until,
+	})
+}
+
+// Delete удаляет курс пользователя. Возвращает false, если курс не найден
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id int, userID int) (bool, error) {
+	result, err := r.queries.DeleteExchangeRate(ctx, query.DeleteExchangeRateParams{
+		ID:     int32(id),
+		UserID: int32(userID),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
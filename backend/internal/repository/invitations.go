@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+// InvitationRepository хранит приглашения в счета
+type InvitationRepository struct {
+	queries *query.Queries
+}
+
+func newInvitationRepository(db query.DBTX) *InvitationRepository {
+	return &InvitationRepository{queries: query.New(db)}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, p *models.CreateInvitationParams) (int, error) {
+	result, err := r.queries.CreateInvitation(ctx, query.CreateInvitationParams{
+		Token:     p.Token,
+		AccountID: int32(p.AccountID),
+		InviterID: sql.NullInt32{Int32: int32(p.InviterID), Valid: true},
+		Email:     p.Email,
+		InviteeID: toNullInt32(p.InviteeID),
+		Role:      p.Role,
+		ExpiresAt: p.ExpiresAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetByID возвращает приглашение в счёт. Возвращает sql.ErrNoRows, если в этот счёт такого приглашения нет
+func (r *InvitationRepository) GetByID(ctx context.Context, accountID int, id int) (*query.Invitation, error) {
+	invitation, err := r.queries.GetAccountInvitation(ctx, query.GetAccountInvitationParams{
+		ID:        int32(id),
+		AccountID: int32(accountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// GetByTokenForUpdate возвращает приглашение по токену и блокирует его до конца транзакции.
+// Возвращает sql.ErrNoRows, если приглашения нет
+func (r *InvitationRepository) GetByTokenForUpdate(ctx context.Context, token string) (*query.Invitation, error) {
+	invitation, err := r.queries.GetInvitationByTokenForUpdate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// ListForAccount возвращает все приглашения в счёт, новые первыми
+func (r *InvitationRepository) ListForAccount(ctx context.Context, accountID int) ([]query.Invitation, error) {
+	return r.queries.ListAccountInvitations(ctx, int32(accountID))
+}
+
+// ListPendingForUser возвращает приглашения пользователя, ожидающие ответа и не истёкшие к моменту now
+func (r *InvitationRepository) ListPendingForUser(
+	ctx context.Context,
+	userID int,
+	now time.Time,
+) ([]query.ListUserInvitationsRow, error) {
+	return r.queries.ListUserInvitations(ctx, query.ListUserInvitationsParams{
+		InviteeID: sql.NullInt32{Int32: int32(userID), Valid: true},
+		ExpiresAt: now,
+	})
+}
+
+// HasPending сообщает, есть ли приглашение email в счёт, ожидающее ответа и не истёкшее к моменту now
+func (r *InvitationRepository) HasPending(ctx context.Context, accountID int, email string, now time.Time) (bool, error) {
+	return r.queries.HasPendingInvitation(ctx, query.HasPendingInvitationParams{
+		AccountID: int32(accountID),
+		Email:     email,
+		ExpiresAt: now,
+	})
+}
+
+// SetStatus записывает ответ на приглашение или его отзыв. Возвращает false,
+// если приглашение уже не ожидает ответа
+func (r *InvitationRepository) SetStatus(
+	ctx context.Context,
+	id int,
+	status query.InvitationsStatus,
+	at time.Time,
+) (bool, error) {
+	result, err := r.queries.SetInvitationStatus(ctx, query.SetInvitationStatusParams{
+		Status:      status,
+		RespondedAt: sql.NullTime{Time: at, Valid: true},
+		ID:          int32(id),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// LinkUser связывает приглашения на email с зарегистрировавшимся пользователем
+func (r *InvitationRepository) LinkUser(ctx context.Context, email string, userID int) error {
+	return r.queries.LinkInvitationsToUser(ctx, query.LinkInvitationsToUserParams{
+		InviteeID: sql.NullInt32{Int32: int32(userID), Valid: true},
+		Email:     email,
+	})
+}
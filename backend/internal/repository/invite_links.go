@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+// InviteLinkRepository хранит ссылки-приглашения в счета
+type InviteLinkRepository struct {
+	queries *query.Queries
+}
+
+func newInviteLinkRepository(db query.DBTX) *InviteLinkRepository {
+	return &InviteLinkRepository{queries: query.New(db)}
+}
+
+func (r *InviteLinkRepository) Create(ctx context.Context, p *models.CreateInviteLinkParams) (int, error) {
+	result, err := r.queries.CreateInviteLink(ctx, query.CreateInviteLinkParams{
+		Code:      p.Code,
+		AccountID: int32(p.AccountID),
+		CreatedBy: sql.NullInt32{Int32: int32(p.CreatedBy), Valid: true},
+		Role:      p.Role,
+		MaxUses:   toNullInt32(p.MaxUses),
+		ExpiresAt: p.ExpiresAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetByID возвращает ссылку-приглашение в счёт. Возвращает sql.ErrNoRows, если в этом счёте такой ссылки нет
+func (r *InviteLinkRepository) GetByID(ctx context.Context, accountID int, id int) (*query.InviteLink, error) {
+	link, err := r.queries.GetAccountInviteLink(ctx, query.GetAccountInviteLinkParams{
+		ID:        int32(id),
+		AccountID: int32(accountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// GetByCodeForUpdate возвращает ссылку по коду и блокирует её до конца транзакции.
+// Возвращает sql.ErrNoRows, если ссылки нет
+func (r *InviteLinkRepository) GetByCodeForUpdate(ctx context.Context, code string) (*query.InviteLink, error) {
+	link, err := r.queries.GetInviteLinkByCodeForUpdate(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ListForAccount возвращает все ссылки-приглашения в счёт, новые первыми
+func (r *InviteLinkRepository) ListForAccount(ctx context.Context, accountID int) ([]query.InviteLink, error) {
+	return r.queries.ListAccountInviteLinks(ctx, int32(accountID))
+}
+
+// IncrementUses учитывает присоединение к счёту по ссылке
+func (r *InviteLinkRepository) IncrementUses(ctx context.Context, id int) error {
+	return r.queries.IncrementInviteLinkUses(ctx, int32(id))
+}
+
+// Revoke отзывает ссылку. Уже отозванная ссылка не меняется
+func (r *InviteLinkRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	return r.queries.RevokeInviteLink(ctx, query.RevokeInviteLinkParams{
+		RevokedAt: sql.NullTime{Time: at, Valid: true},
+		ID:        int32(id),
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+// OwnershipTransferRepository хранит передачи счетов, ожидающие подтверждения нового владельца
+type OwnershipTransferRepository struct {
+	queries *query.Queries
+}
+
+func newOwnershipTransferRepository(db query.DBTX) *OwnershipTransferRepository {
+	return &OwnershipTransferRepository{queries: query.New(db)}
+}
+
+// Create сохраняет передачу счёта участнику toUserID. Прежняя неподтверждённая передача счёта заменяется
+func (r *OwnershipTransferRepository) Create(
+	ctx context.Context,
+	accountID int,
+	fromUserID int,
+	toUserID int,
+	expiresAt time.Time,
+) error {
+	return r.queries.CreateOwnershipTransfer(ctx, query.CreateOwnershipTransferParams{
+		AccountID:  int32(accountID),
+		FromUserID: int32(fromUserID),
+		ToUserID:   int32(toUserID),
+		ExpiresAt:  expiresAt,
+	})
+}
+
+// Get возвращает передачу счёта. Возвращает sql.ErrNoRows, если передачи нет
+func (r *OwnershipTransferRepository) Get(ctx context.Context, accountID int) (*query.OwnershipTransfer, error) {
+	transfer, err := r.queries.GetOwnershipTransfer(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// GetForUpdate возвращает передачу счёта и блокирует её до конца транзакции.
+// Возвращает sql.ErrNoRows, если передачи нет
+func (r *OwnershipTransferRepository) GetForUpdate(ctx context.Context, accountID int) (*query.OwnershipTransfer, error) {
+	transfer, err := r.queries.GetOwnershipTransferForUpdate(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (r *OwnershipTransferRepository) Delete(ctx context.Context, accountID int) error {
+	return r.queries.DeleteOwnershipTransfer(ctx, int32(accountID))
+}
@@ -9,6 +9,8 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"time"
+
+	"microservices/accounter/internal/money"
 )
 
 type AccountMembersRole string
@@ -18,6 +20,7 @@ const (
 	AccountMembersRoleEditor AccountMembersRole = "editor"
 	AccountMembersRoleAdmin  AccountMembersRole = "admin"
 	AccountMembersRoleOwner  AccountMembersRole = "owner"
+	AccountMembersRoleCustom AccountMembersRole = "custom"
 )
 
 func (e *AccountMembersRole) Scan(src interface{}) error {
@@ -55,6 +58,180 @@ func (ns NullAccountMembersRole) Value() (driver.Value, error) {
 	return string(ns.AccountMembersRole), nil
 }
 
+type InvitationsRole string
+
+const (
+	InvitationsRoleViewer InvitationsRole = "viewer"
+	InvitationsRoleEditor InvitationsRole = "editor"
+	InvitationsRoleAdmin  InvitationsRole = "admin"
+)
+
+func (e *InvitationsRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InvitationsRole(s)
+	case string:
+		*e = InvitationsRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InvitationsRole: %T", src)
+	}
+	return nil
+}
+
+type NullInvitationsRole struct {
+	InvitationsRole InvitationsRole
+	Valid           bool // Valid is true if InvitationsRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInvitationsRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.InvitationsRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InvitationsRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInvitationsRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InvitationsRole), nil
+}
+
+type InvitationsStatus string
+
+const (
+	InvitationsStatusPending  InvitationsStatus = "pending"
+	InvitationsStatusAccepted InvitationsStatus = "accepted"
+	InvitationsStatusDeclined InvitationsStatus = "declined"
+	InvitationsStatusRevoked  InvitationsStatus = "revoked"
+)
+
+func (e *InvitationsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InvitationsStatus(s)
+	case string:
+		*e = InvitationsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InvitationsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullInvitationsStatus struct {
+	InvitationsStatus InvitationsStatus
+	Valid             bool // Valid is true if InvitationsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInvitationsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.InvitationsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InvitationsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInvitationsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InvitationsStatus), nil
+}
+
+type InviteLinksRole string
+
+const (
+	InviteLinksRoleViewer InviteLinksRole = "viewer"
+	InviteLinksRoleEditor InviteLinksRole = "editor"
+	InviteLinksRoleAdmin  InviteLinksRole = "admin"
+)
+
+func (e *InviteLinksRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InviteLinksRole(s)
+	case string:
+		*e = InviteLinksRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InviteLinksRole: %T", src)
+	}
+	return nil
+}
+
+type NullInviteLinksRole struct {
+	InviteLinksRole InviteLinksRole
+	Valid           bool // Valid is true if InviteLinksRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInviteLinksRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.InviteLinksRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InviteLinksRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInviteLinksRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InviteLinksRole), nil
+}
+
+type RecurringRulesPeriod string
+
+const (
+	RecurringRulesPeriodDay   RecurringRulesPeriod = "day"
+	RecurringRulesPeriodWeek  RecurringRulesPeriod = "week"
+	RecurringRulesPeriodMonth RecurringRulesPeriod = "month"
+	RecurringRulesPeriodYear  RecurringRulesPeriod = "year"
+)
+
+func (e *RecurringRulesPeriod) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RecurringRulesPeriod(s)
+	case string:
+		*e = RecurringRulesPeriod(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RecurringRulesPeriod: %T", src)
+	}
+	return nil
+}
+
+type NullRecurringRulesPeriod struct {
+	RecurringRulesPeriod RecurringRulesPeriod
+	Valid                bool // Valid is true if RecurringRulesPeriod is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRecurringRulesPeriod) Scan(value interface{}) error {
+	if value == nil {
+		ns.RecurringRulesPeriod, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RecurringRulesPeriod.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRecurringRulesPeriod) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RecurringRulesPeriod), nil
+}
+
 type TransactionsPeriod string
 
 const (
@@ -100,26 +277,168 @@ func (ns NullTransactionsPeriod) Value() (driver.Value, error) {
 }
 
 type Account struct {
-	ID          int32
-	Name        string
-	Description sql.NullString
-	OwnerID     int32
+	ID             int32
+	Name           string
+	Description    sql.NullString
+	OwnerID        int32
+	Currency       money.Currency
+	OpeningBalance money.Amount
 }
 
 type AccountMember struct {
 	AccountID int32
 	UserID    int32
 	Role      AccountMembersRole
+	RoleID    sql.NullInt32
 }
 
-type Transaction struct {
-	ID         int32
+type AccountRole struct {
+	ID        int32
+	AccountID int32
+	Name      string
+	CreatedAt time.Time
+}
+
+type AccountRolePermission struct {
+	RoleID     int32
+	Permission string
+}
+
+type Category struct {
+	ID        int32
+	AccountID int32
+	ParentID  sql.NullInt32
+	Name      string
+	CreatedAt time.Time
+}
+
+type DuplicateDismissal struct {
+	TransactionID int32
+	DuplicateID   int32
+	CreatedAt     time.Time
+}
+
+type ExchangeRate struct {
+	ID            int32
+	UserID        int32
+	BaseCurrency  money.Currency
+	QuoteCurrency money.Currency
+	RateDate      time.Time
+	Rate          string
+	CreatedAt     time.Time
+}
+
+type Invitation struct {
+	ID          int32
+	Token       string
+	AccountID   int32
+	InviterID   sql.NullInt32
+	Email       string
+	InviteeID   sql.NullInt32
+	Role        InvitationsRole
+	Status      InvitationsStatus
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	RespondedAt sql.NullTime
+}
+
+type InviteLink struct {
+	ID        int32
+	Code      string
+	AccountID int32
+	CreatedBy sql.NullInt32
+	Role      InviteLinksRole
+	MaxUses   sql.NullInt32
+	Uses      int32
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type LegacyRecurringRule struct {
+	RuleID             int32
+	FirstTransactionID int32
+}
+
+type OwnershipTransfer struct {
 	AccountID  int32
+	FromUserID int32
+	ToUserID   int32
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+type RecurringRule struct {
+	ID               int32
+	AccountID        int32
+	Title            string
+	Amount           money.Amount
+	Period           RecurringRulesPeriod
+	IntervalCount    int32
+	StartsAt         time.Time
+	EndsAt           sql.NullTime
+	MaxOccurrences   sql.NullInt32
+	Paused           bool
+	NextIndex        int32
+	NextOccurrenceAt time.Time
+	OccurrencesCount int32
+	CreatedAt        time.Time
+	Currency         money.Currency
+	CategoryID       sql.NullInt32
+	Notes            sql.NullString
+	UserID           sql.NullInt32
+}
+
+type RecurringRuleTag struct {
+	RuleID int32
+	TagID  int32
+}
+
+type RefreshToken struct {
+	ID        int32
+	UserID    int32
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type Session struct {
+	ID         string
 	UserID     int32
-	Title      string
-	Amount     string
-	OccurredAt time.Time
-	Period     NullTransactionsPeriod
+	UserAgent  string
+	Ip         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+}
+
+type Tag struct {
+	ID        int32
+	AccountID int32
+	Name      string
+}
+
+type Transaction struct {
+	ID          int32
+	AccountID   int32
+	Title       string
+	Amount      money.Amount
+	OccurredAt  time.Time
+	Period      NullTransactionsPeriod
+	RuleID      sql.NullInt32
+	Currency    money.Currency
+	CategoryID  sql.NullInt32
+	Notes       sql.NullString
+	ExternalRef sql.NullString
+	UserID      sql.NullInt32
+}
+
+type TransactionTag struct {
+	TransactionID int32
+	TagID         int32
 }
 
 type User struct {
@@ -127,3 +446,15 @@ type User struct {
 	Email        string
 	PasswordHash string
 }
+
+type UserDeletion struct {
+	UserID      int32
+	RequestedAt time.Time
+	DeleteAt    time.Time
+}
+
+type UserDeletionAccount struct {
+	UserID     int32
+	AccountID  int32
+	NewOwnerID sql.NullInt32
+}
@@ -8,7 +8,10 @@ package query
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
+
+	"microservices/accounter/internal/money"
 )
 
 const addAccountMember = `-- name: AddAccountMember :exec
@@ -27,6 +30,67 @@ func (q *Queries) AddAccountMember(ctx context.Context, arg AddAccountMemberPara
 	return err
 }
 
+const addAccountRolePermission = `-- name: AddAccountRolePermission :exec
+INSERT INTO account_role_permissions (role_id, permission)
+VALUES (?, ?)
+`
+
+type AddAccountRolePermissionParams struct {
+	RoleID     int32
+	Permission string
+}
+
+func (q *Queries) AddAccountRolePermission(ctx context.Context, arg AddAccountRolePermissionParams) error {
+	_, err := q.db.ExecContext(ctx, addAccountRolePermission, arg.RoleID, arg.Permission)
+	return err
+}
+
+const addRecurringRuleTag = `-- name: AddRecurringRuleTag :exec
+INSERT INTO recurring_rule_tags (rule_id, tag_id)
+VALUES (?, ?)
+`
+
+type AddRecurringRuleTagParams struct {
+	RuleID int32
+	TagID  int32
+}
+
+func (q *Queries) AddRecurringRuleTag(ctx context.Context, arg AddRecurringRuleTagParams) error {
+	_, err := q.db.ExecContext(ctx, addRecurringRuleTag, arg.RuleID, arg.TagID)
+	return err
+}
+
+const addTransactionTag = `-- name: AddTransactionTag :exec
+INSERT INTO transaction_tags (transaction_id, tag_id)
+VALUES (?, ?)
+`
+
+type AddTransactionTagParams struct {
+	TransactionID int32
+	TagID         int32
+}
+
+func (q *Queries) AddTransactionTag(ctx context.Context, arg AddTransactionTagParams) error {
+	_, err := q.db.ExecContext(ctx, addTransactionTag, arg.TransactionID, arg.TagID)
+	return err
+}
+
+const addUserDeletionAccount = `-- name: AddUserDeletionAccount :exec
+INSERT INTO user_deletion_accounts (user_id, account_id, new_owner_id)
+VALUES (?, ?, ?)
+`
+
+type AddUserDeletionAccountParams struct {
+	UserID     int32
+	AccountID  int32
+	NewOwnerID sql.NullInt32
+}
+
+func (q *Queries) AddUserDeletionAccount(ctx context.Context, arg AddUserDeletionAccountParams) error {
+	_, err := q.db.ExecContext(ctx, addUserDeletionAccount, arg.UserID, arg.AccountID, arg.NewOwnerID)
+	return err
+}
+
 const checkUserByID = `-- name: CheckUserByID :one
 SELECT COUNT(*) = 1 AS user_exists
 FROM users
@@ -40,19 +104,281 @@ func (q *Queries) CheckUserByID(ctx context.Context, id int32) (bool, error) {
 	return user_exists, err
 }
 
+const copyTransactionTags = `-- name: CopyTransactionTags :exec
+INSERT IGNORE INTO transaction_tags (transaction_id, tag_id)
+SELECT ?, tt.tag_id
+FROM transaction_tags tt
+WHERE tt.transaction_id = ?
+`
+
+type CopyTransactionTagsParams struct {
+	ToTransactionID   int32
+	FromTransactionID int32
+}
+
+func (q *Queries) CopyTransactionTags(ctx context.Context, arg CopyTransactionTagsParams) error {
+	_, err := q.db.ExecContext(ctx, copyTransactionTags, arg.ToTransactionID, arg.FromTransactionID)
+	return err
+}
+
+const countAccountRoleMembers = `-- name: CountAccountRoleMembers :one
+SELECT COUNT(*)
+FROM account_members
+WHERE role_id = ?
+`
+
+func (q *Queries) CountAccountRoleMembers(ctx context.Context, roleID sql.NullInt32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountRoleMembers, roleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRuleOccurrencesBefore = `-- name: CountRuleOccurrencesBefore :one
+SELECT COUNT(*)
+FROM transactions
+WHERE rule_id = ? AND occurred_at < ?
+`
+
+type CountRuleOccurrencesBeforeParams struct {
+	RuleID     sql.NullInt32
+	OccurredAt time.Time
+}
+
+func (q *Queries) CountRuleOccurrencesBefore(ctx context.Context, arg CountRuleOccurrencesBeforeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRuleOccurrencesBefore, arg.RuleID, arg.OccurredAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :execresult
-INSERT INTO accounts (name, description, owner_id)
-VALUES (?, ?, ?)
+INSERT INTO accounts (name, description, owner_id, currency, opening_balance)
+VALUES (?, ?, ?, ?, ?)
 `
 
 type CreateAccountParams struct {
-	Name        string
-	Description sql.NullString
-	OwnerID     int32
+	Name           string
+	Description    sql.NullString
+	OwnerID        int32
+	Currency       money.Currency
+	OpeningBalance money.Amount
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, createAccount, arg.Name, arg.Description, arg.OwnerID)
+	return q.db.ExecContext(ctx, createAccount,
+		arg.Name,
+		arg.Description,
+		arg.OwnerID,
+		arg.Currency,
+		arg.OpeningBalance,
+	)
+}
+
+const createAccountRole = `-- name: CreateAccountRole :execresult
+INSERT INTO account_roles (account_id, name)
+VALUES (?, ?)
+`
+
+type CreateAccountRoleParams struct {
+	AccountID int32
+	Name      string
+}
+
+func (q *Queries) CreateAccountRole(ctx context.Context, arg CreateAccountRoleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAccountRole, arg.AccountID, arg.Name)
+}
+
+const createCategory = `-- name: CreateCategory :execresult
+INSERT INTO categories (account_id, parent_id, name)
+VALUES (?, ?, ?)
+`
+
+type CreateCategoryParams struct {
+	AccountID int32
+	ParentID  sql.NullInt32
+	Name      string
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createCategory, arg.AccountID, arg.ParentID, arg.Name)
+}
+
+const createInvitation = `-- name: CreateInvitation :execresult
+INSERT INTO invitations (token, account_id, inviter_id, email, invitee_id, role, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateInvitationParams struct {
+	Token     string
+	AccountID int32
+	InviterID sql.NullInt32
+	Email     string
+	InviteeID sql.NullInt32
+	Role      InvitationsRole
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createInvitation,
+		arg.Token,
+		arg.AccountID,
+		arg.InviterID,
+		arg.Email,
+		arg.InviteeID,
+		arg.Role,
+		arg.ExpiresAt,
+	)
+}
+
+const createInviteLink = `-- name: CreateInviteLink :execresult
+INSERT INTO invite_links (code, account_id, created_by, role, max_uses, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateInviteLinkParams struct {
+	Code      string
+	AccountID int32
+	CreatedBy sql.NullInt32
+	Role      InviteLinksRole
+	MaxUses   sql.NullInt32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateInviteLink(ctx context.Context, arg CreateInviteLinkParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createInviteLink,
+		arg.Code,
+		arg.AccountID,
+		arg.CreatedBy,
+		arg.Role,
+		arg.MaxUses,
+		arg.ExpiresAt,
+	)
+}
+
+const createOwnershipTransfer = `-- name: CreateOwnershipTransfer :exec
+INSERT INTO ownership_transfers (account_id, from_user_id, to_user_id, expires_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    from_user_id = VALUES(from_user_id),
+    to_user_id = VALUES(to_user_id),
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = VALUES(expires_at)
+`
+
+type CreateOwnershipTransferParams struct {
+	AccountID  int32
+	FromUserID int32
+	ToUserID   int32
+	ExpiresAt  time.Time
+}
+
+func (q *Queries) CreateOwnershipTransfer(ctx context.Context, arg CreateOwnershipTransferParams) error {
+	_, err := q.db.ExecContext(ctx, createOwnershipTransfer,
+		arg.AccountID,
+		arg.FromUserID,
+		arg.ToUserID,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createRecurringRule = `-- name: CreateRecurringRule :execresult
+INSERT INTO recurring_rules (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    period,
+    interval_count,
+    starts_at,
+    ends_at,
+    max_occurrences,
+    next_occurrence_at,
+    category_id,
+    notes
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateRecurringRuleParams struct {
+	AccountID        int32
+	UserID           sql.NullInt32
+	Title            string
+	Amount           money.Amount
+	Currency         money.Currency
+	Period           RecurringRulesPeriod
+	IntervalCount    int32
+	StartsAt         time.Time
+	EndsAt           sql.NullTime
+	MaxOccurrences   sql.NullInt32
+	NextOccurrenceAt time.Time
+	CategoryID       sql.NullInt32
+	Notes            sql.NullString
+}
+
+func (q *Queries) CreateRecurringRule(ctx context.Context, arg CreateRecurringRuleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createRecurringRule,
+		arg.AccountID,
+		arg.UserID,
+		arg.Title,
+		arg.Amount,
+		arg.Currency,
+		arg.Period,
+		arg.IntervalCount,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.MaxOccurrences,
+		arg.NextOccurrenceAt,
+		arg.CategoryID,
+		arg.Notes,
+	)
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :execresult
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateRefreshTokenParams struct {
+	UserID    int32
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createRefreshToken,
+		arg.UserID,
+		arg.FamilyID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO sessions (id, user_id, user_agent, ip, expires_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	ID        string
+	UserID    int32
+	UserAgent string
+	Ip        string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.ID,
+		arg.UserID,
+		arg.UserAgent,
+		arg.Ip,
+		arg.ExpiresAt,
+	)
+	return err
 }
 
 const createTransaction = `-- name: CreateTransaction :execresult
@@ -61,19 +387,25 @@ INSERT INTO transactions (
     user_id,
     title,
     amount,
+    currency,
     occurred_at,
-    period
+    period,
+    category_id,
+    notes
 )
-VALUES (?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateTransactionParams struct {
 	AccountID  int32
-	UserID     int32
+	UserID     sql.NullInt32
 	Title      string
-	Amount     string
+	Amount     money.Amount
+	Currency   money.Currency
 	OccurredAt time.Time
 	Period     NullTransactionsPeriod
+	CategoryID sql.NullInt32
+	Notes      sql.NullString
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (sql.Result, error) {
@@ -82,8 +414,11 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.UserID,
 		arg.Title,
 		arg.Amount,
+		arg.Currency,
 		arg.OccurredAt,
 		arg.Period,
+		arg.CategoryID,
+		arg.Notes,
 	)
 }
 
@@ -101,141 +436,2472 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Res
 	return q.db.ExecContext(ctx, createUser, arg.Email, arg.PasswordHash)
 }
 
-const deleteAccountByID = `-- name: DeleteAccountByID :exec
-DELETE FROM accounts
-WHERE id = ?
+const createUserDeletion = `-- name: CreateUserDeletion :exec
+INSERT INTO user_deletions (user_id, delete_at)
+VALUES (?, ?)
+`
+
+type CreateUserDeletionParams struct {
+	UserID   int32
+	DeleteAt time.Time
+}
+
+func (q *Queries) CreateUserDeletion(ctx context.Context, arg CreateUserDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, createUserDeletion, arg.UserID, arg.DeleteAt)
+	return err
+}
+
+const deleteAccountByID = `-- name: DeleteAccountByID :exec
+DELETE FROM accounts
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAccountByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountByID, id)
+	return err
+}
+
+const deleteAccountRole = `-- name: DeleteAccountRole :exec
+DELETE FROM account_roles
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAccountRole(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountRole, id)
+	return err
+}
+
+const deleteAccountRolePermissions = `-- name: DeleteAccountRolePermissions :exec
+DELETE FROM account_role_permissions
+WHERE role_id = ?
+`
+
+func (q *Queries) DeleteAccountRolePermissions(ctx context.Context, roleID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountRolePermissions, roleID)
+	return err
+}
+
+const deleteCategoryByID = `-- name: DeleteCategoryByID :exec
+DELETE FROM categories
+WHERE id = ?
+`
+
+func (q *Queries) DeleteCategoryByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteCategoryByID, id)
+	return err
+}
+
+const deleteExchangeRate = `-- name: DeleteExchangeRate :execresult
+DELETE FROM exchange_rates
+WHERE id = ? AND user_id = ?
+`
+
+type DeleteExchangeRateParams struct {
+	ID     int32
+	UserID int32
+}
+
+func (q *Queries) DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteExchangeRate, arg.ID, arg.UserID)
+}
+
+const deleteOwnershipTransfer = `-- name: DeleteOwnershipTransfer :exec
+DELETE FROM ownership_transfers
+WHERE account_id = ?
+`
+
+func (q *Queries) DeleteOwnershipTransfer(ctx context.Context, accountID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteOwnershipTransfer, accountID)
+	return err
+}
+
+const deleteRecurringRuleByID = `-- name: DeleteRecurringRuleByID :exec
+DELETE FROM recurring_rules
+WHERE id = ?
+`
+
+func (q *Queries) DeleteRecurringRuleByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecurringRuleByID, id)
+	return err
+}
+
+const deleteRecurringRuleTags = `-- name: DeleteRecurringRuleTags :exec
+DELETE FROM recurring_rule_tags
+WHERE rule_id = ?
+`
+
+func (q *Queries) DeleteRecurringRuleTags(ctx context.Context, ruleID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecurringRuleTags, ruleID)
+	return err
+}
+
+const deleteRuleOccurrencesFrom = `-- name: DeleteRuleOccurrencesFrom :execresult
+DELETE FROM transactions
+WHERE rule_id = ? AND occurred_at >= ?
+`
+
+type DeleteRuleOccurrencesFromParams struct {
+	RuleID     sql.NullInt32
+	OccurredAt time.Time
+}
+
+func (q *Queries) DeleteRuleOccurrencesFrom(ctx context.Context, arg DeleteRuleOccurrencesFromParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteRuleOccurrencesFrom, arg.RuleID, arg.OccurredAt)
+}
+
+const deleteRuleOccurrencesTags = `-- name: DeleteRuleOccurrencesTags :exec
+DELETE FROM transaction_tags
+WHERE transaction_id IN (
+    SELECT id FROM transactions WHERE rule_id = ?
+)
+`
+
+func (q *Queries) DeleteRuleOccurrencesTags(ctx context.Context, ruleID sql.NullInt32) error {
+	_, err := q.db.ExecContext(ctx, deleteRuleOccurrencesTags, ruleID)
+	return err
+}
+
+const deleteTransactionByID = `-- name: DeleteTransactionByID :exec
+DELETE FROM transactions
+WHERE id = ?
+`
+
+func (q *Queries) DeleteTransactionByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTransactionByID, id)
+	return err
+}
+
+const deleteTransactionTags = `-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tags
+WHERE transaction_id = ?
+`
+
+func (q *Queries) DeleteTransactionTags(ctx context.Context, transactionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTransactionTags, transactionID)
+	return err
+}
+
+const deleteUserByID = `-- name: DeleteUserByID :exec
+DELETE FROM users
+WHERE id = ?
+`
+
+func (q *Queries) DeleteUserByID(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserByID, id)
+	return err
+}
+
+const deleteUserDeletion = `-- name: DeleteUserDeletion :execresult
+DELETE FROM user_deletions
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserDeletion(ctx context.Context, userID int32) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteUserDeletion, userID)
+}
+
+const dismissDuplicate = `-- name: DismissDuplicate :exec
+INSERT IGNORE INTO duplicate_dismissals (transaction_id, duplicate_id)
+VALUES (?, ?)
+`
+
+type DismissDuplicateParams struct {
+	TransactionID int32
+	DuplicateID   int32
+}
+
+func (q *Queries) DismissDuplicate(ctx context.Context, arg DismissDuplicateParams) error {
+	_, err := q.db.ExecContext(ctx, dismissDuplicate, arg.TransactionID, arg.DuplicateID)
+	return err
+}
+
+const endRecurringRule = `-- name: EndRecurringRule :exec
+UPDATE recurring_rules
+SET ends_at = ?, occurrences_count = ?
+WHERE id = ?
+`
+
+type EndRecurringRuleParams struct {
+	EndsAt           sql.NullTime
+	OccurrencesCount int32
+	ID               int32
+}
+
+func (q *Queries) EndRecurringRule(ctx context.Context, arg EndRecurringRuleParams) error {
+	_, err := q.db.ExecContext(ctx, endRecurringRule, arg.EndsAt, arg.OccurrencesCount, arg.ID)
+	return err
+}
+
+const extendSession = `-- name: ExtendSession :exec
+UPDATE sessions
+SET last_seen_at = CURRENT_TIMESTAMP, ip = ?, expires_at = ?
+WHERE id = ?
+`
+
+type ExtendSessionParams struct {
+	Ip        string
+	ExpiresAt time.Time
+	ID        string
+}
+
+func (q *Queries) ExtendSession(ctx context.Context, arg ExtendSessionParams) error {
+	_, err := q.db.ExecContext(ctx, extendSession, arg.Ip, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const fillTransactionFromDuplicate = `-- name: FillTransactionFromDuplicate :exec
+UPDATE transactions
+SET category_id = COALESCE(category_id, ?),
+    notes = COALESCE(notes, ?),
+    external_ref = COALESCE(external_ref, ?)
+WHERE id = ?
+`
+
+type FillTransactionFromDuplicateParams struct {
+	CategoryID  sql.NullInt32
+	Notes       sql.NullString
+	ExternalRef sql.NullString
+	ID          int32
+}
+
+func (q *Queries) FillTransactionFromDuplicate(ctx context.Context, arg FillTransactionFromDuplicateParams) error {
+	_, err := q.db.ExecContext(ctx, fillTransactionFromDuplicate,
+		arg.CategoryID,
+		arg.Notes,
+		arg.ExternalRef,
+		arg.ID,
+	)
+	return err
+}
+
+const getAccountBalance = `-- name: GetAccountBalance :one
+SELECT
+    a.currency,
+    a.opening_balance,
+    CAST(a.opening_balance + COALESCE(SUM(t.amount), 0) AS DECIMAL(20,2)) AS balance
+FROM accounts a
+LEFT JOIN transactions t ON t.account_id = a.id AND t.occurred_at <= ?
+WHERE a.id = ?
+GROUP BY a.id
+`
+
+type GetAccountBalanceParams struct {
+	At        time.Time
+	AccountID int32
+}
+
+type GetAccountBalanceRow struct {
+	Currency       money.Currency
+	OpeningBalance money.Amount
+	Balance        money.Amount
+}
+
+func (q *Queries) GetAccountBalance(ctx context.Context, arg GetAccountBalanceParams) (GetAccountBalanceRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountBalance, arg.At, arg.AccountID)
+	var i GetAccountBalanceRow
+	err := row.Scan(&i.Currency, &i.OpeningBalance, &i.Balance)
+	return i, err
+}
+
+const getAccountByID = `-- name: GetAccountByID :one
+SELECT id, name, description, owner_id, currency, opening_balance
+FROM accounts
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetAccountByID(ctx context.Context, id int32) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByID, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Currency,
+		&i.OpeningBalance,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, name, description, owner_id, currency, opening_balance
+FROM accounts
+WHERE id = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, id int32) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountForUpdate, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Currency,
+		&i.OpeningBalance,
+	)
+	return i, err
+}
+
+const getAccountInvitation = `-- name: GetAccountInvitation :one
+SELECT id, token, account_id, inviter_id, email, invitee_id, role, status, created_at, expires_at, responded_at
+FROM invitations
+WHERE id = ? AND account_id = ?
+`
+
+type GetAccountInvitationParams struct {
+	ID        int32
+	AccountID int32
+}
+
+func (q *Queries) GetAccountInvitation(ctx context.Context, arg GetAccountInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, getAccountInvitation, arg.ID, arg.AccountID)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Token,
+		&i.AccountID,
+		&i.InviterID,
+		&i.Email,
+		&i.InviteeID,
+		&i.Role,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+	)
+	return i, err
+}
+
+const getAccountInviteLink = `-- name: GetAccountInviteLink :one
+SELECT id, code, account_id, created_by, role, max_uses, uses, created_at, expires_at, revoked_at
+FROM invite_links
+WHERE id = ? AND account_id = ?
+`
+
+type GetAccountInviteLinkParams struct {
+	ID        int32
+	AccountID int32
+}
+
+func (q *Queries) GetAccountInviteLink(ctx context.Context, arg GetAccountInviteLinkParams) (InviteLink, error) {
+	row := q.db.QueryRowContext(ctx, getAccountInviteLink, arg.ID, arg.AccountID)
+	var i InviteLink
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.AccountID,
+		&i.CreatedBy,
+		&i.Role,
+		&i.MaxUses,
+		&i.Uses,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getAccountMember = `-- name: GetAccountMember :one
+SELECT role, role_id
+FROM account_members
+WHERE account_id = ? AND user_id = ?
+LIMIT 1
+`
+
+type GetAccountMemberParams struct {
+	AccountID int32
+	UserID    int32
+}
+
+type GetAccountMemberRow struct {
+	Role   AccountMembersRole
+	RoleID sql.NullInt32
+}
+
+func (q *Queries) GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (GetAccountMemberRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMember, arg.AccountID, arg.UserID)
+	var i GetAccountMemberRow
+	err := row.Scan(&i.Role, &i.RoleID)
+	return i, err
+}
+
+const getAccountMemberRole = `-- name: GetAccountMemberRole :one
+SELECT role
+FROM account_members
+WHERE account_id = ? AND user_id = ?
+LIMIT 1
+`
+
+type GetAccountMemberRoleParams struct {
+	AccountID int32
+	UserID    int32
+}
+
+func (q *Queries) GetAccountMemberRole(ctx context.Context, arg GetAccountMemberRoleParams) (AccountMembersRole, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMemberRole, arg.AccountID, arg.UserID)
+	var role AccountMembersRole
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getAccountRole = `-- name: GetAccountRole :one
+SELECT id, account_id, name, created_at
+FROM account_roles
+WHERE id = ? AND account_id = ?
+`
+
+type GetAccountRoleParams struct {
+	ID        int32
+	AccountID int32
+}
+
+func (q *Queries) GetAccountRole(ctx context.Context, arg GetAccountRoleParams) (AccountRole, error) {
+	row := q.db.QueryRowContext(ctx, getAccountRole, arg.ID, arg.AccountID)
+	var i AccountRole
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCategoryByID = `-- name: GetCategoryByID :one
+SELECT id, account_id, parent_id, name, created_at
+FROM categories
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetCategoryByID(ctx context.Context, id int32) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryByID, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ParentID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getExchangeRate = `-- name: GetExchangeRate :one
+SELECT id, user_id, base_currency, quote_currency, rate_date, rate, created_at
+FROM exchange_rates
+WHERE user_id = ?
+    AND base_currency = ?
+    AND quote_currency = ?
+    AND rate_date = ?
+`
+
+type GetExchangeRateParams struct {
+	UserID        int32
+	BaseCurrency  money.Currency
+	QuoteCurrency money.Currency
+	RateDate      time.Time
+}
+
+func (q *Queries) GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error) {
+	row := q.db.QueryRowContext(ctx, getExchangeRate,
+		arg.UserID,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.RateDate,
+	)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.RateDate,
+		&i.Rate,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFirstRuleOccurrenceID = `-- name: GetFirstRuleOccurrenceID :one
+SELECT id
+FROM transactions
+WHERE rule_id = ?
+ORDER BY occurred_at, id
+LIMIT 1
+`
+
+func (q *Queries) GetFirstRuleOccurrenceID(ctx context.Context, ruleID sql.NullInt32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getFirstRuleOccurrenceID, ruleID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getInvitationByTokenForUpdate = `-- name: GetInvitationByTokenForUpdate :one
+SELECT id, token, account_id, inviter_id, email, invitee_id, role, status, created_at, expires_at, responded_at
+FROM invitations
+WHERE token = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetInvitationByTokenForUpdate(ctx context.Context, token string) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, getInvitationByTokenForUpdate, token)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Token,
+		&i.AccountID,
+		&i.InviterID,
+		&i.Email,
+		&i.InviteeID,
+		&i.Role,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+	)
+	return i, err
+}
+
+const getInviteLinkByCodeForUpdate = `-- name: GetInviteLinkByCodeForUpdate :one
+SELECT id, code, account_id, created_by, role, max_uses, uses, created_at, expires_at, revoked_at
+FROM invite_links
+WHERE code = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetInviteLinkByCodeForUpdate(ctx context.Context, code string) (InviteLink, error) {
+	row := q.db.QueryRowContext(ctx, getInviteLinkByCodeForUpdate, code)
+	var i InviteLink
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.AccountID,
+		&i.CreatedBy,
+		&i.Role,
+		&i.MaxUses,
+		&i.Uses,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getOwnershipTransfer = `-- name: GetOwnershipTransfer :one
+SELECT account_id, from_user_id, to_user_id, created_at, expires_at
+FROM ownership_transfers
+WHERE account_id = ?
+`
+
+func (q *Queries) GetOwnershipTransfer(ctx context.Context, accountID int32) (OwnershipTransfer, error) {
+	row := q.db.QueryRowContext(ctx, getOwnershipTransfer, accountID)
+	var i OwnershipTransfer
+	err := row.Scan(
+		&i.AccountID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getOwnershipTransferForUpdate = `-- name: GetOwnershipTransferForUpdate :one
+SELECT account_id, from_user_id, to_user_id, created_at, expires_at
+FROM ownership_transfers
+WHERE account_id = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetOwnershipTransferForUpdate(ctx context.Context, accountID int32) (OwnershipTransfer, error) {
+	row := q.db.QueryRowContext(ctx, getOwnershipTransferForUpdate, accountID)
+	var i OwnershipTransfer
+	err := row.Scan(
+		&i.AccountID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getRecurringRuleByID = `-- name: GetRecurringRuleByID :one
+SELECT id, account_id, title, amount, period, interval_count, starts_at, ends_at, max_occurrences, paused, next_index, next_occurrence_at, occurrences_count, created_at, currency, category_id, notes, user_id
+FROM recurring_rules
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetRecurringRuleByID(ctx context.Context, id int32) (RecurringRule, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringRuleByID, id)
+	var i RecurringRule
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Title,
+		&i.Amount,
+		&i.Period,
+		&i.IntervalCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.Paused,
+		&i.NextIndex,
+		&i.NextOccurrenceAt,
+		&i.OccurrencesCount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.CategoryID,
+		&i.Notes,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const getRecurringRuleForUpdate = `-- name: GetRecurringRuleForUpdate :one
+SELECT id, account_id, title, amount, period, interval_count, starts_at, ends_at, max_occurrences, paused, next_index, next_occurrence_at, occurrences_count, created_at, currency, category_id, notes, user_id
+FROM recurring_rules
+WHERE id = ?
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetRecurringRuleForUpdate(ctx context.Context, id int32) (RecurringRule, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringRuleForUpdate, id)
+	var i RecurringRule
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Title,
+		&i.Amount,
+		&i.Period,
+		&i.IntervalCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.MaxOccurrences,
+		&i.Paused,
+		&i.NextIndex,
+		&i.NextOccurrenceAt,
+		&i.OccurrencesCount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.CategoryID,
+		&i.Notes,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const getRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
+SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at
+FROM refresh_tokens
+WHERE token_hash = ?
+LIMIT 1
+`
+
+func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenByHash, tokenHash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getSessionByID = `-- name: GetSessionByID :one
+SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+FROM sessions
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByID, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.Ip,
+		&i.CreatedAt,
+		&i.LastSeenAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getTransactionByID = `-- name: GetTransactionByID :one
+SELECT id, account_id, title, amount, occurred_at, period, rule_id, currency, category_id, notes, external_ref, user_id
+FROM transactions
+WHERE id = ?
+`
+
+func (q *Queries) GetTransactionByID(ctx context.Context, id int32) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionByID, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Title,
+		&i.Amount,
+		&i.OccurredAt,
+		&i.Period,
+		&i.RuleID,
+		&i.Currency,
+		&i.CategoryID,
+		&i.Notes,
+		&i.ExternalRef,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, password_hash
+FROM users
+WHERE email = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(&i.ID, &i.Email, &i.PasswordHash)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email
+FROM users
+WHERE id = ?
+LIMIT 1
+`
+
+type GetUserByIDRow struct {
+	ID    int32
+	Email string
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i GetUserByIDRow
+	err := row.Scan(&i.ID, &i.Email)
+	return i, err
+}
+
+const getUserDeletion = `-- name: GetUserDeletion :one
+SELECT user_id, requested_at, delete_at
+FROM user_deletions
+WHERE user_id = ?
+`
+
+func (q *Queries) GetUserDeletion(ctx context.Context, userID int32) (UserDeletion, error) {
+	row := q.db.QueryRowContext(ctx, getUserDeletion, userID)
+	var i UserDeletion
+	err := row.Scan(&i.UserID, &i.RequestedAt, &i.DeleteAt)
+	return i, err
+}
+
+const getUserDeletionForUpdate = `-- name: GetUserDeletionForUpdate :one
+SELECT user_id, requested_at, delete_at
+FROM user_deletions
+WHERE user_id = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetUserDeletionForUpdate(ctx context.Context, userID int32) (UserDeletion, error) {
+	row := q.db.QueryRowContext(ctx, getUserDeletionForUpdate, userID)
+	var i UserDeletion
+	err := row.Scan(&i.UserID, &i.RequestedAt, &i.DeleteAt)
+	return i, err
+}
+
+const getUserPasswordHash = `-- name: GetUserPasswordHash :one
+SELECT password_hash
+FROM users
+WHERE id = ?
+`
+
+func (q *Queries) GetUserPasswordHash(ctx context.Context, id int32) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserPasswordHash, id)
+	var password_hash string
+	err := row.Scan(&password_hash)
+	return password_hash, err
+}
+
+const hasPendingInvitation = `-- name: HasPendingInvitation :one
+SELECT EXISTS (
+    SELECT 1
+    FROM invitations
+    WHERE account_id = ? AND email = ? AND status = 'pending' AND expires_at > ?
+)
+`
+
+type HasPendingInvitationParams struct {
+	AccountID int32
+	Email     string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) HasPendingInvitation(ctx context.Context, arg HasPendingInvitationParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasPendingInvitation, arg.AccountID, arg.Email, arg.ExpiresAt)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const importRecurringRule = `-- name: ImportRecurringRule :execresult
+INSERT INTO recurring_rules (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    period,
+    interval_count,
+    starts_at,
+    ends_at,
+    max_occurrences,
+    paused,
+    next_index,
+    next_occurrence_at,
+    occurrences_count,
+    created_at,
+    category_id,
+    notes
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type ImportRecurringRuleParams struct {
+	AccountID        int32
+	UserID           sql.NullInt32
+	Title            string
+	Amount           money.Amount
+	Currency         money.Currency
+	Period           RecurringRulesPeriod
+	IntervalCount    int32
+	StartsAt         time.Time
+	EndsAt           sql.NullTime
+	MaxOccurrences   sql.NullInt32
+	Paused           bool
+	NextIndex        int32
+	NextOccurrenceAt time.Time
+	OccurrencesCount int32
+	CreatedAt        time.Time
+	CategoryID       sql.NullInt32
+	Notes            sql.NullString
+}
+
+func (q *Queries) ImportRecurringRule(ctx context.Context, arg ImportRecurringRuleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, importRecurringRule,
+		arg.AccountID,
+		arg.UserID,
+		arg.Title,
+		arg.Amount,
+		arg.Currency,
+		arg.Period,
+		arg.IntervalCount,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.MaxOccurrences,
+		arg.Paused,
+		arg.NextIndex,
+		arg.NextOccurrenceAt,
+		arg.OccurrencesCount,
+		arg.CreatedAt,
+		arg.CategoryID,
+		arg.Notes,
+	)
+}
+
+const importTransaction = `-- name: ImportTransaction :execresult
+INSERT INTO transactions (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    occurred_at,
+    period,
+    rule_id,
+    category_id,
+    notes,
+    external_ref
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type ImportTransactionParams struct {
+	AccountID   int32
+	UserID      sql.NullInt32
+	Title       string
+	Amount      money.Amount
+	Currency    money.Currency
+	OccurredAt  time.Time
+	Period      NullTransactionsPeriod
+	RuleID      sql.NullInt32
+	CategoryID  sql.NullInt32
+	Notes       sql.NullString
+	ExternalRef sql.NullString
+}
+
+func (q *Queries) ImportTransaction(ctx context.Context, arg ImportTransactionParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, importTransaction,
+		arg.AccountID,
+		arg.UserID,
+		arg.Title,
+		arg.Amount,
+		arg.Currency,
+		arg.OccurredAt,
+		arg.Period,
+		arg.RuleID,
+		arg.CategoryID,
+		arg.Notes,
+		arg.ExternalRef,
+	)
+}
+
+const incrementInviteLinkUses = `-- name: IncrementInviteLinkUses :exec
+UPDATE invite_links
+SET uses = uses + 1
+WHERE id = ?
+`
+
+func (q *Queries) IncrementInviteLinkUses(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, incrementInviteLinkUses, id)
+	return err
+}
+
+const linkInvitationsToUser = `-- name: LinkInvitationsToUser :exec
+UPDATE invitations
+SET invitee_id = ?
+WHERE email = ? AND invitee_id IS NULL
+`
+
+type LinkInvitationsToUserParams struct {
+	InviteeID sql.NullInt32
+	Email     string
+}
+
+func (q *Queries) LinkInvitationsToUser(ctx context.Context, arg LinkInvitationsToUserParams) error {
+	_, err := q.db.ExecContext(ctx, linkInvitationsToUser, arg.InviteeID, arg.Email)
+	return err
+}
+
+const listAccountCategories = `-- name: ListAccountCategories :many
+SELECT id, account_id, parent_id, name, created_at
+FROM categories
+WHERE account_id = ?
+ORDER BY name, id
+`
+
+func (q *Queries) ListAccountCategories(ctx context.Context, accountID int32) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountCategories, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.ParentID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountDuplicateDismissals = `-- name: ListAccountDuplicateDismissals :many
+SELECT d.transaction_id, d.duplicate_id
+FROM duplicate_dismissals d
+JOIN transactions t ON t.id = d.transaction_id
+WHERE t.account_id = ?
+ORDER BY d.transaction_id, d.duplicate_id
+`
+
+type ListAccountDuplicateDismissalsRow struct {
+	TransactionID int32
+	DuplicateID   int32
+}
+
+func (q *Queries) ListAccountDuplicateDismissals(ctx context.Context, accountID int32) ([]ListAccountDuplicateDismissalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountDuplicateDismissals, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountDuplicateDismissalsRow
+	for rows.Next() {
+		var i ListAccountDuplicateDismissalsRow
+		if err := rows.Scan(&i.TransactionID, &i.DuplicateID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountInvitations = `-- name: ListAccountInvitations :many
+SELECT id, token, account_id, inviter_id, email, invitee_id, role, status, created_at, expires_at, responded_at
+FROM invitations
+WHERE account_id = ?
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListAccountInvitations(ctx context.Context, accountID int32) ([]Invitation, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountInvitations, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Invitation
+	for rows.Next() {
+		var i Invitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Token,
+			&i.AccountID,
+			&i.InviterID,
+			&i.Email,
+			&i.InviteeID,
+			&i.Role,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.RespondedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountInviteLinks = `-- name: ListAccountInviteLinks :many
+SELECT id, code, account_id, created_by, role, max_uses, uses, created_at, expires_at, revoked_at
+FROM invite_links
+WHERE account_id = ?
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListAccountInviteLinks(ctx context.Context, accountID int32) ([]InviteLink, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountInviteLinks, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InviteLink
+	for rows.Next() {
+		var i InviteLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.AccountID,
+			&i.CreatedBy,
+			&i.Role,
+			&i.MaxUses,
+			&i.Uses,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountMembers = `-- name: ListAccountMembers :many
+SELECT am.user_id, u.email, am.role, am.role_id, ar.name AS role_name
+FROM account_members am
+JOIN users u ON u.id = am.user_id
+LEFT JOIN account_roles ar ON ar.id = am.role_id
+WHERE am.account_id = ?
+ORDER BY u.email
+`
+
+type ListAccountMembersRow struct {
+	UserID   int32
+	Email    string
+	Role     AccountMembersRole
+	RoleID   sql.NullInt32
+	RoleName sql.NullString
+}
+
+func (q *Queries) ListAccountMembers(ctx context.Context, accountID int32) ([]ListAccountMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountMembersRow
+	for rows.Next() {
+		var i ListAccountMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Email,
+			&i.Role,
+			&i.RoleID,
+			&i.RoleName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountRecurringRuleTags = `-- name: ListAccountRecurringRuleTags :many
+SELECT rt.rule_id, g.name
+FROM recurring_rule_tags rt
+JOIN tags g ON g.id = rt.tag_id
+WHERE g.account_id = ?
+ORDER BY g.name
+`
+
+type ListAccountRecurringRuleTagsRow struct {
+	RuleID int32
+	Name   string
+}
+
+func (q *Queries) ListAccountRecurringRuleTags(ctx context.Context, accountID int32) ([]ListAccountRecurringRuleTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountRecurringRuleTags, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountRecurringRuleTagsRow
+	for rows.Next() {
+		var i ListAccountRecurringRuleTagsRow
+		if err := rows.Scan(&i.RuleID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountRecurringRules = `-- name: ListAccountRecurringRules :many
+SELECT id, account_id, title, amount, period, interval_count, starts_at, ends_at, max_occurrences, paused, next_index, next_occurrence_at, occurrences_count, created_at, currency, category_id, notes, user_id
+FROM recurring_rules
+WHERE account_id = ?
+ORDER BY starts_at, id
+`
+
+func (q *Queries) ListAccountRecurringRules(ctx context.Context, accountID int32) ([]RecurringRule, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountRecurringRules, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringRule
+	for rows.Next() {
+		var i RecurringRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Title,
+			&i.Amount,
+			&i.Period,
+			&i.IntervalCount,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.MaxOccurrences,
+			&i.Paused,
+			&i.NextIndex,
+			&i.NextOccurrenceAt,
+			&i.OccurrencesCount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.CategoryID,
+			&i.Notes,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountRolePermissions = `-- name: ListAccountRolePermissions :many
+SELECT permission
+FROM account_role_permissions
+WHERE role_id = ?
+ORDER BY permission
+`
+
+func (q *Queries) ListAccountRolePermissions(ctx context.Context, roleID int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountRolePermissions, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		items = append(items, permission)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountRoles = `-- name: ListAccountRoles :many
+SELECT id, account_id, name, created_at
+FROM account_roles
+WHERE account_id = ?
+ORDER BY name
+`
+
+func (q *Queries) ListAccountRoles(ctx context.Context, accountID int32) ([]AccountRole, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountRoles, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountRole
+	for rows.Next() {
+		var i AccountRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountRolesPermissions = `-- name: ListAccountRolesPermissions :many
+SELECT p.role_id, p.permission
+FROM account_role_permissions p
+JOIN account_roles r ON r.id = p.role_id
+WHERE r.account_id = ?
+ORDER BY p.role_id, p.permission
+`
+
+func (q *Queries) ListAccountRolesPermissions(ctx context.Context, accountID int32) ([]AccountRolePermission, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountRolesPermissions, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountRolePermission
+	for rows.Next() {
+		var i AccountRolePermission
+		if err := rows.Scan(&i.RoleID, &i.Permission); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTags = `-- name: ListAccountTags :many
+SELECT g.name, COUNT(*) AS transactions_count
+FROM tags g
+JOIN transaction_tags tt ON tt.tag_id = g.id
+WHERE g.account_id = ?
+GROUP BY g.id, g.name
+ORDER BY g.name
+`
+
+type ListAccountTagsRow struct {
+	Name              string
+	TransactionsCount int64
+}
+
+func (q *Queries) ListAccountTags(ctx context.Context, accountID int32) ([]ListAccountTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTags, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountTagsRow
+	for rows.Next() {
+		var i ListAccountTagsRow
+		if err := rows.Scan(&i.Name, &i.TransactionsCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransactionTags = `-- name: ListAccountTransactionTags :many
+SELECT tt.transaction_id, g.name
+FROM transaction_tags tt
+JOIN tags g ON g.id = tt.tag_id
+WHERE g.account_id = ?
+ORDER BY g.name
+`
+
+type ListAccountTransactionTagsRow struct {
+	TransactionID int32
+	Name          string
+}
+
+func (q *Queries) ListAccountTransactionTags(ctx context.Context, accountID int32) ([]ListAccountTransactionTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransactionTags, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountTransactionTagsRow
+	for rows.Next() {
+		var i ListAccountTransactionTagsRow
+		if err := rows.Scan(&i.TransactionID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransactions = `-- name: ListAccountTransactions :many
+SELECT id, account_id, title, amount, occurred_at, period, rule_id, currency, category_id, notes, external_ref, user_id
+FROM transactions
+WHERE account_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListAccountTransactions(ctx context.Context, accountID int32) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransactions, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Title,
+			&i.Amount,
+			&i.OccurredAt,
+			&i.Period,
+			&i.RuleID,
+			&i.Currency,
+			&i.CategoryID,
+			&i.Notes,
+			&i.ExternalRef,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveUserSessions = `-- name: ListActiveUserSessions :many
+SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+FROM sessions
+WHERE user_id = ?
+    AND revoked_at IS NULL
+    AND expires_at > CURRENT_TIMESTAMP
+ORDER BY last_seen_at DESC
+`
+
+func (q *Queries) ListActiveUserSessions(ctx context.Context, userID int32) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.Ip,
+			&i.CreatedAt,
+			&i.LastSeenAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDedupeCandidates = `-- name: ListDedupeCandidates :many
+SELECT id, title, amount, currency, occurred_at, external_ref
+FROM transactions
+WHERE account_id = ?
+    AND rule_id IS NULL
+    AND occurred_at BETWEEN ? AND ?
+`
+
+type ListDedupeCandidatesParams struct {
+	AccountID int32
+	DateFrom  time.Time
+	DateTo    time.Time
+}
+
+type ListDedupeCandidatesRow struct {
+	ID          int32
+	Title       string
+	Amount      money.Amount
+	Currency    money.Currency
+	OccurredAt  time.Time
+	ExternalRef sql.NullString
+}
+
+func (q *Queries) ListDedupeCandidates(ctx context.Context, arg ListDedupeCandidatesParams) ([]ListDedupeCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDedupeCandidates, arg.AccountID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDedupeCandidatesRow
+	for rows.Next() {
+		var i ListDedupeCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Amount,
+			&i.Currency,
+			&i.OccurredAt,
+			&i.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueAccountRecurringRuleIDs = `-- name: ListDueAccountRecurringRuleIDs :many
+SELECT id
+FROM recurring_rules
+WHERE account_id = ?
+    AND paused = FALSE
+    AND next_occurrence_at <= ?
+    AND (ends_at IS NULL OR next_occurrence_at <= ends_at)
+    AND (max_occurrences IS NULL OR occurrences_count < max_occurrences)
+`
+
+type ListDueAccountRecurringRuleIDsParams struct {
+	AccountID        int32
+	NextOccurrenceAt time.Time
+}
+
+func (q *Queries) ListDueAccountRecurringRuleIDs(ctx context.Context, arg ListDueAccountRecurringRuleIDsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listDueAccountRecurringRuleIDs, arg.AccountID, arg.NextOccurrenceAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueRecurringRuleIDs = `-- name: ListDueRecurringRuleIDs :many
+SELECT id
+FROM recurring_rules
+WHERE paused = FALSE
+    AND next_occurrence_at <= ?
+    AND (ends_at IS NULL OR next_occurrence_at <= ends_at)
+    AND (max_occurrences IS NULL OR occurrences_count < max_occurrences)
+`
+
+func (q *Queries) ListDueRecurringRuleIDs(ctx context.Context, nextOccurrenceAt time.Time) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listDueRecurringRuleIDs, nextOccurrenceAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueUserDeletions = `-- name: ListDueUserDeletions :many
+SELECT user_id
+FROM user_deletions
+WHERE delete_at <= ?
+ORDER BY delete_at
+`
+
+func (q *Queries) ListDueUserDeletions(ctx context.Context, deleteAt time.Time) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listDueUserDeletions, deleteAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var user_id int32
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnedAccounts = `-- name: ListOwnedAccounts :many
+SELECT id, name, description, owner_id, currency, opening_balance
+FROM accounts
+WHERE owner_id = ?
+ORDER BY name, id
+`
+
+func (q *Queries) ListOwnedAccounts(ctx context.Context, ownerID int32) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnedAccounts, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.Currency,
+			&i.OpeningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecurringRuleTags = `-- name: ListRecurringRuleTags :many
+SELECT g.name
+FROM recurring_rule_tags rt
+JOIN tags g ON g.id = rt.tag_id
+WHERE rt.rule_id = ?
+ORDER BY g.name
+`
+
+func (q *Queries) ListRecurringRuleTags(ctx context.Context, ruleID int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listRecurringRuleTags, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionExternalRefs = `-- name: ListTransactionExternalRefs :many
+SELECT external_ref
+FROM transactions
+WHERE account_id = ?
+    AND external_ref IN (/*SLICE:external_refs*/?)
+`
+
+type ListTransactionExternalRefsParams struct {
+	AccountID    int32
+	ExternalRefs []sql.NullString
+}
+
+func (q *Queries) ListTransactionExternalRefs(ctx context.Context, arg ListTransactionExternalRefsParams) ([]sql.NullString, error) {
+	query := listTransactionExternalRefs
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.AccountID)
+	if len(arg.ExternalRefs) > 0 {
+		for _, v := range arg.ExternalRefs {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:external_refs*/?", strings.Repeat(",?", len(arg.ExternalRefs))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:external_refs*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullString
+	for rows.Next() {
+		var external_ref sql.NullString
+		if err := rows.Scan(&external_ref); err != nil {
+			return nil, err
+		}
+		items = append(items, external_ref)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByIDs = `-- name: ListTransactionsByIDs :many
+SELECT id, account_id, title, amount, occurred_at, period, rule_id, currency, category_id, notes, external_ref, user_id
+FROM transactions
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) ListTransactionsByIDs(ctx context.Context, ids []int32) ([]Transaction, error) {
+	query := listTransactionsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Title,
+			&i.Amount,
+			&i.OccurredAt,
+			&i.Period,
+			&i.RuleID,
+			&i.Currency,
+			&i.CategoryID,
+			&i.Notes,
+			&i.ExternalRef,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsTags = `-- name: ListTransactionsTags :many
+SELECT tt.transaction_id, g.name
+FROM transaction_tags tt
+JOIN tags g ON g.id = tt.tag_id
+WHERE tt.transaction_id IN (/*SLICE:transaction_ids*/?)
+ORDER BY g.name
+`
+
+type ListTransactionsTagsRow struct {
+	TransactionID int32
+	Name          string
+}
+
+func (q *Queries) ListTransactionsTags(ctx context.Context, transactionIds []int32) ([]ListTransactionsTagsRow, error) {
+	query := listTransactionsTags
+	var queryParams []interface{}
+	if len(transactionIds) > 0 {
+		for _, v := range transactionIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:transaction_ids*/?", strings.Repeat(",?", len(transactionIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:transaction_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransactionsTagsRow
+	for rows.Next() {
+		var i ListTransactionsTagsRow
+		if err := rows.Scan(&i.TransactionID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserAccounts = `-- name: ListUserAccounts :many
+SELECT
+    a.id,
+    a.name,
+    a.description,
+    a.currency,
+    am.role,
+    am.role_id,
+    ar.name AS role_name
+FROM account_members am
+JOIN accounts a ON a.id = am.account_id
+LEFT JOIN account_roles ar ON ar.id = am.role_id
+WHERE am.user_id = ?
+ORDER BY a.name
+`
+
+type ListUserAccountsRow struct {
+	ID          int32
+	Name        string
+	Description sql.NullString
+	Currency    money.Currency
+	Role        AccountMembersRole
+	RoleID      sql.NullInt32
+	RoleName    sql.NullString
+}
+
+func (q *Queries) ListUserAccounts(ctx context.Context, userID int32) ([]ListUserAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserAccounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserAccountsRow
+	for rows.Next() {
+		var i ListUserAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Currency,
+			&i.Role,
+			&i.RoleID,
+			&i.RoleName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserDeletionAccounts = `-- name: ListUserDeletionAccounts :many
+SELECT user_id, account_id, new_owner_id
+FROM user_deletion_accounts
+WHERE user_id = ?
+`
+
+func (q *Queries) ListUserDeletionAccounts(ctx context.Context, userID int32) ([]UserDeletionAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listUserDeletionAccounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserDeletionAccount
+	for rows.Next() {
+		var i UserDeletionAccount
+		if err := rows.Scan(&i.UserID, &i.AccountID, &i.NewOwnerID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserExchangeRates = `-- name: ListUserExchangeRates :many
+SELECT id, user_id, base_currency, quote_currency, rate_date, rate, created_at
+FROM exchange_rates
+WHERE user_id = ?
+    AND (? = '' OR base_currency = ?)
+    AND (? = '' OR quote_currency = ?)
+    AND (? IS NULL OR rate_date >= ?)
+    AND (? IS NULL OR rate_date <= ?)
+ORDER BY rate_date DESC, base_currency, quote_currency
+`
+
+type ListUserExchangeRatesParams struct {
+	UserID        int32
+	BaseCurrency  money.Currency
+	QuoteCurrency money.Currency
+	DateFrom      sql.NullTime
+	DateTo        sql.NullTime
+}
+
+func (q *Queries) ListUserExchangeRates(ctx context.Context, arg ListUserExchangeRatesParams) ([]ExchangeRate, error) {
+	rows, err := q.db.QueryContext(ctx, listUserExchangeRates,
+		arg.UserID,
+		arg.BaseCurrency,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.QuoteCurrency,
+		arg.DateFrom,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.DateTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExchangeRate
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BaseCurrency,
+			&i.QuoteCurrency,
+			&i.RateDate,
+			&i.Rate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserExchangeRatesUntil = `-- name: ListUserExchangeRatesUntil :many
+SELECT id, user_id, base_currency, quote_currency, rate_date, rate, created_at
+FROM exchange_rates
+WHERE user_id = ? AND rate_date <= ?
+ORDER BY rate_date
+`
+
+type ListUserExchangeRatesUntilParams struct {
+	UserID   int32
+	RateDate time.Time
+}
+
+func (q *Queries) ListUserExchangeRatesUntil(ctx context.Context, arg ListUserExchangeRatesUntilParams) ([]ExchangeRate, error) {
+	rows, err := q.db.QueryContext(ctx, listUserExchangeRatesUntil, arg.UserID, arg.RateDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExchangeRate
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BaseCurrency,
+			&i.QuoteCurrency,
+			&i.RateDate,
+			&i.Rate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserInvitations = `-- name: ListUserInvitations :many
+SELECT i.id, i.token, i.account_id, i.inviter_id, i.email, i.invitee_id, i.role, i.status, i.created_at, i.expires_at, i.responded_at, a.name AS account_name, u.email AS inviter_email
+FROM invitations i
+JOIN accounts a ON a.id = i.account_id
+LEFT JOIN users u ON u.id = i.inviter_id
+WHERE i.invitee_id = ? AND i.status = 'pending' AND i.expires_at > ?
+ORDER BY i.created_at DESC, i.id DESC
+`
+
+type ListUserInvitationsParams struct {
+	InviteeID sql.NullInt32
+	ExpiresAt time.Time
+}
+
+type ListUserInvitationsRow struct {
+	ID           int32
+	Token        string
+	AccountID    int32
+	InviterID    sql.NullInt32
+	Email        string
+	InviteeID    sql.NullInt32
+	Role         InvitationsRole
+	Status       InvitationsStatus
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	RespondedAt  sql.NullTime
+	AccountName  string
+	InviterEmail sql.NullString
+}
+
+func (q *Queries) ListUserInvitations(ctx context.Context, arg ListUserInvitationsParams) ([]ListUserInvitationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserInvitations, arg.InviteeID, arg.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserInvitationsRow
+	for rows.Next() {
+		var i ListUserInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Token,
+			&i.AccountID,
+			&i.InviterID,
+			&i.Email,
+			&i.InviteeID,
+			&i.Role,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.RespondedAt,
+			&i.AccountName,
+			&i.InviterEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByEmails = `-- name: ListUsersByEmails :many
+SELECT id, email
+FROM users
+WHERE email IN (/*SLICE:emails*/?)
+`
+
+type ListUsersByEmailsRow struct {
+	ID    int32
+	Email string
+}
+
+func (q *Queries) ListUsersByEmails(ctx context.Context, emails []string) ([]ListUsersByEmailsRow, error) {
+	query := listUsersByEmails
+	var queryParams []interface{}
+	if len(emails) > 0 {
+		for _, v := range emails {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:emails*/?", strings.Repeat(",?", len(emails))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:emails*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersByEmailsRow
+	for rows.Next() {
+		var i ListUsersByEmailsRow
+		if err := rows.Scan(&i.ID, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByIDs = `-- name: ListUsersByIDs :many
+SELECT id, email
+FROM users
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type ListUsersByIDsRow struct {
+	ID    int32
+	Email string
+}
+
+func (q *Queries) ListUsersByIDs(ctx context.Context, ids []int32) ([]ListUsersByIDsRow, error) {
+	query := listUsersByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersByIDsRow
+	for rows.Next() {
+		var i ListUsersByIDsRow
+		if err := rows.Scan(&i.ID, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeAccountMember = `-- name: RemoveAccountMember :exec
+DELETE FROM account_members
+WHERE account_id = ? AND user_id = ?
+`
+
+type RemoveAccountMemberParams struct {
+	AccountID int32
+	UserID    int32
+}
+
+func (q *Queries) RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) error {
+	_, err := q.db.ExecContext(ctx, removeAccountMember, arg.AccountID, arg.UserID)
+	return err
+}
+
+const renameAccountRole = `-- name: RenameAccountRole :exec
+UPDATE account_roles
+SET name = ?
+WHERE id = ?
+`
+
+type RenameAccountRoleParams struct {
+	Name string
+	ID   int32
+}
+
+func (q *Queries) RenameAccountRole(ctx context.Context, arg RenameAccountRoleParams) error {
+	_, err := q.db.ExecContext(ctx, renameAccountRole, arg.Name, arg.ID)
+	return err
+}
+
+const revokeInviteLink = `-- name: RevokeInviteLink :exec
+UPDATE invite_links
+SET revoked_at = ?
+WHERE id = ? AND revoked_at IS NULL
+`
+
+type RevokeInviteLinkParams struct {
+	RevokedAt sql.NullTime
+	ID        int32
+}
+
+func (q *Queries) RevokeInviteLink(ctx context.Context, arg RevokeInviteLinkParams) error {
+	_, err := q.db.ExecContext(ctx, revokeInviteLink, arg.RevokedAt, arg.ID)
+	return err
+}
+
+const revokeRefreshToken = `-- name: RevokeRefreshToken :execresult
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshToken(ctx context.Context, id int32) (sql.Result, error) {
+	return q.db.ExecContext(ctx, revokeRefreshToken, id)
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokenFamily, familyID)
+	return err
+}
+
+const revokeSession = `-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL
 `
 
-func (q *Queries) DeleteAccountByID(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteAccountByID, id)
+func (q *Queries) RevokeSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, revokeSession, id)
 	return err
 }
 
-const deleteTransactionByID = `-- name: DeleteTransactionByID :exec
-DELETE FROM transactions
-WHERE id = ?
+const revokeUserRefreshTokensExcept = `-- name: RevokeUserRefreshTokensExcept :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND family_id <> ? AND revoked_at IS NULL
 `
 
-func (q *Queries) DeleteTransactionByID(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteTransactionByID, id)
+type RevokeUserRefreshTokensExceptParams struct {
+	UserID   int32
+	FamilyID string
+}
+
+func (q *Queries) RevokeUserRefreshTokensExcept(ctx context.Context, arg RevokeUserRefreshTokensExceptParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserRefreshTokensExcept, arg.UserID, arg.FamilyID)
 	return err
 }
 
-const getAccountByID = `-- name: GetAccountByID :one
-SELECT id, name, description, owner_id
-FROM accounts
-WHERE id = ?
-LIMIT 1
+const revokeUserSessionsExcept = `-- name: RevokeUserSessionsExcept :exec
+UPDATE sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND id <> ? AND revoked_at IS NULL
 `
 
-func (q *Queries) GetAccountByID(ctx context.Context, id int32) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccountByID, id)
-	var i Account
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.OwnerID,
-	)
-	return i, err
+type RevokeUserSessionsExceptParams struct {
+	UserID int32
+	ID     string
 }
 
-const getAccountMemberRole = `-- name: GetAccountMemberRole :one
-SELECT role
-FROM account_members
+func (q *Queries) RevokeUserSessionsExcept(ctx context.Context, arg RevokeUserSessionsExceptParams) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessionsExcept, arg.UserID, arg.ID)
+	return err
+}
+
+const setAccountMemberCustomRole = `-- name: SetAccountMemberCustomRole :exec
+UPDATE account_members
+SET role = 'custom', role_id = ?
 WHERE account_id = ? AND user_id = ?
-LIMIT 1
 `
 
-type GetAccountMemberRoleParams struct {
+type SetAccountMemberCustomRoleParams struct {
+	RoleID    sql.NullInt32
 	AccountID int32
 	UserID    int32
 }
 
-func (q *Queries) GetAccountMemberRole(ctx context.Context, arg GetAccountMemberRoleParams) (AccountMembersRole, error) {
-	row := q.db.QueryRowContext(ctx, getAccountMemberRole, arg.AccountID, arg.UserID)
-	var role AccountMembersRole
-	err := row.Scan(&role)
-	return role, err
+func (q *Queries) SetAccountMemberCustomRole(ctx context.Context, arg SetAccountMemberCustomRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setAccountMemberCustomRole, arg.RoleID, arg.AccountID, arg.UserID)
+	return err
 }
 
-const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT id, account_id, user_id, title, amount, occurred_at, period
-FROM transactions
+const setAccountOpeningBalance = `-- name: SetAccountOpeningBalance :exec
+UPDATE accounts
+SET opening_balance = ?
 WHERE id = ?
 `
 
-func (q *Queries) GetTransactionByID(ctx context.Context, id int32) (Transaction, error) {
-	row := q.db.QueryRowContext(ctx, getTransactionByID, id)
-	var i Transaction
-	err := row.Scan(
-		&i.ID,
-		&i.AccountID,
-		&i.UserID,
-		&i.Title,
-		&i.Amount,
-		&i.OccurredAt,
-		&i.Period,
-	)
-	return i, err
+type SetAccountOpeningBalanceParams struct {
+	OpeningBalance money.Amount
+	ID             int32
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash
-FROM users
-WHERE email = ?
-LIMIT 1
+func (q *Queries) SetAccountOpeningBalance(ctx context.Context, arg SetAccountOpeningBalanceParams) error {
+	_, err := q.db.ExecContext(ctx, setAccountOpeningBalance, arg.OpeningBalance, arg.ID)
+	return err
+}
+
+const setAccountOwner = `-- name: SetAccountOwner :exec
+UPDATE accounts
+SET owner_id = ?, name = ?
+WHERE id = ?
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
-	var i User
-	err := row.Scan(&i.ID, &i.Email, &i.PasswordHash)
-	return i, err
+type SetAccountOwnerParams struct {
+	OwnerID int32
+	Name    string
+	ID      int32
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, email
-FROM users
+func (q *Queries) SetAccountOwner(ctx context.Context, arg SetAccountOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setAccountOwner, arg.OwnerID, arg.Name, arg.ID)
+	return err
+}
+
+const setInvitationStatus = `-- name: SetInvitationStatus :execresult
+UPDATE invitations
+SET status = ?, responded_at = ?
+WHERE id = ? AND status = 'pending'
+`
+
+type SetInvitationStatusParams struct {
+	Status      InvitationsStatus
+	RespondedAt sql.NullTime
+	ID          int32
+}
+
+func (q *Queries) SetInvitationStatus(ctx context.Context, arg SetInvitationStatusParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, setInvitationStatus, arg.Status, arg.RespondedAt, arg.ID)
+}
+
+const setRecurringRulePaused = `-- name: SetRecurringRulePaused :exec
+UPDATE recurring_rules
+SET paused = ?, next_index = ?, next_occurrence_at = ?, occurrences_count = ?
 WHERE id = ?
-LIMIT 1
 `
 
-type GetUserByIDRow struct {
-	ID    int32
-	Email string
+type SetRecurringRulePausedParams struct {
+	Paused           bool
+	NextIndex        int32
+	NextOccurrenceAt time.Time
+	OccurrencesCount int32
+	ID               int32
 }
 
-func (q *Queries) GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i GetUserByIDRow
-	err := row.Scan(&i.ID, &i.Email)
-	return i, err
+func (q *Queries) SetRecurringRulePaused(ctx context.Context, arg SetRecurringRulePausedParams) error {
+	_, err := q.db.ExecContext(ctx, setRecurringRulePaused,
+		arg.Paused,
+		arg.NextIndex,
+		arg.NextOccurrenceAt,
+		arg.OccurrencesCount,
+		arg.ID,
+	)
+	return err
 }
 
-const listAccountMembers = `-- name: ListAccountMembers :many
-SELECT am.user_id, u.email, am.role
-FROM account_members am
-JOIN users u ON u.id = am.user_id
+const summarizeAccountTransactions = `-- name: SummarizeAccountTransactions :many
+SELECT
+    currency,
+    COUNT(*) AS transactions_count,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense,
+    CAST(COALESCE(SUM(amount), 0) AS DECIMAL(20,2)) AS total
+FROM transactions
 WHERE account_id = ?
+    AND (? IS NULL OR occurred_at >= ?)
+    AND occurred_at <= ?
+GROUP BY currency
+ORDER BY currency
 `
 
-type ListAccountMembersRow struct {
-	UserID int32
-	Email  string
-	Role   AccountMembersRole
+type SummarizeAccountTransactionsParams struct {
+	AccountID int32
+	DateFrom  sql.NullTime
+	DateTo    time.Time
 }
 
-func (q *Queries) ListAccountMembers(ctx context.Context, accountID int32) ([]ListAccountMembersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+type SummarizeAccountTransactionsRow struct {
+	Currency          money.Currency
+	TransactionsCount int64
+	Income            money.Amount
+	Expense           money.Amount
+	Total             money.Amount
+}
+
+func (q *Queries) SummarizeAccountTransactions(ctx context.Context, arg SummarizeAccountTransactionsParams) ([]SummarizeAccountTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, summarizeAccountTransactions,
+		arg.AccountID,
+		arg.DateFrom,
+		arg.DateFrom,
+		arg.DateTo,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListAccountMembersRow
+	var items []SummarizeAccountTransactionsRow
 	for rows.Next() {
-		var i ListAccountMembersRow
-		if err := rows.Scan(&i.UserID, &i.Email, &i.Role); err != nil {
+		var i SummarizeAccountTransactionsRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.TransactionsCount,
+			&i.Income,
+			&i.Expense,
+			&i.Total,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -249,64 +2915,52 @@ func (q *Queries) ListAccountMembers(ctx context.Context, accountID int32) ([]Li
 	return items, nil
 }
 
-const listTransactions = `-- name: ListTransactions :many
-SELECT id, account_id, user_id, title, amount, occurred_at, period
+const summarizeAccountTransactionsByDay = `-- name: SummarizeAccountTransactionsByDay :many
+SELECT
+    currency,
+    DATE(occurred_at) AS day,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense
 FROM transactions
 WHERE account_id = ?
-    AND (? IS NULL OR user_id = ?)
-
     AND (? IS NULL OR occurred_at >= ?)
-    AND (? IS NULL OR occurred_at <= ?)
-
-    AND (
-        ? IS NULL
-        OR (? = 'income' AND amount > 0)
-        OR (? = 'expense' AND amount < 0)
-    )
-ORDER BY occurred_at DESC
+    AND occurred_at <= ?
+GROUP BY currency, DATE(occurred_at)
+ORDER BY currency, day
 `
 
-type ListTransactionsParams struct {
-	AccountID    int32
-	Column2      interface{}
-	UserID       int32
-	Column4      interface{}
-	OccurredAt   time.Time
-	Column6      interface{}
-	OccurredAt_2 time.Time
-	Column8      interface{}
-	Column9      interface{}
-	Column10     interface{}
-}
-
-func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listTransactions,
+type SummarizeAccountTransactionsByDayParams struct {
+	AccountID int32
+	DateFrom  sql.NullTime
+	DateTo    time.Time
+}
+
+type SummarizeAccountTransactionsByDayRow struct {
+	Currency money.Currency
+	Day      time.Time
+	Income   money.Amount
+	Expense  money.Amount
+}
+
+func (q *Queries) SummarizeAccountTransactionsByDay(ctx context.Context, arg SummarizeAccountTransactionsByDayParams) ([]SummarizeAccountTransactionsByDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, summarizeAccountTransactionsByDay,
 		arg.AccountID,
-		arg.Column2,
-		arg.UserID,
-		arg.Column4,
-		arg.OccurredAt,
-		arg.Column6,
-		arg.OccurredAt_2,
-		arg.Column8,
-		arg.Column9,
-		arg.Column10,
+		arg.DateFrom,
+		arg.DateFrom,
+		arg.DateTo,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Transaction
+	var items []SummarizeAccountTransactionsByDayRow
 	for rows.Next() {
-		var i Transaction
+		var i SummarizeAccountTransactionsByDayRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.AccountID,
-			&i.UserID,
-			&i.Title,
-			&i.Amount,
-			&i.OccurredAt,
-			&i.Period,
+			&i.Currency,
+			&i.Day,
+			&i.Income,
+			&i.Expense,
 		); err != nil {
 			return nil, err
 		}
@@ -321,38 +2975,63 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 	return items, nil
 }
 
-const listUserAccounts = `-- name: ListUserAccounts :many
+const summarizeAccountsTransactions = `-- name: SummarizeAccountsTransactions :many
 SELECT
-    a.id,
-    a.name,
-    a.description,
-    am.role
-FROM account_members am
-JOIN accounts a ON a.id = am.account_id
-WHERE am.user_id = ?
+    currency,
+    COUNT(*) AS transactions_count,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense,
+    CAST(COALESCE(SUM(amount), 0) AS DECIMAL(20,2)) AS total
+FROM transactions
+WHERE account_id IN (/*SLICE:account_ids*/?)
+    AND (? IS NULL OR occurred_at >= ?)
+    AND occurred_at <= ?
+GROUP BY currency
+ORDER BY currency
 `
 
-type ListUserAccountsRow struct {
-	ID          int32
-	Name        string
-	Description sql.NullString
-	Role        AccountMembersRole
+type SummarizeAccountsTransactionsParams struct {
+	AccountIds []int32
+	DateFrom   sql.NullTime
+	DateTo     time.Time
 }
 
-func (q *Queries) ListUserAccounts(ctx context.Context, userID int32) ([]ListUserAccountsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listUserAccounts, userID)
+type SummarizeAccountsTransactionsRow struct {
+	Currency          money.Currency
+	TransactionsCount int64
+	Income            money.Amount
+	Expense           money.Amount
+	Total             money.Amount
+}
+
+func (q *Queries) SummarizeAccountsTransactions(ctx context.Context, arg SummarizeAccountsTransactionsParams) ([]SummarizeAccountsTransactionsRow, error) {
+	query := summarizeAccountsTransactions
+	var queryParams []interface{}
+	if len(arg.AccountIds) > 0 {
+		for _, v := range arg.AccountIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:account_ids*/?", strings.Repeat(",?", len(arg.AccountIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:account_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.DateFrom)
+	queryParams = append(queryParams, arg.DateFrom)
+	queryParams = append(queryParams, arg.DateTo)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListUserAccountsRow
+	var items []SummarizeAccountsTransactionsRow
 	for rows.Next() {
-		var i ListUserAccountsRow
+		var i SummarizeAccountsTransactionsRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.Role,
+			&i.Currency,
+			&i.TransactionsCount,
+			&i.Income,
+			&i.Expense,
+			&i.Total,
 		); err != nil {
 			return nil, err
 		}
@@ -367,24 +3046,107 @@ func (q *Queries) ListUserAccounts(ctx context.Context, userID int32) ([]ListUse
 	return items, nil
 }
 
-const removeAccountMember = `-- name: RemoveAccountMember :exec
-DELETE FROM account_members
-WHERE account_id = ? AND user_id = ?
+const summarizeAccountsTransactionsByDay = `-- name: SummarizeAccountsTransactionsByDay :many
+SELECT
+    currency,
+    DATE(occurred_at) AS day,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense
+FROM transactions
+WHERE account_id IN (/*SLICE:account_ids*/?)
+    AND (? IS NULL OR occurred_at >= ?)
+    AND occurred_at <= ?
+GROUP BY currency, DATE(occurred_at)
+ORDER BY currency, day
 `
 
-type RemoveAccountMemberParams struct {
-	AccountID int32
-	UserID    int32
+type SummarizeAccountsTransactionsByDayParams struct {
+	AccountIds []int32
+	DateFrom   sql.NullTime
+	DateTo     time.Time
 }
 
-func (q *Queries) RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) error {
-	_, err := q.db.ExecContext(ctx, removeAccountMember, arg.AccountID, arg.UserID)
+type SummarizeAccountsTransactionsByDayRow struct {
+	Currency money.Currency
+	Day      time.Time
+	Income   money.Amount
+	Expense  money.Amount
+}
+
+func (q *Queries) SummarizeAccountsTransactionsByDay(ctx context.Context, arg SummarizeAccountsTransactionsByDayParams) ([]SummarizeAccountsTransactionsByDayRow, error) {
+	query := summarizeAccountsTransactionsByDay
+	var queryParams []interface{}
+	if len(arg.AccountIds) > 0 {
+		for _, v := range arg.AccountIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:account_ids*/?", strings.Repeat(",?", len(arg.AccountIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:account_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.DateFrom)
+	queryParams = append(queryParams, arg.DateFrom)
+	queryParams = append(queryParams, arg.DateTo)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SummarizeAccountsTransactionsByDayRow
+	for rows.Next() {
+		var i SummarizeAccountsTransactionsByDayRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.Day,
+			&i.Income,
+			&i.Expense,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tagRuleOccurrencesFrom = `-- name: TagRuleOccurrencesFrom :exec
+INSERT IGNORE INTO transaction_tags (transaction_id, tag_id)
+SELECT t.id, rt.tag_id
+FROM transactions t
+JOIN recurring_rule_tags rt ON rt.rule_id = t.rule_id
+WHERE t.rule_id = ?
+    AND t.occurred_at >= ?
+`
+
+type TagRuleOccurrencesFromParams struct {
+	RuleID     sql.NullInt32
+	OccurredAt time.Time
+}
+
+func (q *Queries) TagRuleOccurrencesFrom(ctx context.Context, arg TagRuleOccurrencesFromParams) error {
+	_, err := q.db.ExecContext(ctx, tagRuleOccurrencesFrom, arg.RuleID, arg.OccurredAt)
+	return err
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen_at = CURRENT_TIMESTAMP
+WHERE id = ? AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL 1 MINUTE
+`
+
+func (q *Queries) TouchSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, touchSession, id)
 	return err
 }
 
 const updateAccountMemberRole = `-- name: UpdateAccountMemberRole :exec
 UPDATE account_members
-SET role = ?
+SET role = ?, role_id = NULL
 WHERE account_id = ? AND user_id = ?
 `
 
@@ -399,6 +3161,98 @@ func (q *Queries) UpdateAccountMemberRole(ctx context.Context, arg UpdateAccount
 	return err
 }
 
+const updateCategory = `-- name: UpdateCategory :exec
+UPDATE categories
+SET parent_id = ?, name = ?
+WHERE id = ?
+`
+
+type UpdateCategoryParams struct {
+	ParentID sql.NullInt32
+	Name     string
+	ID       int32
+}
+
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategory, arg.ParentID, arg.Name, arg.ID)
+	return err
+}
+
+const updateRecurringRule = `-- name: UpdateRecurringRule :exec
+UPDATE recurring_rules
+SET title = ?,
+    amount = ?,
+    period = ?,
+    interval_count = ?,
+    starts_at = ?,
+    ends_at = ?,
+    max_occurrences = ?,
+    category_id = ?,
+    notes = ?,
+    next_index = ?,
+    next_occurrence_at = ?,
+    occurrences_count = ?
+WHERE id = ?
+`
+
+type UpdateRecurringRuleParams struct {
+	Title            string
+	Amount           money.Amount
+	Period           RecurringRulesPeriod
+	IntervalCount    int32
+	StartsAt         time.Time
+	EndsAt           sql.NullTime
+	MaxOccurrences   sql.NullInt32
+	CategoryID       sql.NullInt32
+	Notes            sql.NullString
+	NextIndex        int32
+	NextOccurrenceAt time.Time
+	OccurrencesCount int32
+	ID               int32
+}
+
+func (q *Queries) UpdateRecurringRule(ctx context.Context, arg UpdateRecurringRuleParams) error {
+	_, err := q.db.ExecContext(ctx, updateRecurringRule,
+		arg.Title,
+		arg.Amount,
+		arg.Period,
+		arg.IntervalCount,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.MaxOccurrences,
+		arg.CategoryID,
+		arg.Notes,
+		arg.NextIndex,
+		arg.NextOccurrenceAt,
+		arg.OccurrencesCount,
+		arg.ID,
+	)
+	return err
+}
+
+const updateRecurringRuleProgress = `-- name: UpdateRecurringRuleProgress :exec
+UPDATE recurring_rules
+SET next_index = ?, next_occurrence_at = ?, occurrences_count = ?
+WHERE id = ?
+`
+
+type UpdateRecurringRuleProgressParams struct {
+	NextIndex        int32
+	NextOccurrenceAt time.Time
+	OccurrencesCount int32
+	ID               int32
+}
+
+func (q *Queries) UpdateRecurringRuleProgress(ctx context.Context, arg UpdateRecurringRuleProgressParams) error {
+	_, err := q.db.ExecContext(ctx, updateRecurringRuleProgress,
+		arg.NextIndex,
+		arg.NextOccurrenceAt,
+		arg.OccurrencesCount,
+		arg.ID,
+	)
+	return err
+}
+
 const updateUserPassword = `-- name: UpdateUserPassword :execresult
 UPDATE users
 SET password_hash = ?
@@ -413,3 +3267,47 @@ type UpdateUserPasswordParams struct {
 func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (sql.Result, error) {
 	return q.db.ExecContext(ctx, updateUserPassword, arg.PasswordHash, arg.ID)
 }
+
+const upsertExchangeRate = `-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rates (user_id, base_currency, quote_currency, rate_date, rate)
+VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE rate = VALUES(rate)
+`
+
+type UpsertExchangeRateParams struct {
+	UserID        int32
+	BaseCurrency  money.Currency
+	QuoteCurrency money.Currency
+	RateDate      time.Time
+	Rate          string
+}
+
+func (q *Queries) UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertExchangeRate,
+		arg.UserID,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.RateDate,
+		arg.Rate,
+	)
+	return err
+}
+
+const upsertTag = `-- name: UpsertTag :execlastid
+INSERT INTO tags (account_id, name)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+`
+
+type UpsertTagParams struct {
+	AccountID int32
+	Name      string
+}
+
+func (q *Queries) UpsertTag(ctx context.Context, arg UpsertTagParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, upsertTag, arg.AccountID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
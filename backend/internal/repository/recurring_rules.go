@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+type RecurringRuleRepository struct {
+	queries *query.Queries
+}
+
+func newRecurringRuleRepository(db query.DBTX) *RecurringRuleRepository {
+	return &RecurringRuleRepository{queries: query.New(db)}
+}
+
+func (r *RecurringRuleRepository) Create(ctx context.Context, p *models.CreateRecurringRuleParams) (int, error) {
+	result, err := r.queries.CreateRecurringRule(ctx, query.CreateRecurringRuleParams{
+		AccountID:        int32(p.AccountID),
+		UserID:           sql.NullInt32{Int32: int32(p.UserID), Valid: true},
+		Title:            p.Title,
+		Amount:           p.Amount,
+		Currency:         p.Currency,
+		Period:           p.Period,
+		IntervalCount:    int32(p.Interval),
+		StartsAt:         p.StartsAt,
+		EndsAt:           toNullTime(p.EndsAt),
+		MaxOccurrences:   toNullInt32(p.MaxOccurrences),
+		NextOccurrenceAt: p.StartsAt,
+		CategoryID:       toNullInt32(p.CategoryID),
+		Notes:            toNullString(p.Notes),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (r *RecurringRuleRepository) GetByID(ctx context.Context, id int) (*query.RecurringRule, error) {
+	rule, err := r.queries.GetRecurringRuleByID(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// GetForUpdate получает правило с блокировкой строки до конца транзакции
+func (r *RecurringRuleRepository) GetForUpdate(ctx context.Context, id int) (*query.RecurringRule, error) {
+	rule, err := r.queries.GetRecurringRuleForUpdate(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *RecurringRuleRepository) ListForAccount(ctx context.Context, accountID int) ([]query.RecurringRule, error) {
+	return r.queries.ListAccountRecurringRules(ctx, int32(accountID))
+}
+
+// ListDueIDs возвращает правила, у которых есть несозданные вхождения до until
+func (r *RecurringRuleRepository) ListDueIDs(ctx context.Context, until time.Time) ([]int32, error) {
+	return r.queries.ListDueRecurringRuleIDs(ctx, until)
+}
+
+func (r *RecurringRuleRepository) ListDueIDsForAccount(ctx context.Context, accountID int, until time.Time) ([]int32, error) {
+	return r.queries.ListDueAccountRecurringRuleIDs(ctx, query.ListDueAccountRecurringRuleIDsParams{
+		AccountID:        int32(accountID),
+		NextOccurrenceAt: until,
+	})
+}
+
+// SetProgress сохраняет состояние материализации правила
+func (r *RecurringRuleRepository) SetProgress(
+	ctx context.Context,
+	id int,
+	nextIndex int,
+	nextAt time.Time,
+	count int,
+) error {
+
+	return r.queries.UpdateRecurringRuleProgress(ctx, query.UpdateRecurringRuleProgressParams{
+		NextIndex:        int32(nextIndex),
+		NextOccurrenceAt: nextAt,
+		OccurrencesCount: int32(count),
+		ID:               int32(id),
+	})
+}
+
+// Reset заменяет параметры правила и сбрасывает состояние материализации
+func (r *RecurringRuleRepository) Reset(
+	ctx context.Context,
+	rule *query.RecurringRule,
+	p *models.UpdateRecurringRuleParams,
+) error {
+
+	return r.queries.UpdateRecurringRule(ctx, query.UpdateRecurringRuleParams{
+		Title:            p.Title,
+		Amount:           p.Amount,
+		Period:           p.Period,
+		IntervalCount:    int32(p.Interval),
+		StartsAt:         p.StartsAt,
+		EndsAt:           toNullTime(p.EndsAt),
+		MaxOccurrences:   toNullInt32(p.MaxOccurrences),
+		CategoryID:       toNullInt32(p.CategoryID),
+		Notes:            toNullString(p.Notes),
+		NextIndex:        0,
+		NextOccurrenceAt: p.StartsAt,
+		OccurrencesCount: 0,
+		ID:               rule.ID,
+	})
+}
+
+// End завершает серию: вхождения после endsAt больше не создаются
+func (r *RecurringRuleRepository) End(ctx context.Context, id int, endsAt time.Time, count int) error {
+	return r.queries.EndRecurringRule(ctx, query.EndRecurringRuleParams{
+		EndsAt:           sql.NullTime{Time: endsAt, Valid: true},
+		OccurrencesCount: int32(count),
+		ID:               int32(id),
+	})
+}
+
+func (r *RecurringRuleRepository) SetPaused(
+	ctx context.Context,
+	id int,
+	paused bool,
+	nextIndex int,
+	nextAt time.Time,
+	count int,
+) error {
+
+	return r.queries.SetRecurringRulePaused(ctx, query.SetRecurringRulePausedParams{
+		Paused:           paused,
+		NextIndex:        int32(nextIndex),
+		NextOccurrenceAt: nextAt,
+		OccurrencesCount: int32(count),
+		ID:               int32(id),
+	})
+}
+
+func (r *RecurringRuleRepository) DeleteByID(ctx context.Context, id int) error {
+	return r.queries.DeleteRecurringRuleByID(ctx, int32(id))
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func toNullInt32(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+type RefreshTokenRepository struct {
+	queries *query.Queries
+}
+
+func newRefreshTokenRepository(db query.DBTX) *RefreshTokenRepository {
+	return &RefreshTokenRepository{queries: query.New(db)}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, params *models.GenerateToken) (int, error) {
+	result, err := r.queries.CreateRefreshToken(ctx, query.CreateRefreshTokenParams{
+		UserID:    int32(params.UserID),
+		FamilyID:  params.FamilyID,
+		TokenHash: params.TokenHash,
+		ExpiresAt: params.ExpiresAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*query.RefreshToken, error) {
+	token, err := r.queries.GetRefreshTokenByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Revoke отзывает токен. Возвращает false, если токен уже был отозван ранее
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id int) (bool, error) {
+	result, err := r.queries.RevokeRefreshToken(ctx, int32(id))
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.queries.RevokeRefreshTokenFamily(ctx, familyID)
+}
+
+func (r *RefreshTokenRepository) RevokeAllExcept(ctx context.Context, userID int, keepFamilyID string) error {
+	return r.queries.RevokeUserRefreshTokensExcept(ctx, query.RevokeUserRefreshTokensExceptParams{
+		UserID:   int32(userID),
+		FamilyID: keepFamilyID,
+	})
+}
@@ -1,21 +1,79 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+
 	"microservices/accounter/internal/repository/query"
 )
 
 type Repository struct {
+	db *sql.DB
+
 	UserRepo          *UserRepository
 	AccountRepo       *AccountRepository
 	AccountMemberRepo *AccountMemberRepository
 	TransactionRepo   *TransactionRepository
+	RefreshTokenRepo  *RefreshTokenRepository
+	SessionRepo       *SessionRepository
+	RecurringRuleRepo *RecurringRuleRepository
+	ExchangeRateRepo  *ExchangeRateRepository
+	CategoryRepo      *CategoryRepository
+	TagRepo           *TagRepository
+	DuplicateRepo     *DuplicateRepository
+	ArchiveRepo       *ArchiveRepository
+	UserDeletionRepo  *UserDeletionRepository
+	InvitationRepo    *InvitationRepository
+	InviteLinkRepo    *InviteLinkRepository
+	OwnershipRepo     *OwnershipTransferRepository
+	AccountRoleRepo   *AccountRoleRepository
 }
 
-func New(db query.DBTX) *Repository {
+func New(db *sql.DB) *Repository {
+	repo := newRepository(db)
+	repo.db = db
+
+	return repo
+}
+
+func newRepository(db query.DBTX) *Repository {
 	return &Repository{
 		UserRepo:          newUserRepository(db),
 		AccountRepo:       newAccountRepository(db),
 		AccountMemberRepo: newAccountMemberRepository(db),
 		TransactionRepo:   newTransactionRepository(db),
+		RefreshTokenRepo:  newRefreshTokenRepository(db),
+		SessionRepo:       newSessionRepository(db),
+		RecurringRuleRepo: newRecurringRuleRepository(db),
+		ExchangeRateRepo:  newExchangeRateRepository(db),
+		CategoryRepo:      newCategoryRepository(db),
+		TagRepo:           newTagRepository(db),
+		DuplicateRepo:     newDuplicateRepository(db),
+		ArchiveRepo:       newArchiveRepository(db),
+		UserDeletionRepo:  newUserDeletionRepository(db),
+		InvitationRepo:    newInvitationRepository(db),
+		InviteLinkRepo:    newInviteLinkRepository(db),
+		OwnershipRepo:     newOwnershipTransferRepository(db),
+		AccountRoleRepo:   newAccountRoleRepository(db),
 	}
 }
+
+// InTx выполняет fn в транзакции БД. Репозиторий, переданный в fn, работает внутри транзакции.
+// Если fn возвращает ошибку, транзакция откатывается. Вложенный вызов выполняется в уже открытой транзакции
+func (r *Repository) InTx(ctx context.Context, fn func(tx *Repository) error) error {
+	if r.db == nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(newRepository(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
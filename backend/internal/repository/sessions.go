@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository/query"
+)
+
+type SessionRepository struct {
+	queries *query.Queries
+}
+
+func newSessionRepository(db query.DBTX) *SessionRepository {
+	return &SessionRepository{queries: query.New(db)}
+}
+
+func (r *SessionRepository) Create(
+	ctx context.Context,
+	id string,
+	userID int,
+	client models.ClientInfo,
+	expiresAt time.Time,
+) error {
+
+	return r.queries.CreateSession(ctx, query.CreateSessionParams{
+		ID:        id,
+		UserID:    int32(userID),
+		UserAgent: client.UserAgent,
+		Ip:        client.IP,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*query.Session, error) {
+	session, err := r.queries.GetSessionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) ListActive(ctx context.Context, userID int) ([]query.Session, error) {
+	return r.queries.ListActiveUserSessions(ctx, int32(userID))
+}
+
+// Extend продлевает сессию при ротации refresh токена
+func (r *SessionRepository) Extend(ctx context.Context, id string, ip string, expiresAt time.Time) error {
+	return r.queries.ExtendSession(ctx, query.ExtendSessionParams{
+		Ip:        ip,
+		ExpiresAt: expiresAt,
+		ID:        id,
+	})
+}
+
+// Touch обновляет время последней активности не чаще раза в минуту
+func (r *SessionRepository) Touch(ctx context.Context, id string) error {
+	return r.queries.TouchSession(ctx, id)
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	return r.queries.RevokeSession(ctx, id)
+}
+
+func (r *SessionRepository) RevokeAllExcept(ctx context.Context, userID int, keepID string) error {
+	return r.queries.RevokeUserSessionsExcept(ctx, query.RevokeUserSessionsExceptParams{
+		UserID: int32(userID),
+		ID:     keepID,
+	})
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+type TagRepository struct {
+	queries *query.Queries
+}
+
+func newTagRepository(db query.DBTX) *TagRepository {
+	return &TagRepository{queries: query.New(db)}
+}
+
+// Ensure возвращает ID меток счёта по названиям, создавая недостающие
+func (r *TagRepository) Ensure(ctx context.Context, accountID int, names []string) ([]int32, error) {
+	ids := make([]int32, len(names))
+	for i, name := range names {
+		id, err := r.queries.UpsertTag(ctx, query.UpsertTagParams{
+			AccountID: int32(accountID),
+			Name:      name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = int32(id)
+	}
+
+	return ids, nil
+}
+
+// ListForAccount возвращает используемые метки счёта с числом транзакций
+func (r *TagRepository) ListForAccount(ctx context.Context, accountID int) ([]query.ListAccountTagsRow, error) {
+	return r.queries.ListAccountTags(ctx, int32(accountID))
+}
+
+// SetForTransaction заменяет метки транзакции
+func (r *TagRepository) SetForTransaction(ctx context.Context, transactionID int, tagIDs []int32) error {
+	if err := r.queries.DeleteTransactionTags(ctx, int32(transactionID)); err != nil {
+		return err
+	}
+
+	for _, tagID := range tagIDs {
+		err := r.queries.AddTransactionTag(ctx, query.AddTransactionTagParams{
+			TransactionID: int32(transactionID),
+			TagID:         tagID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForTransactions возвращает метки транзакций по их ID, метки отсортированы по названию
+func (r *TagRepository) ForTransactions(ctx context.Context, transactionIDs []int32) (map[int32][]string, error) {
+	tags := make(map[int32][]string)
+	if len(transactionIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := r.queries.ListTransactionsTags(ctx, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		tags[row.TransactionID] = append(tags[row.TransactionID], row.Name)
+	}
+
+	return tags, nil
+}
+
+// SetForRule заменяет метки правила повторения
+func (r *TagRepository) SetForRule(ctx context.Context, ruleID int, tagIDs []int32) error {
+	if err := r.queries.DeleteRecurringRuleTags(ctx, int32(ruleID)); err != nil {
+		return err
+	}
+
+	for _, tagID := range tagIDs {
+		err := r.queries.AddRecurringRuleTag(ctx, query.AddRecurringRuleTagParams{
+			RuleID: int32(ruleID),
+			TagID:  tagID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForRule возвращает названия меток правила повторения
+func (r *TagRepository) ForRule(ctx context.Context, ruleID int) ([]string, error) {
+	return r.queries.ListRecurringRuleTags(ctx, int32(ruleID))
+}
+
+// ForAccountRules возвращает метки всех правил повторения счёта по ID правила
+func (r *TagRepository) ForAccountRules(ctx context.Context, accountID int) (map[int32][]string, error) {
+	rows, err := r.queries.ListAccountRecurringRuleTags(ctx, int32(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[int32][]string)
+	for _, row := range rows {
+		tags[row.RuleID] = append(tags[row.RuleID], row.Name)
+	}
+
+	return tags, nil
+}
+
+// TagRuleOccurrencesFrom проставляет метки правила его вхождениям с датой не раньше from
+func (r *TagRepository) TagRuleOccurrencesFrom(ctx context.Context, ruleID int, from time.Time) error {
+	return r.queries.TagRuleOccurrencesFrom(ctx, query.TagRuleOccurrencesFromParams{
+		RuleID:     sql.NullInt32{Int32: int32(ruleID), Valid: true},
+		OccurredAt: from,
+	})
+}
+
+// RetagRuleOccurrences заменяет метки всех вхождений правила его текущими метками
+func (r *TagRepository) RetagRuleOccurrences(ctx context.Context, ruleID int) error {
+	if err := r.queries.DeleteRuleOccurrencesTags(ctx, sql.NullInt32{Int32: int32(ruleID), Valid: true}); err != nil {
+		return err
+	}
+
+	return r.TagRuleOccurrencesFrom(ctx, ruleID, time.Time{})
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,9 @@ import (
 	"microservices/accounter/internal/repository/query"
 )
 
+// insertBatchSize ограничивает размер одного INSERT, чтобы не упереться в лимит плейсхолдеров MySQL
+const insertBatchSize = 1000
+
 type TransactionRepository struct {
 	queries *query.Queries
 	db      query.DBTX
@@ -26,11 +30,14 @@ func newTransactionRepository(db query.DBTX) *TransactionRepository {
 func (r *TransactionRepository) CreateTransaction(ctx context.Context, p *models.CreateTransactionParams) (int, error) {
 	result, err := r.queries.CreateTransaction(ctx, query.CreateTransactionParams{
 		AccountID:  int32(p.AccountID),
-		UserID:     int32(p.UserID),
+		UserID:     sql.NullInt32{Int32: int32(p.UserID), Valid: true},
 		Title:      p.Title,
 		Amount:     p.Amount,
+		Currency:   p.Currency,
 		OccurredAt: p.OccurredAt,
 		Period:     p.Period,
+		CategoryID: toNullInt32(p.CategoryID),
+		Notes:      toNullString(p.Notes),
 	})
 	if err != nil {
 		return 0, err
@@ -44,46 +51,213 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, p *models
 	return int(id), nil
 }
 
-// CreatePeriodicTransactions создаёт серию периодических транзакций (500 штук)
-func (r *TransactionRepository) CreatePeriodicTransactions(
+// CreateOccurrences создаёт транзакции-вхождения правила повторения на указанные даты одним запросом
+func (r *TransactionRepository) CreateOccurrences(
 	ctx context.Context,
-	p *models.CreateTransactionParams,
-	count int,
-) (int, error) {
-	currentDate := p.OccurredAt
+	rule *query.RecurringRule,
+	dates []time.Time,
+) error {
+	for len(dates) > insertBatchSize {
+		if err := r.CreateOccurrences(ctx, rule, dates[:insertBatchSize]); err != nil {
+			return err
+		}
+		dates = dates[insertBatchSize:]
+	}
 
-	id, err := r.CreateTransaction(ctx, p)
-	if err != nil {
-		return 0, err
+	if len(dates) == 0 {
+		return nil
 	}
 
-	values := make([]interface{}, 0, count*6)
-	placeholders := make([]string, 0, count)
-	currentDate = calculateNextDate(currentDate, p.Period.TransactionsPeriod)
+	values := make([]any, 0, len(dates)*10)
+	placeholders := make([]string, 0, len(dates))
 
-	for i := 0; i < count-1; i++ {
-		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+	for _, date := range dates {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		values = append(values,
+			rule.AccountID,
+			rule.UserID,
+			rule.Title,
+			rule.Amount,
+			rule.Currency,
+			date,
+			string(rule.Period),
+			rule.ID,
+			rule.CategoryID,
+			rule.Notes,
+		)
+	}
+
+	sql := fmt.Sprintf(
+		`INSERT INTO transactions (account_id, user_id, title, amount, currency, occurred_at, period, rule_id, category_id, notes)
+         VALUES %s`,
+		strings.Join(placeholders, ", "),
+	)
+
+	_, err := r.db.ExecContext(ctx, sql, values...)
+
+	return err
+}
+
+// CreateTransactions создаёт обычные транзакции одним запросом на каждые insertBatchSize транзакций
+func (r *TransactionRepository) CreateTransactions(ctx context.Context, params []models.CreateTransactionParams) error {
+	for len(params) > insertBatchSize {
+		if err := r.CreateTransactions(ctx, params[:insertBatchSize]); err != nil {
+			return err
+		}
+		params = params[insertBatchSize:]
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	values := make([]any, 0, len(params)*9)
+	placeholders := make([]string, 0, len(params))
+
+	for _, p := range params {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
 		values = append(values,
 			p.AccountID,
 			p.UserID,
 			p.Title,
 			p.Amount,
-			currentDate,
-			p.Period.TransactionsPeriod,
+			p.Currency,
+			p.OccurredAt,
+			toNullInt32(p.CategoryID),
+			toNullString(p.Notes),
+			toNullString(p.ExternalRef),
 		)
-		currentDate = calculateNextDate(currentDate, p.Period.TransactionsPeriod)
 	}
 
-	// Один SQL запрос
 	sql := fmt.Sprintf(
-		`INSERT INTO transactions (account_id, user_id, title, amount, occurred_at, period)
+		`INSERT INTO transactions (account_id, user_id, title, amount, currency, occurred_at, category_id, notes, external_ref)
          VALUES %s`,
 		strings.Join(placeholders, ", "),
 	)
 
-	_, err = r.db.ExecContext(ctx, sql, values...)
+	_, err := r.db.ExecContext(ctx, sql, values...)
+
+	return err
+}
+
+// ExistingExternalRefs возвращает те из идентификаторов выписки refs, с которыми
+// в счёте уже есть транзакции
+func (r *TransactionRepository) ExistingExternalRefs(ctx context.Context, accountID int, refs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+
+	for len(refs) > 0 {
+		batch := refs[:min(len(refs), insertBatchSize)]
+		refs = refs[len(batch):]
+
+		values := make([]sql.NullString, len(batch))
+		for i, ref := range batch {
+			values[i] = sql.NullString{String: ref, Valid: true}
+		}
+
+		rows, err := r.queries.ListTransactionExternalRefs(ctx, query.ListTransactionExternalRefsParams{
+			AccountID:    int32(accountID),
+			ExternalRefs: values,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range rows {
+			existing[ref.String] = true
+		}
+	}
+
+	return existing, nil
+}
+
+// FirstRuleOccurrenceID возвращает ID самого раннего вхождения правила
+func (r *TransactionRepository) FirstRuleOccurrenceID(ctx context.Context, ruleID int) (int, error) {
+	id, err := r.queries.GetFirstRuleOccurrenceID(ctx, sql.NullInt32{Int32: int32(ruleID), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// CountRuleOccurrencesBefore возвращает число вхождений правила с датой раньше before
+func (r *TransactionRepository) CountRuleOccurrencesBefore(ctx context.Context, ruleID int, before time.Time) (int, error) {
+	count, err := r.queries.CountRuleOccurrencesBefore(ctx, query.CountRuleOccurrencesBeforeParams{
+		RuleID:     sql.NullInt32{Int32: int32(ruleID), Valid: true},
+		OccurredAt: before,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// DeleteRuleOccurrencesFrom удаляет вхождения правила начиная с даты from и возвращает их количество
+func (r *TransactionRepository) DeleteRuleOccurrencesFrom(ctx context.Context, ruleID int, from time.Time) (int, error) {
+	result, err := r.queries.DeleteRuleOccurrencesFrom(ctx, query.DeleteRuleOccurrencesFromParams{
+		RuleID:     sql.NullInt32{Int32: int32(ruleID), Valid: true},
+		OccurredAt: from,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rows), nil
+}
+
+// UpdateRuleOccurrences меняет на месте все вхождения правила: ID вхождений и связанные
+// с ними записи сохраняются
+func (r *TransactionRepository) UpdateRuleOccurrences(
+	ctx context.Context,
+	ruleID int,
+	params *models.UpdateRuleOccurrencesParams,
+) error {
+	var (
+		set  []string
+		args []any
+	)
+
+	if params.Title != nil {
+		set = append(set, "title = ?")
+		args = append(args, *params.Title)
+	}
+
+	if params.Amount != nil {
+		set = append(set, "amount = ?")
+		args = append(args, *params.Amount)
+	}
 
-	return id, nil
+	if params.SetCategory {
+		set = append(set, "category_id = ?")
+		args = append(args, toNullInt32(params.CategoryID))
+	}
+
+	if params.SetNotes {
+		set = append(set, "notes = ?")
+		args = append(args, toNullString(params.Notes))
+	}
+
+	if params.Shift != 0 {
+		set = append(set, "occurred_at = occurred_at + INTERVAL ? MICROSECOND")
+		args = append(args, params.Shift.Microseconds())
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	sql := "UPDATE transactions SET " + strings.Join(set, ", ") + " WHERE rule_id = ?"
+	args = append(args, ruleID)
+
+	_, err := r.db.ExecContext(ctx, sql, args...)
+
+	return err
 }
 
 // GetByID получает транзакцию по ID
@@ -95,6 +269,14 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id int32) (*query.T
 	return &transaction, nil
 }
 
+// ListByIDs возвращает транзакции по их ID
+func (r *TransactionRepository) ListByIDs(ctx context.Context, ids []int32) ([]query.Transaction, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.queries.ListTransactionsByIDs(ctx, ids)
+}
+
 // UpdateTransaction обновляет поля транзакции
 func (r *TransactionRepository) UpdateTransaction(
 	ctx context.Context,
@@ -103,7 +285,7 @@ func (r *TransactionRepository) UpdateTransaction(
 ) error {
 	sql := `
 		UPDATE transactions
-		SET title = ?, amount = ?, occurred_at = ?
+		SET title = ?, amount = ?, occurred_at = ?, category_id = ?, notes = ?
 		WHERE id = ?
 	`
 
@@ -111,57 +293,343 @@ func (r *TransactionRepository) UpdateTransaction(
 		params.Title,
 		params.Amount,
 		params.OccurredAt,
+		toNullInt32(params.CategoryID),
+		toNullString(params.Notes),
 		id,
 	)
 
 	return err
 }
 
-// List возвращает список транзакций с фильтрацией
+// transactionSortColumns - колонки, по которым разрешена сортировка списка транзакций.
+// Имя колонки подставляется в SQL только из этой таблицы
+var transactionSortColumns = map[models.TransactionSort]string{
+	models.SortByOccurredAt: "t.occurred_at",
+	models.SortByAmount:     "t.amount",
+	models.SortByTitle:      "t.title",
+}
+
+// List возвращает страницу списка транзакций с фильтрацией
 func (r *TransactionRepository) List(ctx context.Context, f *models.ListTransactionsFilter) ([]query.Transaction, error) {
-	// Подготовка параметров для запроса
-	var userIDParam interface{}
-	var userIDValue int32
-	if f.UserID != nil {
-		userIDParam = *f.UserID
-		userIDValue = int32(*f.UserID)
+	w := transactionFilter(f)
+	order, orderArgs := transactionOrder(f)
+
+	sql := `
+		SELECT
+			t.id, t.account_id, t.user_id, t.title, t.amount,
+			t.occurred_at, t.period, t.rule_id, t.currency, t.category_id, t.notes
+		FROM transactions t
+		WHERE ` + w.SQL() + `
+		ORDER BY ` + order
+
+	rows, err := r.db.QueryContext(ctx, sql, append(w.Args(), orderArgs...)...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	var dateFromParam interface{}
-	var dateFromValue time.Time
-	if f.DateFrom != nil {
-		dateFromParam = *f.DateFrom
-		dateFromValue = *f.DateFrom
+	var items []query.Transaction
+	for rows.Next() {
+		var i query.Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.UserID,
+			&i.Title,
+			&i.Amount,
+			&i.OccurredAt,
+			&i.Period,
+			&i.RuleID,
+			&i.Currency,
+			&i.CategoryID,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	var dateToParam interface{}
-	var dateToValue time.Time
+	return items, nil
+}
+
+// ListWithBalance возвращает страницу списка транзакций с фильтрацией и остатком счёта
+// после каждой из них. Остаток считается оконной функцией по всем транзакциям счёта
+// в порядке (occurred_at, id), поэтому фильтры и сортировка не влияют на его значение
+func (r *TransactionRepository) ListWithBalance(
+	ctx context.Context,
+	f *models.ListTransactionsFilter,
+) ([]models.TransactionWithBalance, error) {
+
+	w := transactionFilter(f)
+	order, orderArgs := transactionOrder(f)
+
+	sql := `
+		SELECT
+			t.id, t.account_id, t.user_id, t.title, t.amount,
+			t.occurred_at, t.period, t.rule_id, t.currency, t.category_id, t.notes,
+			a.opening_balance + t.cumulative AS running_balance
+		FROM (
+			SELECT
+				transactions.*,
+				SUM(amount) OVER (ORDER BY occurred_at, id) AS cumulative
+			FROM transactions
+			WHERE account_id = ?
+		) t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE ` + w.SQL() + `
+		ORDER BY ` + order
+
+	args := append([]any{f.AccountID}, w.Args()...)
+
+	rows, err := r.db.QueryContext(ctx, sql, append(args, orderArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.TransactionWithBalance
+	for rows.Next() {
+		var i models.TransactionWithBalance
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.UserID,
+			&i.Title,
+			&i.Amount,
+			&i.OccurredAt,
+			&i.Period,
+			&i.RuleID,
+			&i.Currency,
+			&i.CategoryID,
+			&i.Notes,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Export передаёт в fn транзакции, подходящие под фильтр, по одной по мере чтения из БД,
+// не собирая их в памяти. Limit и курсор фильтра не учитываются
+func (r *TransactionRepository) Export(
+	ctx context.Context,
+	f *models.ListTransactionsFilter,
+	fn func(row *models.ExportRow) error,
+) error {
+
+	all := *f
+	all.Limit = 0
+	all.After = nil
+
+	w := transactionFilter(&all)
+	order, orderArgs := transactionOrder(&all)
+
+	statement := `
+		SELECT
+			t.id, t.occurred_at, t.title, t.amount, t.currency,
+			a.name, t.category_id, t.notes, u.email, t.rule_id,
+			(
+				SELECT GROUP_CONCAT(g.name ORDER BY g.name SEPARATOR ',')
+				FROM transaction_tags tt
+				JOIN tags g ON g.id = tt.tag_id
+				WHERE tt.transaction_id = t.id
+			) AS tags
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		LEFT JOIN users u ON u.id = t.user_id
+		WHERE ` + w.SQL() + `
+		ORDER BY ` + order
+
+	rows, err := r.db.QueryContext(ctx, statement, append(w.Args(), orderArgs...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row        models.ExportRow
+			categoryID sql.NullInt32
+			notes      sql.NullString
+			email      sql.NullString
+			ruleID     sql.NullInt32
+			tags       sql.NullString
+		)
+
+		if err := rows.Scan(
+			&row.ID,
+			&row.OccurredAt,
+			&row.Title,
+			&row.Amount,
+			&row.Currency,
+			&row.Account,
+			&categoryID,
+			&notes,
+			&email,
+			&ruleID,
+			&tags,
+		); err != nil {
+			return err
+		}
+
+		if categoryID.Valid {
+			row.CategoryID = &categoryID.Int32
+		}
+		if notes.Valid {
+			row.Notes = &notes.String
+		}
+		if ruleID.Valid {
+			row.RuleID = &ruleID.Int32
+		}
+		// Названия меток не содержат запятых
+		if tags.Valid {
+			row.Tags = strings.Split(tags.String, ",")
+		}
+		row.MemberEmail = email.String
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// transactionFilter возвращает условие WHERE для фильтра списка транзакций (таблица под псевдонимом t).
+// Условие включает только заданные фильтры и начало страницы после курсора
+func transactionFilter(f *models.ListTransactionsFilter) *where {
+	w := &where{}
+
+	w.add("t.account_id = ?", f.AccountID)
+
+	if f.UserID != nil {
+		w.add("t.user_id = ?", *f.UserID)
+	}
+	if f.DateFrom != nil {
+		w.add("t.occurred_at >= ?", *f.DateFrom)
+	}
 	if f.DateTo != nil {
-		dateToParam = *f.DateTo
-		dateToValue = *f.DateTo
+		w.add("t.occurred_at <= ?", *f.DateTo)
 	}
 
-	var typeParam interface{}
-	var typeValue1 interface{}
-	var typeValue2 interface{}
 	if f.Type != nil {
-		typeParam = *f.Type
-		typeValue1 = *f.Type
-		typeValue2 = *f.Type
-	}
-
-	return r.queries.ListTransactions(ctx, query.ListTransactionsParams{
-		AccountID:    int32(f.AccountID),
-		Column2:      userIDParam,
-		UserID:       userIDValue,
-		Column4:      dateFromParam,
-		OccurredAt:   dateFromValue,
-		Column6:      dateToParam,
-		OccurredAt_2: dateToValue,
-		Column8:      typeParam,
-		Column9:      typeValue1,
-		Column10:     typeValue2,
-	})
+		switch *f.Type {
+		case "income":
+			w.add("t.amount > 0")
+		case "expense":
+			w.add("t.amount < 0")
+		}
+	}
+
+	if f.AmountMin != nil {
+		w.add("t.amount >= ?", *f.AmountMin)
+	}
+	if f.AmountMax != nil {
+		w.add("t.amount <= ?", *f.AmountMax)
+	}
+
+	if f.Search != "" {
+		pattern := containsPattern(f.Search)
+		w.add("(t.title LIKE ? ESCAPE '!' OR t.notes LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+
+	if f.CategoryIDs != nil {
+		ids := make([]any, len(f.CategoryIDs))
+		for i, id := range f.CategoryIDs {
+			ids[i] = id
+		}
+		w.in("t.category_id", ids)
+	}
+
+	// Транзакция подходит, если у неё есть хотя бы одна метка фильтра или, для all, все
+	if f.Tags != nil && len(f.Tags.Names) > 0 {
+		names := make([]any, len(f.Tags.Names))
+		for i, name := range f.Tags.Names {
+			names[i] = name
+		}
+
+		minMatches := 1
+		if f.Tags.All {
+			minMatches = len(f.Tags.Names)
+		}
+
+		w.add(`(
+				SELECT COUNT(*)
+				FROM transaction_tags tt
+				JOIN tags g ON g.id = tt.tag_id
+				WHERE tt.transaction_id = t.id
+					AND g.name IN (`+placeholders(len(names))+`)
+			) >= ?`, append(names, minMatches)...)
+	}
+
+	// Запланированные вхождения серий: те, что ещё не наступили
+	if f.PlannedAfter != nil {
+		w.add("(t.rule_id IS NULL OR t.occurred_at <= ?)", *f.PlannedAfter)
+	}
+
+	if f.After != nil {
+		column, cmp := transactionSortColumn(f)
+
+		var value any
+		switch f.After.Sort {
+		case models.SortByAmount:
+			value = f.After.Amount
+		case models.SortByTitle:
+			value = f.After.Title
+		default:
+			value = f.After.OccurredAt
+		}
+
+		w.add(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND t.id %[2]s ?))", column, cmp),
+			value, value, f.After.ID)
+	}
+
+	return w
+}
+
+// transactionOrder возвращает ORDER BY и LIMIT страницы списка транзакций.
+// Сортировка всегда дополняется ID, поэтому страницы не пересекаются и не теряют строки
+func transactionOrder(f *models.ListTransactionsFilter) (string, []any) {
+	column, _ := transactionSortColumn(f)
+
+	direction := "ASC"
+	if f.Desc {
+		direction = "DESC"
+	}
+
+	order := fmt.Sprintf("%[1]s %[2]s, t.id %[2]s", column, direction)
+
+	if f.Limit > 0 {
+		return order + `
+		LIMIT ?`, []any{f.Limit}
+	}
+
+	return order, nil
+}
+
+// transactionSortColumn возвращает колонку сортировки и сравнение, которым
+// отбираются транзакции после курсора
+func transactionSortColumn(f *models.ListTransactionsFilter) (string, string) {
+	column, ok := transactionSortColumns[f.Sort]
+	if !ok {
+		column = transactionSortColumns[models.SortByOccurredAt]
+	}
+
+	if f.Desc {
+		return column, "<"
+	}
+	return column, ">"
 }
 
 // DeleteByID удаляет транзакцию по ID
@@ -169,18 +637,126 @@ func (r *TransactionRepository) DeleteByID(ctx context.Context, id int) error {
 	return r.queries.DeleteTransactionByID(ctx, int32(id))
 }
 
-// calculateNextDate вычисляет следующую дату для периодической транзакции
-func calculateNextDate(current time.Time, period query.TransactionsPeriod) time.Time {
-	switch period {
-	case query.TransactionsPeriodDay:
-		return current.AddDate(0, 0, 1)
-	case query.TransactionsPeriodWeek:
-		return current.AddDate(0, 0, 7)
-	case query.TransactionsPeriodMonth:
-		return current.AddDate(0, 1, 0)
-	case query.TransactionsPeriodYear:
-		return current.AddDate(1, 0, 0)
-	default:
-		return current
+// Summarize возвращает итоги по транзакциям счёта в разрезе валют
+func (r *TransactionRepository) Summarize(
+	ctx context.Context,
+	accountID int,
+	f *models.SummaryFilter,
+) ([]models.CurrencyTotal, error) {
+
+	rows, err := r.queries.SummarizeAccountTransactions(ctx, query.SummarizeAccountTransactionsParams{
+		AccountID: int32(accountID),
+		DateFrom:  toNullTime(f.DateFrom),
+		DateTo:    f.DateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]models.CurrencyTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = models.CurrencyTotal{
+			Currency: row.Currency,
+			Count:    int(row.TransactionsCount),
+			Income:   row.Income,
+			Expense:  row.Expense,
+			Total:    row.Total,
+		}
+	}
+
+	return totals, nil
+}
+
+// SummarizeForAccounts возвращает итоги по транзакциям счетов accountIDs вместе в разрезе валют
+func (r *TransactionRepository) SummarizeForAccounts(
+	ctx context.Context,
+	accountIDs []int32,
+	f *models.SummaryFilter,
+) ([]models.CurrencyTotal, error) {
+	if len(accountIDs) == 0 {
+		return []models.CurrencyTotal{}, nil
+	}
+
+	rows, err := r.queries.SummarizeAccountsTransactions(ctx, query.SummarizeAccountsTransactionsParams{
+		AccountIds: accountIDs,
+		DateFrom:   toNullTime(f.DateFrom),
+		DateTo:     f.DateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]models.CurrencyTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = models.CurrencyTotal{
+			Currency: row.Currency,
+			Count:    int(row.TransactionsCount),
+			Income:   row.Income,
+			Expense:  row.Expense,
+			Total:    row.Total,
+		}
+	}
+
+	return totals, nil
+}
+
+// SummarizeByDay возвращает доходы и расходы счёта по валютам и дням
+func (r *TransactionRepository) SummarizeByDay(
+	ctx context.Context,
+	accountID int,
+	f *models.SummaryFilter,
+) ([]models.DailyTotal, error) {
+
+	rows, err := r.queries.SummarizeAccountTransactionsByDay(ctx, query.SummarizeAccountTransactionsByDayParams{
+		AccountID: int32(accountID),
+		DateFrom:  toNullTime(f.DateFrom),
+		DateTo:    f.DateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]models.DailyTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = models.DailyTotal{
+			Currency: row.Currency,
+			Day:      row.Day,
+			Income:   row.Income,
+			Expense:  row.Expense,
+		}
+	}
+
+	return totals, nil
+}
+
+// SummarizeForAccountsByDay возвращает доходы и расходы счетов accountIDs вместе по валютам и дням
+func (r *TransactionRepository) SummarizeForAccountsByDay(
+	ctx context.Context,
+	accountIDs []int32,
+	f *models.SummaryFilter,
+) ([]models.DailyTotal, error) {
+	if len(accountIDs) == 0 {
+		return []models.DailyTotal{}, nil
 	}
+
+	rows, err := r.queries.SummarizeAccountsTransactionsByDay(ctx, query.SummarizeAccountsTransactionsByDayParams{
+		AccountIds: accountIDs,
+		DateFrom:   toNullTime(f.DateFrom),
+		DateTo:     f.DateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]models.DailyTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = models.DailyTotal{
+			Currency: row.Currency,
+			Day:      row.Day,
+			Income:   row.Income,
+			Expense:  row.Expense,
+		}
+	}
+
+	return totals, nil
 }
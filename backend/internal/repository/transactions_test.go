@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+)
+
+func TestTransactionFilter(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	userID := 7
+	income, expense, unknown := "income", "expense", "transfer"
+	minAmount, maxAmount := money.Amount(-500000), money.Amount(-100000)
+
+	const tagsCondition = "( SELECT COUNT(*) FROM transaction_tags tt JOIN tags g ON g.id = tt.tag_id " +
+		"WHERE tt.transaction_id = t.id AND g.name IN (?, ?) ) >= ?"
+
+	tests := []struct {
+		name       string
+		filter     models.ListTransactionsFilter
+		conditions []string
+		args       []any
+	}{
+		{
+			name:       "account only",
+			filter:     models.ListTransactionsFilter{AccountID: 1},
+			conditions: []string{"t.account_id = ?"},
+			args:       []any{1},
+		},
+		{
+			name: "user, dates and amount range",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				UserID:    &userID,
+				DateFrom:  &from,
+				DateTo:    &to,
+				AmountMin: &minAmount,
+				AmountMax: &maxAmount,
+			},
+			conditions: []string{
+				"t.account_id = ?",
+				"t.user_id = ?",
+				"t.occurred_at >= ?",
+				"t.occurred_at <= ?",
+				"t.amount >= ?",
+				"t.amount <= ?",
+			},
+			args: []any{1, 7, from, to, minAmount, maxAmount},
+		},
+		{
+			name:       "income",
+			filter:     models.ListTransactionsFilter{AccountID: 1, Type: &income},
+			conditions: []string{"t.account_id = ?", "t.amount > 0"},
+			args:       []any{1},
+		},
+		{
+			name:       "expense",
+			filter:     models.ListTransactionsFilter{AccountID: 1, Type: &expense},
+			conditions: []string{"t.account_id = ?", "t.amount < 0"},
+			args:       []any{1},
+		},
+		{
+			// Тип проверяет обработчик; неизвестный тип не добавляет условие
+			name:       "unknown type",
+			filter:     models.ListTransactionsFilter{AccountID: 1, Type: &unknown},
+			conditions: []string{"t.account_id = ?"},
+			args:       []any{1},
+		},
+		{
+			// Текст поиска передаётся аргументом, спецсимволы LIKE экранируются
+			name:       "search",
+			filter:     models.ListTransactionsFilter{AccountID: 1, Search: "50%_off' OR 1=1 --"},
+			conditions: []string{"t.account_id = ?", "(t.title LIKE ? ESCAPE '!' OR t.notes LIKE ? ESCAPE '!')"},
+			args:       []any{1, "%50!%!_off' OR 1=1 --%", "%50!%!_off' OR 1=1 --%"},
+		},
+		{
+			name:       "categories",
+			filter:     models.ListTransactionsFilter{AccountID: 1, CategoryIDs: []int{3, 4}},
+			conditions: []string{"t.account_id = ?", "t.category_id IN (?, ?)"},
+			args:       []any{1, 3, 4},
+		},
+		{
+			// Категория без подкатегорий, которой нет в счёте: ничего не найдено
+			name:       "no categories",
+			filter:     models.ListTransactionsFilter{AccountID: 1, CategoryIDs: []int{}},
+			conditions: []string{"t.account_id = ?", "FALSE"},
+			args:       []any{1},
+		},
+		{
+			name: "any tags",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Tags:      &models.TagFilter{Names: []string{"дом", "еда"}},
+			},
+			conditions: []string{"t.account_id = ?", tagsCondition},
+			args:       []any{1, "дом", "еда", 1},
+		},
+		{
+			name: "all tags",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Tags:      &models.TagFilter{Names: []string{"дом", "еда"}, All: true},
+			},
+			conditions: []string{"t.account_id = ?", tagsCondition},
+			args:       []any{1, "дом", "еда", 2},
+		},
+		{
+			name:       "empty tags",
+			filter:     models.ListTransactionsFilter{AccountID: 1, Tags: &models.TagFilter{All: true}},
+			conditions: []string{"t.account_id = ?"},
+			args:       []any{1},
+		},
+		{
+			name:       "exclude planned",
+			filter:     models.ListTransactionsFilter{AccountID: 1, PlannedAfter: &to},
+			conditions: []string{"t.account_id = ?", "(t.rule_id IS NULL OR t.occurred_at <= ?)"},
+			args:       []any{1, to},
+		},
+		{
+			name: "after cursor by date desc",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Sort:      models.SortByOccurredAt,
+				Desc:      true,
+				After:     &models.TransactionCursor{Sort: models.SortByOccurredAt, Desc: true, OccurredAt: from, ID: 42},
+			},
+			conditions: []string{"t.account_id = ?", "(t.occurred_at < ? OR (t.occurred_at = ? AND t.id < ?))"},
+			args:       []any{1, from, from, int32(42)},
+		},
+		{
+			name: "after cursor by amount asc",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Sort:      models.SortByAmount,
+				After:     &models.TransactionCursor{Sort: models.SortByAmount, Amount: -150050, ID: 42},
+			},
+			conditions: []string{"t.account_id = ?", "(t.amount > ? OR (t.amount = ? AND t.id > ?))"},
+			args:       []any{1, money.Amount(-150050), money.Amount(-150050), int32(42)},
+		},
+		{
+			// Значение из курсора, подделанного клиентом, остаётся аргументом запроса
+			name: "after tampered title cursor",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Sort:      models.SortByTitle,
+				After:     &models.TransactionCursor{Sort: models.SortByTitle, Title: "x' OR '1'='1", ID: -1},
+			},
+			conditions: []string{"t.account_id = ?", "(t.title > ? OR (t.title = ? AND t.id > ?))"},
+			args:       []any{1, "x' OR '1'='1", "x' OR '1'='1", int32(-1)},
+		},
+		{
+			// Неизвестное поле сортировки не попадает в SQL: используется дата
+			name: "unknown sort",
+			filter: models.ListTransactionsFilter{
+				AccountID: 1,
+				Sort:      models.TransactionSort("id; DROP TABLE transactions"),
+				After:     &models.TransactionCursor{Sort: models.SortByOccurredAt, OccurredAt: from, ID: 42},
+			},
+			conditions: []string{"t.account_id = ?", "(t.occurred_at > ? OR (t.occurred_at = ? AND t.id > ?))"},
+			args:       []any{1, from, from, int32(42)},
+		},
+	}
+
+	for _, tt := range tests {
+		w := transactionFilter(&tt.filter)
+
+		conditions := make([]string, len(w.conditions))
+		for i, condition := range w.conditions {
+			conditions[i] = strings.Join(strings.Fields(condition), " ")
+		}
+
+		if !reflect.DeepEqual(conditions, tt.conditions) {
+			t.Errorf("%s: conditions = %q, want %q", tt.name, conditions, tt.conditions)
+		}
+		if !reflect.DeepEqual(w.Args(), tt.args) {
+			t.Errorf("%s: args = %#v, want %#v", tt.name, w.Args(), tt.args)
+		}
+	}
+}
+
+func TestTransactionOrder(t *testing.T) {
+	tests := []struct {
+		sort  models.TransactionSort
+		desc  bool
+		limit int
+		order string
+		args  []any
+	}{
+		{sort: models.SortByOccurredAt, desc: true, order: "t.occurred_at DESC, t.id DESC"},
+		{sort: models.SortByOccurredAt, order: "t.occurred_at ASC, t.id ASC"},
+		{sort: models.SortByAmount, desc: true, limit: 50, order: "t.amount DESC, t.id DESC LIMIT ?", args: []any{50}},
+		{sort: models.SortByTitle, limit: 1, order: "t.title ASC, t.id ASC LIMIT ?", args: []any{1}},
+		{sort: "", order: "t.occurred_at ASC, t.id ASC"},
+		{sort: "amount; DELETE FROM transactions", desc: true, order: "t.occurred_at DESC, t.id DESC"},
+	}
+
+	for _, tt := range tests {
+		order, args := transactionOrder(&models.ListTransactionsFilter{Sort: tt.sort, Desc: tt.desc, Limit: tt.limit})
+
+		if got := strings.Join(strings.Fields(order), " "); got != tt.order {
+			t.Errorf("transactionOrder(%q, desc=%v) = %q, want %q", tt.sort, tt.desc, got, tt.order)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("transactionOrder(%q) args = %v, want %v", tt.sort, args, tt.args)
+		}
+	}
+
+	// Каждое допустимое поле сортировки сопоставлено колонке
+	for _, sort := range []models.TransactionSort{models.SortByOccurredAt, models.SortByAmount, models.SortByTitle} {
+		if column := transactionSortColumns[sort]; column != "t."+string(sort) {
+			t.Errorf("sort %q column = %q", sort, column)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"microservices/accounter/internal/repository/query"
+)
+
+// UserDeletionRepository хранит запросы пользователей на удаление аккаунта
+type UserDeletionRepository struct {
+	queries *query.Queries
+}
+
+func newUserDeletionRepository(db query.DBTX) *UserDeletionRepository {
+	return &UserDeletionRepository{queries: query.New(db)}
+}
+
+// Create сохраняет запрос на удаление пользователя в момент deleteAt вместе с выбором для его счетов:
+// новый владелец по ID счёта, nil - счёт удаляется
+func (r *UserDeletionRepository) Create(
+	ctx context.Context,
+	userID int,
+	deleteAt time.Time,
+	accounts map[int]*int,
+) error {
+	err := r.queries.CreateUserDeletion(ctx, query.CreateUserDeletionParams{
+		UserID:   int32(userID),
+		DeleteAt: deleteAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	for accountID, newOwnerID := range accounts {
+		err := r.queries.AddUserDeletionAccount(ctx, query.AddUserDeletionAccountParams{
+			UserID:     int32(userID),
+			AccountID:  int32(accountID),
+			NewOwnerID: toNullInt32(newOwnerID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get возвращает запрос на удаление пользователя. Возвращает sql.ErrNoRows, если запроса нет
+func (r *UserDeletionRepository) Get(ctx context.Context, userID int) (*query.UserDeletion, error) {
+	deletion, err := r.queries.GetUserDeletion(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+// GetForUpdate возвращает запрос на удаление пользователя и блокирует его до конца транзакции.
+// Возвращает sql.ErrNoRows, если запроса нет
+func (r *UserDeletionRepository) GetForUpdate(ctx context.Context, userID int) (*query.UserDeletion, error) {
+	deletion, err := r.queries.GetUserDeletionForUpdate(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+// Accounts возвращает выбор пользователя для его счетов: новый владелец по ID счёта, nil - счёт удаляется
+func (r *UserDeletionRepository) Accounts(ctx context.Context, userID int) (map[int]*int, error) {
+	rows, err := r.queries.ListUserDeletionAccounts(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[int]*int, len(rows))
+	for _, row := range rows {
+		var newOwnerID *int
+		if row.NewOwnerID.Valid {
+			id := int(row.NewOwnerID.Int32)
+			newOwnerID = &id
+		}
+		accounts[int(row.AccountID)] = newOwnerID
+	}
+
+	return accounts, nil
+}
+
+// Cancel удаляет запрос на удаление пользователя. Возвращает false, если запроса нет
+func (r *UserDeletionRepository) Cancel(ctx context.Context, userID int) (bool, error) {
+	result, err := r.queries.DeleteUserDeletion(ctx, int32(userID))
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListDueIDs возвращает ID пользователей, срок удаления которых наступил к моменту now
+func (r *UserDeletionRepository) ListDueIDs(ctx context.Context, now time.Time) ([]int32, error) {
+	return r.queries.ListDueUserDeletions(ctx, now)
+}
@@ -88,3 +88,14 @@ func (r *UserRepository) UpdateUserPassword(ctx context.Context, id int, passwor
 
 	return nil
 }
+
+// GetPasswordHash возвращает хеш пароля пользователя для подтверждения опасных действий
+func (r *UserRepository) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	return r.queries.GetUserPasswordHash(ctx, int32(id))
+}
+
+// DeleteUserByID удаляет пользователя. Его участие в счетах, сессии и курсы удаляются вместе с ним,
+// а транзакции и серии в оставшихся счетах остаются без автора
+func (r *UserRepository) DeleteUserByID(ctx context.Context, id int) error {
+	return r.queries.DeleteUserByID(ctx, int32(id))
+}
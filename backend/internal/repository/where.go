@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"strings"
+)
+
+// where собирает условие WHERE из условий, соединённых через AND. Текст условий
+// задаётся только в коде репозитория, а значения фильтров передаются аргументами
+// запроса, поэтому пользовательский ввод не попадает в текст SQL
+type where struct {
+	conditions []string
+	args       []any
+}
+
+// add добавляет условие с плейсхолдерами ? и значения для них
+func (w *where) add(condition string, args ...any) {
+	w.conditions = append(w.conditions, condition)
+	w.args = append(w.args, args...)
+}
+
+// in добавляет условие column IN (...) по списку значений. Пустой список не совпадает ни с чем
+func (w *where) in(column string, values []any) {
+	if len(values) == 0 {
+		w.add("FALSE")
+		return
+	}
+
+	w.add(column+" IN ("+placeholders(len(values))+")", values...)
+}
+
+// SQL возвращает условие для подстановки после WHERE
+func (w *where) SQL() string {
+	if len(w.conditions) == 0 {
+		return "TRUE"
+	}
+
+	return strings.Join(w.conditions, "\n\t\t\tAND ")
+}
+
+// Args возвращает значения плейсхолдеров в порядке их следования в условии
+func (w *where) Args() []any {
+	return w.args
+}
+
+// placeholders возвращает n плейсхолдеров через запятую
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы строка искалась буквально.
+// Экранирующий символ задаётся в запросе через ESCAPE '!'
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// containsPattern возвращает шаблон LIKE для поиска подстроки s
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
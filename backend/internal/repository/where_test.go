@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhere(t *testing.T) {
+	w := &where{}
+	if w.SQL() != "TRUE" || w.Args() != nil {
+		t.Errorf("empty where = %q %v, want TRUE without args", w.SQL(), w.Args())
+	}
+
+	w.add("a = ?", 1)
+	w.add("b IS NULL")
+	w.in("c", []any{"x", "y"})
+	w.add("d BETWEEN ? AND ?", 2, 3)
+
+	wantSQL := "a = ?\n\t\t\tAND b IS NULL\n\t\t\tAND c IN (?, ?)\n\t\t\tAND d BETWEEN ? AND ?"
+	if w.SQL() != wantSQL {
+		t.Errorf("SQL() = %q, want %q", w.SQL(), wantSQL)
+	}
+
+	wantArgs := []any{1, "x", "y", 2, 3}
+	if !reflect.DeepEqual(w.Args(), wantArgs) {
+		t.Errorf("Args() = %v, want %v", w.Args(), wantArgs)
+	}
+}
+
+func TestWhereInEmpty(t *testing.T) {
+	w := &where{}
+	w.in("c", nil)
+
+	if w.SQL() != "FALSE" || len(w.Args()) != 0 {
+		t.Errorf("in(nil) = %q %v, want FALSE without args", w.SQL(), w.Args())
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: ""},
+		{n: 1, want: "?"},
+		{n: 3, want: "?, ?, ?"},
+	}
+
+	for _, tt := range tests {
+		if got := placeholders(tt.n); got != tt.want {
+			t.Errorf("placeholders(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "ашан", want: "%ашан%"},
+		{in: "50%", want: "%50!%%"},
+		{in: "a_b", want: "%a!_b%"},
+		{in: "!important", want: "%!!important%"},
+		{in: `O'Brien \ "x"`, want: `%O'Brien \ "x"%`},
+	}
+
+	for _, tt := range tests {
+		if got := containsPattern(tt.in); got != tt.want {
+			t.Errorf("containsPattern(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Package statement печатает выписку по счёту за период в PDF: остаток на начало,
+// все транзакции с остатком после каждой, остаток на конец и итоги по категориям и участникам
+package statement
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/pdf"
+)
+
+// Поля страницы и высота строк таблиц в пунктах
+const (
+	marginLeft   = 40
+	marginRight  = pdf.A4Width - 40
+	marginTop    = pdf.A4Height - 40
+	marginBottom = 60
+
+	rowHeight = 14
+	textSize  = 8.5
+)
+
+// Колонки таблицы транзакций: левый край текстовых колонок и правый край сумм
+const (
+	columnDate     = marginLeft
+	columnTitle    = marginLeft + 54
+	columnCategory = marginLeft + 206
+	columnMember   = marginLeft + 306
+	columnAmount   = marginRight - 62
+	columnBalance  = marginRight
+)
+
+// Колонки таблиц итогов
+const (
+	columnTotalName    = marginLeft
+	columnTotalIncome  = marginRight - 160
+	columnTotalExpense = marginRight - 80
+	columnTotalNet     = marginRight
+)
+
+const (
+	dateLayout     = "02.01.2006"
+	dateTimeLayout = "02.01.2006 15:04"
+
+	noCategory = "Без категории"
+	noMember   = "Удалённый участник"
+)
+
+// total - поступления и расходы по категории или участнику
+type total struct {
+	name    string
+	count   int
+	income  money.Amount
+	expense money.Amount
+}
+
+func (t *total) add(amount money.Amount) {
+	t.count++
+	if amount > 0 {
+		t.income += amount
+	} else {
+		t.expense += amount
+	}
+}
+
+// Writer печатает выписку. Транзакции передаются через Write по одной в порядке даты:
+// остаток после каждой из них считается от остатка на начало периода
+type Writer struct {
+	header *models.Statement
+	doc    *pdf.Document
+	page   *pdf.Page
+	pages  int
+	y      float64 // базовая линия следующей строки
+
+	balance    money.Amount
+	all        total
+	categories map[string]*total
+	members    map[string]*total
+}
+
+// New начинает выписку с заголовком header
+func New(w io.Writer, header *models.Statement) *Writer {
+	s := &Writer{
+		header:     header,
+		doc:        pdf.New(w, "Выписка по счёту «"+header.Account+"»"),
+		balance:    header.OpeningBalance,
+		categories: make(map[string]*total),
+		members:    make(map[string]*total),
+	}
+
+	s.newPage()
+	s.writeTitle()
+	s.writeTableHeader()
+
+	return s
+}
+
+// Write добавляет транзакцию в выписку
+func (s *Writer) Write(row *models.ExportRow) error {
+	s.balance += row.Amount
+	s.all.add(row.Amount)
+
+	category := row.Category
+	if category == "" {
+		category = noCategory
+	}
+	s.total(s.categories, category).add(row.Amount)
+
+	member := row.MemberEmail
+	if member == "" {
+		member = noMember
+	}
+	s.total(s.members, member).add(row.Amount)
+
+	if s.y-rowHeight < marginBottom {
+		s.newPage()
+		s.writeTableHeader()
+	}
+
+	if s.all.count%2 == 0 {
+		s.page.Fill(marginLeft, s.y-4, marginRight-marginLeft, rowHeight, 0.95)
+	}
+
+	p := s.page
+	p.Text(pdf.Regular, textSize, columnDate, s.y, row.OccurredAt.UTC().Format(dateLayout))
+	p.Text(pdf.Regular, textSize, columnTitle, s.y, fit(row.Title, columnCategory-columnTitle))
+	p.Text(pdf.Regular, textSize, columnCategory, s.y, fit(row.Category, columnMember-columnCategory))
+	p.Text(pdf.Regular, textSize, columnMember, s.y, fit(row.MemberEmail, columnAmount-56-columnMember))
+	p.TextRight(pdf.Regular, textSize, columnAmount, s.y, s.amount(row.Amount))
+	p.TextRight(pdf.Regular, textSize, columnBalance, s.y, s.amount(s.balance))
+	s.y -= rowHeight
+
+	return nil
+}
+
+// Close дописывает остаток на конец периода и итоги и завершает документ
+func (s *Writer) Close() error {
+	if s.all.count == 0 {
+		s.y -= 4
+		s.page.Text(pdf.Regular, 10, marginLeft, s.y, "За период транзакций нет")
+		s.y -= rowHeight
+	}
+
+	s.writeSummary()
+	s.writeTotals("Итоги по категориям", "Категория", s.categories, noCategory)
+	s.writeTotals("Итоги по участникам", "Участник", s.members, noMember)
+
+	return s.doc.Close()
+}
+
+func (s *Writer) total(totals map[string]*total, name string) *total {
+	t, ok := totals[name]
+	if !ok {
+		t = &total{name: name}
+		totals[name] = t
+	}
+	return t
+}
+
+// newPage начинает страницу и печатает её нижний колонтитул
+func (s *Writer) newPage() {
+	s.page = s.doc.NewPage(pdf.A4Width, pdf.A4Height)
+	s.pages++
+	s.y = marginTop
+
+	footer := "Сформировано " + s.header.GeneratedAt.UTC().Format(dateTimeLayout) + " UTC"
+	s.page.Line(marginLeft, marginBottom-14, marginRight, marginBottom-14, 0.5, 0.6)
+	s.page.Text(pdf.Regular, 8, marginLeft, marginBottom-26, footer)
+	s.page.TextRight(pdf.Regular, 8, marginRight, marginBottom-26, "Стр. "+strconv.Itoa(s.pages))
+
+	if s.pages > 1 {
+		s.page.Text(pdf.Regular, 8, marginLeft, s.y, s.period())
+		s.y -= 18
+	}
+}
+
+func (s *Writer) writeTitle() {
+	p := s.page
+
+	s.y -= 6
+	p.Text(pdf.Bold, 18, marginLeft, s.y, "Выписка по счёту")
+	s.y -= 22
+	p.Text(pdf.Regular, 13, marginLeft, s.y, pdf.Fit(pdf.Regular, 13, marginRight-marginLeft, "«"+s.header.Account+"»"))
+	s.y -= 22
+
+	p.Text(pdf.Regular, 10, marginLeft, s.y, "Период: "+s.periodDates())
+	s.y -= 14
+	p.Text(pdf.Regular, 10, marginLeft, s.y, "Валюта: "+string(s.header.Currency))
+	s.y -= 22
+
+	s.writeBalance("Остаток на начало периода", s.header.OpeningBalance)
+	s.y -= 8
+}
+
+func (s *Writer) writeTableHeader() {
+	p := s.page
+
+	p.Fill(marginLeft, s.y-5, marginRight-marginLeft, rowHeight+2, 0.85)
+	p.Text(pdf.Bold, textSize, columnDate, s.y, "Дата")
+	p.Text(pdf.Bold, textSize, columnTitle, s.y, "Описание")
+	p.Text(pdf.Bold, textSize, columnCategory, s.y, "Категория")
+	p.Text(pdf.Bold, textSize, columnMember, s.y, "Участник")
+	p.TextRight(pdf.Bold, textSize, columnAmount, s.y, "Сумма")
+	p.TextRight(pdf.Bold, textSize, columnBalance, s.y, "Остаток")
+	s.y -= rowHeight + 2
+}
+
+func (s *Writer) writeSummary() {
+	// Остаток на конец и обороты печатаются одним блоком
+	s.ensureSpace(4*rowHeight + 20)
+
+	s.y -= 6
+	s.page.Line(marginLeft, s.y+rowHeight-2, marginRight, s.y+rowHeight-2, 0.5, 0)
+
+	s.writeBalance("Остаток на конец периода", s.balance)
+	s.writeBalance("Поступления", s.all.income)
+	s.writeBalance("Расходы", s.all.expense)
+	s.page.Text(pdf.Regular, 10, marginLeft, s.y, "Транзакций")
+	s.page.TextRight(pdf.Regular, 10, columnBalance, s.y, strconv.Itoa(s.all.count))
+	s.y -= rowHeight
+}
+
+func (s *Writer) writeBalance(label string, amount money.Amount) {
+	s.page.Text(pdf.Bold, 10, marginLeft, s.y, label)
+	s.page.TextRight(pdf.Bold, 10, columnBalance, s.y, s.amount(amount))
+	s.y -= rowHeight
+}
+
+// writeTotals печатает таблицу итогов. Строки упорядочены по названию, строка other - последней
+func (s *Writer) writeTotals(title string, column string, totals map[string]*total, other string) {
+	if len(totals) == 0 {
+		return
+	}
+
+	rows := make([]*total, 0, len(totals))
+	for _, t := range totals {
+		rows = append(rows, t)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if (rows[i].name == other) != (rows[j].name == other) {
+			return rows[j].name == other
+		}
+		return strings.ToLower(rows[i].name) < strings.ToLower(rows[j].name)
+	})
+
+	// Заголовок раздела не отрывается от таблицы
+	s.ensureSpace(3*rowHeight + 30)
+	s.y -= 16
+	s.page.Text(pdf.Bold, 12, marginLeft, s.y, title)
+	s.y -= rowHeight + 4
+
+	header := func() {
+		p := s.page
+		p.Fill(marginLeft, s.y-5, marginRight-marginLeft, rowHeight+2, 0.85)
+		p.Text(pdf.Bold, textSize, columnTotalName, s.y, column)
+		p.TextRight(pdf.Bold, textSize, columnTotalIncome, s.y, "Поступления")
+		p.TextRight(pdf.Bold, textSize, columnTotalExpense, s.y, "Расходы")
+		p.TextRight(pdf.Bold, textSize, columnTotalNet, s.y, "Итого")
+		s.y -= rowHeight + 2
+	}
+	header()
+
+	for i, t := range rows {
+		if s.y-rowHeight < marginBottom {
+			s.newPage()
+			header()
+		}
+
+		if i%2 == 1 {
+			s.page.Fill(marginLeft, s.y-4, marginRight-marginLeft, rowHeight, 0.95)
+		}
+
+		name := t.name + " (" + strconv.Itoa(t.count) + ")"
+		p := s.page
+		p.Text(pdf.Regular, textSize, columnTotalName, s.y, fit(name, columnTotalIncome-70-columnTotalName))
+		p.TextRight(pdf.Regular, textSize, columnTotalIncome, s.y, s.amount(t.income))
+		p.TextRight(pdf.Regular, textSize, columnTotalExpense, s.y, s.amount(t.expense))
+		p.TextRight(pdf.Regular, textSize, columnTotalNet, s.y, s.amount(t.income+t.expense))
+		s.y -= rowHeight
+	}
+}
+
+// ensureSpace начинает новую страницу, если на текущей осталось меньше height пунктов
+func (s *Writer) ensureSpace(height float64) {
+	if s.y-height < marginBottom {
+		s.newPage()
+	}
+}
+
+func (s *Writer) periodDates() string {
+	return s.header.From.UTC().Format(dateLayout) + " — " + s.header.To.UTC().Format(dateLayout)
+}
+
+func (s *Writer) period() string {
+	return "Выписка по счёту «" + s.header.Account + "» за " + s.periodDates()
+}
+
+// amount записывает сумму с разделением разрядов и запятой: "-1 500,50"
+func (s *Writer) amount(a money.Amount) string {
+	text := a.Format(s.header.Currency)
+
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(text, ".")
+
+	// Разряды разделяются неразрывным пробелом, чтобы сумма не переносилась
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteRune('\u00A0')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if hasFraction {
+		return sign + grouped.String() + "," + fraction
+	}
+	return sign + grouped.String()
+}
+
+// fit обрезает текст таблицы до ширины колонки с отступом до следующей
+func fit(text string, width float64) string {
+	return pdf.Fit(pdf.Regular, textSize, width-6, text)
+}
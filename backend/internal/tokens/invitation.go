@@ -0,0 +1,16 @@
+package tokens
+
+const (
+	invitationTokenBytes = 32
+	inviteLinkCodeBytes  = 16
+)
+
+// NewInvitationToken создаёт токен, по которому приглашённый принимает или отклоняет приглашение в счёт
+func NewInvitationToken() (string, error) {
+	return randomHex(invitationTokenBytes)
+}
+
+// NewInviteLinkCode создаёт код ссылки-приглашения в счёт
+func NewInviteLinkCode() (string, error) {
+	return randomHex(inviteLinkCodeBytes)
+}
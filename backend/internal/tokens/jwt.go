@@ -1,6 +1,7 @@
 package tokens
 
 import (
+	"errors"
 	"time"
 
 	"microservices/accounter/internal/config"
@@ -9,20 +10,31 @@ import (
 )
 
 type JWTManager struct {
-	secret []byte
-	ttl    time.Duration
+	secret     []byte
+	ttl        time.Duration
+	refreshTTL time.Duration
 }
 
+// Claims данные, извлечённые из валидного access токена
+type Claims struct {
+	UserID    int
+	SessionID string
+}
+
+var ErrInvalidClaims = errors.New("invalid token claims")
+
 func NewJWTManager(cfg config.JWT) *JWTManager {
 	return &JWTManager{
-		secret: []byte(cfg.Secret),
-		ttl:    cfg.Expires,
+		secret:     []byte(cfg.Secret),
+		ttl:        cfg.Expires,
+		refreshTTL: cfg.RefreshExpires,
 	}
 }
 
-func (m *JWTManager) Generate(userID int) (string, error) {
+func (m *JWTManager) Generate(userID int, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub": userID,
+		"sid": sessionID,
 		"exp": time.Now().Add(m.ttl).Unix(),
 		"iat": time.Now().Unix(),
 	}
@@ -31,14 +43,28 @@ func (m *JWTManager) Generate(userID int) (string, error) {
 	return token.SignedString(m.secret)
 }
 
-func (m *JWTManager) Parse(tokenStr string) (int, error) {
+func (m *JWTManager) Parse(tokenStr string) (*Claims, error) {
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (any, error) {
 		return m.secret, nil
-	})
-	if err != nil || !token.Valid {
-		return 0, err
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, ErrInvalidClaims
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
-	return int(claims["sub"].(float64)), nil
+
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
+
+	sid, ok := claims["sid"].(string)
+	if !ok || sid == "" {
+		return nil, ErrInvalidClaims
+	}
+
+	return &Claims{UserID: int(sub), SessionID: sid}, nil
 }
@@ -0,0 +1,50 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const refreshTokenBytes = 32
+
+// GenerateRefresh создаёт непрозрачный refresh токен и его хэш для хранения в БД
+func (m *JWTManager) GenerateRefresh() (token string, hash string, err error) {
+	token, err = randomHex(refreshTokenBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, HashRefresh(token), nil
+}
+
+// NewSessionID создаёт идентификатор сессии, он же идентификатор цепочки ротируемых refresh токенов
+func (m *JWTManager) NewSessionID() (string, error) {
+	return randomHex(16)
+}
+
+// AccessTTL время жизни access токена
+func (m *JWTManager) AccessTTL() time.Duration {
+	return m.ttl
+}
+
+// RefreshTTL время жизни refresh токена
+func (m *JWTManager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// HashRefresh возвращает SHA-256 хэш refresh токена в hex
+func HashRefresh(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -4,75 +4,483 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"microservices/accounter/internal/config"
+	"microservices/accounter/internal/models"
 	"microservices/accounter/internal/repository"
 	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/tokens"
 )
 
 type AccountMemberService struct {
-	members *repository.AccountMemberRepository
-	users   *repository.UserRepository
+	repo          *repository.Repository
+	members       *repository.AccountMemberRepository
+	users         *repository.UserRepository
+	invitations   *repository.InvitationRepository
+	inviteLinks   *repository.InviteLinkRepository
+	roles         *repository.AccountRoleRepository
+	invitationTTL time.Duration
 }
 
-func newAccountMemberService(repo *repository.Repository) *AccountMemberService {
+func newAccountMemberService(repo *repository.Repository, cfg config.Invitation) *AccountMemberService {
 	return &AccountMemberService{
-		members: repo.AccountMemberRepo,
-		users:   repo.UserRepo,
+		repo:          repo,
+		members:       repo.AccountMemberRepo,
+		users:         repo.UserRepo,
+		invitations:   repo.InvitationRepo,
+		inviteLinks:   repo.InviteLinkRepo,
+		roles:         repo.AccountRoleRepo,
+		invitationTTL: cfg.TTL,
 	}
 }
 
+// Invite приглашает email в счёт с ролью role. Участником приглашённый становится, только приняв
+// приглашение. Email может быть ещё не зарегистрирован: приглашение появится у пользователя после регистрации.
+// Нужно право PermMembersInvite, для роли admin - ещё PermMembersManageAdmins. Роль не может
+// давать прав, которых нет у приглашающего
 func (s *AccountMemberService) Invite(
 	ctx context.Context,
 	accountID int,
-	ownerID int,
+	inviterID int,
 	inviteeEmail string,
-	role query.AccountMembersRole,
-) error {
+	role query.InvitationsRole,
+) (*query.Invitation, error) {
 
-	if err := s.requireOwner(ctx, accountID, ownerID); err != nil {
-		return err
+	inviter, err := requirePermissions(ctx, s.repo, accountID, inviterID, PermMembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireRoleManagement(inviter, string(role)); err != nil {
+		return nil, err
 	}
 
+	if err := requireGrantable(inviter, rolePermissions[query.AccountMembersRole(role)]); err != nil {
+		return nil, err
+	}
+
+	var inviteeID *int
 	user, err := s.users.GetUserByEmail(ctx, inviteeEmail)
+	switch {
+	case err == nil:
+		err := s.members.IsMember(ctx, accountID, user.ID)
+		if err == nil {
+			return nil, ErrAlreadyMember
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		inviteeID = &user.ID
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	pending, err := s.invitations.HasPending(ctx, accountID, inviteeEmail, now)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrInvitationExists
+	}
+
+	token, err := tokens.NewInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.invitations.Create(ctx, &models.CreateInvitationParams{
+		Token:     token,
+		AccountID: accountID,
+		InviterID: inviterID,
+		Email:     inviteeEmail,
+		InviteeID: inviteeID,
+		Role:      role,
+		ExpiresAt: now.Add(s.invitationTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invitations.GetByID(ctx, accountID, id)
+}
+
+// ListInvitations возвращает все приглашения в счёт. Нужно право PermMembersInvite
+func (s *AccountMemberService) ListInvitations(
+	ctx context.Context,
+	accountID int,
+	userID int,
+) ([]query.Invitation, error) {
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermMembersInvite); err != nil {
+		return nil, err
+	}
+
+	return s.invitations.ListForAccount(ctx, accountID)
+}
+
+// RevokeInvitation отзывает приглашение, ещё не получившее ответа. Нужно право PermMembersInvite,
+// для приглашения с ролью admin - ещё PermMembersManageAdmins
+func (s *AccountMemberService) RevokeInvitation(ctx context.Context, accountID, userID, invitationID int) error {
+	access, err := requirePermissions(ctx, s.repo, accountID, userID, PermMembersInvite)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.invitations.GetByID(ctx, accountID, invitationID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrUserNotFound
+			return ErrInvitationNotFound
 		}
 		return err
 	}
 
-	return s.members.AddMember(ctx, accountID, user.ID, role)
+	if err := requireRoleManagement(access, string(invitation.Role)); err != nil {
+		return err
+	}
+
+	revoked, err := s.invitations.SetStatus(ctx, invitationID, query.InvitationsStatusRevoked, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// ListUserInvitations возвращает приглашения пользователя, ожидающие ответа
+func (s *AccountMemberService) ListUserInvitations(ctx context.Context, userID int) ([]query.ListUserInvitationsRow, error) {
+	return s.invitations.ListPendingForUser(ctx, userID, time.Now().UTC())
 }
 
-func (s *AccountMemberService) ChangeRole(ctx context.Context, accountID, ownerID, userID int, role query.AccountMembersRole) error {
-	if err := s.requireOwner(ctx, accountID, ownerID); err != nil {
+// AcceptInvitation принимает приглашение: пользователь становится участником счёта с ролью из приглашения
+func (s *AccountMemberService) AcceptInvitation(ctx context.Context, userID int, token string) (*query.Invitation, error) {
+	var invitation *query.Invitation
+	err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		invitation, err = answerableInvitation(ctx, tx, userID, token)
+		if err != nil {
+			return err
+		}
+
+		err = tx.AccountMemberRepo.IsMember(ctx, int(invitation.AccountID), userID)
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		role := query.AccountMembersRole(invitation.Role)
+		if err := tx.AccountMemberRepo.AddMember(ctx, int(invitation.AccountID), userID, role); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if _, err := tx.InvitationRepo.SetStatus(ctx, int(invitation.ID), query.InvitationsStatusAccepted, now); err != nil {
+			return err
+		}
+
+		invitation.Status = query.InvitationsStatusAccepted
+		invitation.RespondedAt = sql.NullTime{Time: now, Valid: true}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// DeclineInvitation отклоняет приглашение
+func (s *AccountMemberService) DeclineInvitation(ctx context.Context, userID int, token string) error {
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		invitation, err := answerableInvitation(ctx, tx, userID, token)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.InvitationRepo.SetStatus(ctx, int(invitation.ID), query.InvitationsStatusDeclined, time.Now().UTC())
+		return err
+	})
+}
+
+// ChangeRole меняет роль участника. Для роли custom roleID - пользовательская роль счёта.
+// Нужно право PermMembersChangeRole, чтобы назначить или снять роль кроме viewer и editor -
+// ещё PermMembersManageAdmins. Новая роль не может давать прав, которых нет у меняющего
+func (s *AccountMemberService) ChangeRole(
+	ctx context.Context,
+	accountID, actorID, userID int,
+	role query.AccountMembersRole,
+	roleID *int,
+) error {
+	actor, err := requirePermissions(ctx, s.repo, accountID, actorID, PermMembersChangeRole)
+	if err != nil {
 		return err
 	}
 
-	return s.members.UpdateMemberRole(ctx, accountID, userID, role)
+	current, err := s.memberRoleNotOwner(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireRoleManagement(actor, string(current), string(role)); err != nil {
+		return err
+	}
+
+	if role != query.AccountMembersRoleCustom {
+		if roleID != nil {
+			return ErrInvalidRoleID
+		}
+
+		if err := requireGrantable(actor, rolePermissions[role]); err != nil {
+			return err
+		}
+
+		return s.members.UpdateMemberRole(ctx, accountID, userID, role)
+	}
+
+	if roleID == nil {
+		return ErrInvalidRoleID
+	}
+
+	if _, err := s.roles.Get(ctx, accountID, *roleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountRoleNotFound
+		}
+		return err
+	}
+
+	permissions, err := s.roles.Permissions(ctx, *roleID)
+	if err != nil {
+		return err
+	}
+
+	granted := make([]Permission, len(permissions))
+	for i, permission := range permissions {
+		granted[i] = Permission(permission)
+	}
+
+	if err := requireGrantable(actor, granted); err != nil {
+		return err
+	}
+
+	return s.members.SetCustomRole(ctx, accountID, userID, *roleID)
 }
 
 func (s *AccountMemberService) IsMember(ctx context.Context, accountID, userID int) error {
 	return s.members.IsMember(ctx, accountID, userID)
 }
 
-func (s *AccountMemberService) Remove(ctx context.Context, accountID, ownerID, userID int) error {
-	if err := s.requireOwner(ctx, accountID, ownerID); err != nil {
+// Remove удаляет участника из счёта. Нужно право PermMembersRemove, для admin - ещё PermMembersManageAdmins
+func (s *AccountMemberService) Remove(ctx context.Context, accountID, actorID, userID int) error {
+	actor, err := requirePermissions(ctx, s.repo, accountID, actorID, PermMembersRemove)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.memberRoleNotOwner(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireRoleManagement(actor, string(role)); err != nil {
 		return err
 	}
 
 	return s.members.RemoveMember(ctx, accountID, userID)
 }
 
-func (s *AccountMemberService) requireOwner(ctx context.Context, accountID, userID int) error {
+// CreateInviteLink создаёт ссылку-приглашение в счёт с ролью role. maxUses == nil - без ограничения
+// числа использований, expiresAt == nil - ссылка действует столько же, сколько приглашение.
+// Нужно право PermMembersInvite, для роли admin - ещё PermMembersManageAdmins. Роль не может
+// давать прав, которых нет у создателя ссылки
+func (s *AccountMemberService) CreateInviteLink(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	role query.InviteLinksRole,
+	maxUses *int,
+	expiresAt *time.Time,
+) (*query.InviteLink, error) {
+	creator, err := requirePermissions(ctx, s.repo, accountID, userID, PermMembersInvite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireRoleManagement(creator, string(role)); err != nil {
+		return nil, err
+	}
+
+	if err := requireGrantable(creator, rolePermissions[query.AccountMembersRole(role)]); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	expires := now.Add(s.invitationTTL)
+	if expiresAt != nil {
+		expires = expiresAt.UTC()
+	}
+
+	if (maxUses != nil && *maxUses < 1) || !expires.After(now) {
+		return nil, ErrInvalidInviteLink
+	}
+
+	code, err := tokens.NewInviteLinkCode()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.inviteLinks.Create(ctx, &models.CreateInviteLinkParams{
+		Code:      code,
+		AccountID: accountID,
+		CreatedBy: userID,
+		Role:      role,
+		MaxUses:   maxUses,
+		ExpiresAt: expires,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.inviteLinks.GetByID(ctx, accountID, id)
+}
+
+// ListInviteLinks возвращает все ссылки-приглашения в счёт. Нужно право PermMembersInvite
+func (s *AccountMemberService) ListInviteLinks(ctx context.Context, accountID, userID int) ([]query.InviteLink, error) {
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermMembersInvite); err != nil {
+		return nil, err
+	}
+
+	return s.inviteLinks.ListForAccount(ctx, accountID)
+}
+
+// RevokeInviteLink отзывает ссылку-приглашение: присоединиться по ней больше нельзя,
+// уже присоединившиеся остаются участниками. Нужно право PermMembersInvite, для ссылки с ролью admin -
+// ещё PermMembersManageAdmins
+func (s *AccountMemberService) RevokeInviteLink(ctx context.Context, accountID, userID, linkID int) error {
+	access, err := requirePermissions(ctx, s.repo, accountID, userID, PermMembersInvite)
+	if err != nil {
+		return err
+	}
+
+	link, err := s.inviteLinks.GetByID(ctx, accountID, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInviteLinkNotFound
+		}
+		return err
+	}
+
+	if err := requireRoleManagement(access, string(link.Role)); err != nil {
+		return err
+	}
+
+	return s.inviteLinks.Revoke(ctx, linkID, time.Now().UTC())
+}
+
+// JoinByInviteLink делает пользователя участником счёта с ролью ссылки. Ссылка блокируется
+// на время транзакции, поэтому параллельные присоединения не превышают max_uses
+func (s *AccountMemberService) JoinByInviteLink(ctx context.Context, userID int, code string) (*query.InviteLink, error) {
+	var link *query.InviteLink
+	err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		link, err = tx.InviteLinkRepo.GetByCodeForUpdate(ctx, code)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInviteLinkNotFound
+			}
+			return err
+		}
+
+		switch {
+		case link.RevokedAt.Valid:
+			return ErrInviteLinkRevoked
+		case !time.Now().Before(link.ExpiresAt):
+			return ErrInviteLinkExpired
+		case link.MaxUses.Valid && link.Uses >= link.MaxUses.Int32:
+			return ErrInviteLinkUsedUp
+		}
+
+		err = tx.AccountMemberRepo.IsMember(ctx, int(link.AccountID), userID)
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		role := query.AccountMembersRole(link.Role)
+		if err := tx.AccountMemberRepo.AddMember(ctx, int(link.AccountID), userID, role); err != nil {
+			return err
+		}
+
+		if err := tx.InviteLinkRepo.IncrementUses(ctx, int(link.ID)); err != nil {
+			return err
+		}
+
+		link.Uses++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// answerableInvitation возвращает приглашение пользователя, ожидающее ответа, и блокирует его до конца транзакции.
+// Чужое приглашение не отличается от несуществующего
+func answerableInvitation(
+	ctx context.Context,
+	tx *repository.Repository,
+	userID int,
+	token string,
+) (*query.Invitation, error) {
+	invitation, err := tx.InvitationRepo.GetByTokenForUpdate(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	if !invitation.InviteeID.Valid || int(invitation.InviteeID.Int32) != userID {
+		return nil, ErrInvitationNotFound
+	}
+
+	if invitation.Status != query.InvitationsStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	if !time.Now().Before(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+
+	return invitation, nil
+}
+
+// memberRoleNotOwner возвращает роль участника счёта, если он не владелец: роль владельца
+// меняется только передачей счёта, иначе у счёта не останется владельца или их станет два
+func (s *AccountMemberService) memberRoleNotOwner(
+	ctx context.Context,
+	accountID, userID int,
+) (query.AccountMembersRole, error) {
 	role, err := s.members.GetMemberRole(ctx, accountID, userID)
 	if err != nil {
-		return ErrForbidden
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrMemberNotFound
+		}
+		return "", err
 	}
 
-	if role != query.AccountMembersRoleOwner {
-		return ErrForbidden
+	if role == query.AccountMembersRoleOwner {
+		return "", ErrOwnerMembership
 	}
 
-	return nil
+	return role, nil
 }
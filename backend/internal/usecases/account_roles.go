@@ -0,0 +1,217 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+const maxAccountRoleNameLength = 50
+
+// builtinRoles - встроенные роли счёта в порядке возрастания прав
+var builtinRoles = []query.AccountMembersRole{
+	query.AccountMembersRoleViewer,
+	query.AccountMembersRoleEditor,
+	query.AccountMembersRoleAdmin,
+	query.AccountMembersRoleOwner,
+}
+
+// ListRoles возвращает встроенные роли и пользовательские роли счёта с их правами. Доступно участникам счёта
+func (s *AccountMemberService) ListRoles(ctx context.Context, accountID, userID int) ([]models.AccountRole, error) {
+	if err := s.members.IsMember(ctx, accountID, userID); err != nil {
+		return nil, ErrForbidden
+	}
+
+	roles, err := s.roles.List(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.roles.PermissionsForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.AccountRole, 0, len(builtinRoles)+len(roles))
+	for _, role := range builtinRoles {
+		names := make([]string, len(rolePermissions[role]))
+		for i, permission := range rolePermissions[role] {
+			names[i] = string(permission)
+		}
+		result = append(result, models.AccountRole{Name: string(role), Permissions: names})
+	}
+
+	for _, role := range roles {
+		id := int(role.ID)
+		result = append(result, models.AccountRole{
+			ID:          &id,
+			Name:        role.Name,
+			Permissions: permissions[role.ID],
+		})
+	}
+
+	return result, nil
+}
+
+// CreateRole создаёт пользовательскую роль счёта с правами permissions. Доступно только Owner
+func (s *AccountMemberService) CreateRole(
+	ctx context.Context,
+	accountID, ownerID int,
+	name string,
+	permissions []string,
+) (*models.AccountRole, error) {
+	if err := requireOwner(ctx, s.repo, accountID, ownerID); err != nil {
+		return nil, err
+	}
+
+	name, permissions, err := accountRole(name, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		if err := checkRoleName(ctx, tx, accountID, 0, name); err != nil {
+			return err
+		}
+
+		id, err = tx.AccountRoleRepo.Create(ctx, accountID, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.AccountRoleRepo.SetPermissions(ctx, id, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccountRole{ID: &id, Name: name, Permissions: permissions}, nil
+}
+
+// UpdateRole меняет название и права пользовательской роли счёта. Новые права сразу действуют
+// для всех участников с этой ролью. Доступно только Owner
+func (s *AccountMemberService) UpdateRole(
+	ctx context.Context,
+	accountID, ownerID, roleID int,
+	name string,
+	permissions []string,
+) (*models.AccountRole, error) {
+	if err := requireOwner(ctx, s.repo, accountID, ownerID); err != nil {
+		return nil, err
+	}
+
+	name, permissions, err := accountRole(name, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		if _, err := tx.AccountRoleRepo.Get(ctx, accountID, roleID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAccountRoleNotFound
+			}
+			return err
+		}
+
+		if err := checkRoleName(ctx, tx, accountID, roleID, name); err != nil {
+			return err
+		}
+
+		if err := tx.AccountRoleRepo.Rename(ctx, roleID, name); err != nil {
+			return err
+		}
+
+		return tx.AccountRoleRepo.SetPermissions(ctx, roleID, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccountRole{ID: &roleID, Name: name, Permissions: permissions}, nil
+}
+
+// DeleteRole удаляет пользовательскую роль счёта, не назначенную участникам. Доступно только Owner
+func (s *AccountMemberService) DeleteRole(ctx context.Context, accountID, ownerID, roleID int) error {
+	if err := requireOwner(ctx, s.repo, accountID, ownerID); err != nil {
+		return err
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		if _, err := tx.AccountRoleRepo.Get(ctx, accountID, roleID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAccountRoleNotFound
+			}
+			return err
+		}
+
+		count, err := tx.AccountRoleRepo.CountMembers(ctx, roleID)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrAccountRoleInUse
+		}
+
+		return tx.AccountRoleRepo.Delete(ctx, roleID)
+	})
+}
+
+// accountRole проверяет название и права пользовательской роли и возвращает название без пробелов
+// по краям и права без повторов в порядке Permissions
+func accountRole(name string, permissions []string) (string, []string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccountRoleNameLength {
+		return "", nil, ErrInvalidAccountRole
+	}
+
+	// Название не должно совпадать со встроенной ролью
+	switch query.AccountMembersRole(strings.ToLower(name)) {
+	case query.AccountMembersRoleViewer, query.AccountMembersRoleEditor, query.AccountMembersRoleAdmin,
+		query.AccountMembersRoleOwner, query.AccountMembersRoleCustom:
+		return "", nil, ErrInvalidAccountRole
+	}
+
+	requested := make(map[Permission]bool, len(permissions))
+	for _, permission := range permissions {
+		requested[Permission(permission)] = true
+	}
+
+	result := make([]string, 0, len(requested))
+	for _, permission := range Permissions {
+		if requested[permission] {
+			result = append(result, string(permission))
+			delete(requested, permission)
+		}
+	}
+
+	if len(requested) > 0 || slices.Contains(result, string(PermMembersManageAdmins)) {
+		return "", nil, ErrInvalidAccountRole
+	}
+
+	return name, result, nil
+}
+
+// checkRoleName проверяет, что название не занято другой ролью счёта. Названия сравниваются без учёта регистра
+func checkRoleName(ctx context.Context, tx *repository.Repository, accountID, roleID int, name string) error {
+	roles, err := tx.AccountRoleRepo.List(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if int(role.ID) != roleID && strings.EqualFold(role.Name, name) {
+			return ErrAccountRoleExists
+		}
+	}
+
+	return nil
+}
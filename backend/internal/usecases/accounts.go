@@ -4,37 +4,63 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
+	"microservices/accounter/internal/config"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository"
 	"microservices/accounter/internal/repository/query"
 )
 
+// AccountService управляет счетами. transferTTL - срок, в течение которого новый владелец
+// может подтвердить передачу счёта
 type AccountService struct {
-	accounts *repository.AccountRepository
-	members  *repository.AccountMemberRepository
+	repo        *repository.Repository
+	accounts    *repository.AccountRepository
+	members     *repository.AccountMemberRepository
+	transfers   *repository.OwnershipTransferRepository
+	transferTTL time.Duration
 }
 
-func newAccountService(repo *repository.Repository) *AccountService {
+func newAccountService(repo *repository.Repository, cfg config.Invitation) *AccountService {
 	return &AccountService{
-		accounts: repo.AccountRepo,
-		members:  repo.AccountMemberRepo,
+		repo:        repo,
+		accounts:    repo.AccountRepo,
+		members:     repo.AccountMemberRepo,
+		transfers:   repo.OwnershipRepo,
+		transferTTL: cfg.TTL,
 	}
 }
 
-func (s *AccountService) CreateAccount(ctx context.Context, userID int, name string, description *string) (int, error) {
-	accountID, err := s.accounts.CreateAccount(ctx, userID, name, description)
-	if err != nil {
-		return 0, err
+func (s *AccountService) CreateAccount(ctx context.Context, params *models.CreateAccountParams) (int, error) {
+	if !params.OpeningBalance.Fits(params.Currency) {
+		return 0, ErrAmountPrecision
 	}
 
-	// creator = owner
-	err = s.members.AddMember(
-		ctx,
-		accountID,
-		userID,
-		query.AccountMembersRoleOwner,
-	)
+	var accountID int
+	err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		accountID, err = tx.AccountRepo.CreateAccount(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		// creator = owner
+		err = tx.AccountMemberRepo.AddMember(
+			ctx,
+			accountID,
+			params.OwnerID,
+			query.AccountMembersRoleOwner,
+		)
+		if err != nil {
+			return err
+		}
 
+		return seedDefaultCategories(ctx, tx, accountID)
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -67,17 +93,188 @@ func (s *AccountService) ListMembers(ctx context.Context, accountID int, userID
 }
 
 func (s *AccountService) DeleteAccount(ctx context.Context, accountID int, userID int) error {
-	role, err := s.members.GetMemberRole(ctx, accountID, userID)
+	if err := requireOwner(ctx, s.repo, accountID, userID); err != nil {
+		return err
+	}
+
+	return s.accounts.DeleteAccountByID(ctx, accountID)
+}
+
+// SetOpeningBalance меняет начальный остаток счёта. Нужно право PermAccountEdit
+func (s *AccountService) SetOpeningBalance(ctx context.Context, accountID int, userID int, balance money.Amount) error {
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermAccountEdit); err != nil {
+		return err
+	}
+
+	currency, err := accountCurrency(ctx, s.accounts, accountID)
+	if err != nil {
+		return err
+	}
+
+	if !balance.Fits(currency) {
+		return ErrAmountPrecision
+	}
+
+	return s.accounts.SetOpeningBalance(ctx, accountID, balance)
+}
+
+// RequestOwnershipTransfer предлагает передать счёт участнику newOwnerID. Владельцем он станет, только
+// подтвердив передачу. Прежнее неподтверждённое предложение заменяется. Доступно Owner
+func (s *AccountService) RequestOwnershipTransfer(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	newOwnerID int,
+) (*query.OwnershipTransfer, error) {
+	if err := requireOwner(ctx, s.repo, accountID, userID); err != nil {
+		return nil, err
+	}
+
+	if newOwnerID == userID {
+		return nil, ErrInvalidOwnershipTransfer
+	}
+
+	if err := s.members.IsMember(ctx, accountID, newOwnerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidOwnershipTransfer
+		}
+		return nil, err
+	}
+
+	err := s.transfers.Create(ctx, accountID, userID, newOwnerID, time.Now().UTC().Add(s.transferTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transfers.Get(ctx, accountID)
+}
+
+// OwnershipTransfer возвращает неподтверждённую передачу счёта. Её видят только владелец и новый владелец
+func (s *AccountService) OwnershipTransfer(ctx context.Context, accountID int, userID int) (*query.OwnershipTransfer, error) {
+	transfer, err := s.transfers.Get(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrForbidden
+			return nil, ErrOwnershipTransferNotFound
+		}
+		return nil, err
+	}
+
+	if int(transfer.FromUserID) != userID && int(transfer.ToUserID) != userID {
+		return nil, ErrOwnershipTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+// CancelOwnershipTransfer отменяет передачу счёта: владелец отзывает предложение, новый владелец отказывается
+func (s *AccountService) CancelOwnershipTransfer(ctx context.Context, accountID int, userID int) error {
+	if _, err := s.OwnershipTransfer(ctx, accountID, userID); err != nil {
+		return err
+	}
+
+	return s.transfers.Delete(ctx, accountID)
+}
+
+// AcceptOwnershipTransfer подтверждает передачу счёта новым владельцем. Владелец счёта, обе роли
+// и предложение меняются в одной транзакции, поэтому у счёта всегда ровно один владелец
+func (s *AccountService) AcceptOwnershipTransfer(ctx context.Context, accountID int, userID int) (*query.Account, error) {
+	var account *query.Account
+	err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		account, err = tx.AccountRepo.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrOwnershipTransferNotFound
+			}
+			return err
+		}
+
+		transfer, err := tx.OwnershipRepo.GetForUpdate(ctx, accountID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrOwnershipTransferNotFound
+			}
+			return err
 		}
+
+		if int(transfer.ToUserID) != userID {
+			return ErrOwnershipTransferNotFound
+		}
+
+		if !time.Now().Before(transfer.ExpiresAt) {
+			return ErrOwnershipTransferExpired
+		}
+
+		// Предложение сделано прежним владельцем или новый владелец уже покинул счёт
+		if transfer.FromUserID != account.OwnerID {
+			return ErrOwnershipTransferNotFound
+		}
+		if err := tx.AccountMemberRepo.IsMember(ctx, accountID, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrForbidden
+			}
+			return err
+		}
+
+		if err := transferOwnership(ctx, tx, account, userID); err != nil {
+			return err
+		}
+
+		account, err = tx.AccountRepo.GetAccountByID(ctx, accountID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// transferOwnership передаёт счёт участнику newOwnerID: он становится владельцем, прежний владелец - админом.
+// Названия счетов владельца уникальны, поэтому счёт с занятым у нового владельца названием переименовывается
+func transferOwnership(ctx context.Context, tx *repository.Repository, account *query.Account, newOwnerID int) error {
+	owned, err := tx.AccountRepo.ListOwned(ctx, newOwnerID)
+	if err != nil {
 		return err
 	}
 
-	if role != query.AccountMembersRoleOwner {
-		return ErrForbidden
+	taken := make(map[string]bool, len(owned))
+	for _, a := range owned {
+		taken[strings.ToLower(a.Name)] = true
 	}
 
-	return s.accounts.DeleteAccountByID(ctx, accountID)
+	err = tx.AccountRepo.SetOwner(ctx, int(account.ID), newOwnerID, freeAccountName(taken, account.Name))
+	if err != nil {
+		return err
+	}
+
+	err = tx.AccountMemberRepo.UpdateMemberRole(ctx, int(account.ID), newOwnerID, query.AccountMembersRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	err = tx.AccountMemberRepo.UpdateMemberRole(
+		ctx,
+		int(account.ID),
+		int(account.OwnerID),
+		query.AccountMembersRoleAdmin,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Предложение передать счёт делал прежний владелец, оно больше не действует
+	return tx.OwnershipRepo.Delete(ctx, int(account.ID))
+}
+
+// freeAccountName возвращает название, не занятое в taken (без учёта регистра), и занимает его:
+// к занятому названию добавляется номер, "Счёт (2)"
+func freeAccountName(taken map[string]bool, name string) string {
+	unique := name
+	for n := 2; taken[strings.ToLower(unique)]; n++ {
+		unique = name + " (" + strconv.Itoa(n) + ")"
+	}
+	taken[strings.ToLower(unique)] = true
+
+	return unique
 }
@@ -0,0 +1,912 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"microservices/accounter/internal/archive"
+	"microservices/accounter/internal/config"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/tokens"
+)
+
+// maxArchiveEmailLength - наибольшая длина email участника: столько вмещают пользователи и приглашения
+const maxArchiveEmailLength = 64
+
+// ArchiveService выгружает данные пользователя в переносимый архив и загружает их из архива,
+// например с другого сервера. В архив попадают счета, которыми пользователь владеет, со всеми
+// участниками, категориями, правилами повторения и транзакциями, а также его курсы валют
+type ArchiveService struct {
+	repo          *repository.Repository
+	archives      *repository.ArchiveRepository
+	users         *repository.UserRepository
+	recurring     *RecurringService
+	invitationTTL time.Duration
+}
+
+func newArchiveService(repo *repository.Repository, recurring *RecurringService, cfg config.Invitation) *ArchiveService {
+	return &ArchiveService{
+		repo:          repo,
+		archives:      repo.ArchiveRepo,
+		users:         repo.UserRepo,
+		recurring:     recurring,
+		invitationTTL: cfg.TTL,
+	}
+}
+
+// Export записывает архив данных пользователя в формате file (zip или json)
+func (s *ArchiveService) Export(ctx context.Context, userID int, file string, w io.Writer) error {
+	if file != archive.FileZIP && file != archive.FileJSON {
+		return ErrUnsupportedArchiveFormat
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	a := &archive.Archive{
+		Format:        archive.Format,
+		Version:       archive.Version,
+		ExportedAt:    time.Now().UTC(),
+		User:          archive.User{Email: user.Email},
+		ExchangeRates: []archive.ExchangeRate{},
+		Accounts:      []archive.Account{},
+	}
+
+	rates, err := s.repo.ExchangeRateRepo.List(ctx, &models.ListExchangeRatesFilter{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	for _, rate := range rates {
+		a.ExchangeRates = append(a.ExchangeRates, archive.ExchangeRate{
+			Base:  rate.BaseCurrency,
+			Quote: rate.QuoteCurrency,
+			Date:  rate.RateDate.Format("2006-01-02"),
+			Rate:  rate.Rate,
+		})
+	}
+
+	accounts, err := s.repo.AccountRepo.ListOwned(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range accounts {
+		account, err := s.exportAccount(ctx, &accounts[i])
+		if err != nil {
+			return err
+		}
+		a.Accounts = append(a.Accounts, *account)
+	}
+
+	return archive.Encode(w, file, a)
+}
+
+func (s *ArchiveService) exportAccount(ctx context.Context, account *query.Account) (*archive.Account, error) {
+	accountID := int(account.ID)
+
+	// Вхождения серий до горизонта выгружаются как транзакции, как их видит пользователь
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	result := &archive.Account{
+		ID:             accountID,
+		Name:           account.Name,
+		Description:    nullStringPtr(account.Description),
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+	}
+
+	members, err := s.repo.AccountMemberRepo.ListMembers(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Members = make([]archive.Member, len(members))
+	for i, m := range members {
+		result.Members[i] = archive.Member{Email: m.Email, Role: string(m.Role), CustomRole: m.RoleName.String}
+	}
+
+	roles, err := s.repo.AccountRoleRepo.List(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.repo.AccountRoleRepo.PermissionsForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range roles {
+		result.Roles = append(result.Roles, archive.Role{Name: r.Name, Permissions: permissions[r.ID]})
+	}
+
+	categories, err := s.repo.CategoryRepo.ListForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Categories = make([]archive.Category, len(categories))
+	for i, c := range categories {
+		result.Categories[i] = archive.Category{ID: int(c.ID), ParentID: nullIntPtr(c.ParentID), Name: c.Name}
+	}
+
+	rules, err := s.repo.RecurringRuleRepo.ListForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleTags, err := s.repo.TagRepo.ForAccountRules(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.archives.ListTransactions(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionTags, err := s.archives.TransactionTags(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Авторы записей переносятся по email: ID пользователей на другом сервере другие
+	var authorIDs []int32
+	for _, r := range rules {
+		if r.UserID.Valid {
+			authorIDs = append(authorIDs, r.UserID.Int32)
+		}
+	}
+	for _, t := range transactions {
+		if t.UserID.Valid {
+			authorIDs = append(authorIDs, t.UserID.Int32)
+		}
+	}
+
+	authors, err := s.archives.EmailsByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result.RecurringRules = make([]archive.RecurringRule, len(rules))
+	for i, r := range rules {
+		result.RecurringRules[i] = archive.RecurringRule{
+			ID:               int(r.ID),
+			AuthorEmail:      authors[r.UserID.Int32],
+			Title:            r.Title,
+			Amount:           r.Amount,
+			Currency:         r.Currency,
+			Period:           string(r.Period),
+			Interval:         int(r.IntervalCount),
+			StartsAt:         r.StartsAt,
+			EndsAt:           nullTimePtr(r.EndsAt),
+			MaxOccurrences:   nullIntPtr(r.MaxOccurrences),
+			Paused:           r.Paused,
+			NextIndex:        int(r.NextIndex),
+			NextOccurrenceAt: r.NextOccurrenceAt,
+			OccurrencesCount: int(r.OccurrencesCount),
+			CategoryID:       nullIntPtr(r.CategoryID),
+			Notes:            nullStringPtr(r.Notes),
+			Tags:             tagsOrEmpty(ruleTags[r.ID]),
+			CreatedAt:        r.CreatedAt,
+		}
+	}
+
+	result.Transactions = make([]archive.Transaction, len(transactions))
+	for i, t := range transactions {
+		var period *string
+		if t.Period.Valid {
+			p := string(t.Period.TransactionsPeriod)
+			period = &p
+		}
+
+		result.Transactions[i] = archive.Transaction{
+			ID:          int(t.ID),
+			AuthorEmail: authors[t.UserID.Int32],
+			Title:       t.Title,
+			Amount:      t.Amount,
+			Currency:    t.Currency,
+			OccurredAt:  t.OccurredAt,
+			Period:      period,
+			RuleID:      nullIntPtr(t.RuleID),
+			CategoryID:  nullIntPtr(t.CategoryID),
+			Notes:       nullStringPtr(t.Notes),
+			ExternalRef: nullStringPtr(t.ExternalRef),
+			Tags:        tagsOrEmpty(transactionTags[t.ID]),
+		}
+	}
+
+	dismissals, err := s.archives.ListDuplicateDismissals(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result.DuplicateDismissals = make([]archive.DuplicateDismissal, len(dismissals))
+	for i, d := range dismissals {
+		result.DuplicateDismissals[i] = archive.DuplicateDismissal{
+			TransactionID: int(d.TransactionID),
+			DuplicateID:   int(d.DuplicateID),
+		}
+	}
+
+	return result, nil
+}
+
+// Import создаёт счета из архива, владельцем которых становится пользователь, и сохраняет его курсы.
+// Участники счетов архива получают приглашения в созданные счета, как при обычном приглашении.
+// Импорт выполняется в одной транзакции БД: ошибка в любой записи архива отменяет его целиком.
+// Записи, которые удалось перенести не как есть, перечислены в конфликтах результата
+func (s *ArchiveService) Import(ctx context.Context, userID int, file io.Reader) (*models.ArchiveImportResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := archive.Decode(data)
+	if err != nil {
+		if errors.Is(err, archive.ErrUnsupportedVersion) {
+			return nil, ErrUnsupportedArchiveVersion
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	result := &models.ArchiveImportResult{
+		Accounts:    []models.ArchiveImportedAccount{},
+		Invitations: []models.ArchiveInvitation{},
+		Conflicts:   []models.ArchiveConflict{},
+	}
+
+	invitationExpiresAt := time.Now().UTC().Add(s.invitationTTL)
+
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		importer, err := newArchiveImporter(ctx, tx, userID, a, invitationExpiresAt, result)
+		if err != nil {
+			return err
+		}
+
+		for i := range a.Accounts {
+			if err := importer.importAccount(ctx, &a.Accounts[i]); err != nil {
+				return err
+			}
+		}
+
+		return importer.importRates(ctx, a.ExchangeRates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// archiveImporter переносит записи архива и пересчитывает ссылки между ними
+type archiveImporter struct {
+	tx     *repository.Repository
+	userID int
+	owner  string         // email владельца архива: его записи переходят к пользователю
+	users  map[string]int // зарегистрированные пользователи из участников архива по email
+	names  map[string]bool
+	result *models.ArchiveImportResult
+
+	invitationExpiresAt time.Time
+
+	// Авторы записей импортируемого счёта, о которых уже есть конфликт
+	missingAuthors map[string]bool
+}
+
+func newArchiveImporter(
+	ctx context.Context,
+	tx *repository.Repository,
+	userID int,
+	a *archive.Archive,
+	invitationExpiresAt time.Time,
+	result *models.ArchiveImportResult,
+) (*archiveImporter, error) {
+	var emails []string
+	for _, account := range a.Accounts {
+		for _, m := range account.Members {
+			emails = append(emails, m.Email)
+		}
+	}
+
+	users, err := tx.ArchiveRepo.IDsByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	// Названия счетов владельца уникальны: совпадающие с уже существующими меняются
+	owned, err := tx.AccountRepo.ListOwned(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(owned))
+	for _, account := range owned {
+		names[strings.ToLower(account.Name)] = true
+	}
+
+	return &archiveImporter{
+		tx:     tx,
+		userID: userID,
+		owner:  a.User.Email,
+		users:  users,
+		names:  names,
+		result: result,
+
+		invitationExpiresAt: invitationExpiresAt,
+	}, nil
+}
+
+func (i *archiveImporter) importAccount(ctx context.Context, src *archive.Account) error {
+	i.missingAuthors = make(map[string]bool)
+
+	name := strings.TrimSpace(src.Name)
+	if name == "" {
+		return invalidArchive("account %d: name is required", src.ID)
+	}
+
+	currency, err := money.ParseCurrency(string(src.Currency))
+	if err != nil {
+		return invalidArchive("account %d: %s", src.ID, err)
+	}
+
+	if !src.OpeningBalance.Fits(currency) {
+		return invalidArchive("account %d: opening balance: %s", src.ID, ErrAmountPrecision)
+	}
+
+	accountID, err := i.tx.AccountRepo.CreateAccount(ctx, &models.CreateAccountParams{
+		OwnerID:        i.userID,
+		Name:           i.accountName(name),
+		Description:    src.Description,
+		Currency:       currency,
+		OpeningBalance: src.OpeningBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	account, err := i.tx.AccountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = i.tx.AccountMemberRepo.AddMember(ctx, accountID, i.userID, query.AccountMembersRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	roles, err := i.importRoles(ctx, accountID, src)
+	if err != nil {
+		return err
+	}
+
+	if err := i.importMembers(ctx, accountID, roles, src); err != nil {
+		return err
+	}
+
+	categories, err := i.importCategories(ctx, accountID, src)
+	if err != nil {
+		return err
+	}
+
+	rules, err := i.importRules(ctx, accountID, currency, categories, src)
+	if err != nil {
+		return err
+	}
+
+	transactions, err := i.importTransactions(ctx, accountID, currency, categories, rules, src)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range src.DuplicateDismissals {
+		transactionID, ok := transactions[d.TransactionID]
+		duplicateID, okDuplicate := transactions[d.DuplicateID]
+		if !ok || !okDuplicate || transactionID == duplicateID {
+			return invalidArchive("account %d: duplicate dismissal refers to a missing transaction", src.ID)
+		}
+
+		if err := i.tx.DuplicateRepo.Dismiss(ctx, transactionID, duplicateID); err != nil {
+			return err
+		}
+	}
+
+	i.result.Accounts = append(i.result.Accounts, models.ArchiveImportedAccount{
+		SourceID: src.ID,
+		ID:       accountID,
+		Name:     account.Name,
+	})
+
+	return nil
+}
+
+// accountName возвращает свободное название счёта: к занятому добавляется номер, "Счёт (2)"
+func (i *archiveImporter) accountName(name string) string {
+	unique := freeAccountName(i.names, name)
+	if unique != name {
+		i.conflict(models.ArchiveConflictAccountName, name, "account renamed to "+strconv.Quote(unique))
+	}
+
+	return unique
+}
+
+// importRoles создаёт пользовательские роли счёта и возвращает их ID по названию в нижнем регистре:
+// названия ролей счёта сравниваются без учёта регистра
+func (i *archiveImporter) importRoles(ctx context.Context, accountID int, src *archive.Account) (map[string]int, error) {
+	roles := make(map[string]int, len(src.Roles))
+
+	for _, r := range src.Roles {
+		name, permissions, err := accountRole(r.Name, r.Permissions)
+		if err != nil {
+			return nil, invalidArchive("account %d: role %q: %s", src.ID, r.Name, err)
+		}
+
+		key := strings.ToLower(name)
+		if _, ok := roles[key]; ok {
+			return nil, invalidArchive("account %d: role %q: %s", src.ID, r.Name, ErrAccountRoleExists)
+		}
+
+		id, err := i.tx.AccountRoleRepo.Create(ctx, accountID, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := i.tx.AccountRoleRepo.SetPermissions(ctx, id, permissions); err != nil {
+			return nil, err
+		}
+
+		roles[key] = id
+	}
+
+	return roles, nil
+}
+
+// importMembers приглашает участников архива в счёт с их ролями: участниками они становятся, только
+// приняв приглашение. Незарегистрированные получат приглашение после регистрации. Владелец архива
+// не приглашается: владельцем счёта становится пользователь. Пользовательскую роль приглашение
+// не выдаёт, поэтому участники с ней приглашаются наблюдателями
+func (i *archiveImporter) importMembers(ctx context.Context, accountID int, roles map[string]int, src *archive.Account) error {
+	seen := make(map[string]bool, len(src.Members))
+
+	for _, m := range src.Members {
+		var role query.InvitationsRole
+		switch memberRole := query.AccountMembersRole(m.Role); memberRole {
+		case query.AccountMembersRoleViewer, query.AccountMembersRoleEditor, query.AccountMembersRoleAdmin:
+			role = query.InvitationsRole(memberRole)
+		case query.AccountMembersRoleCustom:
+			if _, ok := roles[strings.ToLower(strings.TrimSpace(m.CustomRole))]; !ok {
+				return invalidArchive("account %d: member %s: unknown custom role %q", src.ID, m.Email, m.CustomRole)
+			}
+			role = query.InvitationsRoleViewer
+		case query.AccountMembersRoleOwner:
+			continue
+		default:
+			return invalidArchive("account %d: member %s: invalid role %q", src.ID, m.Email, m.Role)
+		}
+
+		address, err := mail.ParseAddress(m.Email)
+		if err != nil || address.Address != m.Email || len(m.Email) > maxArchiveEmailLength {
+			return invalidArchive("account %d: member %q: invalid email", src.ID, m.Email)
+		}
+
+		if m.Email == i.owner || seen[m.Email] {
+			continue
+		}
+		seen[m.Email] = true
+
+		var inviteeID *int
+		if id, ok := i.users[m.Email]; ok {
+			if id == i.userID {
+				continue
+			}
+			inviteeID = &id
+		}
+
+		token, err := tokens.NewInvitationToken()
+		if err != nil {
+			return err
+		}
+
+		_, err = i.tx.InvitationRepo.Create(ctx, &models.CreateInvitationParams{
+			Token:     token,
+			AccountID: accountID,
+			InviterID: i.userID,
+			Email:     m.Email,
+			InviteeID: inviteeID,
+			Role:      role,
+			ExpiresAt: i.invitationExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if role != query.InvitationsRole(m.Role) {
+			i.conflict(models.ArchiveConflictMemberRole, m.Email,
+				"custom role "+strconv.Quote(m.CustomRole)+" is not granted by invitation, invited to account "+
+					strconv.Quote(src.Name)+" as viewer")
+		}
+
+		i.result.Invitations = append(i.result.Invitations, models.ArchiveInvitation{
+			AccountID: accountID,
+			Email:     m.Email,
+			Role:      role,
+		})
+	}
+
+	return nil
+}
+
+// importCategories создаёт категории счёта, родителей раньше подкатегорий, и возвращает
+// новые ID категорий по их ID в архиве
+func (i *archiveImporter) importCategories(ctx context.Context, accountID int, src *archive.Account) (map[int]int, error) {
+	ids := make(map[int]int, len(src.Categories))
+
+	pending := src.Categories
+	for len(pending) > 0 {
+		var postponed []archive.Category
+
+		for _, c := range pending {
+			var parentID *int
+			if c.ParentID != nil {
+				id, ok := ids[*c.ParentID]
+				if !ok {
+					postponed = append(postponed, c)
+					continue
+				}
+				parentID = &id
+			}
+
+			if _, ok := ids[c.ID]; ok {
+				return nil, invalidArchive("account %d: duplicate category id %d", src.ID, c.ID)
+			}
+
+			name, err := categoryName(c.Name)
+			if err != nil {
+				return nil, invalidArchive("account %d: category %d: %s", src.ID, c.ID, err)
+			}
+
+			id, err := i.tx.CategoryRepo.Create(ctx, accountID, parentID, name)
+			if err != nil {
+				return nil, err
+			}
+			ids[c.ID] = id
+			i.result.Categories++
+		}
+
+		// Ни одна категория не создана: у оставшихся нет родителя в архиве или они образуют цикл
+		if len(postponed) == len(pending) {
+			return nil, invalidArchive("account %d: category %d refers to a missing parent", src.ID, postponed[0].ID)
+		}
+		pending = postponed
+	}
+
+	return ids, nil
+}
+
+func (i *archiveImporter) importRules(
+	ctx context.Context,
+	accountID int,
+	currency money.Currency,
+	categories map[int]int,
+	src *archive.Account,
+) (map[int]int, error) {
+	ids := make(map[int]int, len(src.RecurringRules))
+
+	for _, r := range src.RecurringRules {
+		fail := func(err error) error {
+			return invalidArchive("account %d: recurring rule %d: %s", src.ID, r.ID, err)
+		}
+
+		if _, ok := ids[r.ID]; ok {
+			return nil, fail(errors.New("duplicate id"))
+		}
+
+		period := query.RecurringRulesPeriod(r.Period)
+		switch period {
+		case query.RecurringRulesPeriodDay, query.RecurringRulesPeriodWeek,
+			query.RecurringRulesPeriodMonth, query.RecurringRulesPeriodYear:
+		default:
+			return nil, fail(fmt.Errorf("invalid period %q", r.Period))
+		}
+
+		if err := validateRecurringRule(r.StartsAt, r.Interval, r.EndsAt, r.MaxOccurrences); err != nil {
+			return nil, fail(err)
+		}
+
+		entry, err := checkArchiveEntry(r.Title, r.Amount, r.Currency, currency, r.Notes, r.Tags)
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		categoryID, err := archiveRef(categories, r.CategoryID, "category")
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		// Следующее вхождение и число созданных переносятся как есть: уже созданные вхождения
+		// есть в архиве, и планировщик продолжит серию с того же места
+		nextOccurrenceAt := r.NextOccurrenceAt
+		if nextOccurrenceAt.IsZero() {
+			nextOccurrenceAt = r.StartsAt
+		}
+		createdAt := r.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		id, err := i.tx.ArchiveRepo.CreateRule(ctx, &models.ArchiveRecurringRuleParams{
+			CreateRecurringRuleParams: models.CreateRecurringRuleParams{
+				AccountID:      accountID,
+				UserID:         i.author(r.AuthorEmail, src.Name),
+				Title:          r.Title,
+				Amount:         r.Amount,
+				Currency:       currency,
+				Period:         period,
+				Interval:       r.Interval,
+				StartsAt:       r.StartsAt,
+				EndsAt:         r.EndsAt,
+				MaxOccurrences: r.MaxOccurrences,
+				CategoryID:     categoryID,
+				Notes:          entry.notes,
+			},
+			Paused:           r.Paused,
+			NextIndex:        r.NextIndex,
+			NextOccurrenceAt: nextOccurrenceAt,
+			OccurrencesCount: r.OccurrencesCount,
+			CreatedAt:        createdAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setRuleTags(ctx, i.tx, accountID, id, entry.tags); err != nil {
+			return nil, err
+		}
+
+		ids[r.ID] = id
+		i.result.RecurringRules++
+	}
+
+	return ids, nil
+}
+
+func (i *archiveImporter) importTransactions(
+	ctx context.Context,
+	accountID int,
+	currency money.Currency,
+	categories map[int]int,
+	rules map[int]int,
+	src *archive.Account,
+) (map[int]int, error) {
+	ids := make(map[int]int, len(src.Transactions))
+
+	for _, t := range src.Transactions {
+		fail := func(err error) error {
+			return invalidArchive("account %d: transaction %d: %s", src.ID, t.ID, err)
+		}
+
+		if _, ok := ids[t.ID]; ok {
+			return nil, fail(errors.New("duplicate id"))
+		}
+
+		var period query.NullTransactionsPeriod
+		if t.Period != nil {
+			period = query.NullTransactionsPeriod{TransactionsPeriod: query.TransactionsPeriod(*t.Period), Valid: true}
+
+			switch period.TransactionsPeriod {
+			case query.TransactionsPeriodDay, query.TransactionsPeriodWeek,
+				query.TransactionsPeriodMonth, query.TransactionsPeriodYear:
+			default:
+				return nil, fail(fmt.Errorf("invalid period %q", *t.Period))
+			}
+		}
+
+		if t.OccurredAt.IsZero() {
+			return nil, fail(errors.New("occurred_at is required"))
+		}
+
+		entry, err := checkArchiveEntry(t.Title, t.Amount, t.Currency, currency, t.Notes, t.Tags)
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		categoryID, err := archiveRef(categories, t.CategoryID, "category")
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		ruleID, err := archiveRef(rules, t.RuleID, "recurring rule")
+		if err != nil {
+			return nil, fail(err)
+		}
+
+		id, err := i.tx.ArchiveRepo.CreateTransaction(ctx, &models.CreateTransactionParams{
+			AccountID:   accountID,
+			UserID:      i.author(t.AuthorEmail, src.Name),
+			Title:       t.Title,
+			Amount:      t.Amount,
+			Currency:    currency,
+			OccurredAt:  t.OccurredAt,
+			Period:      period,
+			CategoryID:  categoryID,
+			Notes:       entry.notes,
+			ExternalRef: t.ExternalRef,
+			RuleID:      ruleID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setTransactionTags(ctx, i.tx, accountID, id, entry.tags); err != nil {
+			return nil, err
+		}
+
+		ids[t.ID] = id
+		i.result.Transactions++
+	}
+
+	return ids, nil
+}
+
+// importRates сохраняет курсы архива. Курс, уже сохранённый на ту же дату с другим
+// значением, не заменяется
+func (i *archiveImporter) importRates(ctx context.Context, rates []archive.ExchangeRate) error {
+	for _, r := range rates {
+		subject := string(r.Base) + "/" + string(r.Quote) + " " + r.Date
+
+		base, err := money.ParseCurrency(string(r.Base))
+		if err != nil {
+			return invalidArchive("exchange rate %s: %s", subject, err)
+		}
+
+		quote, err := money.ParseCurrency(string(r.Quote))
+		if err != nil {
+			return invalidArchive("exchange rate %s: %s", subject, err)
+		}
+
+		if base == quote {
+			return invalidArchive("exchange rate %s: %s", subject, ErrInvalidExchangeRate)
+		}
+
+		date, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			return invalidArchive("exchange rate %s: date must be YYYY-MM-DD", subject)
+		}
+
+		rate, err := money.ParseRate(r.Rate)
+		if err != nil {
+			return invalidArchive("exchange rate %s: %s", subject, err)
+		}
+
+		existing, found, err := i.tx.ArchiveRepo.GetExchangeRate(ctx, i.userID, base, quote, date)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			if saved, err := money.ParseRate(existing); err != nil || saved.String() != rate.String() {
+				i.conflict(models.ArchiveConflictExchangeRate, subject,
+					"rate "+existing+" is already saved, archive rate "+rate.String()+" skipped")
+			}
+			continue
+		}
+
+		err = i.tx.ExchangeRateRepo.Upsert(ctx, i.userID, &models.ExchangeRate{
+			Base:  base,
+			Quote: quote,
+			Date:  date,
+			Rate:  rate,
+		})
+		if err != nil {
+			return err
+		}
+		i.result.ExchangeRates++
+	}
+
+	return nil
+}
+
+// author возвращает ID автора записи счёта account по email. Запись не может принадлежать тому,
+// у кого нет доступа к счёту, а участников в созданном счёте пока нет, только приглашения.
+// Поэтому все записи переходят к импортирующему пользователю, о чужих сообщает конфликт
+func (i *archiveImporter) author(email string, account string) int {
+	if email == "" || email == i.owner {
+		return i.userID
+	}
+
+	if !i.missingAuthors[email] {
+		i.missingAuthors[email] = true
+		i.conflict(models.ArchiveConflictAuthorNotFound, email,
+			"user is not a member of account "+strconv.Quote(account)+", records are attributed to the importing user")
+	}
+
+	return i.userID
+}
+
+func (i *archiveImporter) conflict(kind string, subject string, message string) {
+	i.result.Conflicts = append(i.result.Conflicts, models.ArchiveConflict{
+		Kind:    kind,
+		Subject: subject,
+		Message: message,
+	})
+}
+
+// archiveEntry - проверенные примечание и метки правила или транзакции архива
+type archiveEntry struct {
+	notes *string
+	tags  []string
+}
+
+// checkArchiveEntry проверяет общие поля правила повторения и транзакции архива.
+// Записи счёта хранятся в его валюте, пустая валюта в архиве означает валюту счёта
+func checkArchiveEntry(
+	title string,
+	amount money.Amount,
+	currency money.Currency,
+	accountCurrency money.Currency,
+	notes *string,
+	tags []string,
+) (*archiveEntry, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, errors.New("title is required")
+	}
+
+	if currency != "" && currency != accountCurrency {
+		return nil, fmt.Errorf("currency %s does not match the account currency %s", currency, accountCurrency)
+	}
+
+	if !amount.Fits(accountCurrency) {
+		return nil, ErrAmountPrecision
+	}
+
+	notes, err := normalizeNotes(notes)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &archiveEntry{notes: notes, tags: tags}, nil
+}
+
+// archiveRef переводит ссылку на запись архива в новый ID
+func archiveRef(ids map[int]int, ref *int, what string) (*int, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	id, ok := ids[*ref]
+	if !ok {
+		return nil, fmt.Errorf("%s %d not found in archive", what, *ref)
+	}
+
+	return &id, nil
+}
+
+func invalidArchive(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidArchive}, args...)...)
+}
+
+// tagsOrEmpty возвращает пустой список вместо nil, чтобы в архиве было [] а не null
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
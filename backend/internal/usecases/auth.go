@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"microservices/accounter/internal/crypto"
 	"microservices/accounter/internal/models"
@@ -13,30 +14,36 @@ import (
 )
 
 type AuthService struct {
-	users  *repository.UserRepository
-	tokens *tokens.JWTManager
+	users         *repository.UserRepository
+	refreshTokens *repository.RefreshTokenRepository
+	sessions      *repository.SessionRepository
+	invitations   *repository.InvitationRepository
+	tokens        *tokens.JWTManager
 }
 
 func newAuthService(repo *repository.Repository, tokens *tokens.JWTManager) *AuthService {
 	return &AuthService{
-		users:  repo.UserRepo,
-		tokens: tokens,
+		users:         repo.UserRepo,
+		refreshTokens: repo.RefreshTokenRepo,
+		sessions:      repo.SessionRepo,
+		invitations:   repo.InvitationRepo,
+		tokens:        tokens,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, email string, password string) (string, error) {
+func (s *AuthService) Register(ctx context.Context, email string, password string, client models.ClientInfo) (*models.Token, error) {
 
 	_, err := s.users.GetUserByEmail(ctx, email)
 	if err == nil {
-		return "", ErrUserAlreadyExists
+		return nil, ErrUserAlreadyExists
 	}
 	if !errors.Is(err, sql.ErrNoRows) {
-		return "", err
+		return nil, err
 	}
 
 	hash, err := crypto.Hash(password)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	userID, err := s.users.CreateUser(ctx, &models.CreateUser{
@@ -44,10 +51,15 @@ func (s *AuthService) Register(ctx context.Context, email string, password strin
 		PasswordHash: hash,
 	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	// Приглашения, отправленные на этот email до регистрации, становятся приглашениями пользователя
+	if err := s.invitations.LinkUser(ctx, email, userID); err != nil {
+		return nil, err
 	}
 
-	return s.tokens.Generate(userID)
+	return s.startSession(ctx, userID, client)
 }
 
 func (s *AuthService) Profile(ctx context.Context, userID int) (*query.GetUserByIDRow, error) {
@@ -62,24 +74,91 @@ func (s *AuthService) Profile(ctx context.Context, userID int) (*query.GetUserBy
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email string, password string) (string, error) {
+func (s *AuthService) Login(ctx context.Context, email string, password string, client models.ClientInfo) (*models.Token, error) {
 
 	user, err := s.users.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", ErrInvalidCredentials
+			return nil, ErrInvalidCredentials
 		}
-		return "", err
+		return nil, err
 	}
 
 	if !crypto.Compare(user.PasswordHash, password) {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.startSession(ctx, user.ID, client)
+}
+
+// Refresh обменивает refresh токен на новую пару токенов (ротация).
+// Повторное использование уже отозванного токена отзывает всю сессию
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.Token, error) {
+	stored, err := s.refreshTokens.GetByHash(ctx, tokens.HashRefresh(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt.Valid {
+		// Сессия уже завершена (выход или удалённое завершение) - это не кража
+		session, err := s.sessions.GetByID(ctx, stored.FamilyID)
+		if err != nil || session.RevokedAt.Valid {
+			return nil, ErrInvalidRefreshToken
+		}
+
+		if err := s.revokeSession(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.refreshTokens.Revoke(ctx, int(stored.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Токен успели использовать параллельно - считаем это переиспользованием
+	if !revoked {
+		if err := s.revokeSession(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	token, err := s.issueTokens(ctx, int(stored.UserID), stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.Extend(ctx, stored.FamilyID, client.IP, token.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Logout завершает сессию, к которой относится переданный refresh токен
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.refreshTokens.GetByHash(ctx, tokens.HashRefresh(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
 	}
 
-	return s.tokens.Generate(user.ID)
+	return s.revokeSession(ctx, stored.FamilyID)
 }
 
-func (s *AuthService) ChangePassword(ctx context.Context, userID int, newPassword string) error {
+// ChangePassword меняет пароль и завершает все сессии пользователя, кроме текущей
+func (s *AuthService) ChangePassword(ctx context.Context, userID int, sessionID string, newPassword string) error {
 
 	exists, err := s.users.UserExistsByID(ctx, userID)
 	if err != nil {
@@ -94,9 +173,81 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID int, newPasswor
 		return err
 	}
 
-	return s.users.UpdateUserPassword(ctx, userID, hash)
+	if err := s.users.UpdateUserPassword(ctx, userID, hash); err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeAllExcept(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	return s.refreshTokens.RevokeAllExcept(ctx, userID, sessionID)
 }
 
 func (s *AuthService) CheckUserExists(ctx context.Context, userID int) (bool, error) {
 	return s.users.UserExistsByID(ctx, userID)
 }
+
+// startSession создаёт новую сессию для входа с указанного устройства и выдаёт для неё токены
+func (s *AuthService) startSession(ctx context.Context, userID int, client models.ClientInfo) (*models.Token, error) {
+	sessionID, err := s.tokens.NewSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessions.Create(
+		ctx,
+		sessionID,
+		userID,
+		client,
+		time.Now().Add(s.tokens.RefreshTTL()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, userID, sessionID)
+}
+
+// revokeSession отзывает сессию вместе со всей цепочкой её refresh токенов
+func (s *AuthService) revokeSession(ctx context.Context, sessionID string) error {
+	if err := s.sessions.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return s.refreshTokens.RevokeFamily(ctx, sessionID)
+}
+
+// issueTokens выдаёт access токен и новый refresh токен в цепочке сессии
+func (s *AuthService) issueTokens(ctx context.Context, userID int, sessionID string) (*models.Token, error) {
+	now := time.Now()
+
+	accessToken, err := s.tokens.Generate(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := s.tokens.GenerateRefresh()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiresAt := now.Add(s.tokens.RefreshTTL())
+
+	_, err = s.refreshTokens.Create(ctx, &models.GenerateToken{
+		UserID:    userID,
+		FamilyID:  sessionID,
+		TokenHash: refreshHash,
+		ExpiresAt: refreshExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Token{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  now.Add(s.tokens.AccessTTL()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
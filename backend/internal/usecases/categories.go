@@ -0,0 +1,341 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+const (
+	// maxCategoryDepth - максимальная вложенность дерева категорий
+	maxCategoryDepth = 5
+
+	maxCategoryNameLength = 100
+)
+
+// defaultCategory - категория стандартного набора, создаваемого вместе со счётом
+type defaultCategory struct {
+	name     string
+	children []string
+}
+
+var defaultCategories = []defaultCategory{
+	{name: "Еда", children: []string{"Продукты", "Кафе и рестораны"}},
+	{name: "Транспорт", children: []string{"Общественный транспорт", "Такси", "Топливо"}},
+	{name: "Жильё", children: []string{"Аренда", "Коммунальные услуги"}},
+	{name: "Покупки", children: []string{"Одежда", "Техника"}},
+	{name: "Здоровье"},
+	{name: "Развлечения"},
+	{name: "Доходы", children: []string{"Зарплата", "Прочие доходы"}},
+}
+
+// CategoryService управляет деревом категорий счёта
+type CategoryService struct {
+	repo       *repository.Repository
+	categories *repository.CategoryRepository
+	members    *repository.AccountMemberRepository
+}
+
+func newCategoryService(repo *repository.Repository) *CategoryService {
+	return &CategoryService{
+		repo:       repo,
+		categories: repo.CategoryRepo,
+		members:    repo.AccountMemberRepo,
+	}
+}
+
+// Create создаёт категорию. Нужно право PermCategoriesManage
+func (s *CategoryService) Create(ctx context.Context, params *models.CreateCategoryParams) (int, error) {
+	if err := s.authorize(ctx, params.AccountID, params.UserID); err != nil {
+		return 0, err
+	}
+
+	name, err := categoryName(params.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	all, err := s.categories.ListForAccount(ctx, params.AccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	tree := newCategoryTree(all)
+
+	if params.ParentID != nil {
+		if _, ok := tree.byID[int32(*params.ParentID)]; !ok {
+			return 0, ErrCategoryNotFound
+		}
+
+		if tree.depth(int32(*params.ParentID))+1 > maxCategoryDepth {
+			return 0, ErrCategoryDepth
+		}
+	}
+
+	if tree.hasSibling(params.ParentID, name, 0) {
+		return 0, ErrCategoryExists
+	}
+
+	return s.categories.Create(ctx, params.AccountID, params.ParentID, name)
+}
+
+// Tree возвращает дерево категорий счёта. Доступно всем участникам счёта
+func (s *CategoryService) Tree(ctx context.Context, accountID int, userID int) ([]models.CategoryNode, error) {
+	if err := s.members.IsMember(ctx, accountID, userID); err != nil {
+		return nil, ErrForbidden
+	}
+
+	all, err := s.categories.ListForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCategoryTree(all).nodes(sql.NullInt32{}), nil
+}
+
+// Update переименовывает категорию и/или переносит её вместе с подкатегориями к другому родителю
+func (s *CategoryService) Update(
+	ctx context.Context,
+	accountID int,
+	categoryID int,
+	userID int,
+	params *models.UpdateCategoryParams,
+) error {
+
+	if err := s.authorize(ctx, accountID, userID); err != nil {
+		return err
+	}
+
+	name, err := categoryName(params.Name)
+	if err != nil {
+		return err
+	}
+
+	all, err := s.categories.ListForAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	tree := newCategoryTree(all)
+
+	id := int32(categoryID)
+	if _, ok := tree.byID[id]; !ok {
+		return ErrCategoryNotFound
+	}
+
+	if params.ParentID != nil {
+		parentID := int32(*params.ParentID)
+		if _, ok := tree.byID[parentID]; !ok {
+			return ErrCategoryNotFound
+		}
+
+		if tree.isDescendant(parentID, id) {
+			return ErrCategoryCycle
+		}
+
+		if tree.depth(parentID)+tree.height(id) > maxCategoryDepth {
+			return ErrCategoryDepth
+		}
+	}
+
+	if tree.hasSibling(params.ParentID, name, id) {
+		return ErrCategoryExists
+	}
+
+	return s.categories.Update(ctx, categoryID, params.ParentID, name)
+}
+
+// Delete удаляет категорию вместе с подкатегориями. Транзакции и серии
+// удалённых категорий остаются без категории
+func (s *CategoryService) Delete(ctx context.Context, accountID int, categoryID int, userID int) error {
+	if err := s.authorize(ctx, accountID, userID); err != nil {
+		return err
+	}
+
+	if err := checkCategory(ctx, s.categories, accountID, &categoryID); err != nil {
+		return err
+	}
+
+	return s.categories.DeleteByID(ctx, categoryID)
+}
+
+func (s *CategoryService) authorize(ctx context.Context, accountID int, userID int) error {
+	_, err := requirePermissions(ctx, s.repo, accountID, userID, PermCategoriesManage)
+	return err
+}
+
+// seedDefaultCategories создаёт стандартный набор категорий нового счёта
+func seedDefaultCategories(ctx context.Context, tx *repository.Repository, accountID int) error {
+	for _, category := range defaultCategories {
+		parentID, err := tx.CategoryRepo.Create(ctx, accountID, nil, category.name)
+		if err != nil {
+			return err
+		}
+
+		for _, child := range category.children {
+			if _, err := tx.CategoryRepo.Create(ctx, accountID, &parentID, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCategory проверяет, что категория принадлежит счёту. nil - без категории
+func checkCategory(ctx context.Context, categories *repository.CategoryRepository, accountID int, categoryID *int) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	category, err := categories.GetByID(ctx, *categoryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	if int(category.AccountID) != accountID {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// categorySubtree возвращает ID категории и всех её подкатегорий
+func categorySubtree(
+	ctx context.Context,
+	categories *repository.CategoryRepository,
+	accountID int,
+	categoryID int,
+) ([]int, error) {
+
+	all, err := categories.ListForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := newCategoryTree(all)
+	if _, ok := tree.byID[int32(categoryID)]; !ok {
+		return nil, ErrCategoryNotFound
+	}
+
+	return tree.subtree(int32(categoryID)), nil
+}
+
+func categoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCategoryNameLength {
+		return "", ErrInvalidCategoryName
+	}
+
+	return name, nil
+}
+
+// categoryTree - категории счёта с индексами по ID и по родителю
+type categoryTree struct {
+	byID     map[int32]query.Category
+	children map[int32][]query.Category // 0 - категории верхнего уровня
+}
+
+func newCategoryTree(categories []query.Category) *categoryTree {
+	tree := &categoryTree{
+		byID:     make(map[int32]query.Category, len(categories)),
+		children: make(map[int32][]query.Category),
+	}
+
+	for _, c := range categories {
+		tree.byID[c.ID] = c
+		tree.children[c.ParentID.Int32] = append(tree.children[c.ParentID.Int32], c)
+	}
+
+	return tree
+}
+
+// nodes строит поддерево подкатегорий parent (все категории верхнего уровня, если parent не задан)
+func (t *categoryTree) nodes(parent sql.NullInt32) []models.CategoryNode {
+	children := t.children[parent.Int32]
+
+	result := make([]models.CategoryNode, len(children))
+	for i, c := range children {
+		result[i] = models.CategoryNode{
+			Category: c,
+			Children: t.nodes(sql.NullInt32{Int32: c.ID, Valid: true}),
+		}
+	}
+
+	return result
+}
+
+// depth возвращает уровень категории, у категорий верхнего уровня он равен 1
+func (t *categoryTree) depth(id int32) int {
+	depth := 1
+	for c := t.byID[id]; c.ParentID.Valid; c = t.byID[c.ParentID.Int32] {
+		depth++
+	}
+
+	return depth
+}
+
+// height возвращает число уровней поддерева категории, включая её саму
+func (t *categoryTree) height(id int32) int {
+	height := 0
+	for _, c := range t.children[id] {
+		height = max(height, t.height(c.ID))
+	}
+
+	return height + 1
+}
+
+// isDescendant сообщает, совпадает ли id с ancestor или лежит в его поддереве
+func (t *categoryTree) isDescendant(id int32, ancestor int32) bool {
+	for c, ok := t.byID[id]; ok; c, ok = t.byID[c.ParentID.Int32] {
+		if c.ID == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// path возвращает названия категории и её родителей от верхнего уровня: "Еда / Продукты"
+func (t *categoryTree) path(id int32) string {
+	var names []string
+	for c, ok := t.byID[id]; ok; c, ok = t.byID[c.ParentID.Int32] {
+		names = append([]string{c.Name}, names...)
+	}
+
+	return strings.Join(names, " / ")
+}
+
+func (t *categoryTree) subtree(id int32) []int {
+	result := []int{int(id)}
+	for _, c := range t.children[id] {
+		result = append(result, t.subtree(c.ID)...)
+	}
+
+	return result
+}
+
+// hasSibling сообщает, есть ли у родителя другая подкатегория (не except) с тем же названием
+func (t *categoryTree) hasSibling(parentID *int, name string, except int32) bool {
+	var parent int32
+	if parentID != nil {
+		parent = int32(*parentID)
+	}
+
+	for _, c := range t.children[parent] {
+		if c.ID != except && strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"microservices/accounter/internal/dedupe"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+// MaxDuplicatePairs - наибольшее число пар в списке вероятных дубликатов
+const MaxDuplicatePairs = 100
+
+// DuplicateService находит вероятные дубликаты транзакций счёта и объединяет или
+// разделяет их по решению пользователя
+type DuplicateService struct {
+	repo         *repository.Repository
+	duplicates   *repository.DuplicateRepository
+	transactions *repository.TransactionRepository
+	tags         *repository.TagRepository
+}
+
+func newDuplicateService(repo *repository.Repository) *DuplicateService {
+	return &DuplicateService{
+		repo:         repo,
+		duplicates:   repo.DuplicateRepo,
+		transactions: repo.TransactionRepo,
+		tags:         repo.TagRepo,
+	}
+}
+
+// List возвращает не больше MaxDuplicatePairs пар вероятных дубликатов, новые первыми
+func (s *DuplicateService) List(ctx context.Context, accountID int, userID int) ([]models.DuplicatePair, error) {
+	// Пары составляются из всех транзакций счёта
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermTxViewAny); err != nil {
+		return nil, err
+	}
+
+	// SQL отбирает пары по сумме и дате, названия сравниваются здесь
+	match := func(p *models.DuplicateCandidatePair) bool {
+		return dedupe.Match(candidateFingerprint(&p.Transaction), candidateFingerprint(&p.Duplicate))
+	}
+
+	pairs, err := s.duplicates.ListPairs(ctx, accountID, dedupe.Window, match, MaxDuplicatePairs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		ids = append(ids, pair.Transaction.ID, pair.Duplicate.ID)
+	}
+
+	transactions, err := s.transactions.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tags.ForTransactions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]models.TaggedTransaction, len(transactions))
+	for _, t := range transactions {
+		byID[t.ID] = models.TaggedTransaction{Transaction: t, Tags: tags[t.ID]}
+	}
+
+	result := make([]models.DuplicatePair, len(pairs))
+	for i, pair := range pairs {
+		result[i] = models.DuplicatePair{
+			Transaction: byID[pair.Transaction.ID],
+			Duplicate:   byID[pair.Duplicate.ID],
+		}
+	}
+
+	return result, nil
+}
+
+// Merge объединяет дубликаты: транзакция removeID удаляется, её метки переходят к keepID,
+// а категория, примечание и идентификатор выписки - если у keepID их нет. Свои транзакции
+// объединяются с правом PermTxEditOwn, любые - с PermTxEditAny
+func (s *DuplicateService) Merge(ctx context.Context, accountID int, userID int, keepID int, removeID int) error {
+	keep, remove, err := s.pair(ctx, accountID, userID, keepID, removeID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		return tx.DuplicateRepo.Merge(ctx, keep, remove)
+	})
+}
+
+// Dismiss отмечает две транзакции как разные операции: пара больше не попадает в список
+// дубликатов. Права те же, что у Merge
+func (s *DuplicateService) Dismiss(ctx context.Context, accountID int, userID int, transactionID int, duplicateID int) error {
+	if _, _, err := s.pair(ctx, accountID, userID, transactionID, duplicateID); err != nil {
+		return err
+	}
+
+	return s.duplicates.Dismiss(ctx, transactionID, duplicateID)
+}
+
+// pair проверяет права на пару транзакций и возвращает их
+func (s *DuplicateService) pair(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	firstID int,
+	secondID int,
+) (*query.Transaction, *query.Transaction, error) {
+
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if firstID == secondID {
+		return nil, nil, ErrSameTransaction
+	}
+
+	transactions := make([]*query.Transaction, 2)
+	for i, id := range []int{firstID, secondID} {
+		transaction, err := s.transactions.GetByID(ctx, int32(id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, ErrTransactionNotFound
+			}
+			return nil, nil, err
+		}
+
+		if int(transaction.AccountID) != accountID {
+			return nil, nil, ErrTransactionNotFound
+		}
+
+		if err := requireEditPermission(access, transaction.UserID, userID); err != nil {
+			return nil, nil, err
+		}
+
+		transactions[i] = transaction
+	}
+
+	return transactions[0], transactions[1], nil
+}
+
+// findDuplicates возвращает для каждого отпечатка ID похожих на него транзакций счёта
+func findDuplicates(
+	ctx context.Context,
+	duplicates *repository.DuplicateRepository,
+	accountID int,
+	fingerprints []dedupe.Fingerprint,
+) ([][]int, error) {
+
+	result := make([][]int, len(fingerprints))
+	if len(fingerprints) == 0 {
+		return result, nil
+	}
+
+	from, to := fingerprints[0].OccurredAt, fingerprints[0].OccurredAt
+	for _, f := range fingerprints[1:] {
+		if f.OccurredAt.Before(from) {
+			from = f.OccurredAt
+		}
+		if f.OccurredAt.After(to) {
+			to = f.OccurredAt
+		}
+	}
+
+	candidates, err := duplicates.ListCandidates(ctx, accountID, from.Add(-dedupe.Window), to.Add(dedupe.Window))
+	if err != nil {
+		return nil, err
+	}
+
+	// Дубликаты совпадают по сумме, поэтому кандидаты группируются по ней
+	byAmount := make(map[money.Amount][]dedupe.Fingerprint)
+	ids := make(map[money.Amount][]int)
+	for i := range candidates {
+		amount := candidates[i].Amount
+		byAmount[amount] = append(byAmount[amount], candidateFingerprint(&candidates[i]))
+		ids[amount] = append(ids[amount], int(candidates[i].ID))
+	}
+
+	for i, f := range fingerprints {
+		for j, candidate := range byAmount[f.Amount] {
+			if dedupe.Match(f, candidate) {
+				result[i] = append(result[i], ids[f.Amount][j])
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func candidateFingerprint(c *query.ListDedupeCandidatesRow) dedupe.Fingerprint {
+	var ref *string
+	if c.ExternalRef.Valid {
+		ref = &c.ExternalRef.String
+	}
+
+	return dedupe.New(ref, c.OccurredAt, c.Amount, c.Currency, c.Title)
+}
@@ -7,15 +7,121 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrUserNotFound       = errors.New("user not found")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrSessionRevoked      = errors.New("session revoked")
+)
+
+// Account deletion
+var (
+	ErrInvalidPassword          = errors.New("invalid password")
+	ErrDeletionAlreadyScheduled = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled     = errors.New("account deletion is not scheduled")
+	ErrInvalidDeletionPlan      = errors.New("accounts must be owned by you and transferred to one of their other members")
 )
 
 // Account
 var (
 	ErrAccountNotFound = errors.New("account not found")
 	ErrForbidden       = errors.New("forbidden")
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrOwnerMembership = errors.New("the owner cannot be demoted or removed, transfer ownership first")
+)
+
+// Account role
+var (
+	ErrAccountRoleNotFound = errors.New("account role not found")
+	ErrAccountRoleExists   = errors.New("account role with this name already exists")
+	ErrAccountRoleInUse    = errors.New("account role is assigned to members, change their roles first")
+	ErrInvalidAccountRole  = errors.New("role name must be 1-50 characters other than a built-in role and permissions must be known, members.manage_admins cannot be granted")
+	ErrInvalidRoleID       = errors.New("role_id must be set for role custom and only for it")
+)
+
+// Ownership transfer
+var (
+	ErrOwnershipTransferNotFound = errors.New("ownership transfer not found")
+	ErrOwnershipTransferExpired  = errors.New("ownership transfer has expired")
+	ErrInvalidOwnershipTransfer  = errors.New("new owner must be another member of the account")
+)
+
+// Invitation
+var (
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationExists     = errors.New("this email already has a pending invitation to the account")
+	ErrInvitationNotPending = errors.New("invitation has already been accepted, declined or revoked")
+	ErrInvitationExpired    = errors.New("invitation has expired")
+	ErrAlreadyMember        = errors.New("user is already a member of the account")
+)
+
+// Invite link
+var (
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	ErrInviteLinkRevoked  = errors.New("invite link has been revoked")
+	ErrInviteLinkExpired  = errors.New("invite link has expired")
+	ErrInviteLinkUsedUp   = errors.New("invite link has reached its maximum number of uses")
+	ErrInvalidInviteLink  = errors.New("max_uses must be positive and expires_at must be in the future")
 )
 
 // Transaction
 var (
 	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotInSeries         = errors.New("transaction is not part of a recurring series")
+	ErrAmountPrecision     = errors.New("amount has more decimal places than the account currency allows")
+	ErrInvalidLimit        = errors.New("limit must be between 1 and 500")
+	ErrCursorMismatch      = errors.New("cursor was issued for a different sort order")
+	ErrNotesTooLong        = errors.New("notes must be at most 1000 characters long")
+	ErrSearchTooLong       = errors.New("search query must be at most 100 characters long")
+	ErrInvalidAmountRange  = errors.New("amount_min must not be greater than amount_max")
+
+	ErrInvalidStatementPeriod = errors.New("statement period must end after it starts and be at most a year long")
+)
+
+// Category
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryExists      = errors.New("category with this name already exists")
+	ErrInvalidCategoryName = errors.New("category name must be 1 to 100 characters long")
+	ErrCategoryCycle       = errors.New("category cannot be moved into itself or its subcategory")
+	ErrCategoryDepth       = errors.New("categories can be nested at most 5 levels deep")
+)
+
+// Tag
+var (
+	ErrInvalidTag  = errors.New("tag must be 1 to 50 characters long and must not contain commas")
+	ErrTooManyTags = errors.New("at most 20 tags are allowed")
+)
+
+// Import
+var (
+	ErrInvalidImportFile       = errors.New("invalid import file")
+	ErrUnsupportedImportFormat = errors.New("unsupported import format, use csv, ofx, qfx, qif or camt053")
+	ErrImportCurrencyMismatch  = errors.New("statement currency does not match the account currency")
+)
+
+// Duplicate
+var (
+	ErrSameTransaction = errors.New("a duplicate pair must consist of two different transactions")
+)
+
+// Archive
+var (
+	ErrInvalidArchive            = errors.New("invalid archive")
+	ErrUnsupportedArchiveFormat  = errors.New("unsupported archive format, use zip or json")
+	ErrUnsupportedArchiveVersion = errors.New("unsupported archive version")
+)
+
+// Recurring rule
+var (
+	ErrRecurringRuleNotFound = errors.New("recurring rule not found")
+	ErrInvalidRecurringRule  = errors.New("invalid recurring rule")
+)
+
+// Exchange rate
+var (
+	ErrExchangeRateNotFound   = errors.New("exchange rate not found")
+	ErrInvalidExchangeRate    = errors.New("invalid exchange rate")
+	ErrUnsupportedRatesFormat = errors.New("unsupported rates file format, use csv or cbr")
+	ErrInvalidRatesFile       = errors.New("invalid rates file")
 )
@@ -0,0 +1,253 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/rates"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+// Форматы файлов с курсами
+const (
+	RatesFormatCSV = "csv"
+	RatesFormatCBR = "cbr"
+)
+
+// ExchangeRateService управляет курсами валют пользователя и пересчитывает по ним итоги.
+// Курсы хранятся по датам; для пересчёта суммы берётся последний курс на дату транзакции или раньше
+type ExchangeRateService struct {
+	repo  *repository.Repository
+	rates *repository.ExchangeRateRepository
+}
+
+func newExchangeRateService(repo *repository.Repository) *ExchangeRateService {
+	return &ExchangeRateService{
+		repo:  repo,
+		rates: repo.ExchangeRateRepo,
+	}
+}
+
+// Save сохраняет курс на дату, заменяя уже сохранённый курс той же пары на ту же дату
+func (s *ExchangeRateService) Save(ctx context.Context, userID int, rate *models.ExchangeRate) error {
+	if rate.Base == rate.Quote {
+		return ErrInvalidExchangeRate
+	}
+
+	return s.rates.Upsert(ctx, userID, rate)
+}
+
+// List возвращает курсы пользователя
+func (s *ExchangeRateService) List(ctx context.Context, filter *models.ListExchangeRatesFilter) ([]query.ExchangeRate, error) {
+	return s.rates.List(ctx, filter)
+}
+
+// Delete удаляет курс пользователя
+func (s *ExchangeRateService) Delete(ctx context.Context, id int, userID int) error {
+	found, err := s.rates.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrExchangeRateNotFound
+	}
+
+	return nil
+}
+
+// Import сохраняет все курсы из файла в одной транзакции БД и возвращает их число.
+// Ошибка в любой строке файла отменяет импорт целиком
+func (s *ExchangeRateService) Import(ctx context.Context, userID int, format string, file io.Reader) (int, error) {
+	var (
+		parsed []models.ExchangeRate
+		err    error
+	)
+
+	switch format {
+	case RatesFormatCSV:
+		parsed, err = rates.ParseCSV(file)
+	case RatesFormatCBR:
+		parsed, err = rates.ParseCBR(file)
+	default:
+		return 0, ErrUnsupportedRatesFormat
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidRatesFile, err)
+	}
+
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		for i := range parsed {
+			if err := tx.ExchangeRateRepo.Upsert(ctx, userID, &parsed[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(parsed), nil
+}
+
+// Convert пересчитывает дневные итоги в валюту to по курсам пользователя на эти дни
+func (s *ExchangeRateService) Convert(
+	ctx context.Context,
+	userID int,
+	totals []models.DailyTotal,
+	to money.Currency,
+) (*models.ConvertedTotal, error) {
+
+	result := &models.ConvertedTotal{Currency: to}
+	if len(totals) == 0 {
+		return result, nil
+	}
+
+	until := totals[0].Day
+	for _, t := range totals {
+		if t.Day.After(until) {
+			until = t.Day
+		}
+	}
+
+	table, err := s.loadTable(ctx, userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range totals {
+		rate, ok := table.rate(t.Currency, to, t.Day)
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: %s to %s on %s",
+				ErrExchangeRateNotFound, t.Currency, to, t.Day.Format(time.DateOnly),
+			)
+		}
+
+		result.Income += t.Income.Convert(rate, to)
+		result.Expense += t.Expense.Convert(rate, to)
+	}
+
+	result.Total = result.Income + result.Expense
+
+	return result, nil
+}
+
+func (s *ExchangeRateService) loadTable(ctx context.Context, userID int, until time.Time) (*rateTable, error) {
+	rows, err := s.rates.ListUntil(ctx, userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &rateTable{points: make(map[currencyPair][]ratePoint)}
+	for _, row := range rows {
+		rate, err := money.ParseRate(row.Rate)
+		if err != nil {
+			return nil, err
+		}
+
+		pair := currencyPair{base: row.BaseCurrency, quote: row.QuoteCurrency}
+		table.points[pair] = append(table.points[pair], ratePoint{day: row.RateDate, rate: rate})
+	}
+
+	return table, nil
+}
+
+type currencyPair struct {
+	base  money.Currency
+	quote money.Currency
+}
+
+type ratePoint struct {
+	day  time.Time
+	rate money.Rate
+}
+
+// rateTable - курсы пользователя по парам валют, отсортированные по дате
+type rateTable struct {
+	points map[currencyPair][]ratePoint
+}
+
+// rate ищет курс from/to на дату: прямой, обратный или кросс-курс через третью валюту
+// (например, USD/EUR через курсы USD/RUB и EUR/RUB из выгрузки ЦБ)
+func (t *rateTable) rate(from, to money.Currency, day time.Time) (money.Rate, bool) {
+	if from == to {
+		return money.One, true
+	}
+
+	if rate, ok := t.pairRate(from, to, day); ok {
+		return rate, true
+	}
+
+	for _, pivot := range t.currencies() {
+		if pivot == from || pivot == to {
+			continue
+		}
+
+		toPivot, ok := t.pairRate(from, pivot, day)
+		if !ok {
+			continue
+		}
+
+		fromPivot, ok := t.pairRate(pivot, to, day)
+		if !ok {
+			continue
+		}
+
+		return toPivot.Mul(fromPivot), true
+	}
+
+	return money.Rate{}, false
+}
+
+// currencies возвращает все валюты таблицы в алфавитном порядке, чтобы выбор
+// валюты для кросс-курса не зависел от порядка обхода map
+func (t *rateTable) currencies() []money.Currency {
+	seen := make(map[money.Currency]bool)
+	for pair := range t.points {
+		seen[pair.base] = true
+		seen[pair.quote] = true
+	}
+
+	result := make([]money.Currency, 0, len(seen))
+	for currency := range seen {
+		result = append(result, currency)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+// pairRate ищет прямой или обратный курс from/to на дату
+func (t *rateTable) pairRate(from, to money.Currency, day time.Time) (money.Rate, bool) {
+	if rate, ok := t.latest(currencyPair{base: from, quote: to}, day); ok {
+		return rate, true
+	}
+
+	if rate, ok := t.latest(currencyPair{base: to, quote: from}, day); ok {
+		return rate.Inverse(), true
+	}
+
+	return money.Rate{}, false
+}
+
+// latest возвращает последний курс пары на дату day или раньше
+func (t *rateTable) latest(pair currencyPair, day time.Time) (money.Rate, bool) {
+	points := t.points[pair]
+
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].day.After(day)
+	})
+	if i == 0 {
+		return money.Rate{}, false
+	}
+
+	return points[i-1].rate, true
+}
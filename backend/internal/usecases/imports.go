@@ -0,0 +1,265 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"microservices/accounter/internal/dedupe"
+	"microservices/accounter/internal/imports"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository"
+)
+
+// Форматы банковских выписок
+const (
+	ImportFormatCSV     = "csv"
+	ImportFormatOFX     = "ofx"
+	ImportFormatQFX     = "qfx"
+	ImportFormatQIF     = "qif"
+	ImportFormatCAMT053 = "camt053"
+)
+
+type ImportService struct {
+	repo         *repository.Repository
+	accounts     *repository.AccountRepository
+	transactions *repository.TransactionRepository
+	duplicates   *repository.DuplicateRepository
+}
+
+func newImportService(repo *repository.Repository) *ImportService {
+	return &ImportService{
+		repo:         repo,
+		accounts:     repo.AccountRepo,
+		transactions: repo.TransactionRepo,
+		duplicates:   repo.DuplicateRepo,
+	}
+}
+
+// Import разбирает выписку в формате format и записывает её транзакции в счёт одной транзакцией БД.
+// mapping нужен только для CSV. Транзакции, которые уже были импортированы (по идентификатору
+// транзакции в выписке), пропускаются, а похожие на существующие отмечаются как вероятные
+// дубликаты и пропускаются при options.SkipDuplicates. При options.DryRun или если хотя бы одна
+// строка содержит ошибку, ничего не записывается: результат содержит прочитанные транзакции
+// и ошибки по строкам для предпросмотра
+func (s *ImportService) Import(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	format string,
+	mapping *imports.CSVMapping,
+	file io.Reader,
+	options models.ImportOptions,
+) (*models.ImportResult, error) {
+
+	// Строки с суммами, которые пользователь не может создавать, попадают в ошибки по строкам
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !access.has(PermTxCreateIncome) && !access.has(PermTxCreateExpense) {
+		return nil, &PermissionError{Permission: PermTxCreateExpense}
+	}
+
+	var statement *imports.Statement
+	switch format {
+	case ImportFormatCSV:
+		statement, err = imports.ParseCSV(file, mapping)
+	case ImportFormatOFX, ImportFormatQFX:
+		statement, err = imports.ParseOFX(file)
+	case ImportFormatQIF:
+		statement, err = imports.ParseQIF(file)
+	case ImportFormatCAMT053:
+		statement, err = imports.ParseCAMT053(file)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		if errors.Is(err, imports.ErrInvalidMapping) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImportFile, err)
+	}
+
+	return s.save(ctx, accountID, userID, access, statement, options)
+}
+
+// save проверяет прочитанные транзакции по правилам счёта, отмечает уже импортированные
+// и вероятные дубликаты и, если ошибок нет и это не предпросмотр, записывает остальные
+func (s *ImportService) save(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	access *memberAccess,
+	statement *imports.Statement,
+	options models.ImportOptions,
+) (*models.ImportResult, error) {
+
+	currency, err := accountCurrency(ctx, s.accounts, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выписка в другой валюте не пересчитывается: её суммы нельзя записать в счёт как есть
+	if statement.Currency != "" && statement.Currency != currency {
+		return nil, ErrImportCurrencyMismatch
+	}
+
+	result := &models.ImportResult{Currency: currency, Errors: statement.Errors}
+
+	for _, row := range statement.Rows {
+		message := checkImportRow(&row, currency)
+		if message == "" {
+			if err := requireAmountPermission(access, row.Amount); err != nil {
+				message = err.Error()
+			}
+		}
+		if message != "" {
+			result.Errors = append(result.Errors, models.ImportLineError{Line: row.Line, Message: message})
+			continue
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	sortLineErrors(result.Errors)
+
+	if err := s.markImported(ctx, accountID, result); err != nil {
+		return nil, err
+	}
+
+	if err := s.markDuplicates(ctx, accountID, result); err != nil {
+		return nil, err
+	}
+
+	if options.DryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	params := make([]models.CreateTransactionParams, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		if row.AlreadyImported {
+			continue
+		}
+
+		if options.SkipDuplicates && len(row.DuplicateOf) > 0 {
+			result.Skipped++
+			continue
+		}
+
+		params = append(params, models.CreateTransactionParams{
+			AccountID:   accountID,
+			UserID:      userID,
+			Title:       row.Title,
+			Amount:      row.Amount,
+			Currency:    currency,
+			OccurredAt:  row.OccurredAt,
+			Notes:       row.Notes,
+			ExternalRef: row.ExternalRef,
+		})
+	}
+
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		return tx.TransactionRepo.CreateTransactions(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(params)
+	return result, nil
+}
+
+// markImported отмечает транзакции, идентификатор которых уже есть в счёте
+// или встречался выше в той же выписке
+func (s *ImportService) markImported(ctx context.Context, accountID int, result *models.ImportResult) error {
+	var refs []string
+	for _, row := range result.Rows {
+		if row.ExternalRef != nil {
+			refs = append(refs, *row.ExternalRef)
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	existing, err := s.transactions.ExistingExternalRefs(ctx, accountID, refs)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Rows {
+		ref := result.Rows[i].ExternalRef
+		if ref == nil {
+			continue
+		}
+
+		if existing[*ref] {
+			result.Rows[i].AlreadyImported = true
+			result.Skipped++
+		}
+		existing[*ref] = true
+	}
+
+	return nil
+}
+
+// markDuplicates отмечает транзакции, похожие на уже существующие в счёте.
+// Уже импортированные транзакции не проверяются: они пропускаются и так
+func (s *ImportService) markDuplicates(ctx context.Context, accountID int, result *models.ImportResult) error {
+	var (
+		rows         []int
+		fingerprints []dedupe.Fingerprint
+	)
+	for i, row := range result.Rows {
+		if row.AlreadyImported {
+			continue
+		}
+
+		rows = append(rows, i)
+		fingerprints = append(fingerprints, dedupe.New(row.ExternalRef, row.OccurredAt, row.Amount, result.Currency, row.Title))
+	}
+
+	duplicates, err := findDuplicates(ctx, s.duplicates, accountID, fingerprints)
+	if err != nil {
+		return err
+	}
+
+	for i, ids := range duplicates {
+		if len(ids) == 0 {
+			continue
+		}
+
+		result.Rows[rows[i]].DuplicateOf = ids
+		result.Duplicates++
+	}
+
+	return nil
+}
+
+// checkImportRow проверяет транзакцию выписки так же, как при создании транзакции вручную,
+// и нормализует примечание. Возвращает текст ошибки или пустую строку
+func checkImportRow(row *models.ImportRow, currency money.Currency) string {
+	if !row.Amount.Fits(currency) {
+		return ErrAmountPrecision.Error()
+	}
+
+	notes, err := normalizeNotes(row.Notes)
+	if err != nil {
+		return err.Error()
+	}
+	row.Notes = notes
+
+	return ""
+}
+
+// sortLineErrors упорядочивает ошибки по номеру строки
+func sortLineErrors(errs []models.ImportLineError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+}
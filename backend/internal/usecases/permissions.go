@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+// Permission - право участника счёта на действие
+type Permission string
+
+// Права на транзакции, отчёты и настройки счёта. Права на транзакции распространяются и на серии:
+// правило повторения создаёт и меняет тот, кто может создавать и менять его вхождения
+const (
+	// PermTxViewAny - видеть все транзакции и серии счёта. Без него видны только свои
+	PermTxViewAny Permission = "tx.view.any"
+	// PermTxCreateIncome - создавать и импортировать доходы
+	PermTxCreateIncome Permission = "tx.create.income"
+	// PermTxCreateExpense - создавать и импортировать расходы
+	PermTxCreateExpense Permission = "tx.create.expense"
+	// PermTxEditOwn - менять и удалять свои транзакции
+	PermTxEditOwn Permission = "tx.edit.own"
+	// PermTxEditAny - менять и удалять любые транзакции счёта
+	PermTxEditAny Permission = "tx.edit.any"
+	// PermReportsView - остаток, итоги и выписка по счёту
+	PermReportsView Permission = "reports.view"
+	// PermCategoriesManage - создавать, переименовывать и удалять категории
+	PermCategoriesManage Permission = "categories.manage"
+	// PermAccountEdit - менять начальный остаток счёта
+	PermAccountEdit Permission = "account.edit"
+)
+
+// Права на управление участниками. Роль владельца не выдаётся и не отнимается ни одним правом:
+// она меняется только передачей счёта
+const (
+	// PermMembersInvite - приглашать viewer и editor, просматривать и отзывать приглашения и ссылки-приглашения
+	PermMembersInvite Permission = "members.invite"
+	// PermMembersRemove - удалять из счёта viewer и editor
+	PermMembersRemove Permission = "members.remove"
+	// PermMembersChangeRole - менять роль участника между viewer и editor
+	PermMembersChangeRole Permission = "members.change_role"
+	// PermMembersManageAdmins - дополнительно к правам выше приглашать, назначать, понижать и удалять admin
+	// и участников с пользовательскими ролями. В пользовательскую роль не входит
+	PermMembersManageAdmins Permission = "members.manage_admins"
+)
+
+// Permissions - все права в порядке, в котором они показываются пользователю
+var Permissions = []Permission{
+	PermTxViewAny,
+	PermTxCreateIncome,
+	PermTxCreateExpense,
+	PermTxEditOwn,
+	PermTxEditAny,
+	PermReportsView,
+	PermCategoriesManage,
+	PermAccountEdit,
+	PermMembersInvite,
+	PermMembersRemove,
+	PermMembersChangeRole,
+	PermMembersManageAdmins,
+}
+
+// rolePermissions - матрица прав встроенных ролей счёта. Права роли custom задаёт владелец счёта
+var rolePermissions = map[query.AccountMembersRole][]Permission{
+	query.AccountMembersRoleOwner: Permissions,
+	query.AccountMembersRoleAdmin: {
+		PermTxViewAny,
+		PermTxCreateIncome,
+		PermTxCreateExpense,
+		PermTxEditOwn,
+		PermTxEditAny,
+		PermReportsView,
+		PermCategoriesManage,
+		PermAccountEdit,
+		PermMembersInvite,
+		PermMembersRemove,
+		PermMembersChangeRole,
+	},
+	query.AccountMembersRoleEditor: {
+		PermTxViewAny,
+		PermTxCreateIncome,
+		PermTxCreateExpense,
+		PermTxEditOwn,
+		PermReportsView,
+		PermCategoriesManage,
+	},
+	query.AccountMembersRoleViewer: {
+		PermTxViewAny,
+		PermReportsView,
+	},
+}
+
+// PermissionError - у участника счёта нет права на действие. errors.Is(err, ErrForbidden) выполняется
+type PermissionError struct {
+	Permission Permission
+}
+
+func (e *PermissionError) Error() string {
+	return "forbidden: missing permission " + string(e.Permission)
+}
+
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// memberAccess - права участника счёта
+type memberAccess struct {
+	permissions map[Permission]bool
+}
+
+func (a *memberAccess) has(permission Permission) bool {
+	return a.permissions[permission]
+}
+
+// loadAccess возвращает права пользователя в счёте. Участник с пользовательской ролью,
+// удалённой вместе со счётом, прав не имеет. Возвращает sql.ErrNoRows, если пользователь не участник счёта
+func loadAccess(ctx context.Context, repo *repository.Repository, accountID, userID int) (*memberAccess, error) {
+	member, err := repo.AccountMemberRepo.GetMember(ctx, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &memberAccess{permissions: make(map[Permission]bool)}
+
+	for _, permission := range rolePermissions[member.Role] {
+		access.permissions[permission] = true
+	}
+
+	if member.Role == query.AccountMembersRoleCustom && member.RoleID.Valid {
+		permissions, err := repo.AccountRoleRepo.Permissions(ctx, int(member.RoleID.Int32))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, permission := range permissions {
+			access.permissions[Permission(permission)] = true
+		}
+	}
+
+	return access, nil
+}
+
+// requirePermissions возвращает права пользователя в счёте, если у него есть все права permissions.
+// Не участнику счёта возвращается ErrForbidden, участнику без права - PermissionError с первым недостающим
+func requirePermissions(
+	ctx context.Context,
+	repo *repository.Repository,
+	accountID, userID int,
+	permissions ...Permission,
+) (*memberAccess, error) {
+	access, err := loadAccess(ctx, repo, accountID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+
+	for _, permission := range permissions {
+		if !access.has(permission) {
+			return nil, &PermissionError{Permission: permission}
+		}
+	}
+
+	return access, nil
+}
+
+// requireOwner проверяет, что пользователь - владелец счёта. Только владелец удаляет и передаёт счёт
+// и определяет его пользовательские роли: право на это нельзя выдать, иначе участник мог бы расширить
+// собственные права. Поэтому проверяется сама роль, а не права. Остальным возвращается ErrForbidden
+func requireOwner(ctx context.Context, repo *repository.Repository, accountID, userID int) error {
+	role, err := repo.AccountMemberRepo.GetMemberRole(ctx, accountID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrForbidden
+		}
+		return err
+	}
+
+	if role != query.AccountMembersRoleOwner {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// requireRoleManagement проверяет, что участник может управлять участниками с ролями roles:
+// приглашать, назначать, понижать и удалять кого-либо кроме viewer и editor можно только
+// с правом PermMembersManageAdmins
+func requireRoleManagement(actor *memberAccess, roles ...string) error {
+	for _, role := range roles {
+		switch query.AccountMembersRole(role) {
+		case query.AccountMembersRoleViewer, query.AccountMembersRoleEditor:
+		default:
+			if !actor.has(PermMembersManageAdmins) {
+				return &PermissionError{Permission: PermMembersManageAdmins}
+			}
+		}
+	}
+
+	return nil
+}
+
+// requireGrantable проверяет, что участник actor сам имеет все права permissions выдаваемой роли.
+// Иначе участник с урезанной ролью пригласил бы второй свой аккаунт с ролью шире и получил бы
+// недоступные ему права. Возвращает PermissionError с первым недостающим правом
+func requireGrantable(actor *memberAccess, permissions []Permission) error {
+	for _, permission := range permissions {
+		if !actor.has(permission) {
+			return &PermissionError{Permission: permission}
+		}
+	}
+
+	return nil
+}
+
+// requireEditPermission проверяет право менять и удалять транзакцию или серию автора authorID
+func requireEditPermission(access *memberAccess, authorID sql.NullInt32, userID int) error {
+	if access.has(PermTxEditAny) {
+		return nil
+	}
+
+	if !access.has(PermTxEditOwn) {
+		return &PermissionError{Permission: PermTxEditOwn}
+	}
+
+	if !isAuthor(authorID, userID) {
+		return &PermissionError{Permission: PermTxEditAny}
+	}
+
+	return nil
+}
+
+// amountPermission возвращает право на создание транзакции с суммой amount: расходы отрицательные
+func amountPermission(amount money.Amount) Permission {
+	if amount < 0 {
+		return PermTxCreateExpense
+	}
+
+	return PermTxCreateIncome
+}
+
+// requireAmountPermission проверяет право записать транзакцию с суммой amount
+func requireAmountPermission(access *memberAccess, amount money.Amount) error {
+	if permission := amountPermission(amount); !access.has(permission) {
+		return &PermissionError{Permission: permission}
+	}
+
+	return nil
+}
+
+// requireOwnTransactions ограничивает фильтр транзакциями пользователя, если он не видит чужие.
+// Фильтр по другому автору без права PermTxViewAny запрещён
+func requireOwnTransactions(access *memberAccess, userID int, filter *models.ListTransactionsFilter) error {
+	if access.has(PermTxViewAny) {
+		return nil
+	}
+
+	if filter.UserID != nil && *filter.UserID != userID {
+		return &PermissionError{Permission: PermTxViewAny}
+	}
+
+	filter.UserID = &userID
+	return nil
+}
@@ -0,0 +1,426 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"microservices/accounter/internal/money"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role       query.AccountMembersRole
+		permission Permission
+		want       bool
+	}{
+		{role: query.AccountMembersRoleOwner, permission: PermMembersManageAdmins, want: true},
+		{role: query.AccountMembersRoleOwner, permission: PermAccountEdit, want: true},
+
+		{role: query.AccountMembersRoleAdmin, permission: PermTxEditAny, want: true},
+		{role: query.AccountMembersRoleAdmin, permission: PermAccountEdit, want: true},
+		{role: query.AccountMembersRoleAdmin, permission: PermMembersInvite, want: true},
+		{role: query.AccountMembersRoleAdmin, permission: PermMembersChangeRole, want: true},
+		{role: query.AccountMembersRoleAdmin, permission: PermMembersManageAdmins, want: false},
+
+		{role: query.AccountMembersRoleEditor, permission: PermTxCreateIncome, want: true},
+		{role: query.AccountMembersRoleEditor, permission: PermTxCreateExpense, want: true},
+		{role: query.AccountMembersRoleEditor, permission: PermTxEditOwn, want: true},
+		{role: query.AccountMembersRoleEditor, permission: PermCategoriesManage, want: true},
+		{role: query.AccountMembersRoleEditor, permission: PermTxEditAny, want: false},
+		{role: query.AccountMembersRoleEditor, permission: PermAccountEdit, want: false},
+		{role: query.AccountMembersRoleEditor, permission: PermMembersInvite, want: false},
+
+		{role: query.AccountMembersRoleViewer, permission: PermTxViewAny, want: true},
+		{role: query.AccountMembersRoleViewer, permission: PermReportsView, want: true},
+		{role: query.AccountMembersRoleViewer, permission: PermTxCreateExpense, want: false},
+		{role: query.AccountMembersRoleViewer, permission: PermTxEditOwn, want: false},
+
+		// Права пользовательской роли задаёт владелец, сама роль custom прав не даёт
+		{role: query.AccountMembersRoleCustom, permission: PermTxViewAny, want: false},
+		{role: query.AccountMembersRoleCustom, permission: PermReportsView, want: false},
+	}
+
+	for _, tt := range tests {
+		access := accessWith(rolePermissions[tt.role]...)
+		if got := access.has(tt.permission); got != tt.want {
+			t.Errorf("%s has %s = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+
+	// Владелец имеет все права
+	if len(rolePermissions[query.AccountMembersRoleOwner]) != len(Permissions) {
+		t.Errorf("owner has %d permissions, want %d", len(rolePermissions[query.AccountMembersRoleOwner]), len(Permissions))
+	}
+}
+
+func TestRequireRoleManagement(t *testing.T) {
+	admin := accessWith(rolePermissions[query.AccountMembersRoleAdmin]...)
+	owner := accessWith(rolePermissions[query.AccountMembersRoleOwner]...)
+
+	tests := []struct {
+		name  string
+		actor *memberAccess
+		roles []string
+		err   error
+	}{
+		{name: "admin manages viewer", actor: admin, roles: []string{"viewer"}},
+		{name: "admin manages editor", actor: admin, roles: []string{"viewer", "editor"}},
+		{name: "admin manages admin", actor: admin, roles: []string{"admin"}, err: ErrForbidden},
+		{name: "admin promotes to admin", actor: admin, roles: []string{"editor", "admin"}, err: ErrForbidden},
+		{name: "admin manages custom", actor: admin, roles: []string{"custom"}, err: ErrForbidden},
+		{name: "owner manages admin", actor: owner, roles: []string{"editor", "admin"}},
+		{name: "owner manages custom", actor: owner, roles: []string{"custom"}},
+	}
+
+	for _, tt := range tests {
+		err := requireRoleManagement(tt.actor, tt.roles...)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: requireRoleManagement() error = %v, want %v", tt.name, err, tt.err)
+		}
+		if err != nil && missingPermission(err) != PermMembersManageAdmins {
+			t.Errorf("%s: missing permission = %s, want %s", tt.name, missingPermission(err), PermMembersManageAdmins)
+		}
+	}
+}
+
+func TestRequireGrantable(t *testing.T) {
+	// Пользовательская роль "расходы и приглашения"
+	limited := accessWith(PermTxViewAny, PermReportsView, PermTxCreateExpense, PermMembersInvite)
+	admin := accessWith(rolePermissions[query.AccountMembersRoleAdmin]...)
+
+	tests := []struct {
+		name    string
+		actor   *memberAccess
+		granted []Permission
+		missing Permission
+	}{
+		{name: "limited grants viewer", actor: limited, granted: rolePermissions[query.AccountMembersRoleViewer]},
+		{name: "limited grants editor", actor: limited, granted: rolePermissions[query.AccountMembersRoleEditor], missing: PermTxCreateIncome},
+		{name: "limited grants admin", actor: limited, granted: rolePermissions[query.AccountMembersRoleAdmin], missing: PermTxCreateIncome},
+		{name: "limited grants subset", actor: limited, granted: []Permission{PermTxCreateExpense}},
+		{name: "limited grants own set", actor: limited, granted: []Permission{PermTxViewAny, PermReportsView, PermTxCreateExpense, PermMembersInvite}},
+		{name: "limited grants edit any", actor: limited, granted: []Permission{PermTxCreateExpense, PermTxEditAny}, missing: PermTxEditAny},
+		{name: "empty role", actor: limited},
+		{name: "admin grants editor", actor: admin, granted: rolePermissions[query.AccountMembersRoleEditor]},
+		{name: "admin grants admin", actor: admin, granted: rolePermissions[query.AccountMembersRoleAdmin]},
+		{name: "admin grants owner", actor: admin, granted: rolePermissions[query.AccountMembersRoleOwner], missing: PermMembersManageAdmins},
+	}
+
+	for _, tt := range tests {
+		err := requireGrantable(tt.actor, tt.granted)
+		if got := missingPermission(err); got != tt.missing {
+			t.Errorf("%s: requireGrantable() missing = %q, want %q", tt.name, got, tt.missing)
+		}
+	}
+}
+
+func TestRequireEditPermission(t *testing.T) {
+	editor := accessWith(rolePermissions[query.AccountMembersRoleEditor]...)
+	admin := accessWith(rolePermissions[query.AccountMembersRoleAdmin]...)
+	viewer := accessWith(rolePermissions[query.AccountMembersRoleViewer]...)
+
+	const userID = 7
+	own := sql.NullInt32{Int32: userID, Valid: true}
+	other := sql.NullInt32{Int32: userID + 1, Valid: true}
+
+	// Автор удалённого пользователя не известен
+	deleted := sql.NullInt32{}
+
+	tests := []struct {
+		name    string
+		access  *memberAccess
+		author  sql.NullInt32
+		missing Permission
+	}{
+		{name: "editor edits own", access: editor, author: own},
+		{name: "editor edits other", access: editor, author: other, missing: PermTxEditAny},
+		{name: "editor edits deleted author", access: editor, author: deleted, missing: PermTxEditAny},
+		{name: "admin edits own", access: admin, author: own},
+		{name: "admin edits other", access: admin, author: other},
+		{name: "admin edits deleted author", access: admin, author: deleted},
+		{name: "viewer edits own", access: viewer, author: own, missing: PermTxEditOwn},
+		{name: "viewer edits other", access: viewer, author: other, missing: PermTxEditOwn},
+	}
+
+	for _, tt := range tests {
+		err := requireEditPermission(tt.access, tt.author, userID)
+		if got := missingPermission(err); got != tt.missing {
+			t.Errorf("%s: requireEditPermission() missing = %q, want %q", tt.name, got, tt.missing)
+		}
+	}
+}
+
+func TestRequireAmountPermission(t *testing.T) {
+	income := accessWith(PermTxCreateIncome)
+	expense := accessWith(PermTxCreateExpense)
+
+	tests := []struct {
+		access  *memberAccess
+		amount  money.Amount
+		missing Permission
+	}{
+		{access: income, amount: 150050},
+		{access: income, amount: -150050, missing: PermTxCreateExpense},
+		{access: expense, amount: -1},
+		{access: expense, amount: 1, missing: PermTxCreateIncome},
+
+		// Нулевая сумма записывается как доход
+		{access: income, amount: 0},
+		{access: expense, amount: 0, missing: PermTxCreateIncome},
+	}
+
+	for _, tt := range tests {
+		err := requireAmountPermission(tt.access, tt.amount)
+		if got := missingPermission(err); got != tt.missing {
+			t.Errorf("requireAmountPermission(%v, %s) missing = %q, want %q", tt.access.permissions, tt.amount, got, tt.missing)
+		}
+	}
+}
+
+func TestRequireOwner(t *testing.T) {
+	repo := testMembersRepo(t)
+
+	tests := []struct {
+		userID int
+		err    error
+	}{
+		{userID: testOwnerID},
+		{userID: testAdminID, err: ErrForbidden},
+		{userID: testLimitedID, err: ErrForbidden},
+		{userID: testOrphanID, err: ErrForbidden},
+		{userID: testStrangerID, err: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		err := requireOwner(context.Background(), repo, testAccountID, tt.userID)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("requireOwner(user %d) error = %v, want %v", tt.userID, err, tt.err)
+		}
+	}
+}
+
+func TestLoadAccess(t *testing.T) {
+	repo := testMembersRepo(t)
+
+	tests := []struct {
+		name    string
+		userID  int
+		granted []Permission
+		denied  []Permission
+		err     error
+	}{
+		{
+			name:    "owner",
+			userID:  testOwnerID,
+			granted: Permissions,
+		},
+		{
+			name:    "admin",
+			userID:  testAdminID,
+			granted: []Permission{PermTxEditAny, PermMembersInvite},
+			denied:  []Permission{PermMembersManageAdmins},
+		},
+		{
+			name:    "custom role",
+			userID:  testLimitedID,
+			granted: []Permission{PermTxViewAny, PermTxCreateExpense, PermMembersInvite},
+			denied:  []Permission{PermTxCreateIncome, PermTxEditOwn, PermReportsView},
+		},
+		{
+			// Пользовательская роль удалена: role_id обнулён, прав не остаётся
+			name:   "custom role without role_id",
+			userID: testOrphanID,
+			denied: Permissions,
+		},
+		{
+			name:   "not a member",
+			userID: testStrangerID,
+			err:    sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		access, err := loadAccess(context.Background(), repo, testAccountID, tt.userID)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: loadAccess() error = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		for _, permission := range tt.granted {
+			if !access.has(permission) {
+				t.Errorf("%s: has %s = false, want true", tt.name, permission)
+			}
+		}
+		for _, permission := range tt.denied {
+			if access.has(permission) {
+				t.Errorf("%s: has %s = true, want false", tt.name, permission)
+			}
+		}
+	}
+
+	// Не участнику requirePermissions возвращает ErrForbidden, участнику без права - недостающее право
+	if _, err := requirePermissions(context.Background(), repo, testAccountID, testStrangerID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("requirePermissions(stranger) error = %v, want %v", err, ErrForbidden)
+	}
+	_, err := requirePermissions(context.Background(), repo, testAccountID, testLimitedID, PermTxViewAny, PermTxCreateIncome)
+	if got := missingPermission(err); got != PermTxCreateIncome {
+		t.Errorf("requirePermissions(limited) missing = %q, want %q", got, PermTxCreateIncome)
+	}
+}
+
+func accessWith(permissions ...Permission) *memberAccess {
+	access := &memberAccess{permissions: make(map[Permission]bool)}
+	for _, permission := range permissions {
+		access.permissions[permission] = true
+	}
+	return access
+}
+
+// missingPermission возвращает недостающее право из PermissionError, для nil - пустую строку
+func missingPermission(err error) Permission {
+	var permissionErr *PermissionError
+	if errors.As(err, &permissionErr) {
+		return permissionErr.Permission
+	}
+	if err != nil {
+		return Permission("unexpected: " + err.Error())
+	}
+	return ""
+}
+
+const (
+	testAccountID     = 1
+	testLimitedRoleID = 10
+)
+
+// Пользователи счёта testAccountID. testStrangerID в счёте не участвует
+const (
+	testOwnerID = iota + 1
+	testAdminID
+	testLimitedID
+	testOrphanID
+	testStrangerID
+)
+
+// testMembersRepo возвращает репозиторий над участниками счёта testAccountID без MySQL
+func testMembersRepo(t *testing.T) *repository.Repository {
+	db := sql.OpenDB(&fakeConnector{
+		members: map[int]fakeMember{
+			testOwnerID:   {role: "owner"},
+			testAdminID:   {role: "admin"},
+			testLimitedID: {role: "custom", roleID: testLimitedRoleID},
+			testOrphanID:  {role: "custom"},
+		},
+		permissions: map[int][]string{
+			testLimitedRoleID: {"members.invite", "tx.create.expense", "tx.view.any"},
+		},
+	})
+	t.Cleanup(func() { _ = db.Close() })
+
+	return repository.New(db)
+}
+
+type fakeMember struct {
+	role   string
+	roleID int // 0 - role_id NULL
+}
+
+// fakeConnector - драйвер БД, отвечающий только на запросы участника счёта и прав его роли
+type fakeConnector struct {
+	members     map[int]fakeMember
+	permissions map[int][]string
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver: use sql.OpenDB")
+}
+
+type fakeConn struct {
+	db *fakeConnector
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake driver: prepare is not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake driver: transactions are not supported")
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	arg := func(i int) int { return int(args[i].Value.(int64)) }
+
+	switch {
+	case strings.Contains(q, "name: GetAccountMember :one"), strings.Contains(q, "name: GetAccountMemberRole :one"):
+		member, ok := c.db.members[arg(1)]
+		if arg(0) != testAccountID || !ok {
+			return &fakeRows{columns: []string{"role"}}, nil
+		}
+
+		var roleID driver.Value
+		if member.roleID != 0 {
+			roleID = int64(member.roleID)
+		}
+
+		if strings.Contains(q, "GetAccountMemberRole") {
+			return &fakeRows{columns: []string{"role"}, values: [][]driver.Value{{member.role}}}, nil
+		}
+		return &fakeRows{columns: []string{"role", "role_id"}, values: [][]driver.Value{{member.role, roleID}}}, nil
+
+	case strings.Contains(q, "name: ListAccountRolePermissions :many"):
+		rows := &fakeRows{columns: []string{"permission"}}
+		for _, permission := range c.db.permissions[arg(0)] {
+			rows.values = append(rows.values, []driver.Value{permission})
+		}
+		return rows, nil
+	}
+
+	return nil, errors.New("fake driver: unexpected query " + q)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,806 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"microservices/accounter/internal/config"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/pkg/logger"
+)
+
+// RecurringService управляет правилами повторения. Вхождения правила создаются
+// как обычные транзакции не сразу все, а только до горизонта (now + horizon):
+// лениво при чтении списка транзакций счёта и периодически фоновым планировщиком
+type RecurringService struct {
+	repo         *repository.Repository
+	rules        *repository.RecurringRuleRepository
+	accounts     *repository.AccountRepository
+	transactions *repository.TransactionRepository
+	horizon      time.Duration
+}
+
+func newRecurringService(repo *repository.Repository, cfg config.Recurring) *RecurringService {
+	return &RecurringService{
+		repo:         repo,
+		rules:        repo.RecurringRuleRepo,
+		accounts:     repo.AccountRepo,
+		transactions: repo.TransactionRepo,
+		horizon:      cfg.Horizon,
+	}
+}
+
+// Create создаёт правило повторения и сразу материализует его вхождения до горизонта
+func (s *RecurringService) Create(ctx context.Context, params *models.CreateRecurringRuleParams) (int, error) {
+	_, err := requirePermissions(ctx, s.repo, params.AccountID, params.UserID, amountPermission(params.Amount))
+	if err != nil {
+		return 0, err
+	}
+
+	if err := validateRecurringRule(params.StartsAt, params.Interval, params.EndsAt, params.MaxOccurrences); err != nil {
+		return 0, err
+	}
+
+	// Вхождения правила создаются в валюте счёта
+	params.Currency, err = accountCurrency(ctx, s.accounts, params.AccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !params.Amount.Fits(params.Currency) {
+		return 0, ErrAmountPrecision
+	}
+
+	if err := checkCategory(ctx, s.repo.CategoryRepo, params.AccountID, params.CategoryID); err != nil {
+		return 0, err
+	}
+
+	params.Notes, err = normalizeNotes(params.Notes)
+	if err != nil {
+		return 0, err
+	}
+
+	params.Tags, err = normalizeTags(params.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	var ruleID int
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		ruleID, err = tx.RecurringRuleRepo.Create(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		if err := setRuleTags(ctx, tx, params.AccountID, ruleID, params.Tags); err != nil {
+			return err
+		}
+
+		return s.materialize(ctx, tx, ruleID, s.until(params.StartsAt))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ruleID, nil
+}
+
+// List возвращает правила повторения счёта с их метками. Без права PermTxViewAny - только правила пользователя
+func (s *RecurringService) List(ctx context.Context, accountID int, userID int) ([]models.TaggedRecurringRule, error) {
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.rules.ListForAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.TagRepo.ForAccountRules(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.TaggedRecurringRule, 0, len(rules))
+	for _, rule := range rules {
+		if !access.has(PermTxViewAny) && !isAuthor(rule.UserID, userID) {
+			continue
+		}
+		result = append(result, models.TaggedRecurringRule{RecurringRule: rule, Tags: tags[rule.ID]})
+	}
+
+	return result, nil
+}
+
+// UpdateFrom меняет серию начиная с даты params.From. Вхождения до этой даты не меняются:
+// старое правило завершается перед From, а с From начинается новое правило с новыми параметрами.
+// Если From не позже начала серии, правило меняется целиком. Возвращает ID правила,
+// действующего с From
+func (s *RecurringService) UpdateFrom(
+	ctx context.Context,
+	ruleID int,
+	userID int,
+	params *models.UpdateRecurringRuleParams,
+) (int, error) {
+
+	if params.StartsAt.IsZero() {
+		params.StartsAt = params.From
+	}
+
+	if err := s.checkUpdate(ctx, ruleID, userID, params); err != nil {
+		return 0, err
+	}
+
+	resultID := ruleID
+	err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		rule, err := s.lockForUpdate(ctx, tx, ruleID, params)
+		if err != nil {
+			return err
+		}
+
+		// Без новых меток серия сохраняет прежние
+		tags := params.Tags
+		if tags == nil {
+			if tags, err = tx.TagRepo.ForRule(ctx, ruleID); err != nil {
+				return err
+			}
+		}
+
+		deleted, err := tx.TransactionRepo.DeleteRuleOccurrencesFrom(ctx, ruleID, params.From)
+		if err != nil {
+			return err
+		}
+
+		if !params.From.After(rule.StartsAt) {
+			if err := tx.RecurringRuleRepo.Reset(ctx, rule, params); err != nil {
+				return err
+			}
+			if err := setRuleTags(ctx, tx, int(rule.AccountID), ruleID, tags); err != nil {
+				return err
+			}
+			return s.materialize(ctx, tx, ruleID, s.until(params.StartsAt))
+		}
+
+		count := int(rule.OccurrencesCount) - deleted
+		if err := tx.RecurringRuleRepo.End(ctx, ruleID, params.From.Add(-time.Second), count); err != nil {
+			return err
+		}
+
+		// Серию удалённого пользователя продолжает тот, кто её изменил
+		authorID := userID
+		if rule.UserID.Valid {
+			authorID = int(rule.UserID.Int32)
+		}
+
+		resultID, err = tx.RecurringRuleRepo.Create(ctx, &models.CreateRecurringRuleParams{
+			AccountID:      int(rule.AccountID),
+			UserID:         authorID,
+			Title:          params.Title,
+			Amount:         params.Amount,
+			Currency:       rule.Currency,
+			Period:         params.Period,
+			Interval:       params.Interval,
+			StartsAt:       params.StartsAt,
+			EndsAt:         params.EndsAt,
+			MaxOccurrences: params.MaxOccurrences,
+			CategoryID:     params.CategoryID,
+			Notes:          params.Notes,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := setRuleTags(ctx, tx, int(rule.AccountID), resultID, tags); err != nil {
+			return err
+		}
+
+		return s.materialize(ctx, tx, resultID, s.until(params.StartsAt))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resultID, nil
+}
+
+// Pause приостанавливает серию и удаляет её будущие вхождения
+func (s *RecurringService) Pause(ctx context.Context, ruleID int, userID int) error {
+	if _, err := s.authorize(ctx, ruleID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		rule, err := tx.RecurringRuleRepo.GetForUpdate(ctx, ruleID)
+		if err != nil {
+			return err
+		}
+
+		if rule.Paused {
+			return nil
+		}
+
+		now := time.Now()
+
+		deleted, err := tx.TransactionRepo.DeleteRuleOccurrencesFrom(ctx, ruleID, now)
+		if err != nil {
+			return err
+		}
+
+		next := firstIndexFrom(rule, now)
+
+		return tx.RecurringRuleRepo.SetPaused(
+			ctx,
+			ruleID,
+			true,
+			next,
+			occurrenceAt(rule, next),
+			int(rule.OccurrencesCount)-deleted,
+		)
+	})
+}
+
+// Resume возобновляет серию. Вхождения, пропущенные за время паузы, не создаются
+func (s *RecurringService) Resume(ctx context.Context, ruleID int, userID int) error {
+	if _, err := s.authorize(ctx, ruleID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		rule, err := tx.RecurringRuleRepo.GetForUpdate(ctx, ruleID)
+		if err != nil {
+			return err
+		}
+
+		if !rule.Paused {
+			return nil
+		}
+
+		now := time.Now()
+		next := firstIndexFrom(rule, now)
+
+		err = tx.RecurringRuleRepo.SetPaused(
+			ctx,
+			ruleID,
+			false,
+			next,
+			occurrenceAt(rule, next),
+			int(rule.OccurrencesCount),
+		)
+		if err != nil {
+			return err
+		}
+
+		return s.materialize(ctx, tx, ruleID, s.until(now))
+	})
+}
+
+// Delete удаляет серию вместе с её будущими вхождениями. Прошедшие вхождения
+// остаются в истории счёта как обычные транзакции
+func (s *RecurringService) Delete(ctx context.Context, ruleID int, userID int) error {
+	if _, err := s.authorize(ctx, ruleID, userID); err != nil {
+		return err
+	}
+
+	return s.deleteFrom(ctx, ruleID, time.Now())
+}
+
+// UpdateOccurrences применяет изменения вхождения серии к этому и следующим вхождениям
+// (scope following) или ко всей серии (scope all). Сдвиг даты вхождения сдвигает расписание
+func (s *RecurringService) UpdateOccurrences(
+	ctx context.Context,
+	occurrence *query.Transaction,
+	userID int,
+	scope models.SeriesScope,
+	params *models.UpdateTransactionParams,
+) error {
+
+	rule, err := s.ruleOf(ctx, occurrence)
+	if err != nil {
+		return err
+	}
+
+	update := &models.UpdateRecurringRuleParams{
+		Title:          params.Title,
+		Amount:         params.Amount,
+		Period:         rule.Period,
+		Interval:       int(rule.IntervalCount),
+		EndsAt:         nullTimePtr(rule.EndsAt),
+		MaxOccurrences: nullIntPtr(rule.MaxOccurrences),
+		CategoryID:     params.CategoryID,
+		Notes:          params.Notes,
+		Tags:           params.Tags,
+	}
+
+	if scope == models.SeriesScopeAll {
+		update.From = rule.StartsAt
+		update.StartsAt = rule.StartsAt.Add(params.OccurredAt.Sub(occurrence.OccurredAt))
+
+		return s.updateAll(ctx, int(rule.ID), userID, update)
+	}
+
+	update.From = occurrence.OccurredAt
+	update.StartsAt = params.OccurredAt
+
+	// Ограничение по числу повторений переносится на остаток серии
+	if rule.MaxOccurrences.Valid {
+		before, err := s.transactions.CountRuleOccurrencesBefore(ctx, int(rule.ID), occurrence.OccurredAt)
+		if err != nil {
+			return err
+		}
+
+		remaining := max(int(rule.MaxOccurrences.Int32)-before, 1)
+		update.MaxOccurrences = &remaining
+	}
+
+	_, err = s.UpdateFrom(ctx, int(rule.ID), userID, update)
+
+	return err
+}
+
+// updateAll меняет всю серию, не пересоздавая её вхождения: они сохраняют ID, а с ними отметки
+// дубликатов. У вхождений меняются только поля, отличающиеся от прежних параметров серии,
+// поэтому правки отдельных вхождений в остальных полях сохраняются. Если сдвинулось начало серии,
+// вхождения сдвигаются на ту же величину, а те, что после сдвига оказались в будущем,
+// создаются заново по новому расписанию
+func (s *RecurringService) updateAll(
+	ctx context.Context,
+	ruleID int,
+	userID int,
+	params *models.UpdateRecurringRuleParams,
+) error {
+
+	if err := s.checkUpdate(ctx, ruleID, userID, params); err != nil {
+		return err
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		rule, err := s.lockForUpdate(ctx, tx, ruleID, params)
+		if err != nil {
+			return err
+		}
+
+		changes := &models.UpdateRuleOccurrencesParams{Shift: params.StartsAt.Sub(rule.StartsAt)}
+		if params.Title != rule.Title {
+			changes.Title = &params.Title
+		}
+		if params.Amount != rule.Amount {
+			changes.Amount = &params.Amount
+		}
+		if !samePtr(params.CategoryID, nullIntPtr(rule.CategoryID)) {
+			changes.SetCategory = true
+			changes.CategoryID = params.CategoryID
+		}
+		if !samePtr(params.Notes, nullStringPtr(rule.Notes)) {
+			changes.SetNotes = true
+			changes.Notes = params.Notes
+		}
+
+		if err := tx.TransactionRepo.UpdateRuleOccurrences(ctx, ruleID, changes); err != nil {
+			return err
+		}
+
+		if params.Tags != nil {
+			tags, err := tx.TagRepo.ForRule(ctx, ruleID)
+			if err != nil {
+				return err
+			}
+
+			sort.Strings(tags)
+			if !slices.Equal(tags, params.Tags) {
+				if err := setRuleTags(ctx, tx, int(rule.AccountID), ruleID, params.Tags); err != nil {
+					return err
+				}
+				if err := tx.TagRepo.RetagRuleOccurrences(ctx, ruleID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.RecurringRuleRepo.Reset(ctx, rule, params); err != nil {
+			return err
+		}
+
+		next := int(rule.NextIndex)
+		nextAt := rule.NextOccurrenceAt
+		count := int(rule.OccurrencesCount)
+
+		if changes.Shift != 0 {
+			// Будущие вхождения и вхождения после окончания серии создаются заново по новому расписанию
+			from := time.Now()
+			if rule.EndsAt.Valid && rule.EndsAt.Time.Before(from) {
+				from = rule.EndsAt.Time.Add(time.Second)
+			}
+
+			deleted, err := tx.TransactionRepo.DeleteRuleOccurrencesFrom(ctx, ruleID, from)
+			if err != nil {
+				return err
+			}
+
+			rule.StartsAt = params.StartsAt
+			next = firstIndexFrom(rule, from)
+			nextAt = occurrenceAt(rule, next)
+			count -= deleted
+		}
+
+		if err := tx.RecurringRuleRepo.SetProgress(ctx, ruleID, next, nextAt, count); err != nil {
+			return err
+		}
+
+		return s.materialize(ctx, tx, ruleID, s.until(params.StartsAt))
+	})
+}
+
+// DeleteOccurrences удаляет это и следующие вхождения серии (scope following)
+// или всю серию вместе с прошедшими вхождениями (scope all)
+func (s *RecurringService) DeleteOccurrences(
+	ctx context.Context,
+	occurrence *query.Transaction,
+	userID int,
+	scope models.SeriesScope,
+) error {
+
+	rule, err := s.ruleOf(ctx, occurrence)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.authorize(ctx, int(rule.ID), userID); err != nil {
+		return err
+	}
+
+	if scope == models.SeriesScopeAll || !occurrence.OccurredAt.After(rule.StartsAt) {
+		return s.deleteFrom(ctx, int(rule.ID), time.Time{})
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		locked, err := tx.RecurringRuleRepo.GetForUpdate(ctx, int(rule.ID))
+		if err != nil {
+			return err
+		}
+
+		deleted, err := tx.TransactionRepo.DeleteRuleOccurrencesFrom(ctx, int(rule.ID), occurrence.OccurredAt)
+		if err != nil {
+			return err
+		}
+
+		return tx.RecurringRuleRepo.End(
+			ctx,
+			int(rule.ID),
+			occurrence.OccurredAt.Add(-time.Second),
+			int(locked.OccurrencesCount)-deleted,
+		)
+	})
+}
+
+// deleteFrom удаляет правило и его вхождения с датой не раньше from
+func (s *RecurringService) deleteFrom(ctx context.Context, ruleID int, from time.Time) error {
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		if _, err := tx.RecurringRuleRepo.GetForUpdate(ctx, ruleID); err != nil {
+			return err
+		}
+
+		if _, err := tx.TransactionRepo.DeleteRuleOccurrencesFrom(ctx, ruleID, from); err != nil {
+			return err
+		}
+
+		return tx.RecurringRuleRepo.DeleteByID(ctx, ruleID)
+	})
+}
+
+// ruleOf возвращает правило, к которому относится вхождение
+func (s *RecurringService) ruleOf(ctx context.Context, occurrence *query.Transaction) (*query.RecurringRule, error) {
+	if !occurrence.RuleID.Valid {
+		return nil, ErrNotInSeries
+	}
+
+	rule, err := s.rules.GetByID(ctx, int(occurrence.RuleID.Int32))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurringRuleNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// MaterializeAccount создаёт недостающие вхождения всех правил счёта до горизонта
+func (s *RecurringService) MaterializeAccount(ctx context.Context, accountID int) error {
+	until := s.until(time.Now())
+
+	ids, err := s.rules.ListDueIDsForAccount(ctx, accountID, until)
+	if err != nil {
+		return err
+	}
+
+	return s.materializeAll(ctx, ids, until)
+}
+
+// MaterializeDue создаёт недостающие вхождения всех правил до горизонта
+func (s *RecurringService) MaterializeDue(ctx context.Context) error {
+	until := s.until(time.Now())
+
+	ids, err := s.rules.ListDueIDs(ctx, until)
+	if err != nil {
+		return err
+	}
+
+	return s.materializeAll(ctx, ids, until)
+}
+
+// Run запускает планировщик, который раз в interval материализует вхождения правил.
+// Блокируется до отмены ctx
+func (s *RecurringService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.MaterializeDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Msg("failed to materialize recurring transactions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RecurringService) materializeAll(ctx context.Context, ids []int32, until time.Time) error {
+	for _, id := range ids {
+		err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+			return s.materialize(ctx, tx, int(id), until)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// materialize создаёт вхождения правила с датой не позже until. Правило блокируется
+// на время транзакции, поэтому параллельные вызовы не создают дубликатов
+func (s *RecurringService) materialize(ctx context.Context, tx *repository.Repository, ruleID int, until time.Time) error {
+	rule, err := tx.RecurringRuleRepo.GetForUpdate(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if rule.Paused {
+		return nil
+	}
+
+	index := int(rule.NextIndex)
+	count := int(rule.OccurrencesCount)
+	next := rule.NextOccurrenceAt
+
+	var dates []time.Time
+	for !next.After(until) && hasOccurrence(rule, count, next) {
+		dates = append(dates, next)
+		count++
+		index++
+		next = occurrenceAt(rule, index)
+	}
+
+	if len(dates) == 0 {
+		return nil
+	}
+
+	if err := tx.TransactionRepo.CreateOccurrences(ctx, rule, dates); err != nil {
+		return err
+	}
+
+	if err := tx.TagRepo.TagRuleOccurrencesFrom(ctx, ruleID, dates[0]); err != nil {
+		return err
+	}
+
+	return tx.RecurringRuleRepo.SetProgress(ctx, ruleID, index, next, count)
+}
+
+// authorize проверяет права на изменение серии: свои серии - с правом PermTxEditOwn, любые - с PermTxEditAny.
+// Возвращает права пользователя в счёте серии
+func (s *RecurringService) authorize(ctx context.Context, ruleID int, userID int) (*memberAccess, error) {
+	rule, err := s.rules.GetByID(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurringRuleNotFound
+		}
+		return nil, err
+	}
+
+	access, err := requirePermissions(ctx, s.repo, int(rule.AccountID), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireEditPermission(access, rule.UserID, userID); err != nil {
+		return nil, err
+	}
+
+	return access, nil
+}
+
+// checkUpdate проверяет права на изменение серии и её новые параметры, нормализует примечание и метки
+func (s *RecurringService) checkUpdate(
+	ctx context.Context,
+	ruleID int,
+	userID int,
+	params *models.UpdateRecurringRuleParams,
+) error {
+
+	access, err := s.authorize(ctx, ruleID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAmountPermission(access, params.Amount); err != nil {
+		return err
+	}
+
+	if err := validateRecurringRule(params.StartsAt, params.Interval, params.EndsAt, params.MaxOccurrences); err != nil {
+		return err
+	}
+
+	if params.Notes, err = normalizeNotes(params.Notes); err != nil {
+		return err
+	}
+
+	if params.Tags != nil {
+		if params.Tags, err = normalizeTags(params.Tags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockForUpdate блокирует правило до конца транзакции и проверяет новые параметры,
+// зависящие от счёта серии: точность суммы и категорию
+func (s *RecurringService) lockForUpdate(
+	ctx context.Context,
+	tx *repository.Repository,
+	ruleID int,
+	params *models.UpdateRecurringRuleParams,
+) (*query.RecurringRule, error) {
+
+	rule, err := tx.RecurringRuleRepo.GetForUpdate(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !params.Amount.Fits(rule.Currency) {
+		return nil, ErrAmountPrecision
+	}
+
+	if err := checkCategory(ctx, tx.CategoryRepo, int(rule.AccountID), params.CategoryID); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// until возвращает горизонт материализации. Первое вхождение серии создаётся всегда,
+// даже если серия начинается позже горизонта
+func (s *RecurringService) until(from time.Time) time.Time {
+	horizon := time.Now().Add(s.horizon)
+	if from.After(horizon) {
+		return from
+	}
+
+	return horizon
+}
+
+func validateRecurringRule(startsAt time.Time, interval int, endsAt *time.Time, maxOccurrences *int) error {
+	if interval < 1 {
+		return ErrInvalidRecurringRule
+	}
+
+	if endsAt != nil && endsAt.Before(startsAt) {
+		return ErrInvalidRecurringRule
+	}
+
+	if maxOccurrences != nil && *maxOccurrences < 1 {
+		return ErrInvalidRecurringRule
+	}
+
+	return nil
+}
+
+// hasOccurrence проверяет, что серия не закончилась к вхождению с датой at
+func hasOccurrence(rule *query.RecurringRule, count int, at time.Time) bool {
+	if rule.EndsAt.Valid && at.After(rule.EndsAt.Time) {
+		return false
+	}
+
+	if rule.MaxOccurrences.Valid && count >= int(rule.MaxOccurrences.Int32) {
+		return false
+	}
+
+	return true
+}
+
+// firstIndexFrom возвращает номер первого вхождения серии с датой не раньше from
+func firstIndexFrom(rule *query.RecurringRule, from time.Time) int {
+	index := 0
+	for occurrenceAt(rule, index).Before(from) {
+		index++
+	}
+
+	return index
+}
+
+// occurrenceAt вычисляет дату вхождения серии с номером index (с нуля).
+// Даты считаются от начала серии, а не от предыдущего вхождения, и прижимаются
+// к концу месяца: серия с 31 января даёт 29 февраля, затем 31 марта
+func occurrenceAt(rule *query.RecurringRule, index int) time.Time {
+	step := index * int(rule.IntervalCount)
+
+	switch rule.Period {
+	case query.RecurringRulesPeriodDay:
+		return rule.StartsAt.AddDate(0, 0, step)
+	case query.RecurringRulesPeriodWeek:
+		return rule.StartsAt.AddDate(0, 0, 7*step)
+	case query.RecurringRulesPeriodMonth:
+		return addMonthsClamped(rule.StartsAt, step)
+	case query.RecurringRulesPeriodYear:
+		return addMonthsClamped(rule.StartsAt, 12*step)
+	default:
+		return rule.StartsAt
+	}
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// isAuthor проверяет, что запись создана пользователем. У записей удалённых пользователей автора нет
+func isAuthor(authorID sql.NullInt32, userID int) bool {
+	return authorID.Valid && int(authorID.Int32) == userID
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullIntPtr(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int32)
+	return &i
+}
+
+func nullStringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+// samePtr сравнивает значения указателей: nil равен только nil
+func samePtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
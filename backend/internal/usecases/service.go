@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"microservices/accounter/internal/config"
 	"microservices/accounter/internal/repository"
 	"microservices/accounter/internal/tokens"
 )
@@ -10,13 +11,34 @@ type Service struct {
 	AccountScv     *AccountService
 	AccountMember  *AccountMemberService
 	TransactionScv *TransactionService
+	SessionScv     *SessionService
+	RecurringScv   *RecurringService
+	RatesScv       *ExchangeRateService
+	CategoryScv    *CategoryService
+	TagScv         *TagService
+	ImportScv      *ImportService
+	DuplicateScv   *DuplicateService
+	ArchiveScv     *ArchiveService
+	DeletionScv    *UserDeletionService
 }
 
-func New(repo *repository.Repository, tokens *tokens.JWTManager) *Service {
+func New(repo *repository.Repository, tokens *tokens.JWTManager, cfg *config.Config) *Service {
+	recurring := newRecurringService(repo, cfg.Recurring)
+	rates := newExchangeRateService(repo)
+
 	return &Service{
 		AuthScv:        newAuthService(repo, tokens),
-		AccountScv:     newAccountService(repo),
-		AccountMember: newAccountMemberService(repo),
-		TransactionScv: newTransactionService(repo),
+		AccountScv:     newAccountService(repo, cfg.Invitation),
+		AccountMember: newAccountMemberService(repo, cfg.Invitation),
+		TransactionScv: newTransactionService(repo, recurring, rates),
+		SessionScv:     newSessionService(repo),
+		RecurringScv:   recurring,
+		RatesScv:       rates,
+		CategoryScv:    newCategoryService(repo),
+		TagScv:         newTagService(repo),
+		ImportScv:      newImportService(repo),
+		DuplicateScv:   newDuplicateService(repo),
+		ArchiveScv:     newArchiveService(repo, recurring, cfg.Invitation),
+		DeletionScv:    newUserDeletionService(repo, cfg.Deletion),
 	}
 }
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+type SessionService struct {
+	sessions      *repository.SessionRepository
+	refreshTokens *repository.RefreshTokenRepository
+}
+
+func newSessionService(repo *repository.Repository) *SessionService {
+	return &SessionService{
+		sessions:      repo.SessionRepo,
+		refreshTokens: repo.RefreshTokenRepo,
+	}
+}
+
+// Validate проверяет, что сессия access токена принадлежит пользователю и не завершена,
+// и отмечает активность сессии
+func (s *SessionService) Validate(ctx context.Context, userID int, sessionID string) error {
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+
+	if int(session.UserID) != userID || session.RevokedAt.Valid {
+		return ErrSessionRevoked
+	}
+
+	return s.sessions.Touch(ctx, sessionID)
+}
+
+// List возвращает активные сессии пользователя
+func (s *SessionService) List(ctx context.Context, userID int) ([]query.Session, error) {
+	return s.sessions.ListActive(ctx, userID)
+}
+
+// Revoke завершает одну сессию пользователя
+func (s *SessionService) Revoke(ctx context.Context, userID int, sessionID string) error {
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	// Чужие сессии неотличимы от несуществующих
+	if int(session.UserID) != userID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.sessions.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return s.refreshTokens.RevokeFamily(ctx, sessionID)
+}
+
+// RevokeOthers завершает все сессии пользователя, кроме текущей
+func (s *SessionService) RevokeOthers(ctx context.Context, userID int, currentSessionID string) error {
+	if err := s.sessions.RevokeAllExcept(ctx, userID, currentSessionID); err != nil {
+		return err
+	}
+
+	return s.refreshTokens.RevokeAllExcept(ctx, userID, currentSessionID)
+}
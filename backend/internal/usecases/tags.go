@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+)
+
+const (
+	maxTagLength       = 50
+	maxTagsPerInstance = 20
+)
+
+// TagService возвращает метки счёта. Метки создаются при первом использовании в транзакции
+type TagService struct {
+	tags    *repository.TagRepository
+	members *repository.AccountMemberRepository
+}
+
+func newTagService(repo *repository.Repository) *TagService {
+	return &TagService{
+		tags:    repo.TagRepo,
+		members: repo.AccountMemberRepo,
+	}
+}
+
+// List возвращает метки, которые есть у транзакций счёта, с числом транзакций
+func (s *TagService) List(ctx context.Context, accountID int, userID int) ([]query.ListAccountTagsRow, error) {
+	if err := s.members.IsMember(ctx, accountID, userID); err != nil {
+		return nil, ErrForbidden
+	}
+
+	return s.tags.ListForAccount(ctx, accountID)
+}
+
+// normalizeTags приводит метки к нижнему регистру, убирает пробелы по краям и повторы
+// и сортирует их. Запятая в метке недопустима: через неё метки перечисляются в фильтре
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" || utf8.RuneCountInString(name) > maxTagLength || strings.Contains(name, ",") {
+			return nil, ErrInvalidTag
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+
+	if len(result) > maxTagsPerInstance {
+		return nil, ErrTooManyTags
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// setTransactionTags заменяет метки транзакции, создавая недостающие метки счёта
+func setTransactionTags(ctx context.Context, tx *repository.Repository, accountID int, transactionID int, names []string) error {
+	ids, err := tx.TagRepo.Ensure(ctx, accountID, names)
+	if err != nil {
+		return err
+	}
+
+	return tx.TagRepo.SetForTransaction(ctx, transactionID, ids)
+}
+
+// setRuleTags заменяет метки правила повторения, создавая недостающие метки счёта
+func setRuleTags(ctx context.Context, tx *repository.Repository, accountID int, ruleID int, names []string) error {
+	ids, err := tx.TagRepo.Ensure(ctx, accountID, names)
+	if err != nil {
+		return err
+	}
+
+	return tx.TagRepo.SetForRule(ctx, ruleID, ids)
+}
@@ -4,62 +4,165 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"io"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"microservices/accounter/internal/dedupe"
 	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/money"
 	"microservices/accounter/internal/repository"
 	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/internal/statement"
+)
+
+// Размер страницы списка транзакций
+const (
+	DefaultTransactionsLimit = 100
+	MaxTransactionsLimit     = 500
+)
+
+// MaxStatementPeriod - наибольший период выписки по счёту
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+const (
+	maxNotesLength  = 1000
+	maxSearchLength = 100
 )
 
 type TransactionService struct {
+	repo         *repository.Repository
 	transactions *repository.TransactionRepository
-	members      *repository.AccountMemberRepository
+	accounts     *repository.AccountRepository
+	categories   *repository.CategoryRepository
+	tags         *repository.TagRepository
+	duplicates   *repository.DuplicateRepository
+	recurring    *RecurringService
+	rates        *ExchangeRateService
 }
 
-func newTransactionService(repo *repository.Repository) *TransactionService {
+func newTransactionService(
+	repo *repository.Repository,
+	recurring *RecurringService,
+	rates *ExchangeRateService,
+) *TransactionService {
 	return &TransactionService{
+		repo:         repo,
 		transactions: repo.TransactionRepo,
-		members:      repo.AccountMemberRepo,
+		accounts:     repo.AccountRepo,
+		categories:   repo.CategoryRepo,
+		tags:         repo.TagRepo,
+		duplicates:   repo.DuplicateRepo,
+		recurring:    recurring,
+		rates:        rates,
 	}
 }
 
-// Create создаёт транзакцию. Если указан период, создаёт 500 периодических записей
+// Create создаёт транзакцию с метками и возвращает её ID и ID похожих на неё транзакций счёта -
+// вероятных дубликатов. Если указан период, создаёт бессрочное правило повторения и возвращает
+// ID его первого вхождения; вхождения серий на дубликаты не проверяются
 func (s *TransactionService) Create(
 	ctx context.Context,
 	accountID int,
 	userID int,
 	title string,
-	amount string,
+	amount money.Amount,
 	occurredAt time.Time,
 	period query.NullTransactionsPeriod,
-) (int, error) {
+	categoryID *int,
+	notes *string,
+	tags []string,
+) (int, []int, error) {
+
+	// Доходы и расходы создаются по отдельным правам
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, amountPermission(amount)); err != nil {
+		return 0, nil, err
+	}
+
+	// Транзакция записывается в валюте счёта
+	currency, err := accountCurrency(ctx, s.accounts, accountID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if !amount.Fits(currency) {
+		return 0, nil, ErrAmountPrecision
+	}
+
+	if err := checkCategory(ctx, s.categories, accountID, categoryID); err != nil {
+		return 0, nil, err
+	}
 
-	// Проверка прав доступа
-	role, err := s.members.GetMemberRole(ctx, accountID, userID)
+	notes, err = normalizeNotes(notes)
 	if err != nil {
-		return 0, ErrForbidden
+		return 0, nil, err
 	}
 
-	if role == query.AccountMembersRoleViewer {
-		return 0, ErrForbidden
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Если период не указан - создаём одну транзакцию
+	if !period.Valid {
+		// Похожие транзакции ищутся до создания, чтобы не найти саму новую
+		duplicates, err := findDuplicates(ctx, s.duplicates, accountID, []dedupe.Fingerprint{
+			dedupe.New(nil, occurredAt, amount, currency, title),
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+
+		var transactionID int
+		err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+			transactionID, err = tx.TransactionRepo.CreateTransaction(ctx, &models.CreateTransactionParams{
+				AccountID:  accountID,
+				UserID:     userID,
+				Title:      title,
+				Amount:     amount,
+				Currency:   currency,
+				OccurredAt: occurredAt,
+				Period:     period,
+				CategoryID: categoryID,
+				Notes:      notes,
+			})
+			if err != nil {
+				return err
+			}
+
+			return setTransactionTags(ctx, tx, accountID, transactionID, tags)
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return transactionID, duplicates[0], nil
 	}
 
-	params := &models.CreateTransactionParams{
+	// Если период указан - создаём правило повторения
+	ruleID, err := s.recurring.Create(ctx, &models.CreateRecurringRuleParams{
 		AccountID:  accountID,
 		UserID:     userID,
 		Title:      title,
 		Amount:     amount,
-		OccurredAt: occurredAt,
-		Period:     period,
+		Period:     query.RecurringRulesPeriod(period.TransactionsPeriod),
+		Interval:   1,
+		StartsAt:   occurredAt,
+		CategoryID: categoryID,
+		Notes:      notes,
+		Tags:       tags,
+	})
+	if err != nil {
+		return 0, nil, err
 	}
 
-	// Если период не указан - создаём одну транзакцию
-	if !period.Valid {
-		return s.transactions.CreateTransaction(ctx, params)
+	transactionID, err := s.transactions.FirstRuleOccurrenceID(ctx, ruleID)
+	if err != nil {
+		return 0, nil, err
 	}
 
-	// Если период указан - создаём 500 периодических транзакций
-	return s.transactions.CreatePeriodicTransactions(ctx, params, 500)
+	return transactionID, nil, nil
 }
 
 // GetByID получает транзакцию по ID
@@ -76,21 +179,280 @@ func (s *TransactionService) GetByID(ctx context.Context, id int32) (*query.Tran
 	return transaction, nil
 }
 
-// List возвращает список транзакций с фильтрацией
+// List возвращает страницу списка транзакций с фильтрацией и курсор следующей страницы
+// (nil, если страница последняя)
 func (s *TransactionService) List(
 	ctx context.Context,
 	accountID int,
 	userID int,
 	params *models.ListTransactionsFilter,
-) ([]query.Transaction, error) {
+) ([]models.TaggedTransaction, *models.TransactionCursor, error) {
+
+	// Без права PermTxViewAny список ограничивается транзакциями пользователя
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := requireOwnTransactions(access, userID, params); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.resolveFilter(ctx, params); err != nil {
+		return nil, nil, err
+	}
+
+	// Досоздаём вхождения периодических серий, если планировщик ещё не успел
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return nil, nil, err
+	}
+
+	// Запрашиваем на одну транзакцию больше, чтобы узнать, есть ли следующая страница
+	limit := params.Limit
+	params.Limit++
+
+	transactions, err := s.transactions.List(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(transactions) > limit
+	if hasMore {
+		transactions = transactions[:limit]
+	}
+
+	ids := make([]int32, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+
+	tags, err := s.tags.ForTransactions(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]models.TaggedTransaction, len(transactions))
+	for i, t := range transactions {
+		result[i] = models.TaggedTransaction{Transaction: t, Tags: tags[t.ID]}
+	}
+
+	if !hasMore {
+		return result, nil, nil
+	}
+
+	return result, models.NewTransactionCursor(params.Sort, params.Desc, &result[limit-1]), nil
+}
+
+// ListWithBalance возвращает страницу списка транзакций с фильтрацией и остатком счёта
+// после каждой из них и курсор следующей страницы
+func (s *TransactionService) ListWithBalance(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	params *models.ListTransactionsFilter,
+) ([]models.TransactionWithBalance, *models.TransactionCursor, error) {
+
+	// Остаток после транзакции складывается из всех транзакций счёта
+	access, err := requirePermissions(ctx, s.repo, accountID, userID, PermReportsView)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := requireOwnTransactions(access, userID, params); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.resolveFilter(ctx, params); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return nil, nil, err
+	}
+
+	limit := params.Limit
+	params.Limit++
+
+	transactions, err := s.transactions.ListWithBalance(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(transactions) > limit
+	if hasMore {
+		transactions = transactions[:limit]
+	}
+
+	ids := make([]int32, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+
+	tags, err := s.tags.ForTransactions(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range transactions {
+		transactions[i].Tags = tags[transactions[i].ID]
+	}
+
+	if !hasMore {
+		return transactions, nil, nil
+	}
+
+	last := &transactions[limit-1].TaggedTransaction
+
+	return transactions, models.NewTransactionCursor(params.Sort, params.Desc, last), nil
+}
+
+// Export передаёт в write все транзакции счёта, подходящие под фильтр, в порядке сортировки фильтра.
+// Транзакции читаются из БД по одной, без постраничной выдачи: limit и курсор не учитываются.
+// Категория выгружается путём от верхнего уровня
+func (s *TransactionService) Export(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	params *models.ListTransactionsFilter,
+	write func(row *models.ExportRow) error,
+) error {
+
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireOwnTransactions(access, userID, params); err != nil {
+		return err
+	}
+
+	if err := s.resolveFilter(ctx, params); err != nil {
+		return err
+	}
+
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return err
+	}
+
+	categories, err := s.categories.ListForAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	tree := newCategoryTree(categories)
+
+	return s.transactions.Export(ctx, params, func(row *models.ExportRow) error {
+		if row.CategoryID != nil {
+			row.Category = tree.path(*row.CategoryID)
+		}
+		return write(row)
+	})
+}
+
+// Statement печатает в w выписку по счёту в PDF за период с from по to включительно:
+// остаток на начало, все транзакции периода по порядку даты, остаток на конец и итоги
+// по категориям и участникам
+func (s *TransactionService) Statement(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	from time.Time,
+	to time.Time,
+	w io.Writer,
+) error {
+
+	// Выписка содержит все транзакции счёта
+	_, err := requirePermissions(ctx, s.repo, accountID, userID, PermReportsView, PermTxViewAny)
+	if err != nil {
+		return err
+	}
+
+	// Даты транзакций хранятся с точностью до секунды
+	from = from.Truncate(time.Second)
+	if to.Before(from) || to.Sub(from) > MaxStatementPeriod {
+		return ErrInvalidStatementPeriod
+	}
+
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return err
+	}
+
+	account, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+
+	// Остаток на начало - остаток сразу перед первой секундой периода
+	opening, err := s.accounts.Balance(ctx, accountID, from.Add(-time.Second))
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.categories.ListForAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	tree := newCategoryTree(categories)
+
+	doc := statement.New(w, &models.Statement{
+		Account:        account.Name,
+		Currency:       opening.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening.Balance,
+		GeneratedAt:    time.Now(),
+	})
+
+	filter := &models.ListTransactionsFilter{
+		AccountID: accountID,
+		DateFrom:  &from,
+		DateTo:    &to,
+		Sort:      models.SortByOccurredAt,
+	}
+
+	err = s.transactions.Export(ctx, filter, func(row *models.ExportRow) error {
+		if row.CategoryID != nil {
+			row.Category = tree.path(*row.CategoryID)
+		}
+		return doc.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	return doc.Close()
+}
+
+// Balance возвращает остаток счёта на момент at
+func (s *TransactionService) Balance(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	at time.Time,
+) (*query.GetAccountBalanceRow, error) {
 
-	// Проверка, что пользователь является участником счёта
-	_, err := s.members.GetMemberRole(ctx, accountID, userID)
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermReportsView); err != nil {
+		return nil, err
+	}
+
+	// Остаток на будущую дату учитывает запланированные вхождения серий
+	if at.After(time.Now()) {
+		if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	balance, err := s.accounts.Balance(ctx, accountID, at)
 	if err != nil {
-		return nil, ErrForbidden
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
 	}
 
-	return s.transactions.List(ctx, params)
+	return balance, nil
 }
 
 // Update обновляет транзакцию с проверкой прав доступа
@@ -99,27 +461,58 @@ func (s *TransactionService) Update(
 	transactionID int32,
 	accountID int,
 	userID int,
-	transactionOwnerID int,
+	transactionOwnerID sql.NullInt32,
 	params *models.UpdateTransactionParams,
 ) error {
 
-	role, err := s.members.GetMemberRole(ctx, accountID, userID)
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
 	if err != nil {
-		return ErrForbidden
+		return err
+	}
+
+	if err := requireEditPermission(access, transactionOwnerID, userID); err != nil {
+		return err
+	}
+
+	// Новая сумма должна быть доступна для создания: доход нельзя превратить в расход без права на расходы
+	if err := requireAmountPermission(access, params.Amount); err != nil {
+		return err
+	}
+
+	currency, err := accountCurrency(ctx, s.accounts, accountID)
+	if err != nil {
+		return err
+	}
+
+	if !params.Amount.Fits(currency) {
+		return ErrAmountPrecision
 	}
 
-	// Viewer не может редактировать
-	if role == query.AccountMembersRoleViewer {
-		return ErrForbidden
+	if err := checkCategory(ctx, s.categories, accountID, params.CategoryID); err != nil {
+		return err
 	}
 
-	// Editor может редактировать только свои транзакции
-	if role == query.AccountMembersRoleEditor && userID != transactionOwnerID {
-		return ErrForbidden
+	if params.Notes, err = normalizeNotes(params.Notes); err != nil {
+		return err
 	}
 
-	// Admin и Owner могут редактировать любые транзакции
-	return s.transactions.UpdateTransaction(ctx, transactionID, params)
+	if params.Tags != nil {
+		if params.Tags, err = normalizeTags(params.Tags); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.TransactionRepo.UpdateTransaction(ctx, transactionID, params); err != nil {
+			return err
+		}
+
+		if params.Tags == nil {
+			return nil
+		}
+
+		return setTransactionTags(ctx, tx, accountID, int(transactionID), params.Tags)
+	})
 }
 
 // Delete удаляет транзакцию с проверкой прав
@@ -127,25 +520,239 @@ func (s *TransactionService) Delete(
 	ctx context.Context,
 	accountID int,
 	userID int,
-	transactionOwnerID int,
+	transactionOwnerID sql.NullInt32,
 	transactionID int,
 ) error {
 
-	role, err := s.members.GetMemberRole(ctx, accountID, userID)
+	access, err := requirePermissions(ctx, s.repo, accountID, userID)
 	if err != nil {
-		return ErrForbidden
+		return err
 	}
 
-	// Viewer не может удалять
-	if role == query.AccountMembersRoleViewer {
-		return ErrForbidden
+	if err := requireEditPermission(access, transactionOwnerID, userID); err != nil {
+		return err
 	}
 
-	// Editor может удалять только свои транзакции
-	if role == query.AccountMembersRoleEditor && userID != transactionOwnerID {
-		return ErrForbidden
+	return s.transactions.DeleteByID(ctx, transactionID)
+}
+
+// UpdateSeries применяет изменения вхождения периодической серии к следующим вхождениям
+// или ко всей серии. Права доступа проверяются по правилу повторения
+func (s *TransactionService) UpdateSeries(
+	ctx context.Context,
+	transaction *query.Transaction,
+	userID int,
+	scope models.SeriesScope,
+	params *models.UpdateTransactionParams,
+) error {
+
+	return s.recurring.UpdateOccurrences(ctx, transaction, userID, scope, params)
+}
+
+// DeleteSeries удаляет вхождение периодической серии вместе со следующими вхождениями
+// или всю серию. Права доступа проверяются по правилу повторения
+func (s *TransactionService) DeleteSeries(
+	ctx context.Context,
+	transaction *query.Transaction,
+	userID int,
+	scope models.SeriesScope,
+) error {
+
+	return s.recurring.DeleteOccurrences(ctx, transaction, userID, scope)
+}
+
+// Summary возвращает итоги по транзакциям счёта в разрезе валют
+func (s *TransactionService) Summary(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	filter *models.SummaryFilter,
+) ([]models.CurrencyTotal, error) {
+
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermReportsView); err != nil {
+		return nil, err
 	}
 
-	// Admin и Owner могут удалять любые транзакции
-	return s.transactions.DeleteByID(ctx, transactionID)
+	// Период может заканчиваться в будущем: досоздаём вхождения серий
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return s.transactions.Summarize(ctx, accountID, filter)
+}
+
+// SummaryForUser возвращает итоги по транзакциям всех счетов пользователя в разрезе валют.
+// Суммы в разных валютах не складываются
+func (s *TransactionService) SummaryForUser(
+	ctx context.Context,
+	userID int,
+	filter *models.SummaryFilter,
+) ([]models.CurrencyTotal, error) {
+
+	accountIDs, err := s.reportAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactions.SummarizeForAccounts(ctx, accountIDs, filter)
+}
+
+// ConvertedSummary возвращает итоги по транзакциям счёта, пересчитанные в валюту to
+// по курсам пользователя на даты транзакций
+func (s *TransactionService) ConvertedSummary(
+	ctx context.Context,
+	accountID int,
+	userID int,
+	filter *models.SummaryFilter,
+	to money.Currency,
+) (*models.ConvertedTotal, error) {
+
+	if _, err := requirePermissions(ctx, s.repo, accountID, userID, PermReportsView); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurring.MaterializeAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	daily, err := s.transactions.SummarizeByDay(ctx, accountID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rates.Convert(ctx, userID, daily, to)
+}
+
+// ConvertedSummaryForUser возвращает итоги по всем счетам пользователя, пересчитанные в валюту to
+func (s *TransactionService) ConvertedSummaryForUser(
+	ctx context.Context,
+	userID int,
+	filter *models.SummaryFilter,
+	to money.Currency,
+) (*models.ConvertedTotal, error) {
+
+	accountIDs, err := s.reportAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.transactions.SummarizeForAccountsByDay(ctx, accountIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rates.Convert(ctx, userID, daily, to)
+}
+
+// reportAccounts возвращает счета пользователя, в которых у него есть право на просмотр отчётов,
+// и досоздаёт в них вхождения серий: период итогов может заканчиваться в будущем
+func (s *TransactionService) reportAccounts(ctx context.Context, userID int) ([]int32, error) {
+	accounts, err := s.accounts.ListUserAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIDs := make([]int32, 0, len(accounts))
+	for _, account := range accounts {
+		access, err := loadAccess(ctx, s.repo, int(account.ID), userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !access.has(PermReportsView) {
+			continue
+		}
+
+		if err := s.recurring.MaterializeAccount(ctx, int(account.ID)); err != nil {
+			return nil, err
+		}
+
+		accountIDs = append(accountIDs, account.ID)
+	}
+
+	return accountIDs, nil
+}
+
+// resolveFilter проверяет сортировку и страницу, дополняет фильтр по категории её подкатегориями
+// и нормализует метки фильтра
+func (s *TransactionService) resolveFilter(ctx context.Context, filter *models.ListTransactionsFilter) error {
+	if filter.Sort == "" {
+		filter.Sort = models.SortByOccurredAt
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultTransactionsLimit
+	}
+
+	if filter.Limit < 0 || filter.Limit > MaxTransactionsLimit {
+		return ErrInvalidLimit
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+	if utf8.RuneCountInString(filter.Search) > maxSearchLength {
+		return ErrSearchTooLong
+	}
+
+	if filter.AmountMin != nil && filter.AmountMax != nil && *filter.AmountMin > *filter.AmountMax {
+		return ErrInvalidAmountRange
+	}
+
+	// Курсор действителен только для того порядка сортировки, в котором он выдан
+	if filter.After != nil && (filter.After.Sort != filter.Sort || filter.After.Desc != filter.Desc) {
+		return ErrCursorMismatch
+	}
+
+	if filter.Tags != nil {
+		names, err := normalizeTags(filter.Tags.Names)
+		if err != nil {
+			return err
+		}
+		filter.Tags.Names = names
+	}
+
+	if filter.CategoryID == nil {
+		return nil
+	}
+
+	ids, err := categorySubtree(ctx, s.categories, filter.AccountID, *filter.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	filter.CategoryIDs = ids
+	return nil
+}
+
+// normalizeNotes обрезает пробелы по краям примечания. Пустое примечание - это его отсутствие
+func normalizeNotes(notes *string) (*string, error) {
+	if notes == nil {
+		return nil, nil
+	}
+
+	trimmed := strings.TrimSpace(*notes)
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	if utf8.RuneCountInString(trimmed) > maxNotesLength {
+		return nil, ErrNotesTooLong
+	}
+
+	return &trimmed, nil
+}
+
+// accountCurrency возвращает валюту счёта
+func accountCurrency(ctx context.Context, accounts *repository.AccountRepository, accountID int) (money.Currency, error) {
+	account, err := accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+
+	return account.Currency, nil
 }
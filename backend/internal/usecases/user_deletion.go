@@ -0,0 +1,316 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"microservices/accounter/internal/config"
+	"microservices/accounter/internal/crypto"
+	"microservices/accounter/internal/models"
+	"microservices/accounter/internal/repository"
+	"microservices/accounter/internal/repository/query"
+	"microservices/accounter/pkg/logger"
+)
+
+// UserDeletionService удаляет аккаунты пользователей. Удаление откладывается на срок, в течение
+// которого его можно отменить. Счета пользователя передаются участникам или удаляются, а его
+// транзакции и серии в оставшихся счетах остаются без автора
+type UserDeletionService struct {
+	repo      *repository.Repository
+	users     *repository.UserRepository
+	deletions *repository.UserDeletionRepository
+	grace     time.Duration
+}
+
+func newUserDeletionService(repo *repository.Repository, cfg config.Deletion) *UserDeletionService {
+	return &UserDeletionService{
+		repo:      repo,
+		users:     repo.UserRepo,
+		deletions: repo.UserDeletionRepo,
+		grace:     cfg.GracePeriod,
+	}
+}
+
+// Request планирует удаление пользователя после подтверждения паролем. accounts - выбор для счетов
+// пользователя: новый владелец по ID счёта или nil, если счёт нужно удалить. Для остальных счетов
+// выбор делает сервер (см. defaultSuccessor)
+func (s *UserDeletionService) Request(
+	ctx context.Context,
+	userID int,
+	password string,
+	accounts map[int]*int,
+) (*models.UserDeletion, error) {
+	hash, err := s.users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if !crypto.Compare(hash, password) {
+		return nil, ErrInvalidPassword
+	}
+
+	var deletion *models.UserDeletion
+	err = s.repo.InTx(ctx, func(tx *repository.Repository) error {
+		_, err := tx.UserDeletionRepo.Get(ctx, userID)
+		if err == nil {
+			return ErrDeletionAlreadyScheduled
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		plan, err := deletionPlan(ctx, tx, userID, accounts)
+		if err != nil {
+			return err
+		}
+
+		if !followsChoice(plan, accounts) {
+			return ErrInvalidDeletionPlan
+		}
+
+		now := time.Now().UTC()
+		deleteAt := now.Add(s.grace)
+		if err := tx.UserDeletionRepo.Create(ctx, userID, deleteAt, accounts); err != nil {
+			return err
+		}
+
+		deletion = &models.UserDeletion{RequestedAt: now, DeleteAt: deleteAt, Accounts: plan}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deletion, nil
+}
+
+// Status возвращает запланированное удаление пользователя и что на текущий момент будет с его счетами
+func (s *UserDeletionService) Status(ctx context.Context, userID int) (*models.UserDeletion, error) {
+	stored, err := s.deletions.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeletionNotScheduled
+		}
+		return nil, err
+	}
+
+	accounts, err := s.deletions.Accounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := deletionPlan(ctx, s.repo, userID, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserDeletion{
+		RequestedAt: stored.RequestedAt,
+		DeleteAt:    stored.DeleteAt,
+		Accounts:    plan,
+	}, nil
+}
+
+// Cancel отменяет запланированное удаление пользователя
+func (s *UserDeletionService) Cancel(ctx context.Context, userID int) error {
+	cancelled, err := s.deletions.Cancel(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return ErrDeletionNotScheduled
+	}
+
+	return nil
+}
+
+// DeleteDue удаляет пользователей, срок отмены удаления которых истёк
+func (s *UserDeletionService) DeleteDue(ctx context.Context) error {
+	ids, err := s.deletions.ListDueIDs(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := s.repo.InTx(ctx, func(tx *repository.Repository) error {
+			return s.deleteUser(ctx, tx, int(id))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run запускает планировщик, который раз в interval удаляет пользователей с истёкшим сроком отмены.
+// Блокируется до отмены ctx
+func (s *UserDeletionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DeleteDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Msg("failed to delete users")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteUser выполняет план удаления пользователя. Запрос блокируется на время транзакции,
+// поэтому отменённое в это время удаление не выполняется
+func (s *UserDeletionService) deleteUser(ctx context.Context, tx *repository.Repository, userID int) error {
+	if _, err := tx.UserDeletionRepo.GetForUpdate(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	accounts, err := tx.UserDeletionRepo.Accounts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	plan, err := deletionPlan(ctx, tx, userID, accounts)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range plan {
+		if account.Action == models.DeletionDeleteAccount {
+			if err := tx.AccountRepo.DeleteAccountByID(ctx, account.AccountID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		acc, err := tx.AccountRepo.GetAccountByID(ctx, account.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := transferOwnership(ctx, tx, acc, *account.NewOwnerID); err != nil {
+			return err
+		}
+	}
+
+	// Участие в счетах, сессии, курсы и сам запрос удаляются каскадно,
+	// транзакции и серии в переданных и чужих счетах остаются без автора
+	return tx.UserRepo.DeleteUserByID(ctx, userID)
+}
+
+// deletionPlan определяет, что будет с каждым счётом пользователя: выбранный участник становится
+// владельцем, если он всё ещё участник счёта, nil в accounts удаляет счёт. Для остальных счетов
+// владельцем становится defaultSuccessor, а счёт без других участников удаляется
+func deletionPlan(
+	ctx context.Context,
+	repo *repository.Repository,
+	userID int,
+	accounts map[int]*int,
+) ([]models.DeletionAccount, error) {
+	owned, err := repo.AccountRepo.ListOwned(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]models.DeletionAccount, 0, len(owned))
+	for _, account := range owned {
+		members, err := repo.AccountMemberRepo.ListMembers(ctx, int(account.ID))
+		if err != nil {
+			return nil, err
+		}
+
+		item := models.DeletionAccount{AccountID: int(account.ID), Name: account.Name}
+
+		newOwnerID, chosen := accounts[item.AccountID]
+		switch {
+		case chosen && newOwnerID == nil:
+			item.Action = models.DeletionDeleteAccount
+		case chosen && *newOwnerID != userID && memberOf(members, *newOwnerID):
+			item.Action = models.DeletionTransferAccount
+			item.NewOwnerID = newOwnerID
+		default:
+			item.Default = true
+			item.NewOwnerID = defaultSuccessor(members, userID)
+			item.Action = models.DeletionTransferAccount
+			if item.NewOwnerID == nil {
+				item.Action = models.DeletionDeleteAccount
+			}
+		}
+
+		plan = append(plan, item)
+	}
+
+	return plan, nil
+}
+
+// defaultSuccessor выбирает нового владельца счёта: участника с самой старшей ролью
+// (admin, editor, затем viewer и пользовательские роли), при равных ролях - зарегистрированного раньше.
+// nil, если других участников нет
+func defaultSuccessor(members []query.ListAccountMembersRow, ownerID int) *int {
+	rank := map[query.AccountMembersRole]int{
+		query.AccountMembersRoleAdmin:  3,
+		query.AccountMembersRoleEditor: 2,
+		query.AccountMembersRoleViewer: 1,
+		query.AccountMembersRoleCustom: 1,
+	}
+
+	var best *query.ListAccountMembersRow
+	for i := range members {
+		m := &members[i]
+		if int(m.UserID) == ownerID || rank[m.Role] == 0 {
+			continue
+		}
+
+		if best == nil || rank[m.Role] > rank[best.Role] ||
+			rank[m.Role] == rank[best.Role] && m.UserID < best.UserID {
+			best = m
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	id := int(best.UserID)
+	return &id
+}
+
+func memberOf(members []query.ListAccountMembersRow, userID int) bool {
+	for _, m := range members {
+		if int(m.UserID) == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// followsChoice сообщает, учтён ли в плане выбор для каждого счёта из accounts: счёт принадлежит
+// пользователю, а выбранный новый владелец - другой участник этого счёта
+func followsChoice(plan []models.DeletionAccount, accounts map[int]*int) bool {
+	planned := make(map[int]bool, len(plan))
+	for _, account := range plan {
+		planned[account.AccountID] = !account.Default
+	}
+
+	for accountID := range accounts {
+		if !planned[accountID] {
+			return false
+		}
+	}
+
+	return true
+}
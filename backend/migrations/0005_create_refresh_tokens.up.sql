@@ -0,0 +1,15 @@
+CREATE TABLE refresh_tokens (
+    id          INT PRIMARY KEY AUTO_INCREMENT,
+    user_id     INT NOT NULL,
+    family_id   CHAR(32) NOT NULL,
+    token_hash  CHAR(64) NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at  DATETIME DEFAULT NULL,
+
+    UNIQUE KEY uniq_token_hash (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_family (family_id),
+    INDEX idx_user (user_id)
+);
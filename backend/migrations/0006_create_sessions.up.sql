@@ -0,0 +1,30 @@
+CREATE TABLE sessions (
+    id            CHAR(32) PRIMARY KEY,
+    user_id       INT NOT NULL,
+    user_agent    VARCHAR(255) NOT NULL DEFAULT '',
+    ip            VARCHAR(45) NOT NULL DEFAULT '',
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    DATETIME NOT NULL,
+    revoked_at    DATETIME DEFAULT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_user (user_id)
+);
+
+-- Каждая уже выданная цепочка refresh токенов становится отдельной сессией
+INSERT INTO sessions (id, user_id, created_at, last_seen_at, expires_at, revoked_at)
+SELECT
+    family_id,
+    MIN(user_id),
+    MIN(created_at),
+    MAX(created_at),
+    MAX(expires_at),
+    IF(SUM(revoked_at IS NULL) = 0, MAX(revoked_at), NULL)
+FROM refresh_tokens
+GROUP BY family_id;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session
+    FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_rule,
+    DROP INDEX idx_rule_date,
+    DROP COLUMN rule_id;
+DROP TABLE IF EXISTS recurring_rules;
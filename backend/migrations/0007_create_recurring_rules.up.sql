@@ -0,0 +1,35 @@
+CREATE TABLE recurring_rules (
+    id                  INT PRIMARY KEY AUTO_INCREMENT,
+    account_id          INT NOT NULL,
+    user_id             INT NOT NULL,
+
+    title               VARCHAR(255) NOT NULL,
+    amount              DECIMAL(12,2) NOT NULL,
+
+    period              ENUM('day', 'week', 'month', 'year') NOT NULL,
+    interval_count      INT NOT NULL DEFAULT 1,
+    starts_at           DATETIME NOT NULL,
+    ends_at             DATETIME DEFAULT NULL,
+    max_occurrences     INT DEFAULT NULL,
+    paused              BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Состояние материализации: номер следующего вхождения в расписании,
+    -- его дата и число уже созданных транзакций
+    next_index          INT NOT NULL DEFAULT 0,
+    next_occurrence_at  DATETIME NOT NULL,
+    occurrences_count   INT NOT NULL DEFAULT 0,
+
+    created_at          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_account (account_id),
+    INDEX idx_next_occurrence (paused, next_occurrence_at)
+);
+
+ALTER TABLE transactions
+    ADD COLUMN rule_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_transactions_rule
+        FOREIGN KEY (rule_id) REFERENCES recurring_rules(id) ON DELETE SET NULL,
+    ADD INDEX idx_rule_date (rule_id, occurred_at);
@@ -0,0 +1,10 @@
+-- Записи серий, созданных из старых периодических транзакций, снова становятся
+-- отдельными транзакциями, а сами серии удаляются. Серии, созданные позже, не трогаются
+UPDATE transactions t
+JOIN legacy_recurring_rules l ON l.rule_id = t.rule_id
+SET t.rule_id = NULL;
+
+DELETE FROM recurring_rules
+WHERE id IN (SELECT rule_id FROM legacy_recurring_rules);
+
+DROP TABLE legacy_recurring_rules;
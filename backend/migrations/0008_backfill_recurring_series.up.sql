@@ -0,0 +1,145 @@
+-- Старые периодические транзакции создавались пакетами по 500 записей: первая запись, затем
+-- остальные одним INSERT, каждая на шаг периода позже предыдущей. Для каждого пакета создаётся
+-- правило, завершающееся на последней уже созданной записи. Записи пакета идут подряд по id,
+-- поэтому новый пакет начинается там, где предыдущая по id запись относится к другому счёту,
+-- пользователю или периоду. Одинаковый пакет, созданный сразу следом, начинается не позже
+-- предыдущей записи, а его следующая запись отстоит от первой ровно на шаг периода.
+-- Название, сумма и дата в разбиении не участвуют, поэтому записи, изменённые по отдельности,
+-- остаются вхождениями своей серии
+CREATE TABLE legacy_series_rows (
+    transaction_id        INT PRIMARY KEY,
+    first_transaction_id  INT NOT NULL,
+
+    INDEX idx_first_transaction (first_transaction_id)
+);
+
+INSERT INTO legacy_series_rows (transaction_id, first_transaction_id)
+SELECT
+    id,
+    -- Первая запись пакета - последняя запись серии до текущей, с которой начался пакет
+    MAX(IF(continues, NULL, id)) OVER (PARTITION BY account_id, user_id, period ORDER BY id)
+FROM (
+    SELECT
+        id,
+        account_id,
+        user_id,
+        period,
+        (
+            prev_account_id = account_id
+            AND prev_user_id = user_id
+            AND prev_period = period
+            AND NOT (occurred_at <= prev_occurred_at AND next_occurred_at <=> step_after)
+        )
+        -- Между записями пакета могла попасть чужая запись: пакет продолжается,
+        -- если запись отстоит от предыдущей записи серии ровно на шаг периода
+        OR occurred_at = step_after_series_prev AS continues
+    FROM (
+        SELECT
+            *,
+            -- Шаг периода считается как в старом коде (time.AddDate): 31 января + месяц = 3 марта
+            CASE period
+                WHEN 'day' THEN DATE_ADD(occurred_at, INTERVAL 1 DAY)
+                WHEN 'week' THEN DATE_ADD(occurred_at, INTERVAL 7 DAY)
+                WHEN 'month' THEN DATE_ADD(
+                    DATE_ADD(DATE_SUB(occurred_at, INTERVAL DAY(occurred_at) - 1 DAY), INTERVAL 1 MONTH),
+                    INTERVAL DAY(occurred_at) - 1 DAY
+                )
+                WHEN 'year' THEN DATE_ADD(
+                    DATE_ADD(DATE_SUB(occurred_at, INTERVAL DAY(occurred_at) - 1 DAY), INTERVAL 1 YEAR),
+                    INTERVAL DAY(occurred_at) - 1 DAY
+                )
+            END AS step_after,
+            CASE period
+                WHEN 'day' THEN DATE_ADD(series_prev_occurred_at, INTERVAL 1 DAY)
+                WHEN 'week' THEN DATE_ADD(series_prev_occurred_at, INTERVAL 7 DAY)
+                WHEN 'month' THEN DATE_ADD(
+                    DATE_ADD(DATE_SUB(series_prev_occurred_at, INTERVAL DAY(series_prev_occurred_at) - 1 DAY), INTERVAL 1 MONTH),
+                    INTERVAL DAY(series_prev_occurred_at) - 1 DAY
+                )
+                WHEN 'year' THEN DATE_ADD(
+                    DATE_ADD(DATE_SUB(series_prev_occurred_at, INTERVAL DAY(series_prev_occurred_at) - 1 DAY), INTERVAL 1 YEAR),
+                    INTERVAL DAY(series_prev_occurred_at) - 1 DAY
+                )
+            END AS step_after_series_prev
+        FROM (
+            SELECT
+                id,
+                account_id,
+                user_id,
+                period,
+                rule_id,
+                occurred_at,
+                LAG(account_id) OVER by_id AS prev_account_id,
+                LAG(user_id) OVER by_id AS prev_user_id,
+                LAG(period) OVER by_id AS prev_period,
+                LAG(occurred_at) OVER by_id AS prev_occurred_at,
+                LEAD(occurred_at) OVER by_id AS next_occurred_at,
+                LAG(occurred_at) OVER (PARTITION BY account_id, user_id, period ORDER BY id) AS series_prev_occurred_at
+            FROM transactions
+            WINDOW by_id AS (ORDER BY id)
+        ) neighbours
+        WHERE period IS NOT NULL AND rule_id IS NULL
+    ) steps
+) boundaries;
+
+-- Созданные здесь правила запоминаются, чтобы откат удалил только их
+CREATE TABLE legacy_recurring_rules (
+    rule_id               INT PRIMARY KEY,
+    first_transaction_id  INT NOT NULL UNIQUE
+);
+
+ALTER TABLE recurring_rules
+    ADD COLUMN legacy_first_transaction_id INT DEFAULT NULL;
+
+-- Название и сумма серии берутся из первой записи пакета
+INSERT INTO recurring_rules (
+    account_id,
+    user_id,
+    title,
+    amount,
+    period,
+    starts_at,
+    ends_at,
+    next_index,
+    next_occurrence_at,
+    occurrences_count,
+    legacy_first_transaction_id
+)
+SELECT
+    f.account_id,
+    f.user_id,
+    f.title,
+    f.amount,
+    f.period,
+    b.starts_at,
+    b.ends_at,
+    b.occurrences,
+    b.ends_at + INTERVAL 1 DAY,
+    b.occurrences,
+    b.first_transaction_id
+FROM (
+    SELECT
+        s.first_transaction_id,
+        MIN(t.occurred_at) AS starts_at,
+        MAX(t.occurred_at) AS ends_at,
+        COUNT(*) AS occurrences
+    FROM legacy_series_rows s
+    JOIN transactions t ON t.id = s.transaction_id
+    GROUP BY s.first_transaction_id
+) b
+JOIN transactions f ON f.id = b.first_transaction_id;
+
+UPDATE transactions t
+JOIN legacy_series_rows s ON s.transaction_id = t.id
+JOIN recurring_rules r ON r.legacy_first_transaction_id = s.first_transaction_id
+SET t.rule_id = r.id;
+
+INSERT INTO legacy_recurring_rules (rule_id, first_transaction_id)
+SELECT id, legacy_first_transaction_id
+FROM recurring_rules
+WHERE legacy_first_transaction_id IS NOT NULL;
+
+ALTER TABLE recurring_rules
+    DROP COLUMN legacy_first_transaction_id;
+
+DROP TABLE legacy_series_rows;
@@ -0,0 +1,9 @@
+ALTER TABLE recurring_rules
+    DROP COLUMN currency;
+
+ALTER TABLE transactions
+    DROP INDEX idx_account_currency,
+    DROP COLUMN currency;
+
+ALTER TABLE accounts
+    DROP COLUMN currency;
@@ -0,0 +1,12 @@
+-- Валюта счёта (код ISO 4217). Существующие счета считаются рублёвыми
+ALTER TABLE accounts
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+-- Валюта хранится и на транзакциях, и на правилах повторения, чтобы итоги
+-- можно было считать по валютам без соединения со счетами
+ALTER TABLE transactions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    ADD INDEX idx_account_currency (account_id, currency);
+
+ALTER TABLE recurring_rules
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
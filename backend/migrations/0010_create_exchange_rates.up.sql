@@ -0,0 +1,16 @@
+-- Курсы обмена пользователя по датам: одна единица base_currency стоит rate единиц quote_currency
+CREATE TABLE exchange_rates (
+    id              INT PRIMARY KEY AUTO_INCREMENT,
+    user_id         INT NOT NULL,
+
+    base_currency   CHAR(3) NOT NULL,
+    quote_currency  CHAR(3) NOT NULL,
+    rate_date       DATE NOT NULL,
+    rate            DECIMAL(18,8) NOT NULL,
+
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    UNIQUE KEY uniq_user_pair_date (user_id, base_currency, quote_currency, rate_date)
+);
@@ -0,0 +1,3 @@
+-- Остаток на счёте до первой транзакции
+ALTER TABLE accounts
+    ADD COLUMN opening_balance DECIMAL(12,2) NOT NULL DEFAULT 0;
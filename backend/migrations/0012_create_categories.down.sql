@@ -0,0 +1,10 @@
+ALTER TABLE recurring_rules
+    DROP FOREIGN KEY fk_recurring_rules_category,
+    DROP COLUMN category_id;
+
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_category,
+    DROP INDEX idx_account_category,
+    DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,28 @@
+-- Дерево категорий счёта (например, Еда > Продукты). Удаление категории
+-- удаляет её подкатегории
+CREATE TABLE categories (
+    id          INT PRIMARY KEY AUTO_INCREMENT,
+    account_id  INT NOT NULL,
+    parent_id   INT DEFAULT NULL,
+
+    name        VARCHAR(100) NOT NULL,
+
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE CASCADE,
+
+    INDEX idx_account (account_id)
+);
+
+-- Транзакции и серии удалённой категории остаются без категории
+ALTER TABLE transactions
+    ADD COLUMN category_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_transactions_category
+        FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL,
+    ADD INDEX idx_account_category (account_id, category_id);
+
+ALTER TABLE recurring_rules
+    ADD COLUMN category_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_recurring_rules_category
+        FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL;
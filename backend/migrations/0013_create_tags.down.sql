@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recurring_rule_tags;
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,33 @@
+-- Метки счёта (например, vacation-2026, reimbursable). Метка создаётся
+-- при первом использовании, название хранится в нижнем регистре
+CREATE TABLE tags (
+    id          INT PRIMARY KEY AUTO_INCREMENT,
+    account_id  INT NOT NULL,
+
+    name        VARCHAR(50) NOT NULL,
+
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+
+    UNIQUE KEY uniq_account_name (account_id, name)
+);
+
+CREATE TABLE transaction_tags (
+    transaction_id  INT NOT NULL,
+    tag_id          INT NOT NULL,
+
+    PRIMARY KEY (transaction_id, tag_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+
+    INDEX idx_tag (tag_id)
+);
+
+-- Метки серии переходят к её вхождениям при их создании
+CREATE TABLE recurring_rule_tags (
+    rule_id  INT NOT NULL,
+    tag_id   INT NOT NULL,
+
+    PRIMARY KEY (rule_id, tag_id),
+    FOREIGN KEY (rule_id) REFERENCES recurring_rules(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE recurring_rules
+    DROP COLUMN notes;
+
+ALTER TABLE transactions
+    DROP COLUMN notes;
@@ -0,0 +1,6 @@
+-- Произвольное примечание к транзакции. Примечание серии переходит к её вхождениям
+ALTER TABLE transactions
+    ADD COLUMN notes TEXT DEFAULT NULL;
+
+ALTER TABLE recurring_rules
+    ADD COLUMN notes TEXT DEFAULT NULL;
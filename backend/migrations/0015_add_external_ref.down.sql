@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    DROP INDEX idx_account_external_ref,
+    DROP COLUMN external_ref;
@@ -0,0 +1,5 @@
+-- Идентификатор транзакции в банковской выписке (например, FITID в OFX).
+-- Повторный импорт той же выписки пропускает уже загруженные транзакции
+ALTER TABLE transactions
+    ADD COLUMN external_ref VARCHAR(255) DEFAULT NULL,
+    ADD UNIQUE INDEX idx_account_external_ref (account_id, external_ref);
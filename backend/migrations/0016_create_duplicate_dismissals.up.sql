@@ -0,0 +1,14 @@
+-- Пары транзакций, которые пользователь отметил как разные операции, хотя они похожи
+-- на дубликаты. Пара хранится один раз: transaction_id < duplicate_id
+CREATE TABLE duplicate_dismissals (
+    transaction_id  INT NOT NULL,
+    duplicate_id    INT NOT NULL,
+
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (transaction_id, duplicate_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (duplicate_id) REFERENCES transactions(id) ON DELETE CASCADE,
+
+    INDEX idx_duplicate (duplicate_id)
+);
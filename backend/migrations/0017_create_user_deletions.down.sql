@@ -0,0 +1,22 @@
+-- Записи без автора нельзя вернуть под ограничение NOT NULL, они удаляются
+DELETE FROM recurring_rules WHERE user_id IS NULL;
+DELETE FROM transactions WHERE user_id IS NULL;
+
+ALTER TABLE recurring_rules
+    DROP FOREIGN KEY fk_recurring_rules_user;
+
+ALTER TABLE recurring_rules
+    MODIFY COLUMN user_id INT NOT NULL,
+    ADD CONSTRAINT recurring_rules_ibfk_2
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_user;
+
+ALTER TABLE transactions
+    MODIFY COLUMN user_id INT NOT NULL,
+    ADD CONSTRAINT transactions_ibfk_2
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP TABLE IF EXISTS user_deletion_accounts;
+DROP TABLE IF EXISTS user_deletions;
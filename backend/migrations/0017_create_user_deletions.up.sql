@@ -0,0 +1,40 @@
+-- Удаление пользователя откладывается на срок, в течение которого его можно отменить.
+-- Для счетов, которыми он владеет, в user_deletion_accounts сохраняется выбор пользователя:
+-- передать счёт участнику (new_owner_id) или удалить (new_owner_id IS NULL)
+CREATE TABLE user_deletions (
+    user_id       INT PRIMARY KEY,
+    requested_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delete_at     DATETIME NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_delete_at (delete_at)
+);
+
+CREATE TABLE user_deletion_accounts (
+    user_id       INT NOT NULL,
+    account_id    INT NOT NULL,
+    new_owner_id  INT DEFAULT NULL,
+
+    PRIMARY KEY (user_id, account_id),
+    FOREIGN KEY (user_id) REFERENCES user_deletions(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (new_owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Транзакции и серии удалённого пользователя в чужих счетах остаются без автора
+ALTER TABLE transactions
+    DROP FOREIGN KEY transactions_ibfk_2;
+
+ALTER TABLE transactions
+    MODIFY COLUMN user_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_transactions_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE recurring_rules
+    DROP FOREIGN KEY recurring_rules_ibfk_2;
+
+ALTER TABLE recurring_rules
+    MODIFY COLUMN user_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_recurring_rules_user
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,24 @@
+-- Приглашение в счёт становится участием только после согласия приглашённого.
+-- invitee_id заполняется, когда пользователь с этим email зарегистрирован
+CREATE TABLE invitations (
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    token         CHAR(64) NOT NULL,
+    account_id    INT NOT NULL,
+    inviter_id    INT DEFAULT NULL,
+    email         VARCHAR(64) NOT NULL,
+    invitee_id    INT DEFAULT NULL,
+    role          ENUM('viewer', 'editor', 'admin') NOT NULL,
+    status        ENUM('pending', 'accepted', 'declined', 'revoked') NOT NULL DEFAULT 'pending',
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    DATETIME NOT NULL,
+    responded_at  DATETIME DEFAULT NULL,
+
+    UNIQUE KEY uniq_token (token),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (inviter_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (invitee_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_account (account_id, created_at),
+    INDEX idx_email (email),
+    INDEX idx_invitee_status (invitee_id, status)
+);
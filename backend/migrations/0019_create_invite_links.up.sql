@@ -0,0 +1,20 @@
+-- Ссылки-приглашения в счёт: любой, у кого есть код, может присоединиться к счёту с ролью ссылки,
+-- пока ссылка не отозвана, не истекла и не исчерпала max_uses (NULL - без ограничения)
+CREATE TABLE invite_links (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    code        CHAR(32) NOT NULL,
+    account_id  INT NOT NULL,
+    created_by  INT DEFAULT NULL,
+    role        ENUM('viewer', 'editor', 'admin') NOT NULL,
+    max_uses    INT DEFAULT NULL,
+    uses        INT NOT NULL DEFAULT 0,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at  DATETIME NOT NULL,
+    revoked_at  DATETIME DEFAULT NULL,
+
+    UNIQUE KEY uniq_code (code),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+
+    INDEX idx_account (account_id, created_at)
+);
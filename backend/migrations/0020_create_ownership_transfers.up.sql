@@ -0,0 +1,14 @@
+-- Передача счёта другому участнику, ожидающая его подтверждения. У счёта не больше одной такой передачи
+CREATE TABLE ownership_transfers (
+    account_id    INT PRIMARY KEY,
+    from_user_id  INT NOT NULL,
+    to_user_id    INT NOT NULL,
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    DATETIME NOT NULL,
+
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    INDEX idx_to_user (to_user_id)
+);
@@ -0,0 +1,10 @@
+-- Участники с пользовательскими ролями остаются в счёте с ролью viewer
+UPDATE account_members SET role = 'viewer' WHERE role = 'custom';
+
+ALTER TABLE account_members
+    DROP FOREIGN KEY fk_account_members_role,
+    DROP COLUMN role_id,
+    MODIFY COLUMN role ENUM('viewer', 'editor', 'admin', 'owner') NOT NULL;
+
+DROP TABLE IF EXISTS account_role_permissions;
+DROP TABLE IF EXISTS account_roles;
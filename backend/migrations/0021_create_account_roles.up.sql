@@ -0,0 +1,28 @@
+-- Пользовательские роли счёта: набор прав, который владелец счёта задаёт сам
+CREATE TABLE account_roles (
+    id          INT PRIMARY KEY AUTO_INCREMENT,
+    account_id  INT NOT NULL,
+    name        VARCHAR(50) NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+
+    UNIQUE KEY uq_account_name (account_id, name)
+);
+
+CREATE TABLE account_role_permissions (
+    role_id     INT NOT NULL,
+    permission  VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES account_roles(id) ON DELETE CASCADE
+);
+
+-- Участник с ролью custom получает права пользовательской роли role_id. Роль, назначенную
+-- участникам, удалить нельзя; SET NULL нужен только при удалении счёта, когда участники
+-- и роли удаляются каскадом в произвольном порядке
+ALTER TABLE account_members
+    MODIFY COLUMN role ENUM('viewer', 'editor', 'admin', 'owner', 'custom') NOT NULL,
+    ADD COLUMN role_id INT DEFAULT NULL,
+    ADD CONSTRAINT fk_account_members_role
+        FOREIGN KEY (role_id) REFERENCES account_roles(id) ON DELETE SET NULL;
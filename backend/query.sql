@@ -24,20 +24,33 @@ UPDATE users
 SET password_hash = ?
 WHERE id = ?;
 
+-- name: GetUserPasswordHash :one
+SELECT password_hash
+FROM users
+WHERE id = ?;
+
+-- name: DeleteUserByID :exec
+DELETE FROM users
+WHERE id = ?;
+
 -- name: ListUserAccounts :many
 SELECT
     a.id,
     a.name,
     a.description,
-    am.role
+    a.currency,
+    am.role,
+    am.role_id,
+    ar.name AS role_name
 FROM account_members am
 JOIN accounts a ON a.id = am.account_id
+LEFT JOIN account_roles ar ON ar.id = am.role_id
 WHERE am.user_id = ?
 ORDER BY a.name;
 
 -- name: CreateAccount :execresult
-INSERT INTO accounts (name, description, owner_id)
-VALUES (?, ?, ?);
+INSERT INTO accounts (name, description, owner_id, currency, opening_balance)
+VALUES (?, ?, ?, ?, ?);
 
 -- name: GetAccountByID :one
 SELECT *
@@ -45,10 +58,60 @@ FROM accounts
 WHERE id = ?
 LIMIT 1;
 
+-- name: SetAccountOpeningBalance :exec
+UPDATE accounts
+SET opening_balance = ?
+WHERE id = ?;
+
+-- name: GetAccountBalance :one
+SELECT
+    a.currency,
+    a.opening_balance,
+    CAST(a.opening_balance + COALESCE(SUM(t.amount), 0) AS DECIMAL(20,2)) AS balance
+FROM accounts a
+LEFT JOIN transactions t ON t.account_id = a.id AND t.occurred_at <= sqlc.arg(at)
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
 -- name: DeleteAccountByID :exec
 DELETE FROM accounts
 WHERE id = ?;
 
+-- name: SetAccountOwner :exec
+UPDATE accounts
+SET owner_id = ?, name = ?
+WHERE id = ?;
+
+-- name: GetAccountForUpdate :one
+SELECT *
+FROM accounts
+WHERE id = ?
+FOR UPDATE;
+
+-- name: CreateOwnershipTransfer :exec
+INSERT INTO ownership_transfers (account_id, from_user_id, to_user_id, expires_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    from_user_id = VALUES(from_user_id),
+    to_user_id = VALUES(to_user_id),
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = VALUES(expires_at);
+
+-- name: GetOwnershipTransfer :one
+SELECT *
+FROM ownership_transfers
+WHERE account_id = ?;
+
+-- name: GetOwnershipTransferForUpdate :one
+SELECT *
+FROM ownership_transfers
+WHERE account_id = ?
+FOR UPDATE;
+
+-- name: DeleteOwnershipTransfer :exec
+DELETE FROM ownership_transfers
+WHERE account_id = ?;
+
 -- name: AddAccountMember :exec
 INSERT INTO account_members (account_id, user_id, role)
 VALUES (?, ?, ?);
@@ -59,11 +122,18 @@ FROM account_members
 WHERE account_id = ? AND user_id = ?
 LIMIT 1;
 
+-- name: GetAccountMember :one
+SELECT role, role_id
+FROM account_members
+WHERE account_id = ? AND user_id = ?
+LIMIT 1;
+
 -- name: ListAccountMembers :many
-SELECT am.user_id, u.email, am.role
+SELECT am.user_id, u.email, am.role, am.role_id, ar.name AS role_name
 FROM account_members am
 JOIN users u ON u.id = am.user_id
-WHERE account_id = ?
+LEFT JOIN account_roles ar ON ar.id = am.role_id
+WHERE am.account_id = ?
 ORDER BY u.email;
 
 -- name: RemoveAccountMember :exec
@@ -72,41 +142,647 @@ WHERE account_id = ? AND user_id = ?;
 
 -- name: UpdateAccountMemberRole :exec
 UPDATE account_members
-SET role = ?
+SET role = ?, role_id = NULL
 WHERE account_id = ? AND user_id = ?;
 
+-- name: SetAccountMemberCustomRole :exec
+UPDATE account_members
+SET role = 'custom', role_id = ?
+WHERE account_id = ? AND user_id = ?;
+
+-- name: CreateAccountRole :execresult
+INSERT INTO account_roles (account_id, name)
+VALUES (?, ?);
+
+-- name: GetAccountRole :one
+SELECT *
+FROM account_roles
+WHERE id = ? AND account_id = ?;
+
+-- name: ListAccountRoles :many
+SELECT *
+FROM account_roles
+WHERE account_id = ?
+ORDER BY name;
+
+-- name: RenameAccountRole :exec
+UPDATE account_roles
+SET name = ?
+WHERE id = ?;
+
+-- name: DeleteAccountRole :exec
+DELETE FROM account_roles
+WHERE id = ?;
+
+-- name: CountAccountRoleMembers :one
+SELECT COUNT(*)
+FROM account_members
+WHERE role_id = ?;
+
+-- name: AddAccountRolePermission :exec
+INSERT INTO account_role_permissions (role_id, permission)
+VALUES (?, ?);
+
+-- name: DeleteAccountRolePermissions :exec
+DELETE FROM account_role_permissions
+WHERE role_id = ?;
+
+-- name: ListAccountRolePermissions :many
+SELECT permission
+FROM account_role_permissions
+WHERE role_id = ?
+ORDER BY permission;
+
+-- name: ListAccountRolesPermissions :many
+SELECT p.role_id, p.permission
+FROM account_role_permissions p
+JOIN account_roles r ON r.id = p.role_id
+WHERE r.account_id = ?
+ORDER BY p.role_id, p.permission;
+
+-- name: CreateInvitation :execresult
+INSERT INTO invitations (token, account_id, inviter_id, email, invitee_id, role, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetAccountInvitation :one
+SELECT *
+FROM invitations
+WHERE id = ? AND account_id = ?;
+
+-- name: GetInvitationByTokenForUpdate :one
+SELECT *
+FROM invitations
+WHERE token = ?
+FOR UPDATE;
+
+-- name: ListAccountInvitations :many
+SELECT *
+FROM invitations
+WHERE account_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListUserInvitations :many
+SELECT i.*, a.name AS account_name, u.email AS inviter_email
+FROM invitations i
+JOIN accounts a ON a.id = i.account_id
+LEFT JOIN users u ON u.id = i.inviter_id
+WHERE i.invitee_id = ? AND i.status = 'pending' AND i.expires_at > ?
+ORDER BY i.created_at DESC, i.id DESC;
+
+-- name: HasPendingInvitation :one
+SELECT EXISTS (
+    SELECT 1
+    FROM invitations
+    WHERE account_id = ? AND email = ? AND status = 'pending' AND expires_at > ?
+);
+
+-- name: SetInvitationStatus :execresult
+UPDATE invitations
+SET status = ?, responded_at = ?
+WHERE id = ? AND status = 'pending';
+
+-- name: LinkInvitationsToUser :exec
+UPDATE invitations
+SET invitee_id = ?
+WHERE email = ? AND invitee_id IS NULL;
+
+-- name: CreateInviteLink :execresult
+INSERT INTO invite_links (code, account_id, created_by, role, max_uses, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetAccountInviteLink :one
+SELECT *
+FROM invite_links
+WHERE id = ? AND account_id = ?;
+
+-- name: GetInviteLinkByCodeForUpdate :one
+SELECT *
+FROM invite_links
+WHERE code = ?
+FOR UPDATE;
+
+-- name: ListAccountInviteLinks :many
+SELECT *
+FROM invite_links
+WHERE account_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: IncrementInviteLinkUses :exec
+UPDATE invite_links
+SET uses = uses + 1
+WHERE id = ?;
+
+-- name: RevokeInviteLink :exec
+UPDATE invite_links
+SET revoked_at = ?
+WHERE id = ? AND revoked_at IS NULL;
+
 -- name: CreateTransaction :execresult
 INSERT INTO transactions (
     account_id,
     user_id,
     title,
     amount,
+    currency,
     occurred_at,
-    period
+    period,
+    category_id,
+    notes
 )
-VALUES (?, ?, ?, ?, ?, ?);
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetTransactionByID :one
 SELECT *
 FROM transactions
 WHERE id = ?;
 
--- name: ListTransactions :many
-SELECT *
+-- name: SummarizeAccountTransactions :many
+SELECT
+    currency,
+    COUNT(*) AS transactions_count,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense,
+    CAST(COALESCE(SUM(amount), 0) AS DECIMAL(20,2)) AS total
 FROM transactions
-WHERE account_id = ?
-    AND (? IS NULL OR user_id = ?)
+WHERE account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(date_from) IS NULL OR occurred_at >= sqlc.narg(date_from))
+    AND occurred_at <= sqlc.arg(date_to)
+GROUP BY currency
+ORDER BY currency;
+
+-- name: SummarizeAccountsTransactions :many
+SELECT
+    currency,
+    COUNT(*) AS transactions_count,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense,
+    CAST(COALESCE(SUM(amount), 0) AS DECIMAL(20,2)) AS total
+FROM transactions
+WHERE account_id IN (sqlc.slice(account_ids))
+    AND (sqlc.narg(date_from) IS NULL OR occurred_at >= sqlc.narg(date_from))
+    AND occurred_at <= sqlc.arg(date_to)
+GROUP BY currency
+ORDER BY currency;
 
-    AND (? IS NULL OR occurred_at >= ?)
-    AND (? IS NULL OR occurred_at <= ?)
+-- name: SummarizeAccountTransactionsByDay :many
+SELECT
+    currency,
+    DATE(occurred_at) AS day,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense
+FROM transactions
+WHERE account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(date_from) IS NULL OR occurred_at >= sqlc.narg(date_from))
+    AND occurred_at <= sqlc.arg(date_to)
+GROUP BY currency, DATE(occurred_at)
+ORDER BY currency, day;
 
-    AND (
-        ? IS NULL
-        OR (? = 'income' AND amount > 0)
-        OR (? = 'expense' AND amount < 0)
-    )
-ORDER BY occurred_at DESC;
+-- name: SummarizeAccountsTransactionsByDay :many
+SELECT
+    currency,
+    DATE(occurred_at) AS day,
+    CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0) AS DECIMAL(20,2)) AS income,
+    CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount END), 0) AS DECIMAL(20,2)) AS expense
+FROM transactions
+WHERE account_id IN (sqlc.slice(account_ids))
+    AND (sqlc.narg(date_from) IS NULL OR occurred_at >= sqlc.narg(date_from))
+    AND occurred_at <= sqlc.arg(date_to)
+GROUP BY currency, DATE(occurred_at)
+ORDER BY currency, day;
 
 -- name: DeleteTransactionByID :exec
 DELETE FROM transactions
 WHERE id = ?;
+
+-- name: CreateRefreshToken :execresult
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetRefreshTokenByHash :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = ?
+LIMIT 1;
+
+-- name: RevokeRefreshToken :execresult
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokensExcept :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND family_id <> ? AND revoked_at IS NULL;
+
+-- name: CreateSession :exec
+INSERT INTO sessions (id, user_id, user_agent, ip, expires_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetSessionByID :one
+SELECT *
+FROM sessions
+WHERE id = ?
+LIMIT 1;
+
+-- name: ListActiveUserSessions :many
+SELECT *
+FROM sessions
+WHERE user_id = ?
+    AND revoked_at IS NULL
+    AND expires_at > CURRENT_TIMESTAMP
+ORDER BY last_seen_at DESC;
+
+-- name: ExtendSession :exec
+UPDATE sessions
+SET last_seen_at = CURRENT_TIMESTAMP, ip = ?, expires_at = ?
+WHERE id = ?;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen_at = CURRENT_TIMESTAMP
+WHERE id = ? AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL 1 MINUTE;
+
+-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserSessionsExcept :exec
+UPDATE sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND id <> ? AND revoked_at IS NULL;
+
+-- name: CreateRecurringRule :execresult
+INSERT INTO recurring_rules (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    period,
+    interval_count,
+    starts_at,
+    ends_at,
+    max_occurrences,
+    next_occurrence_at,
+    category_id,
+    notes
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetRecurringRuleByID :one
+SELECT *
+FROM recurring_rules
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetRecurringRuleForUpdate :one
+SELECT *
+FROM recurring_rules
+WHERE id = ?
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListAccountRecurringRules :many
+SELECT *
+FROM recurring_rules
+WHERE account_id = ?
+ORDER BY starts_at, id;
+
+-- name: ListDueRecurringRuleIDs :many
+SELECT id
+FROM recurring_rules
+WHERE paused = FALSE
+    AND next_occurrence_at <= ?
+    AND (ends_at IS NULL OR next_occurrence_at <= ends_at)
+    AND (max_occurrences IS NULL OR occurrences_count < max_occurrences);
+
+-- name: ListDueAccountRecurringRuleIDs :many
+SELECT id
+FROM recurring_rules
+WHERE account_id = ?
+    AND paused = FALSE
+    AND next_occurrence_at <= ?
+    AND (ends_at IS NULL OR next_occurrence_at <= ends_at)
+    AND (max_occurrences IS NULL OR occurrences_count < max_occurrences);
+
+-- name: UpdateRecurringRuleProgress :exec
+UPDATE recurring_rules
+SET next_index = ?, next_occurrence_at = ?, occurrences_count = ?
+WHERE id = ?;
+
+-- name: UpdateRecurringRule :exec
+UPDATE recurring_rules
+SET title = ?,
+    amount = ?,
+    period = ?,
+    interval_count = ?,
+    starts_at = ?,
+    ends_at = ?,
+    max_occurrences = ?,
+    category_id = ?,
+    notes = ?,
+    next_index = ?,
+    next_occurrence_at = ?,
+    occurrences_count = ?
+WHERE id = ?;
+
+-- name: EndRecurringRule :exec
+UPDATE recurring_rules
+SET ends_at = ?, occurrences_count = ?
+WHERE id = ?;
+
+-- name: SetRecurringRulePaused :exec
+UPDATE recurring_rules
+SET paused = ?, next_index = ?, next_occurrence_at = ?, occurrences_count = ?
+WHERE id = ?;
+
+-- name: DeleteRecurringRuleByID :exec
+DELETE FROM recurring_rules
+WHERE id = ?;
+
+-- name: DeleteRuleOccurrencesFrom :execresult
+DELETE FROM transactions
+WHERE rule_id = ? AND occurred_at >= ?;
+
+-- name: GetFirstRuleOccurrenceID :one
+SELECT id
+FROM transactions
+WHERE rule_id = ?
+ORDER BY occurred_at, id
+LIMIT 1;
+
+-- name: CountRuleOccurrencesBefore :one
+SELECT COUNT(*)
+FROM transactions
+WHERE rule_id = ? AND occurred_at < ?;
+
+-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rates (user_id, base_currency, quote_currency, rate_date, rate)
+VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE rate = VALUES(rate);
+
+-- name: ListUserExchangeRates :many
+SELECT *
+FROM exchange_rates
+WHERE user_id = sqlc.arg(user_id)
+    AND (sqlc.arg(base_currency) = '' OR base_currency = sqlc.arg(base_currency))
+    AND (sqlc.arg(quote_currency) = '' OR quote_currency = sqlc.arg(quote_currency))
+    AND (sqlc.narg(date_from) IS NULL OR rate_date >= sqlc.narg(date_from))
+    AND (sqlc.narg(date_to) IS NULL OR rate_date <= sqlc.narg(date_to))
+ORDER BY rate_date DESC, base_currency, quote_currency;
+
+-- name: ListUserExchangeRatesUntil :many
+SELECT *
+FROM exchange_rates
+WHERE user_id = ? AND rate_date <= ?
+ORDER BY rate_date;
+
+-- name: DeleteExchangeRate :execresult
+DELETE FROM exchange_rates
+WHERE id = ? AND user_id = ?;
+
+-- name: CreateCategory :execresult
+INSERT INTO categories (account_id, parent_id, name)
+VALUES (?, ?, ?);
+
+-- name: GetCategoryByID :one
+SELECT *
+FROM categories
+WHERE id = ?
+LIMIT 1;
+
+-- name: ListAccountCategories :many
+SELECT *
+FROM categories
+WHERE account_id = ?
+ORDER BY name, id;
+
+-- name: UpdateCategory :exec
+UPDATE categories
+SET parent_id = ?, name = ?
+WHERE id = ?;
+
+-- name: DeleteCategoryByID :exec
+DELETE FROM categories
+WHERE id = ?;
+
+-- name: UpsertTag :execlastid
+INSERT INTO tags (account_id, name)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id);
+
+-- name: ListAccountTags :many
+SELECT g.name, COUNT(*) AS transactions_count
+FROM tags g
+JOIN transaction_tags tt ON tt.tag_id = g.id
+WHERE g.account_id = ?
+GROUP BY g.id, g.name
+ORDER BY g.name;
+
+-- name: AddTransactionTag :exec
+INSERT INTO transaction_tags (transaction_id, tag_id)
+VALUES (?, ?);
+
+-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tags
+WHERE transaction_id = ?;
+
+-- name: ListTransactionsTags :many
+SELECT tt.transaction_id, g.name
+FROM transaction_tags tt
+JOIN tags g ON g.id = tt.tag_id
+WHERE tt.transaction_id IN (sqlc.slice(transaction_ids))
+ORDER BY g.name;
+
+-- name: AddRecurringRuleTag :exec
+INSERT INTO recurring_rule_tags (rule_id, tag_id)
+VALUES (?, ?);
+
+-- name: DeleteRuleOccurrencesTags :exec
+DELETE FROM transaction_tags
+WHERE transaction_id IN (
+    SELECT id FROM transactions WHERE rule_id = ?
+);
+
+-- name: DeleteRecurringRuleTags :exec
+DELETE FROM recurring_rule_tags
+WHERE rule_id = ?;
+
+-- name: ListRecurringRuleTags :many
+SELECT g.name
+FROM recurring_rule_tags rt
+JOIN tags g ON g.id = rt.tag_id
+WHERE rt.rule_id = ?
+ORDER BY g.name;
+
+-- name: ListAccountRecurringRuleTags :many
+SELECT rt.rule_id, g.name
+FROM recurring_rule_tags rt
+JOIN tags g ON g.id = rt.tag_id
+WHERE g.account_id = ?
+ORDER BY g.name;
+
+-- name: TagRuleOccurrencesFrom :exec
+INSERT IGNORE INTO transaction_tags (transaction_id, tag_id)
+SELECT t.id, rt.tag_id
+FROM transactions t
+JOIN recurring_rule_tags rt ON rt.rule_id = t.rule_id
+WHERE t.rule_id = ?
+    AND t.occurred_at >= ?;
+
+-- name: ListTransactionExternalRefs :many
+SELECT external_ref
+FROM transactions
+WHERE account_id = sqlc.arg(account_id)
+    AND external_ref IN (sqlc.slice(external_refs));
+
+-- name: ListTransactionsByIDs :many
+SELECT *
+FROM transactions
+WHERE id IN (sqlc.slice(ids));
+
+-- name: ListDedupeCandidates :many
+SELECT id, title, amount, currency, occurred_at, external_ref
+FROM transactions
+WHERE account_id = ?
+    AND rule_id IS NULL
+    AND occurred_at BETWEEN sqlc.arg(date_from) AND sqlc.arg(date_to);
+
+-- name: DismissDuplicate :exec
+INSERT IGNORE INTO duplicate_dismissals (transaction_id, duplicate_id)
+VALUES (?, ?);
+
+-- name: CopyTransactionTags :exec
+INSERT IGNORE INTO transaction_tags (transaction_id, tag_id)
+SELECT sqlc.arg(to_transaction_id), tt.tag_id
+FROM transaction_tags tt
+WHERE tt.transaction_id = sqlc.arg(from_transaction_id);
+
+-- name: FillTransactionFromDuplicate :exec
+UPDATE transactions
+SET category_id = COALESCE(category_id, sqlc.arg(category_id)),
+    notes = COALESCE(notes, sqlc.arg(notes)),
+    external_ref = COALESCE(external_ref, sqlc.arg(external_ref))
+WHERE id = sqlc.arg(id);
+
+-- name: ListOwnedAccounts :many
+SELECT *
+FROM accounts
+WHERE owner_id = ?
+ORDER BY name, id;
+
+-- name: ListUsersByIDs :many
+SELECT id, email
+FROM users
+WHERE id IN (sqlc.slice(ids));
+
+-- name: ListUsersByEmails :many
+SELECT id, email
+FROM users
+WHERE email IN (sqlc.slice(emails));
+
+-- name: ListAccountTransactions :many
+SELECT *
+FROM transactions
+WHERE account_id = ?
+ORDER BY id;
+
+-- name: ListAccountTransactionTags :many
+SELECT tt.transaction_id, g.name
+FROM transaction_tags tt
+JOIN tags g ON g.id = tt.tag_id
+WHERE g.account_id = ?
+ORDER BY g.name;
+
+-- name: ListAccountDuplicateDismissals :many
+SELECT d.transaction_id, d.duplicate_id
+FROM duplicate_dismissals d
+JOIN transactions t ON t.id = d.transaction_id
+WHERE t.account_id = ?
+ORDER BY d.transaction_id, d.duplicate_id;
+
+-- name: ImportRecurringRule :execresult
+INSERT INTO recurring_rules (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    period,
+    interval_count,
+    starts_at,
+    ends_at,
+    max_occurrences,
+    paused,
+    next_index,
+    next_occurrence_at,
+    occurrences_count,
+    created_at,
+    category_id,
+    notes
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ImportTransaction :execresult
+INSERT INTO transactions (
+    account_id,
+    user_id,
+    title,
+    amount,
+    currency,
+    occurred_at,
+    period,
+    rule_id,
+    category_id,
+    notes,
+    external_ref
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetExchangeRate :one
+SELECT *
+FROM exchange_rates
+WHERE user_id = ?
+    AND base_currency = ?
+    AND quote_currency = ?
+    AND rate_date = ?;
+
+-- name: CreateUserDeletion :exec
+INSERT INTO user_deletions (user_id, delete_at)
+VALUES (?, ?);
+
+-- name: GetUserDeletion :one
+SELECT *
+FROM user_deletions
+WHERE user_id = ?;
+
+-- name: GetUserDeletionForUpdate :one
+SELECT *
+FROM user_deletions
+WHERE user_id = ?
+FOR UPDATE;
+
+-- name: DeleteUserDeletion :execresult
+DELETE FROM user_deletions
+WHERE user_id = ?;
+
+-- name: AddUserDeletionAccount :exec
+INSERT INTO user_deletion_accounts (user_id, account_id, new_owner_id)
+VALUES (?, ?, ?);
+
+-- name: ListUserDeletionAccounts :many
+SELECT *
+FROM user_deletion_accounts
+WHERE user_id = ?;
+
+-- name: ListDueUserDeletions :many
+SELECT user_id
+FROM user_deletions
+WHERE delete_at <= ?
+ORDER BY delete_at;
@@ -1,29 +1,42 @@
-import { useQuery, queryOptions } from '@tanstack/react-query';
+import { useMemo } from 'react';
+import { useInfiniteQuery, infiniteQueryOptions } from '@tanstack/react-query';
 import {
   dataExtractionWrapper,
   getAccountsByIdTransactions,
   type GetAccountsByIdTransactionsData,
 } from '@/shared/api';
 
+type TransactionsQuery = Omit<
+  NonNullable<GetAccountsByIdTransactionsData['query']>,
+  'cursor'
+>;
+
 export const getTransactionsQueryOptions = (
   id: number,
-  query?: GetAccountsByIdTransactionsData['query'],
+  query?: TransactionsQuery,
 ) =>
-  queryOptions({
+  infiniteQueryOptions({
     queryKey: ['transactions', id, query ? JSON.stringify(query) : ''],
-    queryFn: () =>
+    queryFn: ({ pageParam }) =>
       dataExtractionWrapper(
-        getAccountsByIdTransactions({ path: { id }, query }),
+        getAccountsByIdTransactions({
+          path: { id },
+          query: { ...query, cursor: pageParam },
+        }),
       ),
+    initialPageParam: undefined as string | undefined,
+    // На последней странице next_cursor равен null - следующей страницы нет
+    getNextPageParam: (page) => page.next_cursor,
   });
 
-export const useTransactions = (
-  id: number,
-  query?: GetAccountsByIdTransactionsData['query'],
-) => {
-  const { data: transactions, ...rest } = useQuery(
+export const useTransactions = (id: number, query?: TransactionsQuery) => {
+  const { data, ...rest } = useInfiniteQuery(
     getTransactionsQueryOptions(id, query),
   );
+  const transactions = useMemo(
+    () => data?.pages.flatMap((page) => page.items),
+    [data],
+  );
 
   return { transactions, ...rest };
 };
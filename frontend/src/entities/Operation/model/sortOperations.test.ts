@@ -12,7 +12,7 @@ describe('Sort OpeationStatDatas tests', () => {
     const OpeationStatDatas: OperationStatData[] = [
       {
         date: isoDateToDate.encode(new Date()),
-        amount: 0,
+        amount: '0.00',
       },
     ];
     expect(sortOperations(OpeationStatDatas)).toStrictEqual(OpeationStatDatas);
@@ -23,11 +23,11 @@ describe('Sort OpeationStatDatas tests', () => {
     const OpeationStatDatas: OperationStatData[] = [
       {
         date: date,
-        amount: 1,
+        amount: '1.00',
       },
       {
         date: date,
-        amount: 2,
+        amount: '2.00',
       },
     ];
     expect(sortOperations(OpeationStatDatas)).toStrictEqual(OpeationStatDatas);
@@ -39,11 +39,11 @@ describe('Sort OpeationStatDatas tests', () => {
     const OpeationStatDatas: OperationStatData[] = [
       {
         date: date1,
-        amount: 1,
+        amount: '1.00',
       },
       {
         date: date2,
-        amount: 2,
+        amount: '2.00',
       },
     ];
     expect(sortOperations(OpeationStatDatas)).toStrictEqual(OpeationStatDatas);
@@ -55,11 +55,11 @@ describe('Sort OpeationStatDatas tests', () => {
     const OpeationStatDatas: OperationStatData[] = [
       {
         date: date2,
-        amount: 1,
+        amount: '1.00',
       },
       {
         date: date1,
-        amount: 2,
+        amount: '2.00',
       },
     ];
     expect(sortOperations(OpeationStatDatas)).toStrictEqual([
@@ -76,7 +76,7 @@ describe('Sort OpeationStatDatas tests', () => {
     dates.reverse();
     const expected = dates.map((date, i) => ({
       date: date,
-      amount: i,
+      amount: `${i}.00`,
     }));
     const OpeationStatDatas = [...expected];
     expected.reverse();
@@ -13,6 +13,6 @@ export const periodsLabels: Record<Period, string> = {
 export const periods = Object.keys(periodsLabels) as Period[];
 
 export type OperationStatData = {
-  amount: number;
+  amount: string;
   date: string;
 };
@@ -1,5 +1,6 @@
 import { type OperationStatData, sortOperations } from '@/entities/Operation';
 import { createWrappedStore } from '@/shared/store';
+import { amountToCents } from '@/shared/types';
 import {
   getChartDataset,
   type ChartsDatasets,
@@ -7,7 +8,7 @@ import {
 
 type AccountOperationsStoreType = {
   operations: OperationStatData[];
-  totalAmount: number;
+  totalAmount: string;
   chartsDatasets: ChartsDatasets;
 
   set: (operations: OperationStatData[]) => void;
@@ -17,7 +18,7 @@ export const useAccountOperationsStore =
   createWrappedStore<AccountOperationsStoreType>(
     (mutate) => ({
       operations: [],
-      totalAmount: 0,
+      totalAmount: '0.00',
       chartsDatasets: {
         accumulate: {
           date: [],
@@ -35,9 +36,11 @@ export const useAccountOperationsStore =
         mutate((state) => {
           state.operations.length = 0;
           state.operations = sortOperations(operations);
-          state.totalAmount = state.operations
-            .map((op) => op.amount)
-            .reduce((acc, op) => acc + op, 0);
+          state.totalAmount = amountToCents.encode(
+            state.operations
+              .map((op) => amountToCents.decode(op.amount))
+              .reduce((acc, op) => acc + op, 0),
+          );
           const accDataset = getChartDataset(state.operations, 'accumulate');
           if (accDataset) state.chartsDatasets.accumulate = accDataset;
           const sepDataset = getChartDataset(state.operations, 'separate');
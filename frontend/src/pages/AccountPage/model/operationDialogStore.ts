@@ -8,7 +8,7 @@ export type StretchedOperation = Pick<
 
 const NEW_OPERATION: StretchedOperation = {
   occurred_at: new Date().toDateString(),
-  amount: 0,
+  amount: '0.00',
   title: '',
   id: 0,
   user_id: 0,
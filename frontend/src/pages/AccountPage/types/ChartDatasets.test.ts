@@ -11,7 +11,7 @@ describe('Chart datasets accumulated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: 100,
+        amount: '100.00',
       },
     ];
     expect(getChartDataset(operations, 'accumulate')).toStrictEqual({
@@ -25,7 +25,7 @@ describe('Chart datasets accumulated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
     ];
     expect(getChartDataset(operations, 'accumulate')).toStrictEqual({
@@ -39,11 +39,11 @@ describe('Chart datasets accumulated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
       {
         date: '2025-11-11',
-        amount: 100,
+        amount: '100.00',
       },
     ];
     expect(getChartDataset(operations, 'accumulate')).toStrictEqual({
@@ -57,27 +57,27 @@ describe('Chart datasets accumulated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
       {
         date: '2025-11-12',
-        amount: 100,
+        amount: '100.00',
       },
       {
         date: '2025-11-13',
-        amount: -1000,
+        amount: '-1000.00',
       },
       {
         date: '2025-11-14',
-        amount: 2000,
+        amount: '2000.00',
       },
       {
         date: '2025-11-15',
-        amount: 100,
+        amount: '100.00',
       },
       {
         date: '2025-11-19',
-        amount: -1,
+        amount: '-1.00',
       },
     ];
     expect(getChartDataset(operations, 'accumulate')).toStrictEqual({
@@ -93,6 +93,32 @@ describe('Chart datasets accumulated tests', () => {
       outcome: [100, 100, 1100, 1100, 1100, 1101],
     });
   });
+
+  test('Fractional accumulated', () => {
+    const operations: OperationStatData[] = [
+      {
+        date: '2025-11-11',
+        amount: '0.10',
+      },
+      {
+        date: '2025-11-11',
+        amount: '0.20',
+      },
+      {
+        date: '2025-11-12',
+        amount: '-0.30',
+      },
+      {
+        date: '2025-11-12',
+        amount: '-0.00',
+      },
+    ];
+    expect(getChartDataset(operations, 'accumulate')).toStrictEqual({
+      date: ['11.11.2025', '12.11.2025'],
+      income: [0.3, 0.3],
+      outcome: [0, 0.3],
+    });
+  });
 });
 
 describe('Chart datasets separated tests', () => {
@@ -104,7 +130,7 @@ describe('Chart datasets separated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: 100,
+        amount: '100.00',
       },
     ];
     expect(getChartDataset(operations, 'separate')).toStrictEqual({
@@ -118,7 +144,7 @@ describe('Chart datasets separated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
     ];
     expect(getChartDataset(operations, 'separate')).toStrictEqual({
@@ -132,11 +158,11 @@ describe('Chart datasets separated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
       {
         date: '2025-11-11',
-        amount: 100,
+        amount: '100.00',
       },
     ];
     expect(getChartDataset(operations, 'separate')).toStrictEqual({
@@ -150,27 +176,27 @@ describe('Chart datasets separated tests', () => {
     const operations: OperationStatData[] = [
       {
         date: '2025-11-11',
-        amount: -100,
+        amount: '-100.00',
       },
       {
         date: '2025-11-12',
-        amount: 100,
+        amount: '100.00',
       },
       {
         date: '2025-11-13',
-        amount: -1000,
+        amount: '-1000.00',
       },
       {
         date: '2025-11-14',
-        amount: 2000,
+        amount: '2000.00',
       },
       {
         date: '2025-11-15',
-        amount: 100,
+        amount: '100.00',
       },
       {
         date: '2025-11-19',
-        amount: -1,
+        amount: '-1.00',
       },
     ];
     expect(getChartDataset(operations, 'separate')).toStrictEqual({
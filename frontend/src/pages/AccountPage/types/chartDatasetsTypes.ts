@@ -1,5 +1,5 @@
 import type { OperationStatData } from '@/entities/Operation';
-import { isoDateToDate } from '@/shared/types';
+import { amountToCents, isoDateToDate } from '@/shared/types';
 
 type ChartVariants = 'accumulate' | 'separate';
 
@@ -21,8 +21,10 @@ export const getChartDataset = (
     income: [],
     outcome: [],
   };
+  // Суммы копятся в копейках и переводятся в рубли в конце
   for (const operation of operations) {
-    if (operation.amount === 0) continue;
+    const amount = amountToCents.decode(operation.amount);
+    if (amount === 0) continue;
 
     const opDate = isoDateToDate
       .decode(operation.date.split('T')[0])
@@ -31,9 +33,8 @@ export const getChartDataset = (
     const len = preData.date.length;
     const lastDate = preData.date.at(-1);
     if (lastDate === opDate) {
-      if (operation.amount > 0) preData.income[len - 1] += operation.amount;
-      else if (operation.amount < 0)
-        preData.outcome[len - 1] -= operation.amount;
+      if (amount > 0) preData.income[len - 1] += amount;
+      else if (amount < 0) preData.outcome[len - 1] -= amount;
     } else {
       // Сразу и первый элемент добавит
       let income = 0;
@@ -42,12 +43,14 @@ export const getChartDataset = (
         income = preData.income[len - 1];
         outcome = preData.outcome[len - 1];
       }
-      income += Math.max(operation.amount, 0);
-      outcome += -Math.min(operation.amount, 0);
+      income += Math.max(amount, 0);
+      outcome += -Math.min(amount, 0);
       preData.date.push(opDate);
       preData.income.push(income);
       preData.outcome.push(outcome);
     }
   }
+  preData.income = preData.income.map((cents) => cents / 100);
+  preData.outcome = preData.outcome.map((cents) => cents / 100);
   return preData;
 };
@@ -1,8 +1,12 @@
 import * as z from 'zod';
 
+import { AMOUNT_PATTERN } from '@/shared/types';
+
 export const operationCreateSchema = z.object({
   title: z.string('Название обязательно'),
-  amount: z.coerce.number<number>('Сумма обязательна'),
+  amount: z
+    .string('Сумма обязательна')
+    .regex(AMOUNT_PATTERN, 'Не больше двух знаков после точки'),
   occurred_at: z.iso.date('Некорректная дата'),
   period: z
     .union([
@@ -18,7 +22,9 @@ export type OperationCreateFormType = z.infer<typeof operationCreateSchema>;
 
 export const operationEditSchema = z.object({
   title: z.string('Название обязательно'),
-  amount: z.coerce.number<number>('Сумма обязательна'),
+  amount: z
+    .string('Сумма обязательна')
+    .regex(AMOUNT_PATTERN, 'Не больше двух знаков после точки'),
   occurred_at: z.iso.date('Некорректная дата'),
 });
 
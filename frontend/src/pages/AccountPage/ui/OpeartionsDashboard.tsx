@@ -2,7 +2,7 @@ import clsx from 'clsx';
 import { useEffect, useState, type HTMLAttributes } from 'react';
 import type React from 'react';
 
-import { Button } from '@/shared/ui';
+import { Button, Loader } from '@/shared/ui';
 import { OperationsCommonStats } from './OperationsCommonStats';
 import { OperationsTable } from './OperationsTable';
 import { useAccountOperationsStore } from '../model';
@@ -24,11 +24,12 @@ export const OpeartionsDashboard: React.FC<OpeartionsDashboardProps> = ({
   const [dateFrom, setDateFrom] = useState<string>();
   const [dateTo, setDateTo] = useState<string>();
   const [type, setType] = useState<'income' | 'expense'>();
-  const { transactions } = useTransactions(accountId, {
-    date_from: dateFrom && isoDateToDate.decode(dateFrom).toISOString(),
-    date_to: dateTo && isoDateToDate.decode(dateTo).toISOString(),
-    type,
-  });
+  const { transactions, hasNextPage, fetchNextPage, isFetchingNextPage } =
+    useTransactions(accountId, {
+      date_from: dateFrom && isoDateToDate.decode(dateFrom).toISOString(),
+      date_to: dateTo && isoDateToDate.decode(dateTo).toISOString(),
+      type,
+    });
   const [sortedTransactions, setSortedTransactions] = useState<
     HandlersTransactionResponse[]
   >([]);
@@ -169,6 +170,15 @@ export const OpeartionsDashboard: React.FC<OpeartionsDashboardProps> = ({
         accountId={accountId}
         transactions={sortedTransactions}
       />
+      {hasNextPage && (
+        <Button
+          className="w-full mt-2 p-2"
+          disabled={isFetchingNextPage}
+          onClick={() => fetchNextPage()}
+        >
+          {isFetchingNextPage ? <Loader /> : 'Показать ещё'}
+        </Button>
+      )}
     </div>
   );
 };
@@ -16,7 +16,12 @@ import { useEffect, useMemo, useState } from 'react';
 import { useForm, useWatch } from 'react-hook-form';
 
 import { useOperationDialogStore } from '../model';
-import { Button, ErrorMessage, Loader } from '@/shared/ui';
+import {
+  Button,
+  ErrorMessage,
+  Loader,
+  getAmountColorClass,
+} from '@/shared/ui';
 import {
   operationCreateSchema,
   operationEditSchema,
@@ -154,9 +159,7 @@ export const OperationDialogWindow: React.FC = () => {
                 <span
                   className={clsx(
                     'font-mono p-1',
-                    operation.amount > 0 && 'bg-green-300',
-                    operation.amount === 0 && 'bg-yellow-200',
-                    operation.amount < 0 && 'bg-red-300',
+                    getAmountColorClass(operation.amount),
                   )}
                 >
                   {operation.amount}
@@ -222,9 +225,7 @@ export const OperationDialogWindow: React.FC = () => {
                   type="number"
                   className={clsx(
                     'font-mono p-1 bg-gray-100 transition-base',
-                    amountCreate > 0 && 'bg-green-300',
-                    amountCreate === 0 && 'bg-yellow-200',
-                    amountCreate < 0 && 'bg-red-300',
+                    getAmountColorClass(amountCreate),
                   )}
                   {...createRegister('amount')}
                 />
@@ -313,9 +314,7 @@ export const OperationDialogWindow: React.FC = () => {
                   type="number"
                   className={clsx(
                     'font-mono p-1 bg-gray-100 transition-base',
-                    amountEdit > 0 && 'bg-green-300',
-                    amountEdit === 0 && 'bg-yellow-200',
-                    amountEdit < 0 && 'bg-red-300',
+                    getAmountColorClass(amountEdit),
                   )}
                   {...editRegister('amount')}
                 />
@@ -4,6 +4,7 @@ import { ChevronDownIcon } from '@heroicons/react/20/solid';
 
 import clsx from 'clsx';
 import { Button } from '@/shared/ui/Button';
+import { getAmountColorClass } from '@/shared/ui/getAmountColorClass';
 import { OperationsChart } from './OperationsChart';
 import { useAccountOperationsStore } from '../model';
 
@@ -23,12 +24,7 @@ export const OperationsCommonStats: React.FC<OperationsCommonStatsProps> = ({
       <div className="flex gap-2 justify-start items-center">
         <span>Итого:</span>
         <span
-          className={clsx(
-            'font-mono p-1',
-            totalAmount > 0 && 'bg-green-300',
-            totalAmount === 0 && 'bg-yellow-200',
-            totalAmount < 0 && 'bg-red-300',
-          )}
+          className={clsx('font-mono p-1', getAmountColorClass(totalAmount))}
         >
           {totalAmount}
         </span>
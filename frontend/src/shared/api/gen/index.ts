@@ -80,6 +80,7 @@ export type {
   HandlersMessageResponse,
   HandlersRegisterRequest,
   HandlersTokenResponse,
+  HandlersTransactionListResponse,
   HandlersTransactionResponse,
   HandlersUpdateTransactionRequest,
   HandlersUserProfileResponse,
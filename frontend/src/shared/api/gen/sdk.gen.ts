@@ -216,7 +216,7 @@ export const patchAccountsByIdMembersByUserId = <
 /**
  * Список транзакций с фильтрацией
  *
- * Возвращает список транзакций счёта с возможностью фильтрации. Доступно всем участникам счёта (включая Viewer). Фильтры: date_from/date_to (временной диапазон в RFC3339), type (income/expense для доходов/расходов), user_id (транзакции конкретного пользователя). Все фильтры опциональны и могут комбинироваться. Возвращаются все транзакции (включая периодические), соответствующие фильтрам, отсортированные по дате (новые первыми).
+ * Возвращает список транзакций счёта с возможностью фильтрации. Доступно всем участникам счёта (включая Viewer); участники без права tx.view.any видят только свои транзакции. Фильтры: date_from/date_to (временной диапазон в RFC3339), type (income/expense для доходов/расходов), user_id (транзакции конкретного пользователя), category_id (категория вместе со всеми подкатегориями), tags (any:a,b - хотя бы одна из меток, all:a,b - все метки), q (подстрока названия или примечания, без учёта регистра; символы % и _ ищутся буквально), amount_min/amount_max (границы суммы со знаком включительно: расходы от 1000 до 5000 - amount_min=-5000&amount_max=-1000), exclude_planned (без вхождений серий, дата которых ещё не наступила). Все фильтры опциональны и могут комбинироваться. Возвращаются транзакции (включая вхождения периодических серий до горизонта планирования), соответствующие фильтрам, постранично: не более limit транзакций (по умолчанию 100, максимум 500). Если есть следующая страница, в next_cursor возвращается курсор: запрос с тем же фильтром и cursor=next_cursor вернёт следующую страницу. Курсор указывает на последнюю выданную транзакцию, поэтому новые транзакции не сдвигают страницы. sort задаёт поле сортировки (occurred_at, amount или title), order - направление (по умолчанию desc: новые, крупные или последние по алфавиту первыми); при равных значениях транзакции упорядочиваются по ID. Курсор действует только для того порядка сортировки, в котором он выдан; sort и order можно не повторять. У вхождений серий заполнено поле rule_id. При running_balance=true у каждой транзакции возвращается остаток счёта сразу после неё (начальный остаток плюс все транзакции счёта по порядку даты), фильтры на него не влияют.
  */
 export const getAccountsByIdTransactions = <
   ThrowOnError extends boolean = false,
@@ -32,7 +32,7 @@ export type HandlersCreateAccountRequest = {
 };
 
 export type HandlersCreateTransactionRequest = {
-  amount: number;
+  amount: string;
   occurred_at?: string;
   period?: 'day' | 'week' | 'month' | 'year';
   title: string;
@@ -82,18 +82,35 @@ export type HandlersTokenResponse = {
   access_token: string;
 };
 
+export type HandlersTransactionListResponse = {
+  items: Array<HandlersTransactionResponse>;
+  /**
+   * Курсор следующей страницы, null на последней странице
+   */
+  next_cursor?: string;
+};
+
 export type HandlersTransactionResponse = {
   account_id: number;
-  amount: number;
+  amount: string;
+  category_id?: number;
+  currency: string;
   id: number;
+  notes?: string;
   occurred_at: string;
   period?: string;
+  rule_id?: number;
+  /**
+   * Остаток счёта сразу после транзакции, только при running_balance=true
+   */
+  running_balance?: string;
+  tags: Array<string>;
   title: string;
-  user_id: number;
+  user_id?: number;
 };
 
 export type HandlersUpdateTransactionRequest = {
-  amount: number;
+  amount: string;
   occurred_at: string;
   title: string;
 };
@@ -491,13 +508,57 @@ export type GetAccountsByIdTransactionsData = {
      * Фильтр по ID пользователя (создателя транзакции)
      */
     user_id?: number;
+    /**
+     * Фильтр по категории, включая подкатегории
+     */
+    category_id?: number;
+    /**
+     * Фильтр по меткам: any:метка1,метка2 или all:метка1,метка2
+     */
+    tags?: string;
+    /**
+     * Поиск подстроки в названии и примечании без учёта регистра, до 100 символов
+     */
+    q?: string;
+    /**
+     * Нижняя граница суммы со знаком, включительно
+     */
+    amount_min?: string;
+    /**
+     * Верхняя граница суммы со знаком, включительно
+     */
+    amount_max?: string;
+    /**
+     * Исключить ещё не наступившие вхождения периодических серий
+     */
+    exclude_planned?: boolean;
+    /**
+     * Добавить остаток счёта после каждой транзакции
+     */
+    running_balance?: boolean;
+    /**
+     * Поле сортировки (по умолчанию occurred_at)
+     */
+    sort?: 'occurred_at' | 'amount' | 'title';
+    /**
+     * Направление сортировки (по умолчанию desc)
+     */
+    order?: 'asc' | 'desc';
+    /**
+     * Размер страницы, от 1 до 500 (по умолчанию 100)
+     */
+    limit?: number;
+    /**
+     * Курсор следующей страницы из next_cursor предыдущего ответа
+     */
+    cursor?: string;
   };
   url: '/accounts/{id}/transactions';
 };
 
 export type GetAccountsByIdTransactionsErrors = {
   /**
-   * Неверные параметры фильтрации. Проверьте формат дат и значение type
+   * Неверные параметры фильтрации. Проверьте формат дат, значение type, category_id (категория должна принадлежать счёту), tags, q, amount_min/amount_max (amount_min не больше amount_max), sort, order, limit и cursor
    */
   400: HandlersErrorResponse;
   /**
@@ -505,7 +566,7 @@ export type GetAccountsByIdTransactionsErrors = {
    */
   401: HandlersErrorResponse;
   /**
-   * Пользователь не является участником данного счёта
+   * Пользователь не является участником данного счёта или у него нет нужного права
    */
   403: HandlersErrorResponse;
   /**
@@ -519,9 +580,9 @@ export type GetAccountsByIdTransactionsError =
 
 export type GetAccountsByIdTransactionsResponses = {
   /**
-   * Список транзакций, соответствующих фильтрам. Пустой массив если транзакций нет
+   * Страница списка транзакций, соответствующих фильтрам. Пустой items если транзакций нет
    */
-  200: Array<HandlersTransactionResponse>;
+  200: HandlersTransactionListResponse;
 };
 
 export type GetAccountsByIdTransactionsResponse =
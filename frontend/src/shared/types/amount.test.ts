@@ -0,0 +1,40 @@
+import { amountToCents } from './amount';
+import { describe, expect, test } from 'vitest';
+
+describe('Amount to cents tests', () => {
+  test('Decode', () => {
+    expect(amountToCents.decode('0')).toBe(0);
+    expect(amountToCents.decode('0.00')).toBe(0);
+    expect(amountToCents.decode('-0')).toBe(0);
+    expect(amountToCents.decode('0.1')).toBe(10);
+    expect(amountToCents.decode('-0.01')).toBe(-1);
+    expect(amountToCents.decode('1500.50')).toBe(150050);
+    expect(amountToCents.decode('-1500.50')).toBe(-150050);
+    expect(amountToCents.decode('+42')).toBe(4200);
+    expect(amountToCents.decode('9999999999.99')).toBe(999999999999);
+  });
+
+  test('Decode invalid', () => {
+    expect(amountToCents.safeDecode('').success).toBe(false);
+    expect(amountToCents.safeDecode('-').success).toBe(false);
+    expect(amountToCents.safeDecode('10.').success).toBe(false);
+    expect(amountToCents.safeDecode('10.555').success).toBe(false);
+    expect(amountToCents.safeDecode('1e3').success).toBe(false);
+  });
+
+  test('Encode', () => {
+    expect(amountToCents.encode(0)).toBe('0.00');
+    expect(amountToCents.encode(1)).toBe('0.01');
+    expect(amountToCents.encode(-1)).toBe('-0.01');
+    expect(amountToCents.encode(150050)).toBe('1500.50');
+    expect(amountToCents.encode(-150050)).toBe('-1500.50');
+  });
+
+  test('Sum without float error', () => {
+    const total = ['0.10', '0.20'].reduce(
+      (acc, amount) => acc + amountToCents.decode(amount),
+      0,
+    );
+    expect(amountToCents.encode(total)).toBe('0.30');
+  });
+});
@@ -0,0 +1,24 @@
+import * as z from 'zod';
+
+export const AMOUNT_PATTERN = /^[-+]?\d+(\.\d{1,2})?$/;
+
+// Сервер отдаёт суммы десятичными строками ("-1500.50"). Считать и сравнивать
+// их нужно в целых копейках, иначе float теряет точность
+export const amountToCents = z.codec(
+  z.string().regex(AMOUNT_PATTERN),
+  z.int(),
+  {
+    decode: (amount) => {
+      const negative = amount.startsWith('-');
+      const [whole, fraction = ''] = amount.replace(/^[-+]/, '').split('.');
+      const cents = Number(whole) * 100 + Number(fraction.padEnd(2, '0'));
+      if (negative && cents !== 0) return -cents;
+      return cents;
+    },
+    encode: (cents) => {
+      const abs = Math.abs(cents);
+      const fraction = String(abs % 100).padStart(2, '0');
+      return `${cents < 0 ? '-' : ''}${Math.floor(abs / 100)}.${fraction}`;
+    },
+  },
+);
@@ -10,3 +10,5 @@ export const isoDateToDate = z.codec(z.iso.date(), z.date(), {
   decode: (isoString) => new Date(isoString),
   encode: (date) => date.toISOString().split('T')[0],
 });
+
+export { AMOUNT_PATTERN, amountToCents } from './amount';
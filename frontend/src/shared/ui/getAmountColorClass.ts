@@ -1,5 +1,9 @@
-export const getAmountColorClass = (amount: number) => {
-  if (amount > 0) return 'bg-green-300';
-  if (amount === 0) return 'bg-yellow-200';
+import { amountToCents } from '@/shared/types';
+
+export const getAmountColorClass = (amount: string) => {
+  const cents = amountToCents.safeDecode(amount);
+  if (!cents.success) return;
+  if (cents.data > 0) return 'bg-green-300';
+  if (cents.data === 0) return 'bg-yellow-200';
   return 'bg-red-300';
 };